@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/nativeblocks/nbx"
+)
+
+func runCodegen(args []string) error {
+	fs := flag.NewFlagSet("codegen", flag.ContinueOnError)
+	lang := fs.String("lang", "", "target language: kotlin, swift, typescript or go")
+	pkg := fs.String("package", "", "package of the generated file (kotlin, go)")
+	blocks := fs.String("blocks", "", "blocks.json integration registry")
+	actions := fs.String("actions", "", "actions.json integration registry")
+	output := fs.String("o", "", "output file (defaults to stdout)")
+	if err := _parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	options := nbx.CodegenOptions{Package: *pkg}
+	if *blocks != "" || *actions != "" {
+		registry, err := _readRegistry(*blocks, *actions)
+		if err != nil {
+			return err
+		}
+		options.Registry = registry
+	}
+
+	frames := make([]nbx.FrameDSLModel, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		frame, err := _readFrame(path)
+		if err != nil {
+			return err
+		}
+		frames = append(frames, frame)
+	}
+
+	code, errs := nbx.GenerateCode(frames, *lang, options)
+	if len(errs) > 0 {
+		return _errorOf(errs)
+	}
+	return _writeOutput(*output, code)
+}
+
+// _readRegistry loads the integration registry. A missing file is treated as an empty registry.
+func _readRegistry(blocksPath, actionsPath string) (*nbx.IntegrationRegistry, error) {
+	blocksJSON, actionsJSON := "{}", "{}"
+	if blocksPath != "" {
+		content, err := os.ReadFile(blocksPath)
+		if err != nil {
+			return nil, err
+		}
+		blocksJSON = string(content)
+	}
+	if actionsPath != "" {
+		content, err := os.ReadFile(actionsPath)
+		if err != nil {
+			return nil, err
+		}
+		actionsJSON = string(content)
+	}
+
+	registry, errs := nbx.LoadIntegrations(blocksJSON, actionsJSON)
+	if len(errs) > 0 {
+		return nil, _errorOf(errs)
+	}
+	return registry, nil
+}
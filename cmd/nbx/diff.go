@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/nativeblocks/nbx"
+)
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print changes as JSON")
+	if err := _parseFlags(fs, args, 2); err != nil {
+		return err
+	}
+
+	a, err := _readFrame(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := _readFrame(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	changes := nbx.Diff(a, b)
+	if *asJSON {
+		content, err := changes.JSON()
+		if err != nil {
+			return err
+		}
+		return _writeOutput("", content+"\n")
+	}
+	return _writeOutput("", changes.Text())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nativeblocks/nbx"
+)
+
+func runI18n(args []string) error {
+	if len(args) == 0 || args[0] != "extract" {
+		return fmt.Errorf("expected the subcommand 'extract'")
+	}
+
+	fs := flag.NewFlagSet("i18n extract", flag.ContinueOnError)
+	locale := fs.String("locale", "en", "locale of the extracted strings")
+	output := fs.String("o", "", "bundle file to write; an existing bundle is extended (defaults to stdout)")
+	write := fs.Bool("w", false, "rewrite the frame files to reference the extracted strings")
+	if err := _parseFlags(fs, args[1:], 1); err != nil {
+		return err
+	}
+
+	bundle := nbx.NewBundle(*locale)
+	if *output != "" {
+		content, err := os.ReadFile(*output)
+		switch {
+		case err == nil:
+			var errs nbx.Errors
+			if bundle, errs = nbx.LoadBundle(*output, string(content)); len(errs) > 0 {
+				return _errorOf(errs)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+	}
+
+	files, err := _frameFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, file := range files {
+		frame, err := _readFrame(file)
+		if err != nil {
+			return err
+		}
+		extractions := nbx.ExtractStrings(&frame, bundle)
+		for _, extraction := range extractions {
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: @string.%s = %q\n", file, extraction.Line, extraction.Column, extraction.Key, extraction.Value)
+		}
+		count += len(extractions)
+
+		if *write && len(extractions) > 0 {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			formatted := nbx.FormatFrameDSL(frame)
+			if nbx.DetectFormat(string(content)) == "xml" {
+				formatted = nbx.FormatFrameXML(frame)
+			}
+			if err := _writeOutput(file, formatted); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Extracted %d string(s) from %d frame(s)\n", count, len(files))
+
+	return _writeOutput(*output, bundle.JSON())
+}
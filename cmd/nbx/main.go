@@ -0,0 +1,129 @@
+// Command nbx is a command line tool for working with NBX frames.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nativeblocks/nbx"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"codegen":      {usage: "codegen -lang kotlin|swift|typescript|go [-package name] [-blocks blocks.json] [-actions actions.json] [-o out] [<frame>...]", run: runCodegen},
+	"diff":         {usage: "diff [-json] <old frame> <new frame>", run: runDiff},
+	"i18n":         {usage: "i18n extract [-locale en] [-o bundle.json] [-w] <frame|dir>...", run: runI18n},
+	"merge-driver": {usage: "merge-driver [-path file] <base> <ours> <theirs>", run: runMergeDriver},
+	"preview":      {usage: "preview [-device mobile|tablet|desktop] [-theme theme.json] [-o out.html] <frame>", run: runPreview},
+	"project":      {usage: "project [-json | -dot] [-o out] <dir>", run: runProject},
+	"query":        {usage: "query [-json] <selector> <frame|dir>...", run: runQuery},
+	"rename":       {usage: "rename [-block] [-w] <frame> <old> <new>", run: runRename},
+	"wireframe":    {usage: "wireframe [-device mobile|tablet|desktop] [-o out.svg|dir] <frame>", run: runWireframe},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		_usage()
+		os.Exit(2)
+	}
+
+	cmd, exists := commands[os.Args[1]]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "nbx: unknown command %q\n\n", os.Args[1])
+		_usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "nbx %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func _usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: nbx <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  nbx %s\n", commands[name].usage)
+	}
+}
+
+// _readFrame parses a DSL or XML frame file and the libraries it imports. Warnings are printed to
+// stderr, errors are returned.
+func _readFrame(path string) (nbx.FrameDSLModel, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nbx.FrameDSLModel{}, err
+	}
+
+	root, name, err := _fileSystemOf(path)
+	if err != nil {
+		return nbx.FrameDSLModel{}, err
+	}
+	frame, errs := nbx.ParseFS(os.DirFS(root), name)
+	if errs.HasErrors() {
+		return nbx.FrameDSLModel{}, _errorOf(errs)
+	}
+	if len(errs) > 0 {
+		fmt.Fprint(os.Stderr, errs.FormatAll())
+	}
+	return frame, nil
+}
+
+// _fileSystemOf returns the directory to resolve imports in and the frame's path inside it: the working
+// directory when it contains the frame, the file system root otherwise.
+func _fileSystemOf(path string) (root, name string, err error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+	if rel, err := filepath.Rel(wd, abs); err == nil && filepath.IsLocal(rel) {
+		return wd, filepath.ToSlash(rel), nil
+	}
+	root = filepath.VolumeName(abs) + string(filepath.Separator)
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", "", err
+	}
+	return root, filepath.ToSlash(rel), nil
+}
+
+func _errorOf(errs nbx.Errors) error {
+	return errors.New(errs.FormatAll())
+}
+
+// _writeOutput writes content to path, or to stdout when path is empty.
+func _writeOutput(path, content string) error {
+	if path == "" {
+		_, err := fmt.Fprint(os.Stdout, content)
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+func _parseFlags(fs *flag.FlagSet, args []string, minArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < minArgs {
+		return fmt.Errorf("expected %d argument(s), got %d", minArgs, fs.NArg())
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/nativeblocks/nbx"
+)
+
+// runMergeDriver implements a git merge driver. Register it with:
+//
+//	git config merge.nbx.driver "nbx merge-driver -path %P %O %A %B"
+//	echo "*.nbx merge=nbx" >> .gitattributes
+//
+// git passes the three versions as temporary files; -path is the file's path in the work tree, which
+// imports are resolved from. The merged frame is written to the "ours" file. Conflicts keep our side,
+// are printed to stderr and make the driver exit with status 1 so git reports the file as conflicted.
+// When a version cannot be parsed, the "ours" file is left as it is and the merge is reported as a
+// conflict.
+func runMergeDriver(args []string) error {
+	fs := flag.NewFlagSet("merge-driver", flag.ContinueOnError)
+	path := fs.String("path", "", "path of the merged file in the work tree, to resolve imports from")
+	if err := _parseFlags(fs, args, 3); err != nil {
+		return err
+	}
+	if *path == "" {
+		*path = fs.Arg(1)
+	}
+
+	var frames [3]nbx.FrameDSLModel
+	for i, side := range []string{"base", "ours", "theirs"} {
+		frame, errs, err := _readMergeSide(fs.Arg(i), *path)
+		if err != nil {
+			return err
+		}
+		// A frame that parsed has a source position; otherwise there is nothing to merge.
+		if frame.Line == 0 {
+			fmt.Fprintf(os.Stderr, "conflict at frame\n  %s of %s cannot be parsed:\n%s", side, *path, errs.FormatAll())
+			return fmt.Errorf("%s of %s cannot be parsed, left our side unmerged", side, *path)
+		}
+		frames[i] = frame
+	}
+
+	merged, conflicts := nbx.Merge(frames[0], frames[1], frames[2])
+
+	content, err := os.ReadFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	output := nbx.FormatFrameDSL(merged)
+	if nbx.DetectFormat(string(content)) == "xml" {
+		output = nbx.FormatFrameXML(merged)
+	}
+	if err := _writeOutput(fs.Arg(1), output); err != nil {
+		return err
+	}
+
+	for _, conflict := range conflicts {
+		fmt.Fprintf(os.Stderr, "conflict at %s\n  ours:   %s\n  theirs: %s\n", conflict.Path, conflict.Ours, conflict.Theirs)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%d conflict(s), kept our side", len(conflicts))
+	}
+	return nil
+}
+
+// _readMergeSide parses the version of a frame in file as if it were at path, so its imports resolve
+// from the frame's place in the work tree. Parse and validation errors are returned in errs; the frame
+// is the zero value when it cannot be parsed. err is set when file cannot be read.
+func _readMergeSide(file, path string) (frame nbx.FrameDSLModel, errs nbx.Errors, err error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nbx.FrameDSLModel{}, nil, err
+	}
+	root, name, err := _fileSystemOf(path)
+	if err != nil {
+		return nbx.FrameDSLModel{}, nil, err
+	}
+	frame, errs = nbx.ParseFS(_overlayFS{FS: os.DirFS(root), name: name, content: content}, name)
+	return frame, errs, nil
+}
+
+// _overlayFS reads content for the file at name and every other file from the wrapped file system.
+type _overlayFS struct {
+	fs.FS
+	name    string
+	content []byte
+}
+
+func (o _overlayFS) ReadFile(name string) ([]byte, error) {
+	if name == o.name {
+		return o.content, nil
+	}
+	return fs.ReadFile(o.FS, name)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/nativeblocks/nbx"
+)
+
+func runPreview(args []string) error {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	device := fs.String("device", "mobile", "device class: mobile, tablet or desktop")
+	output := fs.String("o", "", "output file (defaults to stdout)")
+	themeFile := fs.String("theme", "", "theme JSON file to resolve @group.name tokens with")
+	if err := _parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	frame, err := _readFrame(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *themeFile != "" {
+		content, err := os.ReadFile(*themeFile)
+		if err != nil {
+			return err
+		}
+		theme, errs := nbx.LoadTheme(string(content))
+		if len(errs) > 0 {
+			return _errorOf(errs)
+		}
+		if errs := nbx.ResolveTokens(&frame, theme); len(errs) > 0 {
+			return _errorOf(errs)
+		}
+	}
+
+	page, errs := nbx.PreviewHTML(frame, *device)
+	if len(errs) > 0 {
+		return _errorOf(errs)
+	}
+	return _writeOutput(*output, page)
+}
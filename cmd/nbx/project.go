@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nativeblocks/nbx"
+)
+
+func runProject(args []string) error {
+	fs := flag.NewFlagSet("project", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the navigation graph as JSON")
+	asDot := fs.Bool("dot", false, "print the navigation graph in Graphviz dot format")
+	output := fs.String("o", "", "output file for the navigation graph (defaults to stdout)")
+	if err := _parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	project, errs := nbx.LoadProject(os.DirFS(fs.Arg(0)))
+	if project == nil {
+		return _errorOf(errs)
+	}
+
+	var graph string
+	switch {
+	case *asJSON:
+		edges := project.Edges
+		if edges == nil {
+			edges = []nbx.NavigationEdge{}
+		}
+		content, err := json.MarshalIndent(edges, "", "  ")
+		if err != nil {
+			return err
+		}
+		graph = string(content) + "\n"
+	case *asDot:
+		graph = _navigationDot(project)
+	default:
+		var b strings.Builder
+		for _, edge := range project.Edges {
+			to := edge.To
+			if to == "" {
+				to = "?"
+			}
+			fmt.Fprintf(&b, "%s -> %s (%s) %s:%d:%d\n", edge.From, to, edge.Route, edge.File, edge.Line, edge.Column)
+		}
+		graph = b.String()
+	}
+	if err := _writeOutput(*output, graph); err != nil {
+		return err
+	}
+
+	if errs.HasErrors() {
+		return _errorOf(errs)
+	}
+	if len(errs) > 0 {
+		fmt.Fprint(os.Stderr, errs.FormatAll())
+	}
+	return nil
+}
+
+func _navigationDot(project *nbx.Project) string {
+	var b strings.Builder
+	b.WriteString("digraph navigation {\n")
+	for _, frame := range project.Frames {
+		shape := "box"
+		if frame.Frame.Starter {
+			shape = "doublecircle"
+		}
+		fmt.Fprintf(&b, "  %q [shape=%s, tooltip=%q];\n", frame.Frame.Name, shape, frame.Frame.Route)
+	}
+	for _, edge := range project.Edges {
+		if edge.To == "" {
+			fmt.Fprintf(&b, "  %q -> %q [style=dashed, color=red];\n", edge.From, edge.Route)
+			continue
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Trigger)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
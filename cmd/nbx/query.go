@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nativeblocks/nbx"
+)
+
+type queryResult struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+	Path   string `json:"path"`
+}
+
+func runQuery(args []string) error {
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print matches as JSON")
+	if err := _parseFlags(flags, args, 2); err != nil {
+		return err
+	}
+
+	selector, errs := nbx.CompileSelector(flags.Arg(0))
+	if len(errs) > 0 {
+		return _errorOf(errs)
+	}
+
+	files, err := _frameFiles(flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+
+	results := []queryResult{}
+	failed := 0
+	for _, file := range files {
+		frame, err := _readFrame(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			failed++
+			continue
+		}
+		for _, match := range selector.Match(&frame) {
+			results = append(results, queryResult{
+				File:   file,
+				Line:   match.Line,
+				Column: match.Column,
+				Kind:   string(match.Node.Kind),
+				Key:    match.Node.Key(),
+				Path:   match.Path,
+			})
+		}
+	}
+
+	if *asJSON {
+		content, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(content))
+	} else {
+		for _, result := range results {
+			fmt.Printf("%s:%d:%d: %s %s\n", result.File, result.Line, result.Column, result.Kind, result.Path)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d file(s) could not be parsed", failed, len(files))
+	}
+	return nil
+}
+
+// _frameFiles expands directories into the .nbx and .xml frame files they contain, skipping libraries.
+func _frameFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(file))
+			if !entry.IsDir() && (ext == ".nbx" || ext == ".xml") && !_isLibrary(file) {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func _isLibrary(file string) bool {
+	content, err := os.ReadFile(file)
+	return err == nil && nbx.IsLibrary(string(content))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/nativeblocks/nbx"
+)
+
+func runRename(args []string) error {
+	fs := flag.NewFlagSet("rename", flag.ContinueOnError)
+	block := fs.Bool("block", false, "rename a block key instead of a variable")
+	write := fs.Bool("w", false, "write the result back to the frame file instead of stdout")
+	if err := _parseFlags(fs, args, 3); err != nil {
+		return err
+	}
+
+	path, oldKey, newKey := fs.Arg(0), fs.Arg(1), fs.Arg(2)
+	frame, err := _readFrame(path)
+	if err != nil {
+		return err
+	}
+
+	var errs nbx.Errors
+	if *block {
+		errs = nbx.RenameBlockKey(&frame, oldKey, newKey)
+	} else {
+		errs = nbx.RenameVariable(&frame, oldKey, newKey)
+	}
+	if len(errs) > 0 {
+		return _errorOf(errs)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var output string
+	if nbx.DetectFormat(string(content)) == "xml" {
+		output = nbx.FormatFrameXML(frame)
+	} else {
+		output = nbx.FormatFrameDSL(frame)
+	}
+
+	if *write {
+		return _writeOutput(path, output)
+	}
+	return _writeOutput("", output)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/nativeblocks/nbx"
+)
+
+func runWireframe(args []string) error {
+	fs := flag.NewFlagSet("wireframe", flag.ContinueOnError)
+	device := fs.String("device", "", "device class: mobile, tablet or desktop (defaults to all)")
+	output := fs.String("o", "", "output file for a single device, or output directory for all devices")
+	if err := _parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	frame, err := _readFrame(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *device != "" {
+		svg, errs := nbx.WireframeSVG(frame, *device)
+		if len(errs) > 0 {
+			return _errorOf(errs)
+		}
+		return _writeOutput(*output, svg)
+	}
+
+	svgs, errs := nbx.WireframeSVGs(frame)
+	if len(errs) > 0 {
+		return _errorOf(errs)
+	}
+	dir := *output
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for device, svg := range svgs {
+		path := filepath.Join(dir, frame.Name+"_"+device+".svg")
+		if err := os.WriteFile(path, []byte(svg), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/codegen"
+)
+
+type CodegenOptions = codegen.Options
+
+// GenerateCode generates typed code for frames in the given language ("kotlin", "swift", "typescript" or "go").
+// For Kotlin and Swift each frame becomes an object with its route, route-argument builder, variable keys
+// with their types, the frame's enums, block keys and the events each block handles. LIST and MAP variables
+// are typed as lists and maps of their element type; COLOR, URL and JSON variables are strings. For TypeScript it emits .d.ts types for
+// the FrameJson model, the integrations in options.Registry and per-frame key unions. For Go it emits typed
+// structs with parse functions from BlockJson and ActionTriggerJson for every integration in options.Registry.
+// Component instances are expanded first, so block keys are those of the compiled frame.
+func GenerateCode(frames []FrameDSLModel, language string, options CodegenOptions) (string, Errors) {
+	expanded := make([]FrameDSLModel, len(frames))
+	for i, frame := range frames {
+		var errs Errors
+		if expanded[i], errs = Expand(frame); len(errs) > 0 {
+			return "", errs
+		}
+	}
+	result, err := codegen.GenerateFrames(expanded, language, options)
+	if err != nil {
+		return "", _errorsOf(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,33 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/diff"
+)
+
+type FrameChange = diff.Change
+type FrameChanges = diff.Changes
+type ChangeKind = diff.ChangeKind
+
+const (
+	ChangeAdded     = diff.Added
+	ChangeRemoved   = diff.Removed
+	ChangeChanged   = diff.Changed
+	ChangeMoved     = diff.Moved
+	ChangeReordered = diff.Reordered
+)
+
+// Node kinds of the declarations a FrameChange can name. Walk does not visit declarations.
+const (
+	NodeConstant  = diff.NodeConstant
+	NodeEnum      = diff.NodeEnum
+	NodeStyle     = diff.NodeStyle
+	NodeComponent = diff.NodeComponent
+)
+
+// Diff compares two frames semantically. Blocks, variables and constants are matched by key, enums,
+// styles and components by name and actions by block key and event, so regenerated IDs and formatting do
+// not show up. The result lists added, removed, moved and
+// reordered nodes and changed fields, including property values per device. Use Text or JSON to render it.
+func Diff(a, b FrameDSLModel) FrameChanges {
+	return diff.Diff(a, b)
+}
@@ -0,0 +1,50 @@
+package nbx
+
+import (
+	"io/fs"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/detector"
+	"github.com/nativeblocks/nbx/internal/loader"
+)
+
+// ParseFS parses the frame file at name in fsys, DSL or XML, together with the libraries it imports
+// with `import "path"` (<import src="path" /> in XML). Imports are resolved relative to the importing
+// file and bring in the library's constants, variables and components, tagged with their file. The
+// frame keeps its imports and its constants unresolved, so it formats back to its source. Every error
+// names the file it was found in.
+func ParseFS(fsys fs.FS, name string) (FrameDSLModel, Errors) {
+	frame, sources, errs := loader.Load(fsys, name)
+	if len(errs) > 0 {
+		return FrameDSLModel{}, _withFiles(_errorValueOf(errs), name, sources)
+	}
+
+	issues, ok := _compile(&frame, sources[name])
+	issues = _withFiles(issues, name, sources)
+	if !ok {
+		return FrameDSLModel{}, issues
+	}
+	return frame, issues
+}
+
+// IsLibrary reports whether content, DSL or XML, is a library to import rather than a frame.
+func IsLibrary(content string) bool {
+	return detector.IsLibrary(content)
+}
+
+// _withFiles names the frame file in errors without a file and fills in the source lines of errors
+// found in libraries.
+func _withFiles(errs Errors, name string, sources map[string]string) Errors {
+	for i := range errs {
+		if errs[i].File == "" {
+			errs[i].File = name
+		}
+		if errs[i].SourceLine == "" && errs[i].Line > 0 {
+			lines := strings.Split(sources[errs[i].File], "\n")
+			if errs[i].Line <= len(lines) {
+				errs[i].SourceLine = lines[errs[i].Line-1]
+			}
+		}
+	}
+	return errs
+}
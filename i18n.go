@@ -0,0 +1,59 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/compiler"
+	"github.com/nativeblocks/nbx/internal/i18n"
+)
+
+type Bundle = i18n.Bundle
+type StringExtraction = i18n.Extraction
+type TranslationSource = i18n.Source
+
+// LoadBundle reads a locale bundle of translated strings. name selects the format by its extension:
+// .json for {"locale": "fr", "strings": {"welcome": {"title": "Bienvenue"}}}, or .xlf and .xliff for
+// XLIFF 1.2 and 2.0. Frames reference the strings as @string.welcome.title.
+func LoadBundle(name, content string) (*Bundle, Errors) {
+	bundle, err := i18n.Parse(name, content)
+	if err != nil {
+		return nil, _errorsOf(err)
+	}
+	return bundle, nil
+}
+
+// NewBundle creates an empty bundle for locale, for example to extract strings into.
+func NewBundle(locale string) *Bundle {
+	return i18n.NewBundle(locale)
+}
+
+// ValidateTranslations checks the string resources of frames against bundles. References without a
+// translation in some bundle are errors; strings no frame references are warnings.
+func ValidateTranslations(frames []TranslationSource, bundles []*Bundle) Errors {
+	return _errorValueOf(i18n.Check(frames, bundles))
+}
+
+// Localize replaces the string resource references of frame with their translations in bundle.
+// References without a translation are reported and left in place.
+func Localize(frame *FrameDSLModel, bundle *Bundle) Errors {
+	return _errorValueOf(i18n.Localize(frame, bundle))
+}
+
+// ToJSONLocales compiles frameDSL once per bundle and returns the FrameJson of each locale by locale
+// name. All locales share the same ids, so a client can switch locales without losing state.
+func ToJSONLocales(frameDSL FrameDSLModel, blocksJSON, actionsJSON, frameID string, bundles []*Bundle, options ToJSONOptions) (map[string]FrameJson, Errors) {
+	result, err := compiler.ToJsonLocales(frameDSL, blocksJSON, actionsJSON, frameID, bundles, options)
+	if err != nil {
+		return nil, _errorsOf(err)
+	}
+	return result, nil
+}
+
+// ExtractStrings moves the literal text of frame, such as STRING variables and text or title
+// properties, into bundle and replaces it with @string references. It returns what was extracted.
+func ExtractStrings(frame *FrameDSLModel, bundle *Bundle) []StringExtraction {
+	return i18n.Extract(frame, bundle)
+}
+
+// LocalizeJSON returns a copy of a compiled frame with its string resource references translated.
+func LocalizeJSON(frame FrameJson, bundle *Bundle) FrameJson {
+	return i18n.LocalizeJson(frame, bundle)
+}
@@ -0,0 +1,162 @@
+package codegen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/compiler"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/types"
+	"github.com/nativeblocks/nbx/internal/validator"
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+const (
+	LanguageKotlin     = "kotlin"
+	LanguageSwift      = "swift"
+	LanguageTypeScript = "typescript"
+	LanguageGo         = "go"
+)
+
+const generatedHeader = "Code generated by nbx codegen. DO NOT EDIT."
+
+type Options struct {
+	// Package is the Kotlin or Go package of the generated file. It is ignored by languages without packages.
+	Package string
+	// Registry, when set, adds types for its block and action integrations to languages that support them.
+	Registry *validator.IntegrationRegistry
+}
+
+// GenerateFrames generates typed constants for the given frames in the requested language.
+// For TypeScript it emits type definitions for the frame JSON model, the integrations in
+// options.Registry and per-frame key unions. For Go it emits a typed struct, a parse function and event and
+// slot constants for every integration in options.Registry; frames are not used.
+func GenerateFrames(frames []model.FrameDSLModel, language string, options Options) (string, error) {
+	infos := make([]frameInfo, 0, len(frames))
+	for _, frame := range frames {
+		infos = append(infos, _collectFrameInfo(frame))
+	}
+
+	switch strings.ToLower(language) {
+	case LanguageKotlin:
+		return generateKotlin(infos, options), nil
+	case LanguageSwift:
+		return generateSwift(infos), nil
+	case LanguageTypeScript, "ts":
+		return generateTypeScript(infos, options.Registry), nil
+	case LanguageGo, "golang":
+		return generateGo(options.Registry, options)
+	default:
+		return "", fmt.Errorf("unsupported language: %s. Supported languages: %s, %s, %s, %s",
+			language, LanguageKotlin, LanguageSwift, LanguageTypeScript, LanguageGo)
+	}
+}
+
+type frameInfo struct {
+	name      string
+	route     string
+	routeArgs []string
+	variables []variableInfo
+	blocks    []blockInfo
+	// enums are the enums the frame declares or imports, in declaration order.
+	enums []model.EnumDSLModel
+	// frameEvents are the lifecycle events the frame's own actions handle.
+	frameEvents []string
+}
+
+// eventOwners returns the frame, under the key of frame actions, and the blocks that handle events.
+func (f frameInfo) eventOwners() []blockInfo {
+	owners := make([]blockInfo, 0, len(f.blocks)+1)
+	if len(f.frameEvents) > 0 {
+		owners = append(owners, blockInfo{key: model.FrameActionKey, events: f.frameEvents})
+	}
+	for _, block := range f.blocks {
+		if len(block.events) > 0 {
+			owners = append(owners, block)
+		}
+	}
+	return owners
+}
+
+type variableInfo struct {
+	key string
+	// varType is the declared type by its canonical name, such as LIST<INT> or Status.
+	varType string
+	typ     types.Type
+}
+
+type blockInfo struct {
+	key     string
+	keyType string
+	events  []string
+}
+
+func _collectFrameInfo(frame model.FrameDSLModel) frameInfo {
+	info := frameInfo{
+		name:      frame.Name,
+		route:     frame.Route,
+		routeArgs: compiler.RouteArguments(frame.Route),
+	}
+
+	named := make(map[string]types.Type, len(frame.Enums))
+	for _, enum := range frame.Enums {
+		if _, exists := named[enum.Name]; !exists {
+			named[enum.Name] = types.NewEnumType(enum.Name, enum.Members)
+			info.enums = append(info.enums, enum)
+		}
+	}
+	for _, variable := range frame.Variables {
+		typ, err := types.FromStringWith(variable.Type, named)
+		varType := typ.Name()
+		if err != nil {
+			varType = strings.ToUpper(variable.Type)
+		}
+		info.variables = append(info.variables, variableInfo{key: variable.Key, varType: varType, typ: typ})
+	}
+
+	walker.Walk(&frame, walker.Visitor{
+		Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+			switch node.Kind {
+			case walker.KindBlock:
+				info.blocks = append(info.blocks, blockInfo{key: node.Block.Key, keyType: node.Block.KeyType})
+			case walker.KindAction:
+				// Frame actions come before blocks, and block actions before child blocks, so the action
+				// belongs to the frame or to the last collected block.
+				events := &info.frameEvents
+				if parents[len(parents)-1].Kind == walker.KindBlock {
+					events = &info.blocks[len(info.blocks)-1].events
+				}
+				if node.Action.Event != "" && !slices.Contains(*events, node.Action.Event) {
+					*events = append(*events, node.Action.Event)
+				}
+				return walker.SkipChildren
+			case walker.KindSequence, walker.KindSlot, walker.KindProperty, walker.KindData:
+				return walker.SkipChildren
+			}
+			return walker.Continue
+		},
+	})
+	return info
+}
+
+// _routeTemplate renders a route with the target language's string escaping and interpolation.
+func _routeTemplate(route string, escape func(literal string) string, interpolate func(arg string) string) string {
+	var builder strings.Builder
+	for len(route) > 0 {
+		start := strings.Index(route, "{")
+		if start == -1 {
+			builder.WriteString(escape(route))
+			break
+		}
+		end := strings.Index(route[start:], "}")
+		if end == -1 {
+			builder.WriteString(escape(route))
+			break
+		}
+		builder.WriteString(escape(route[:start]))
+		builder.WriteString(interpolate(route[start+1 : start+end]))
+		route = route[start+end+1:]
+	}
+	return builder.String()
+}
@@ -0,0 +1,276 @@
+package codegen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/validator"
+)
+
+func _codegenFrame() model.FrameDSLModel {
+	return model.FrameDSLModel{
+		Name:  "user-profile",
+		Route: "/user/{userId}/posts/{post_id}",
+		Variables: []model.VariableDSLModel{
+			{Key: "visible", Type: "BOOLEAN", Value: "true"},
+			{Key: "title_text", Type: "STRING", Value: "Hello"},
+			{Key: "count", Type: "INT", Value: "0"},
+			{Key: "accent", Type: "COLOR", Value: "#2563EB"},
+			{Key: "avatar", Type: "URL", Value: "https://example.com/a.png"},
+			{Key: "payload", Type: "JSON", Value: "{}"},
+			{Key: "tags", Type: "LIST<STRING>", Value: "[]"},
+			{Key: "scores", Type: "MAP<STRING, LIST<INT>>", Value: "{}"},
+			{Key: "status", Type: "Status", Value: "IDLE"},
+			{Key: "history", Type: "LIST<Status>", Value: "[]"},
+		},
+		Enums: []model.EnumDSLModel{
+			{Name: "Status", Members: []string{"IDLE", "LOADING_MORE"}},
+		},
+		Actions: []model.ActionDSLModel{
+			{Event: "onLoad"},
+		},
+		Blocks: []model.BlockDSLModel{
+			{
+				KeyType: "ROOT",
+				Key:     "root",
+				Blocks: []model.BlockDSLModel{
+					{
+						KeyType: "nativeblocks/button",
+						Key:     "increment-btn",
+						Actions: []model.ActionDSLModel{
+							{Event: "onClick"},
+							{Event: "onClick"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateKotlin(t *testing.T) {
+	output, err := GenerateFrames([]model.FrameDSLModel{_codegenFrame()}, "kotlin", Options{Package: "com.example.frames"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"package com.example.frames",
+		"object UserProfileFrame {",
+		`const val ROUTE = "/user/{userId}/posts/{post_id}"`,
+		`fun route(userId: String, postId: String): String = "/user/${userId}/posts/${postId}"`,
+		`val visible = VariableKey<Boolean>("visible", "BOOLEAN")`,
+		`val titleText = VariableKey<String>("title_text", "STRING")`,
+		`val count = VariableKey<Int>("count", "INT")`,
+		`val accent = VariableKey<String>("accent", "COLOR")`,
+		`val avatar = VariableKey<String>("avatar", "URL")`,
+		`val payload = VariableKey<String>("payload", "JSON")`,
+		`val tags = VariableKey<List<String>>("tags", "LIST<STRING>")`,
+		`val scores = VariableKey<Map<String, List<Int>>>("scores", "MAP<STRING,LIST<INT>>")`,
+		`enum class Status(val value: String) { IDLE("IDLE"), LOADING_MORE("LOADING_MORE") }`,
+		`val status = VariableKey<Enums.Status>("status", "Status")`,
+		`val history = VariableKey<List<Enums.Status>>("history", "LIST<Status>")`,
+		`const val INCREMENT_BTN = "increment-btn"`,
+		"object IncrementBtn {",
+		`const val ON_CLICK = "onClick"`,
+		"object Frame {",
+		`const val ON_LOAD = "onLoad"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Errorf("Expected Kotlin output to contain %q:\n%s", e, output)
+		}
+	}
+	if strings.Count(output, "ON_CLICK") != 1 {
+		t.Errorf("Expected events to be deduplicated:\n%s", output)
+	}
+}
+
+func TestGenerateSwift(t *testing.T) {
+	output, err := GenerateFrames([]model.FrameDSLModel{_codegenFrame()}, "swift", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"public enum UserProfileFrame {",
+		`public static func route(userId: String, postId: String) -> String {`,
+		`return "/user/\(userId)/posts/\(postId)"`,
+		`public static let visible = VariableKey<Bool>(key: "visible", type: "BOOLEAN")`,
+		`public static let count = VariableKey<Int32>(key: "count", type: "INT")`,
+		`public static let accent = VariableKey<String>(key: "accent", type: "COLOR")`,
+		`public static let payload = VariableKey<String>(key: "payload", type: "JSON")`,
+		`public static let tags = VariableKey<[String]>(key: "tags", type: "LIST<STRING>")`,
+		`public static let scores = VariableKey<[String: [Int32]]>(key: "scores", type: "MAP<STRING,LIST<INT>>")`,
+		"public enum Status: String {",
+		`case loadingMore = "LOADING_MORE"`,
+		`public static let status = VariableKey<Enums.Status>(key: "status", type: "Status")`,
+		`public static let history = VariableKey<[Enums.Status]>(key: "history", type: "LIST<Status>")`,
+		`public static let incrementBtn = "increment-btn"`,
+		`public static let onClick = "onClick"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Errorf("Expected Swift output to contain %q:\n%s", e, output)
+		}
+	}
+}
+
+func TestGenerateUnsupportedLanguage(t *testing.T) {
+	if _, err := GenerateFrames(nil, "cobol", Options{}); err == nil {
+		t.Error("Expected error for unsupported language")
+	}
+}
+
+func TestNaming(t *testing.T) {
+	tests := []struct {
+		input  string
+		pascal string
+		camel  string
+		snake  string
+	}{
+		{"mainColumn", "MainColumn", "mainColumn", "MAIN_COLUMN"},
+		{"main-column", "MainColumn", "mainColumn", "MAIN_COLUMN"},
+		{"title_text", "TitleText", "titleText", "TITLE_TEXT"},
+		{"onClick", "OnClick", "onClick", "ON_CLICK"},
+		{"HTMLView", "HtmlView", "htmlView", "HTML_VIEW"},
+		{"1st", "_1st", "_1st", "_1ST"},
+		{"élan_view", "ÉlanView", "élanView", "ÉLAN_VIEW"},
+		{"Ärger", "Ärger", "ärger", "ÄRGER"},
+	}
+
+	for _, tt := range tests {
+		if got := _pascalCase(tt.input); got != tt.pascal {
+			t.Errorf("_pascalCase(%q) = %q, want %q", tt.input, got, tt.pascal)
+		}
+		if got := _camelCase(tt.input); got != tt.camel {
+			t.Errorf("_camelCase(%q) = %q, want %q", tt.input, got, tt.camel)
+		}
+		if got := _screamingSnakeCase(tt.input); got != tt.snake {
+			t.Errorf("_screamingSnakeCase(%q) = %q, want %q", tt.input, got, tt.snake)
+		}
+	}
+}
+
+func TestNameSetUnique(t *testing.T) {
+	names := make(nameSet)
+	if names.unique("key") != "key" || names.unique("key") != "key2" || names.unique("key") != "key3" {
+		t.Error("Expected numbered suffixes for repeated names")
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	registry, err := validator.LoadIntegrations(`{
+		"schema-version": "1",
+		"nativeblocks/button": {
+			"version": 1,
+			"properties": [{"key": "backgroundColor", "type": "STRING"}, {"key": "weight", "type": "FLOAT"}],
+			"data": [{"key": "text", "type": "STRING"}],
+			"events": [{"event": "onClick"}],
+			"slots": [{"slot": "leadingIcon"}, {"slot": "trailingIcon"}]
+		}
+	}`, `{
+		"nativeblocks/change_variable": {
+			"version": 1,
+			"properties": [{"key": "variableValue", "type": "STRING"}],
+			"data": [{"key": "variableKey", "type": "STRING"}],
+			"events": [{"event": "NEXT"}]
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := GenerateFrames([]model.FrameDSLModel{_codegenFrame()}, "typescript", Options{Registry: registry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"export interface FrameJson {",
+		"  routeArguments: RouteArgumentJson[];",
+		"  isStarter: boolean;",
+		"  integrationVersion: number;",
+		"export interface NativeblocksButtonBlockProperties {",
+		`  "weight": "FLOAT";`,
+		`export type NativeblocksButtonBlockSlot = "leadingIcon" | "trailingIcon";`,
+		`  keyType: "nativeblocks/button";`,
+		`export type NativeblocksChangeVariableActionEvent = "NEXT";`,
+		`export type BlockKeyType = "nativeblocks/button";`,
+		`  "nativeblocks/change_variable": NativeblocksChangeVariableAction;`,
+		`export type UserProfileFrameVariableKey = "visible" | "title_text" | "count" | "accent" | "avatar" | "payload" | "tags" | "scores" | "status" | "history";`,
+		`export type UserProfileFrameBlockKey = "root" | "increment-btn";`,
+		`export type UserProfileFrameRouteArgument = "userId" | "post_id";`,
+		`  "increment-btn": "onClick";`,
+		"  template?: TemplatePartJson[];",
+		"export interface TemplatePartJson {",
+		"  repeat?: BlockRepeatJson;",
+	}
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Errorf("Expected TypeScript output to contain %q:\n%s", e, output)
+		}
+	}
+
+	for _, match := range regexp.MustCompile(`: ([A-Z][A-Za-z0-9]*)(\[\])?;`).FindAllStringSubmatch(output, -1) {
+		name := match[1]
+		if !strings.Contains(output, "export interface "+name+" ") && !strings.Contains(output, "export type "+name+" ") {
+			t.Errorf("Expected the referenced type %s to be declared", name)
+		}
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	registry, err := validator.LoadIntegrations(`{
+		"nativeblocks/button": {
+			"version": 2,
+			"properties": [{"key": "text-size", "type": "INT", "value": "14"}, {"key": "weight", "type": "FLOAT", "value": "0F"}],
+			"data": [{"key": "text", "type": "STRING"}],
+			"events": [{"event": "onClick"}],
+			"slots": [{"slot": "leadingIcon"}]
+		}
+	}`, `{
+		"nativeblocks/change_variable": {
+			"version": 1,
+			"properties": [{"key": "enabled", "type": "BOOLEAN"}],
+			"data": [{"key": "variableKey", "type": "STRING"}],
+			"events": [{"event": "NEXT"}]
+		}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := GenerateFrames(nil, "go", Options{Package: "backend", Registry: registry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"// " + generatedHeader,
+		"package backend",
+		`"github.com/nativeblocks/nbx"`,
+		`const NativeblocksButtonBlockKeyType = "nativeblocks/button"`,
+		`NativeblocksButtonBlockEventOnClick = "onClick"`,
+		`NativeblocksButtonBlockSlotLeadingIcon = "leadingIcon"`,
+		"TextSize DeviceValue[int]",
+		"Weight   DeviceValue[float64]",
+		"Text string // STRING",
+		"func ParseNativeblocksButtonBlock(block nbx.BlockJson) (NativeblocksButtonBlock, error) {",
+		`result.Properties.TextSize = defaultDeviceValue("14", parseInt)`,
+		"Enabled bool",
+		"func ParseNativeblocksChangeVariableAction(trigger nbx.ActionTriggerJson) (NativeblocksChangeVariableAction, error) {",
+		`NativeblocksChangeVariableActionEventNext = "NEXT"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Errorf("Expected Go output to contain %q:\n%s", e, output)
+		}
+	}
+
+	if _, err := GenerateFrames(nil, "go", Options{}); err == nil {
+		t.Error("Expected an error when generating Go without a registry")
+	}
+}
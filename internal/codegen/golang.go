@@ -0,0 +1,299 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/validator"
+)
+
+const goDefaultPackage = "integrations"
+
+const goRuntime = `// DeviceValue holds a block property value for each device class.
+type DeviceValue[T any] struct {
+	Mobile  T
+	Tablet  T
+	Desktop T
+}
+
+func parseDeviceValue[T any](prop nbx.BlockPropertyJson, parse func(string) (T, error)) (DeviceValue[T], error) {
+	var result DeviceValue[T]
+	var err error
+	if result.Mobile, err = parse(prop.ValueMobile); err != nil {
+		return result, fmt.Errorf("property '%s' (mobile): %w", prop.Key, err)
+	}
+	if result.Tablet, err = parse(prop.ValueTablet); err != nil {
+		return result, fmt.Errorf("property '%s' (tablet): %w", prop.Key, err)
+	}
+	if result.Desktop, err = parse(prop.ValueDesktop); err != nil {
+		return result, fmt.Errorf("property '%s' (desktop): %w", prop.Key, err)
+	}
+	return result, nil
+}
+
+func defaultDeviceValue[T any](value string, parse func(string) (T, error)) DeviceValue[T] {
+	v, _ := parse(value)
+	return DeviceValue[T]{Mobile: v, Tablet: v, Desktop: v}
+}
+
+func defaultValue[T any](value string, parse func(string) (T, error)) T {
+	v, _ := parse(value)
+	return v
+}
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+func parseInt(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func parseLong(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseFloat(s string) (float64, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "fFdD")
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseBool(s string) (bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+`
+
+func generateGo(registry *validator.IntegrationRegistry, options Options) (string, error) {
+	if registry == nil {
+		return "", fmt.Errorf("go code generation requires an integration registry")
+	}
+
+	pkg := options.Package
+	if pkg == "" {
+		pkg = goDefaultPackage
+	}
+
+	var builder strings.Builder
+	builder.WriteString("// " + generatedHeader + "\n\n")
+	builder.WriteString(fmt.Sprintf("package %s\n\n", pkg))
+	builder.WriteString("import (\n\t\"fmt\"\n\t\"strconv\"\n\t\"strings\"\n\n\t\"github.com/nativeblocks/nbx\"\n)\n\n")
+	builder.WriteString(goRuntime)
+
+	names := make(nameSet)
+
+	blockKeys := make([]string, 0, len(registry.Blocks))
+	for keyType := range registry.Blocks {
+		blockKeys = append(blockKeys, keyType)
+	}
+	sort.Strings(blockKeys)
+	for _, keyType := range blockKeys {
+		_writeGoBlock(&builder, registry.Blocks[keyType], names.unique(_pascalCase(keyType)+"Block"))
+	}
+
+	actionKeys := make([]string, 0, len(registry.Actions))
+	for keyType := range registry.Actions {
+		actionKeys = append(actionKeys, keyType)
+	}
+	sort.Strings(actionKeys)
+	for _, keyType := range actionKeys {
+		_writeGoAction(&builder, registry.Actions[keyType], names.unique(_pascalCase(keyType)+"Action"))
+	}
+
+	source, err := format.Source([]byte(builder.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated go code: %w", err)
+	}
+	return string(source), nil
+}
+
+func _writeGoBlock(builder *strings.Builder, block validator.BlockIntegration, name string) {
+	builder.WriteString(fmt.Sprintf("\n// %sKeyType is the keyType of the %q block integration.\n", name, block.KeyType))
+	builder.WriteString(fmt.Sprintf("const %sKeyType = %s\n", name, strconv.Quote(block.KeyType)))
+	_writeGoConstants(builder, name+"Event", _eventNames(block.Events))
+	_writeGoConstants(builder, name+"Slot", _slotNames(block.Slots))
+
+	propFields := _goFieldNames(block.Properties)
+	dataFields := _goDataFieldNames(block.Data)
+
+	builder.WriteString(fmt.Sprintf("\n// %s is the typed form of the %q block integration, version %d.\n", name, block.KeyType, block.Version))
+	builder.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	builder.WriteString("Key string\nVisibilityKey string\n")
+	builder.WriteString(fmt.Sprintf("Properties %sProperties\nData %sData\n}\n", name, name))
+
+	builder.WriteString(fmt.Sprintf("\ntype %sProperties struct {\n", name))
+	for i, prop := range block.Properties {
+		builder.WriteString(fmt.Sprintf("%s DeviceValue[%s]\n", propFields[i], _goType(prop.Type)))
+	}
+	builder.WriteString("}\n")
+
+	_writeGoDataStruct(builder, name+"Data", block.Data, dataFields)
+
+	builder.WriteString(fmt.Sprintf("\n// Parse%s reads a %q block from its JSON form.\n", name, block.KeyType))
+	builder.WriteString(fmt.Sprintf("func Parse%s(block nbx.BlockJson) (%s, error) {\n", name, name))
+	builder.WriteString(fmt.Sprintf("if block.KeyType != %sKeyType {\n", name))
+	builder.WriteString(fmt.Sprintf("return %s{}, fmt.Errorf(\"block '%%s' has keyType '%%s', expected '%%s'\", block.Key, block.KeyType, %sKeyType)\n}\n", name, name))
+	builder.WriteString(fmt.Sprintf("result := %s{Key: block.Key, VisibilityKey: block.VisibilityKey}\n", name))
+	for i, prop := range block.Properties {
+		builder.WriteString(fmt.Sprintf("result.Properties.%s = defaultDeviceValue(%s, %s)\n",
+			propFields[i], strconv.Quote(prop.Value), _goParser(prop.Type)))
+	}
+	builder.WriteString("for _, prop := range block.Properties {\nswitch prop.Key {\n")
+	for i, prop := range block.Properties {
+		builder.WriteString(fmt.Sprintf("case %s:\n", strconv.Quote(prop.Key)))
+		builder.WriteString(fmt.Sprintf("value, err := parseDeviceValue(prop, %s)\n", _goParser(prop.Type)))
+		builder.WriteString(fmt.Sprintf("if err != nil {\nreturn %s{}, fmt.Errorf(\"block '%%s': %%w\", block.Key, err)\n}\n", name))
+		builder.WriteString(fmt.Sprintf("result.Properties.%s = value\n", propFields[i]))
+	}
+	builder.WriteString("}\n}\n")
+	_writeGoDataAssignments(builder, "block", block.Data, dataFields)
+	builder.WriteString("return result, nil\n}\n")
+}
+
+func _writeGoAction(builder *strings.Builder, action validator.ActionIntegration, name string) {
+	builder.WriteString(fmt.Sprintf("\n// %sKeyType is the keyType of the %q action integration.\n", name, action.KeyType))
+	builder.WriteString(fmt.Sprintf("const %sKeyType = %s\n", name, strconv.Quote(action.KeyType)))
+	_writeGoConstants(builder, name+"Event", _eventNames(action.Events))
+
+	propFields := _goFieldNames(action.Properties)
+	dataFields := _goDataFieldNames(action.Data)
+
+	builder.WriteString(fmt.Sprintf("\n// %s is the typed form of the %q action integration, version %d.\n", name, action.KeyType, action.Version))
+	builder.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	builder.WriteString("Name string\nThen string\n")
+	builder.WriteString(fmt.Sprintf("Properties %sProperties\nData %sData\n}\n", name, name))
+
+	builder.WriteString(fmt.Sprintf("\ntype %sProperties struct {\n", name))
+	for i, prop := range action.Properties {
+		builder.WriteString(fmt.Sprintf("%s %s\n", propFields[i], _goType(prop.Type)))
+	}
+	builder.WriteString("}\n")
+
+	_writeGoDataStruct(builder, name+"Data", action.Data, dataFields)
+
+	builder.WriteString(fmt.Sprintf("\n// Parse%s reads a %q trigger from its JSON form.\n", name, action.KeyType))
+	builder.WriteString(fmt.Sprintf("func Parse%s(trigger nbx.ActionTriggerJson) (%s, error) {\n", name, name))
+	builder.WriteString(fmt.Sprintf("if trigger.KeyType != %sKeyType {\n", name))
+	builder.WriteString(fmt.Sprintf("return %s{}, fmt.Errorf(\"trigger '%%s' has keyType '%%s', expected '%%s'\", trigger.Name, trigger.KeyType, %sKeyType)\n}\n", name, name))
+	builder.WriteString(fmt.Sprintf("result := %s{Name: trigger.Name, Then: trigger.Then}\n", name))
+	for i, prop := range action.Properties {
+		builder.WriteString(fmt.Sprintf("result.Properties.%s = defaultValue(%s, %s)\n",
+			propFields[i], strconv.Quote(prop.Value), _goParser(prop.Type)))
+	}
+	builder.WriteString("for _, prop := range trigger.Properties {\nswitch prop.Key {\n")
+	for i, prop := range action.Properties {
+		builder.WriteString(fmt.Sprintf("case %s:\n", strconv.Quote(prop.Key)))
+		builder.WriteString(fmt.Sprintf("value, err := %s(prop.Value)\n", _goParser(prop.Type)))
+		builder.WriteString(fmt.Sprintf("if err != nil {\nreturn %s{}, fmt.Errorf(\"trigger '%%s': property '%%s': %%w\", trigger.Name, prop.Key, err)\n}\n", name))
+		builder.WriteString(fmt.Sprintf("result.Properties.%s = value\n", propFields[i]))
+	}
+	builder.WriteString("}\n}\n")
+	_writeGoDataAssignments(builder, "trigger", action.Data, dataFields)
+	builder.WriteString("return result, nil\n}\n")
+}
+
+// _writeGoDataStruct writes the data struct. Data entries bind variables, so each field holds a variable key.
+func _writeGoDataStruct(builder *strings.Builder, name string, data []validator.DataDefinition, fields []string) {
+	builder.WriteString(fmt.Sprintf("\n// %s holds the variable keys bound to each data entry.\n", name))
+	builder.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	for i, d := range data {
+		builder.WriteString(fmt.Sprintf("%s string // %s\n", fields[i], d.Type))
+	}
+	builder.WriteString("}\n")
+}
+
+func _writeGoDataAssignments(builder *strings.Builder, source string, data []validator.DataDefinition, fields []string) {
+	if len(data) == 0 {
+		return
+	}
+	builder.WriteString(fmt.Sprintf("for _, data := range %s.Data {\nswitch data.Key {\n", source))
+	for i, d := range data {
+		builder.WriteString(fmt.Sprintf("case %s:\nresult.Data.%s = data.Value\n", strconv.Quote(d.Key), fields[i]))
+	}
+	builder.WriteString("}\n}\n")
+}
+
+func _writeGoConstants(builder *strings.Builder, prefix string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	names := make(nameSet)
+	builder.WriteString("\nconst (\n")
+	for _, value := range values {
+		builder.WriteString(fmt.Sprintf("%s = %s\n", names.unique(prefix+_pascalCase(value)), strconv.Quote(value)))
+	}
+	builder.WriteString(")\n")
+}
+
+func _goFieldNames(properties []validator.PropertyDefinition) []string {
+	names := make(nameSet)
+	fields := make([]string, len(properties))
+	for i, prop := range properties {
+		fields[i] = names.unique(_goExported(prop.Key))
+	}
+	return fields
+}
+
+func _goDataFieldNames(data []validator.DataDefinition) []string {
+	names := make(nameSet)
+	fields := make([]string, len(data))
+	for i, d := range data {
+		fields[i] = names.unique(_goExported(d.Key))
+	}
+	return fields
+}
+
+func _goExported(key string) string {
+	name := _pascalCase(key)
+	if strings.HasPrefix(name, "_") {
+		return "X" + name
+	}
+	return name
+}
+
+func _goType(propType string) string {
+	switch strings.ToUpper(propType) {
+	case "INT":
+		return "int"
+	case "LONG":
+		return "int64"
+	case "FLOAT", "DOUBLE":
+		return "float64"
+	case "BOOLEAN":
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+func _goParser(propType string) string {
+	switch strings.ToUpper(propType) {
+	case "INT":
+		return "parseInt"
+	case "LONG":
+		return "parseLong"
+	case "FLOAT", "DOUBLE":
+		return "parseFloat"
+	case "BOOLEAN":
+		return "parseBool"
+	default:
+		return "parseString"
+	}
+}
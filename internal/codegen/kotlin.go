@@ -0,0 +1,152 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/types"
+)
+
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true, "else": true, "false": true,
+	"for": true, "fun": true, "if": true, "in": true, "interface": true, "is": true, "null": true,
+	"object": true, "package": true, "return": true, "super": true, "this": true, "throw": true,
+	"true": true, "try": true, "typealias": true, "typeof": true, "val": true, "var": true,
+	"when": true, "while": true,
+}
+
+func generateKotlin(frames []frameInfo, options Options) string {
+	var builder strings.Builder
+
+	builder.WriteString("// " + generatedHeader + "\n")
+	if options.Package != "" {
+		builder.WriteString(fmt.Sprintf("\npackage %s\n", options.Package))
+	}
+
+	objects := make(nameSet)
+	for _, frame := range frames {
+		builder.WriteString("\n")
+		_writeKotlinFrame(&builder, frame, objects.unique(_pascalCase(frame.name)+"Frame"))
+	}
+
+	return builder.String()
+}
+
+func _writeKotlinFrame(builder *strings.Builder, frame frameInfo, objectName string) {
+	builder.WriteString(fmt.Sprintf("object %s {\n", objectName))
+	builder.WriteString(fmt.Sprintf("    const val NAME = %s\n", _kotlinString(frame.name)))
+	builder.WriteString(fmt.Sprintf("    const val ROUTE = %s\n", _kotlinString(frame.route)))
+
+	params := make([]string, 0, len(frame.routeArgs))
+	argNames := make(nameSet)
+	names := make(map[string]string, len(frame.routeArgs))
+	for _, arg := range frame.routeArgs {
+		if _, exists := names[arg]; !exists {
+			names[arg] = _kotlinIdentifier(argNames.unique(_camelCase(arg)))
+			params = append(params, names[arg]+": String")
+		}
+	}
+	route := _routeTemplate(frame.route, _kotlinEscape, func(arg string) string {
+		return "${" + names[arg] + "}"
+	})
+	builder.WriteString(fmt.Sprintf("\n    fun route(%s): String = \"%s\"\n", strings.Join(params, ", "), route))
+
+	builder.WriteString("\n    class VariableKey<T>(val key: String, val type: String)\n")
+
+	enumNames := make(map[string]string, len(frame.enums))
+	if len(frame.enums) > 0 {
+		builder.WriteString("\n    object Enums {\n")
+		enumClasses := make(nameSet)
+		for _, enum := range frame.enums {
+			enumNames[enum.Name] = enumClasses.unique(_pascalCase(enum.Name))
+			entries := make([]string, 0, len(enum.Members))
+			entryNames := make(nameSet)
+			for _, member := range enum.Members {
+				entries = append(entries, fmt.Sprintf("%s(%s)", entryNames.unique(_screamingSnakeCase(member)), _kotlinString(member)))
+			}
+			builder.WriteString(fmt.Sprintf("        enum class %s(val value: String) { %s }\n",
+				enumNames[enum.Name], strings.Join(entries, ", ")))
+		}
+		builder.WriteString("    }\n")
+	}
+
+	builder.WriteString("\n    object Variables {\n")
+	variableNames := make(nameSet)
+	for _, variable := range frame.variables {
+		builder.WriteString(fmt.Sprintf("        val %s = VariableKey<%s>(%s, %s)\n",
+			_kotlinIdentifier(variableNames.unique(_camelCase(variable.key))),
+			_kotlinType(variable.typ, enumNames),
+			_kotlinString(variable.key),
+			_kotlinString(variable.varType)))
+	}
+	builder.WriteString("    }\n")
+
+	builder.WriteString("\n    object Blocks {\n")
+	blockNames := make(nameSet)
+	for _, block := range frame.blocks {
+		builder.WriteString(fmt.Sprintf("        const val %s = %s\n",
+			blockNames.unique(_screamingSnakeCase(block.key)), _kotlinString(block.key)))
+	}
+	builder.WriteString("    }\n")
+
+	builder.WriteString("\n    object Events {\n")
+	eventObjects := make(nameSet)
+	for _, block := range frame.eventOwners() {
+		builder.WriteString(fmt.Sprintf("        object %s {\n", eventObjects.unique(_pascalCase(block.key))))
+		eventNames := make(nameSet)
+		for _, event := range block.events {
+			builder.WriteString(fmt.Sprintf("            const val %s = %s\n",
+				eventNames.unique(_screamingSnakeCase(event)), _kotlinString(event)))
+		}
+		builder.WriteString("        }\n")
+	}
+	builder.WriteString("    }\n")
+
+	builder.WriteString("}\n")
+}
+
+// _kotlinType returns the Kotlin type of a variable type. enumNames maps enum names to their classes in
+// the frame's Enums object. COLOR, URL and JSON values are strings, as in compiled JSON.
+func _kotlinType(t types.Type, enumNames map[string]string) string {
+	switch t := t.(type) {
+	case types.ListType:
+		return "List<" + _kotlinType(t.Element, enumNames) + ">"
+	case types.MapType:
+		return "Map<String, " + _kotlinType(t.Value, enumNames) + ">"
+	case *types.EnumType:
+		return "Enums." + enumNames[t.Name()]
+	}
+	switch t {
+	case types.TypeInt:
+		return "Int"
+	case types.TypeLong:
+		return "Long"
+	case types.TypeFloat:
+		return "Float"
+	case types.TypeDouble:
+		return "Double"
+	case types.TypeBoolean:
+		return "Boolean"
+	default:
+		return "String"
+	}
+}
+
+func _kotlinIdentifier(name string) string {
+	if kotlinKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+func _kotlinString(s string) string {
+	return "\"" + _kotlinEscape(s) + "\""
+}
+
+func _kotlinEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "$", "\\$")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return s
+}
@@ -0,0 +1,83 @@
+package codegen
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// _words splits an identifier such as "main-column", "title_text" or "mainColumn" into lower-case words.
+func _words(s string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+
+	if len(words) == 0 {
+		return []string{"value"}
+	}
+	return words
+}
+
+func _pascalCase(s string) string {
+	var builder strings.Builder
+	for _, word := range _words(s) {
+		first, size := utf8.DecodeRuneInString(word)
+		builder.WriteRune(unicode.ToUpper(first))
+		builder.WriteString(word[size:])
+	}
+	return _leadingDigit(builder.String())
+}
+
+func _camelCase(s string) string {
+	pascal := _pascalCase(s)
+	if strings.HasPrefix(pascal, "_") {
+		return pascal
+	}
+	first, size := utf8.DecodeRuneInString(pascal)
+	return string(unicode.ToLower(first)) + pascal[size:]
+}
+
+func _screamingSnakeCase(s string) string {
+	return _leadingDigit(strings.ToUpper(strings.Join(_words(s), "_")))
+}
+
+func _leadingDigit(s string) string {
+	if first, _ := utf8.DecodeRuneInString(s); unicode.IsDigit(first) {
+		return "_" + s
+	}
+	return s
+}
+
+// nameSet hands out identifiers that are unique within one generated scope.
+type nameSet map[string]int
+
+func (n nameSet) unique(name string) string {
+	count := n[name]
+	n[name] = count + 1
+	if count == 0 {
+		return name
+	}
+	return name + strconv.Itoa(count+1)
+}
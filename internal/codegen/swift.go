@@ -0,0 +1,156 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/types"
+)
+
+var swiftKeywords = map[string]bool{
+	"associatedtype": true, "class": true, "deinit": true, "enum": true, "extension": true, "func": true,
+	"import": true, "init": true, "inout": true, "internal": true, "let": true, "operator": true,
+	"private": true, "protocol": true, "public": true, "static": true, "struct": true, "subscript": true,
+	"typealias": true, "var": true, "break": true, "case": true, "continue": true, "default": true,
+	"defer": true, "do": true, "else": true, "fallthrough": true, "for": true, "guard": true, "if": true,
+	"in": true, "repeat": true, "return": true, "switch": true, "where": true, "while": true, "as": true,
+	"catch": true, "false": true, "is": true, "nil": true, "self": true, "Self": true, "super": true,
+	"throw": true, "throws": true, "true": true, "try": true, "Type": true,
+}
+
+func generateSwift(frames []frameInfo) string {
+	var builder strings.Builder
+
+	builder.WriteString("// " + generatedHeader + "\n")
+
+	types := make(nameSet)
+	for _, frame := range frames {
+		builder.WriteString("\n")
+		_writeSwiftFrame(&builder, frame, types.unique(_pascalCase(frame.name)+"Frame"))
+	}
+
+	return builder.String()
+}
+
+func _writeSwiftFrame(builder *strings.Builder, frame frameInfo, typeName string) {
+	builder.WriteString(fmt.Sprintf("public enum %s {\n", typeName))
+	builder.WriteString(fmt.Sprintf("    public static let name = %s\n", _swiftString(frame.name)))
+	builder.WriteString(fmt.Sprintf("    public static let routePattern = %s\n", _swiftString(frame.route)))
+
+	params := make([]string, 0, len(frame.routeArgs))
+	argNames := make(nameSet)
+	names := make(map[string]string, len(frame.routeArgs))
+	for _, arg := range frame.routeArgs {
+		if _, exists := names[arg]; !exists {
+			names[arg] = _swiftIdentifier(argNames.unique(_camelCase(arg)))
+			params = append(params, names[arg]+": String")
+		}
+	}
+	route := _routeTemplate(frame.route, _swiftEscape, func(arg string) string {
+		return "\\(" + strings.Trim(names[arg], "`") + ")"
+	})
+	builder.WriteString(fmt.Sprintf("\n    public static func route(%s) -> String {\n", strings.Join(params, ", ")))
+	builder.WriteString(fmt.Sprintf("        return \"%s\"\n", route))
+	builder.WriteString("    }\n")
+
+	builder.WriteString("\n    public struct VariableKey<T> {\n")
+	builder.WriteString("        public let key: String\n")
+	builder.WriteString("        public let type: String\n")
+	builder.WriteString("    }\n")
+
+	enumNames := make(map[string]string, len(frame.enums))
+	if len(frame.enums) > 0 {
+		builder.WriteString("\n    public enum Enums {\n")
+		enumTypes := make(nameSet)
+		for _, enum := range frame.enums {
+			enumNames[enum.Name] = enumTypes.unique(_pascalCase(enum.Name))
+			builder.WriteString(fmt.Sprintf("        public enum %s: String {\n", _swiftIdentifier(enumNames[enum.Name])))
+			caseNames := make(nameSet)
+			for _, member := range enum.Members {
+				builder.WriteString(fmt.Sprintf("            case %s = %s\n",
+					_swiftIdentifier(caseNames.unique(_camelCase(member))), _swiftString(member)))
+			}
+			builder.WriteString("        }\n")
+		}
+		builder.WriteString("    }\n")
+	}
+
+	builder.WriteString("\n    public enum Variables {\n")
+	variableNames := make(nameSet)
+	for _, variable := range frame.variables {
+		builder.WriteString(fmt.Sprintf("        public static let %s = VariableKey<%s>(key: %s, type: %s)\n",
+			_swiftIdentifier(variableNames.unique(_camelCase(variable.key))),
+			_swiftType(variable.typ, enumNames),
+			_swiftString(variable.key),
+			_swiftString(variable.varType)))
+	}
+	builder.WriteString("    }\n")
+
+	builder.WriteString("\n    public enum Blocks {\n")
+	blockNames := make(nameSet)
+	for _, block := range frame.blocks {
+		builder.WriteString(fmt.Sprintf("        public static let %s = %s\n",
+			_swiftIdentifier(blockNames.unique(_camelCase(block.key))), _swiftString(block.key)))
+	}
+	builder.WriteString("    }\n")
+
+	builder.WriteString("\n    public enum Events {\n")
+	eventTypes := make(nameSet)
+	for _, block := range frame.eventOwners() {
+		builder.WriteString(fmt.Sprintf("        public enum %s {\n", _swiftIdentifier(eventTypes.unique(_pascalCase(block.key)))))
+		eventNames := make(nameSet)
+		for _, event := range block.events {
+			builder.WriteString(fmt.Sprintf("            public static let %s = %s\n",
+				_swiftIdentifier(eventNames.unique(_camelCase(event))), _swiftString(event)))
+		}
+		builder.WriteString("        }\n")
+	}
+	builder.WriteString("    }\n")
+
+	builder.WriteString("}\n")
+}
+
+// _swiftType returns the Swift type of a variable type. enumNames maps enum names to their types in the
+// frame's Enums namespace. COLOR, URL and JSON values are strings, as in compiled JSON.
+func _swiftType(t types.Type, enumNames map[string]string) string {
+	switch t := t.(type) {
+	case types.ListType:
+		return "[" + _swiftType(t.Element, enumNames) + "]"
+	case types.MapType:
+		return "[String: " + _swiftType(t.Value, enumNames) + "]"
+	case *types.EnumType:
+		return "Enums." + _swiftIdentifier(enumNames[t.Name()])
+	}
+	switch t {
+	case types.TypeInt:
+		return "Int32"
+	case types.TypeLong:
+		return "Int64"
+	case types.TypeFloat:
+		return "Float"
+	case types.TypeDouble:
+		return "Double"
+	case types.TypeBoolean:
+		return "Bool"
+	default:
+		return "String"
+	}
+}
+
+func _swiftIdentifier(name string) string {
+	if swiftKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+func _swiftString(s string) string {
+	return "\"" + _swiftEscape(s) + "\""
+}
+
+func _swiftEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return s
+}
@@ -0,0 +1,270 @@
+package codegen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/validator"
+)
+
+// typescriptModelTypes are emitted as interfaces in dependency order, derived from the JSON model structs.
+var typescriptModelTypes = []reflect.Type{
+	reflect.TypeOf(model.FrameJson{}),
+	reflect.TypeOf(model.RouteArgumentJson{}),
+	reflect.TypeOf(model.VariableJson{}),
+	reflect.TypeOf(model.BlockJson{}),
+	reflect.TypeOf(model.BlockRepeatJson{}),
+	reflect.TypeOf(model.BlockPropertyJson{}),
+	reflect.TypeOf(model.BlockDataJson{}),
+	reflect.TypeOf(model.TemplatePartJson{}),
+	reflect.TypeOf(model.BlockSlotJson{}),
+	reflect.TypeOf(model.ActionJson{}),
+	reflect.TypeOf(model.ActionTriggerJson{}),
+	reflect.TypeOf(model.TriggerPropertyJson{}),
+	reflect.TypeOf(model.TriggerDataJson{}),
+}
+
+func generateTypeScript(frames []frameInfo, registry *validator.IntegrationRegistry) string {
+	var builder strings.Builder
+
+	builder.WriteString("// " + generatedHeader + "\n")
+
+	builder.WriteString("\n// Frame JSON model\n")
+	for _, t := range typescriptModelTypes {
+		builder.WriteString("\n")
+		_writeTypeScriptInterface(&builder, t)
+	}
+
+	if registry != nil {
+		_writeTypeScriptRegistry(&builder, registry)
+	}
+
+	types := make(nameSet)
+	for _, frame := range frames {
+		_writeTypeScriptFrame(&builder, frame, types.unique(_pascalCase(frame.name)+"Frame"))
+	}
+
+	return builder.String()
+}
+
+func _writeTypeScriptInterface(builder *strings.Builder, t reflect.Type) {
+	builder.WriteString(fmt.Sprintf("export interface %s {\n", t.Name()))
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, optional := _jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+		marker := ""
+		if optional {
+			marker = "?"
+		}
+		builder.WriteString(fmt.Sprintf("  %s%s: %s;\n", name, marker, _typeScriptTypeOf(field.Type)))
+	}
+	builder.WriteString("}\n")
+}
+
+func _jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	optional := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			optional = true
+		}
+	}
+	return name, optional
+}
+
+func _typeScriptTypeOf(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return _typeScriptTypeOf(t.Elem()) + "[]"
+	case reflect.Ptr:
+		return _typeScriptTypeOf(t.Elem())
+	case reflect.Map:
+		return fmt.Sprintf("Record<%s, %s>", _typeScriptTypeOf(t.Key()), _typeScriptTypeOf(t.Elem()))
+	case reflect.Struct:
+		return t.Name()
+	default:
+		return "unknown"
+	}
+}
+
+func _writeTypeScriptRegistry(builder *strings.Builder, registry *validator.IntegrationRegistry) {
+	blockKeys := make([]string, 0, len(registry.Blocks))
+	for keyType := range registry.Blocks {
+		blockKeys = append(blockKeys, keyType)
+	}
+	sort.Strings(blockKeys)
+
+	actionKeys := make([]string, 0, len(registry.Actions))
+	for keyType := range registry.Actions {
+		actionKeys = append(actionKeys, keyType)
+	}
+	sort.Strings(actionKeys)
+
+	names := make(nameSet)
+
+	builder.WriteString("\n// Block integrations\n")
+	blockTypes := make(map[string]string, len(blockKeys))
+	for _, keyType := range blockKeys {
+		block := registry.Blocks[keyType]
+		name := names.unique(_pascalCase(keyType) + "Block")
+		blockTypes[keyType] = name
+
+		builder.WriteString("\n")
+		_writeTypeScriptPropertyMap(builder, name+"Properties", block.Properties)
+		_writeTypeScriptDataMap(builder, name+"Data", block.Data)
+		_writeTypeScriptUnion(builder, name+"Event", _eventNames(block.Events))
+		_writeTypeScriptUnion(builder, name+"Slot", _slotNames(block.Slots))
+		builder.WriteString(fmt.Sprintf("export interface %s {\n", name))
+		builder.WriteString(fmt.Sprintf("  keyType: %s;\n", _typeScriptString(keyType)))
+		builder.WriteString(fmt.Sprintf("  version: %d;\n", block.Version))
+		builder.WriteString(fmt.Sprintf("  properties: %sProperties;\n", name))
+		builder.WriteString(fmt.Sprintf("  data: %sData;\n", name))
+		builder.WriteString(fmt.Sprintf("  events: %sEvent;\n", name))
+		builder.WriteString(fmt.Sprintf("  slots: %sSlot;\n", name))
+		builder.WriteString("}\n")
+	}
+
+	builder.WriteString("\n// Action integrations\n")
+	actionTypes := make(map[string]string, len(actionKeys))
+	for _, keyType := range actionKeys {
+		action := registry.Actions[keyType]
+		name := names.unique(_pascalCase(keyType) + "Action")
+		actionTypes[keyType] = name
+
+		builder.WriteString("\n")
+		_writeTypeScriptPropertyMap(builder, name+"Properties", action.Properties)
+		_writeTypeScriptDataMap(builder, name+"Data", action.Data)
+		_writeTypeScriptUnion(builder, name+"Event", _eventNames(action.Events))
+		builder.WriteString(fmt.Sprintf("export interface %s {\n", name))
+		builder.WriteString(fmt.Sprintf("  keyType: %s;\n", _typeScriptString(keyType)))
+		builder.WriteString(fmt.Sprintf("  version: %d;\n", action.Version))
+		builder.WriteString(fmt.Sprintf("  properties: %sProperties;\n", name))
+		builder.WriteString(fmt.Sprintf("  data: %sData;\n", name))
+		builder.WriteString(fmt.Sprintf("  events: %sEvent;\n", name))
+		builder.WriteString("}\n")
+	}
+
+	builder.WriteString("\n")
+	_writeTypeScriptUnion(builder, "BlockKeyType", blockKeys)
+	_writeTypeScriptUnion(builder, "ActionKeyType", actionKeys)
+
+	builder.WriteString("\nexport interface BlockIntegrations {\n")
+	for _, keyType := range blockKeys {
+		builder.WriteString(fmt.Sprintf("  %s: %s;\n", _typeScriptString(keyType), blockTypes[keyType]))
+	}
+	builder.WriteString("}\n")
+
+	builder.WriteString("\nexport interface ActionIntegrations {\n")
+	for _, keyType := range actionKeys {
+		builder.WriteString(fmt.Sprintf("  %s: %s;\n", _typeScriptString(keyType), actionTypes[keyType]))
+	}
+	builder.WriteString("}\n")
+}
+
+// _writeTypeScriptPropertyMap maps each property key to the literal of its declared integration type.
+func _writeTypeScriptPropertyMap(builder *strings.Builder, name string, properties []validator.PropertyDefinition) {
+	builder.WriteString(fmt.Sprintf("export interface %s {\n", name))
+	for _, prop := range properties {
+		builder.WriteString(fmt.Sprintf("  %s: %s;\n", _typeScriptString(prop.Key), _typeScriptString(prop.Type)))
+	}
+	builder.WriteString("}\n")
+}
+
+func _writeTypeScriptDataMap(builder *strings.Builder, name string, data []validator.DataDefinition) {
+	builder.WriteString(fmt.Sprintf("export interface %s {\n", name))
+	for _, d := range data {
+		builder.WriteString(fmt.Sprintf("  %s: %s;\n", _typeScriptString(d.Key), _typeScriptString(d.Type)))
+	}
+	builder.WriteString("}\n")
+}
+
+func _writeTypeScriptUnion(builder *strings.Builder, name string, values []string) {
+	if len(values) == 0 {
+		builder.WriteString(fmt.Sprintf("export type %s = never;\n", name))
+		return
+	}
+	literals := make([]string, len(values))
+	for i, value := range values {
+		literals[i] = _typeScriptString(value)
+	}
+	builder.WriteString(fmt.Sprintf("export type %s = %s;\n", name, strings.Join(literals, " | ")))
+}
+
+func _writeTypeScriptFrame(builder *strings.Builder, frame frameInfo, name string) {
+	builder.WriteString(fmt.Sprintf("\n// Frame %s\n\n", frame.name))
+
+	builder.WriteString(fmt.Sprintf("export interface %sVariables {\n", name))
+	for _, variable := range frame.variables {
+		builder.WriteString(fmt.Sprintf("  %s: %s;\n", _typeScriptString(variable.key), _typeScriptString(variable.varType)))
+	}
+	builder.WriteString("}\n")
+
+	variableKeys := make([]string, 0, len(frame.variables))
+	for _, variable := range frame.variables {
+		variableKeys = append(variableKeys, variable.key)
+	}
+	_writeTypeScriptUnion(builder, name+"VariableKey", variableKeys)
+
+	blockKeys := make([]string, 0, len(frame.blocks))
+	for _, block := range frame.blocks {
+		blockKeys = append(blockKeys, block.key)
+	}
+	_writeTypeScriptUnion(builder, name+"BlockKey", blockKeys)
+	_writeTypeScriptUnion(builder, name+"RouteArgument", frame.routeArgs)
+
+	builder.WriteString(fmt.Sprintf("export interface %sEvents {\n", name))
+	for _, block := range frame.eventOwners() {
+		literals := make([]string, len(block.events))
+		for i, event := range block.events {
+			literals[i] = _typeScriptString(event)
+		}
+		builder.WriteString(fmt.Sprintf("  %s: %s;\n", _typeScriptString(block.key), strings.Join(literals, " | ")))
+	}
+	builder.WriteString("}\n")
+
+	builder.WriteString(fmt.Sprintf("export type %sJson = FrameJson & { name: %s; route: %s };\n",
+		name, _typeScriptString(frame.name), _typeScriptString(frame.route)))
+}
+
+func _eventNames(events []validator.EventDefinition) []string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Event
+	}
+	return names
+}
+
+func _slotNames(slots []validator.SlotDefinition) []string {
+	names := make([]string, len(slots))
+	for i, slot := range slots {
+		names[i] = slot.Slot
+	}
+	return names
+}
+
+func _typeScriptString(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return "\"" + s + "\""
+}
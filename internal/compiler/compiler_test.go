@@ -6,8 +6,11 @@ import (
 	"testing"
 
 	"github.com/nativeblocks/nbx/internal/formatter"
+	"github.com/nativeblocks/nbx/internal/i18n"
 	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
 	"github.com/nativeblocks/nbx/internal/parser"
+	"github.com/nativeblocks/nbx/internal/theme"
 	"github.com/nativeblocks/nbx/internal/validator"
 )
 
@@ -229,3 +232,846 @@ func TestToString(t *testing.T) {
 
 	t.Log("Reconstructed DSL is valid and parseable!")
 }
+
+const componentsDSL = `
+frame(name = "profile", route = "/profile") {
+    var userName: STRING = "Ada"
+    var showBio: BOOLEAN = true
+
+    component(name = "card") {
+        prop title: STRING = "Untitled"
+        prop size: INT = 16
+        data label
+
+        block(keyType = "nativeblocks/column", key = "card")
+            .slot("content") {
+                block(keyType = "nativeblocks/text", key = "title")
+                    .prop(text = "{prop:title}", fontSize = "{prop:size}")
+                    .data(text = label)
+                block(outlet = "body")
+            }
+            .action(event = "onClick") {
+                trigger(keyType = "nativeblocks/change_block_property", name = "highlight")
+                    .prop(blockKey = "title")
+            }
+    }
+
+    block(keyType = "ROOT", key = "root")
+        .slot("content") {
+            block(component = "card", key = "userCard", visibility = showBio)
+                .prop(title = "Profile")
+                .data(label = userName)
+                .slot("body") {
+                    block(keyType = "nativeblocks/text", key = "bio")
+                }
+            block(component = "card")
+                .data(label = userName)
+        }
+}
+`
+
+func _parseComponents(t *testing.T, dsl string) *model.FrameDSLModel {
+	t.Helper()
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatal("Parse error:", p.ErrorCollector().FormatAll())
+	}
+	return frame
+}
+
+func TestExpandComponents(t *testing.T) {
+	frame := _parseComponents(t, componentsDSL)
+
+	expansions, errs := ExpandComponents(frame)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %s", errs[0].Message)
+	}
+	if len(frame.Components) != 0 {
+		t.Errorf("Expected component declarations to be removed")
+	}
+
+	collector, _ := validator.Validate(frame)
+	if collector.HasErrors() {
+		t.Fatal("Expanded frame is invalid:", collector.FormatAll())
+	}
+
+	children := frame.Blocks[0].Blocks
+	if len(children) != 2 || children[0].Key != "userCard" || children[1].Key != "card_1" {
+		t.Fatalf("Expected instances 'userCard' and 'card_1', got %+v", children)
+	}
+
+	card := children[0]
+	if card.KeyType != "nativeblocks/column" || card.Slot != "content" || card.VisibilityKey != "showBio" {
+		t.Errorf("Unexpected instance root %+v", card)
+	}
+	if len(card.Blocks) != 2 || card.Blocks[0].Key != "userCard_title" || card.Blocks[1].Key != "bio" {
+		t.Fatalf("Expected title and outlet blocks, got %+v", card.Blocks)
+	}
+	title := card.Blocks[0]
+	if title.Properties[0].ValueMobile != "Profile" || title.Properties[1].ValueTablet != "16" || title.Properties[1].Type != "INT" {
+		t.Errorf("Expected props to be bound, got %+v", title.Properties)
+	}
+	if title.Data[0].Value != "userName" {
+		t.Errorf("Expected data to be bound to userName, got %q", title.Data[0].Value)
+	}
+	if card.Blocks[1].Slot != "content" {
+		t.Errorf("Expected outlet block in slot 'content', got %q", card.Blocks[1].Slot)
+	}
+	trigger := card.Actions[0].Triggers[0]
+	if trigger.Properties[0].Value != "userCard_title" {
+		t.Errorf("Expected blockKey to follow the instance keys, got %+v", trigger.Properties)
+	}
+
+	defaulted := children[1].Blocks
+	if len(defaulted) != 1 || defaulted[0].Properties[0].ValueMobile != "Untitled" {
+		t.Errorf("Expected default prop and removed empty outlet, got %+v", defaulted)
+	}
+
+	if len(expansions) != 2 || expansions[1].Key != "card_1" || expansions[1].DefinitionLine != 6 {
+		t.Errorf("Unexpected expansions %+v", expansions)
+	}
+}
+
+func TestExpandComponentsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance string
+		expected string
+	}{
+		{"unknown component", `block(component = "panel", key = "p")`, "Unknown component 'panel'"},
+		{"missing data", `block(component = "card", key = "c")`, "Component 'card' requires data 'label'"},
+		{"unknown prop", `block(component = "card", key = "c").prop(color = "red").data(label = userName)`, "Component 'card' has no prop 'color'"},
+		{"invalid prop", `block(component = "card", key = "c").prop(size = "big").data(label = userName)`, "Invalid value for prop 'size'"},
+		{"unknown outlet", `block(component = "card", key = "c").data(label = userName).slot("footer") { block(keyType = "nativeblocks/text", key = "t") }`, "Component 'card' has no outlet 'footer'"},
+		{"generated key collision", `block(component = "card", key = "c").data(label = userName)
+            block(keyType = "nativeblocks/text", key = "c_title")`, "Block 'title' of component 'card' instance 'c' expands to key 'c_title', which is already used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsl := strings.Replace(componentsDSL, `block(component = "card")
+                .data(label = userName)`, tt.instance, 1)
+			frame := _parseComponents(t, dsl)
+
+			_, errs := ExpandComponents(frame)
+			if len(errs) == 0 || !strings.Contains(errs[0].Message, tt.expected) {
+				t.Fatalf("Expected error containing %q, got %v", tt.expected, errs)
+			}
+			if errs[0].Line == 0 || len(errs[0].RelatedInfo) == 0 && tt.name != "unknown component" {
+				t.Errorf("Expected error to point at the use site and the definition, got %+v", errs[0])
+			}
+		})
+	}
+}
+
+func TestAnnotateExpansions(t *testing.T) {
+	frame := _parseComponents(t, strings.Replace(componentsDSL, `.data(text = label)`, `.data(text = missing)`, 1))
+
+	expansions, errs := ExpandComponents(frame)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %s", errs[0].Message)
+	}
+	collector, _ := validator.Validate(frame)
+	AnnotateExpansions(collector.Errors(), expansions)
+
+	if !collector.HasErrors() {
+		t.Fatal("Expected an undefined variable error")
+	}
+	related := strings.Join(collector.Errors()[0].RelatedInfo, "\n")
+	if !strings.Contains(related, "instantiated as 'userCard' at line 26") || !strings.Contains(related, "instantiated as 'card_1'") {
+		t.Errorf("Expected notes for both instances, got %v", related)
+	}
+}
+
+func TestFormatComponentsRoundTrip(t *testing.T) {
+	frame := _parseComponents(t, componentsDSL)
+	formatted := formatter.FormatFrameDSL(*frame)
+	reparsed := _parseComponents(t, formatted)
+	if len(reparsed.Components) != 1 || reparsed.Blocks[0].Blocks[0].Component != "card" {
+		t.Fatalf("Expected components to survive formatting:\n%s", formatted)
+	}
+
+	xmlFrame, errs := parser.ParseXML(formatter.FormatFrameXML(*frame))
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected XML errors: %s", errs[0].Message)
+	}
+	if _, errs := ExpandComponents(&xmlFrame); len(errs) > 0 {
+		t.Fatalf("Unexpected expansion errors from XML: %s", errs[0].Message)
+	}
+	if key := xmlFrame.Blocks[0].Blocks[0].Blocks[0].Key; key != "userCard_title" {
+		t.Errorf("Expected XML instance to expand, got %q", key)
+	}
+}
+
+func TestToJsonWithTheme(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "themed", route = "/themed") {
+    var title: STRING = "Hello"
+
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(keyType = "nativeblocks/text", key = "title", version = 1)
+        .prop(fontSize = (mobile = @typography.body, desktop = @typography.title))
+        .data(text = title)
+    }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	themeTokens, err := theme.Parse(`{"typography": {"body": "14", "title": {"mobile": "20", "desktop": "28"}}}`)
+	if err != nil {
+		t.Fatalf("Failed to parse theme: %v", err)
+	}
+
+	_fontSize := func(frame model.FrameJson) model.BlockPropertyJson {
+		for _, block := range frame.Blocks {
+			for _, prop := range block.Properties {
+				if prop.Key == "fontSize" {
+					return prop
+				}
+			}
+		}
+		t.Fatal("fontSize not found")
+		return model.BlockPropertyJson{}
+	}
+
+	resolved, err := ToJsonWithOptions(*frameDSL, string(blocksJSON), string(actionsJSON), "", Options{Theme: themeTokens})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if prop := _fontSize(resolved); prop.ValueMobile != "14" || prop.ValueDesktop != "28" {
+		t.Errorf("Expected resolved tokens, got %+v", prop)
+	}
+	if frameDSL.Blocks[0].Blocks[0].Properties[0].ValueMobile != "@typography.body" {
+		t.Errorf("Expected the input frame to be left unchanged")
+	}
+
+	kept, err := ToJsonWithOptions(*frameDSL, string(blocksJSON), string(actionsJSON), "", Options{Theme: themeTokens, KeepTokens: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if prop := _fontSize(kept); prop.ValueMobile != "@typography.body" || prop.ValueDesktop != "@typography.title" {
+		t.Errorf("Expected symbolic tokens, got %+v", prop)
+	}
+
+	unknown, _ := theme.Parse(`{"typography": {"bodyLarge": "16"}}`)
+	_, err = ToJsonWithOptions(*frameDSL, string(blocksJSON), string(actionsJSON), "", Options{Theme: unknown, KeepTokens: true})
+	if err == nil || !strings.Contains(err.Error(), "Unknown token '@typography.body'") {
+		t.Errorf("Expected an unknown token error, got %v", err)
+	}
+
+	formatted := formatter.FormatFrameDSL(*frameDSL)
+	if !strings.Contains(formatted, "fontSize = (mobile = @typography.body, desktop = @typography.title)") {
+		t.Errorf("Expected references to be formatted unquoted:\n%s", formatted)
+	}
+}
+
+func TestToJsonLocales(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "welcome", route = "/welcome") {
+    var title: STRING = @string.welcome.title
+
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(keyType = "nativeblocks/text", key = "title", version = 1)
+        .data(text = title)
+    }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	en, _ := i18n.ParseJSON(`{"locale": "en", "strings": {"welcome": {"title": "Welcome"}}}`)
+	fr, _ := i18n.ParseJSON(`{"locale": "fr", "strings": {"welcome.title": "Bienvenue"}}`)
+
+	locales, err := ToJsonLocales(*frameDSL, string(blocksJSON), string(actionsJSON), "", []*i18n.Bundle{en, fr}, Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if locales["en"].Variables[0].Value != "Welcome" || locales["fr"].Variables[0].Value != "Bienvenue" {
+		t.Errorf("Expected a translated frame per locale, got %q and %q", locales["en"].Variables[0].Value, locales["fr"].Variables[0].Value)
+	}
+	if locales["en"].Id != locales["fr"].Id || locales["en"].Blocks[0].Id != locales["fr"].Blocks[0].Id {
+		t.Errorf("Expected the locales to share their ids")
+	}
+
+	symbolic, err := ToJsonWithOptions(*frameDSL, string(blocksJSON), string(actionsJSON), "", Options{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if symbolic.Variables[0].Value != "@string.welcome.title" {
+		t.Errorf("Expected the key to stay symbolic without a bundle, got %q", symbolic.Variables[0].Value)
+	}
+
+	de, _ := i18n.ParseJSON(`{"locale": "de", "strings": {"welcome.titel": "Willkommen"}}`)
+	_, err = ToJsonWithOptions(*frameDSL, string(blocksJSON), string(actionsJSON), "", Options{Strings: de})
+	if err == nil || !strings.Contains(err.Error(), "Missing translation for '@string.welcome.title' in locale 'de' at line 2") {
+		t.Errorf("Expected a missing translation error, got %v", err)
+	}
+}
+
+func TestToJsonRichTypes(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "catalog", route = "/catalog") {
+    var count: INT = 2
+    var brand: COLOR = #2563EB
+    var tags: LIST<STRING> = ["new", "sale"]
+
+    block(keyType = "ROOT", key = "root")
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][3]string{{"INT", "", "2"}, {"STRING", "COLOR", "#2563EB"}, {"STRING", "LIST<STRING>", `["new", "sale"]`}}
+	for i, variable := range frameJson.Variables {
+		if got := [3]string{variable.Type, variable.SourceType, variable.Value}; got != expected[i] {
+			t.Errorf("Expected %s to compile to %v, got %v", variable.Key, expected[i], got)
+		}
+	}
+
+	back := ToDsl(frameJson)
+	if back.Variables[1].Type != "COLOR" || back.Variables[2].Type != "LIST<STRING>" {
+		t.Errorf("Expected the declared types to be restored, got %s and %s", back.Variables[1].Type, back.Variables[2].Type)
+	}
+}
+
+func TestToJsonEnums(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "loader", route = "/loader") {
+    enum Status { IDLE, LOADING, ERROR }
+    var status: Status = IDLE
+
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(keyType = "nativeblocks/change_variable", name = "load")
+                .data(variableKey = status)
+                .prop(variableValue = "LOADING")
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	variable := frameJson.Variables[0]
+	if variable.Type != "STRING" || variable.SourceType != "Status" || strings.Join(variable.Members, ",") != "IDLE,LOADING,ERROR" {
+		t.Errorf("Expected status to compile to a STRING with the enum metadata, got %+v", variable)
+	}
+
+	back := ToDsl(frameJson)
+	if len(back.Enums) != 1 || back.Enums[0].Name != "Status" || back.Variables[0].Type != "Status" {
+		t.Errorf("Expected the enum to be restored, got %+v and %s", back.Enums, back.Variables[0].Type)
+	}
+}
+
+func TestToJsonExpressions(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "cart", route = "/cart") {
+    var count: INT = 2
+    var title: STRING = "Cart"
+
+    block(keyType = "ROOT", key = "root")
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "summary", visibility = $(count>0))
+                .data(text = $(title + ": " + count))
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var summary model.BlockJson
+	for _, block := range frameJson.Blocks {
+		if block.Key == "summary" {
+			summary = block
+		}
+	}
+	if summary.VisibilityKey != "$(count > 0)" {
+		t.Errorf("Expected the canonical visibility expression, got %q", summary.VisibilityKey)
+	}
+	if len(summary.Data) != 1 || summary.Data[0].Value != `$(title + ": " + count)` || summary.Data[0].Type != "STRING" {
+		t.Errorf("Expected the data expression with its type, got %+v", summary.Data)
+	}
+
+	frameDSL.Blocks[0].Blocks[0].VisibilityKey = "$(title)"
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil || !strings.Contains(err.Error(), "must be BOOLEAN, got STRING") {
+		t.Errorf("Expected a non-BOOLEAN visibility to fail, got %v", err)
+	}
+}
+
+func TestToJsonTemplates(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "inbox", route = "/inbox") {
+    var username: STRING = "Ada"
+    var count: INT = 2
+
+    block(keyType = "ROOT", key = "root")
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "greeting")
+                .data(text = "Hello, {username}! You have {count} messages")
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var data model.BlockDataJson
+	for _, block := range frameJson.Blocks {
+		if block.Key == "greeting" {
+			data = block.Data[0]
+		}
+	}
+	expected := []model.TemplatePartJson{
+		{Text: "Hello, "}, {Variable: "username"}, {Text: "! You have "}, {Variable: "count"}, {Text: " messages"},
+	}
+	if data.Value != "Hello, {username}! You have {count} messages" || data.Type != "STRING" || len(data.Template) != len(expected) {
+		t.Fatalf("Expected the template with its parts, got %+v", data)
+	}
+	for i := range expected {
+		if data.Template[i] != expected[i] {
+			t.Errorf("Part %d: expected %+v, got %+v", i, expected[i], data.Template[i])
+		}
+	}
+
+	formatted := ToString(ToDsl(frameJson))
+	if !strings.Contains(formatted, `.data(text = "Hello, {username}! You have {count} messages")`) {
+		t.Errorf("Expected the template to be formatted as a string, got:\n%s", formatted)
+	}
+
+	frameDSL.Blocks[0].Blocks[0].Data[0].Value = "Hi {nickname}"
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil || !strings.Contains(err.Error(), "undefined variable 'nickname'") {
+		t.Errorf("Expected an undefined placeholder to fail, got %v", err)
+	}
+}
+
+func TestToJsonRepeat(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "shop", route = "/shop") {
+    var products: LIST<MAP<STRING, STRING>> = [{"name": "Tea"}, {"name": "Coffee"}]
+
+    block(keyType = "ROOT", key = "root")
+        .slot("content") {
+            block(keyType = "nativeblocks/column", key = "list")
+                .repeat(items = products, as = product)
+                .slot("content") {
+                    block(keyType = "nativeblocks/text", key = "name")
+                        .data(text = product.name)
+                    block(keyType = "nativeblocks/text", key = "buy")
+                        .data(text = "Buy {product.name}")
+                }
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, block := range frameJson.Blocks {
+		switch block.Key {
+		case "list":
+			expected := model.BlockRepeatJson{Items: "products", As: "product", ItemType: "MAP<STRING,STRING>"}
+			if block.Repeat == nil || *block.Repeat != expected {
+				t.Errorf("Expected repeat %+v, got %+v", expected, block.Repeat)
+			}
+		case "name":
+			if block.Data[0].Value != "product.name" || block.Data[0].Type != "STRING" {
+				t.Errorf("Expected the field binding to resolve, got %+v", block.Data[0])
+			}
+		case "buy":
+			if len(block.Data[0].Template) != 2 || block.Data[0].Template[1].Variable != "product.name" {
+				t.Errorf("Expected the field placeholder in the template, got %+v", block.Data[0])
+			}
+		}
+	}
+
+	formatted := ToString(ToDsl(frameJson))
+	if !strings.Contains(formatted, ".repeat(items = products, as = product)") {
+		t.Errorf("Expected the repeat to be formatted, got:\n%s", formatted)
+	}
+
+	frameDSL.Blocks[0].Blocks[0].Blocks[0].Data[0].Value = "item.name"
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil {
+		t.Errorf("Expected an undefined loop variable to fail")
+	}
+}
+
+func TestToJsonComputed(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "cart", route = "/cart") {
+    var price: DOUBLE = 2.5
+    var quantity: INT = 4
+    val total: DOUBLE = price * quantity
+    val summary: STRING = "Total: " + total
+
+    block(keyType = "ROOT", key = "root")
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "summary")
+                .data(text = summary)
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	total, summary := frameJson.Variables[2], frameJson.Variables[3]
+	if total.Expression != "price * quantity" || total.Value != "10" || total.Type != "DOUBLE" {
+		t.Errorf("Expected the expression and initial value of 'total', got %+v", total)
+	}
+	if summary.Expression != `"Total: " + total` || summary.Value != "Total: 10" {
+		t.Errorf("Expected the expression and initial value of 'summary', got %+v", summary)
+	}
+	if frameJson.Variables[0].Expression != "" {
+		t.Errorf("Expected no expression for 'price', got %q", frameJson.Variables[0].Expression)
+	}
+
+	formatted := ToString(ToDsl(frameJson))
+	if !strings.Contains(formatted, "val total: DOUBLE = price * quantity") {
+		t.Errorf("Expected the computed variable to be formatted with val, got:\n%s", formatted)
+	}
+
+	frameDSL.Variables[1] = model.VariableDSLModel{Key: "quantity", Type: "INT", Value: "total", Computed: true}
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil || !strings.Contains(err.Error(), "depends on itself: quantity -> total -> quantity") {
+		t.Errorf("Expected a cycle to fail, got %v", err)
+	}
+}
+
+func TestToJsonFrameActions(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "feed", route = "/feed") {
+    var status: STRING = "idle"
+
+    action(event = "onLoad") {
+        trigger(keyType = "nativeblocks/change_variable", name = "load")
+            .data(variableKey = status)
+            .prop(variableValue = "loading")
+    }
+
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(keyType = "nativeblocks/change_variable", name = "reset")
+                .data(variableKey = status)
+                .prop(variableValue = "idle")
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(frameJson.Actions) != 2 || frameJson.Actions[0].Key != model.FrameActionKey || frameJson.Actions[0].Event != "onLoad" {
+		t.Fatalf("Expected the frame action first with key %s, got %+v", model.FrameActionKey, frameJson.Actions)
+	}
+
+	back := ToDsl(frameJson)
+	if len(back.Actions) != 1 || back.Actions[0].Event != "onLoad" || back.Actions[0].Key != "" {
+		t.Errorf("Expected the frame action to be restored at frame level, got %+v", back.Actions)
+	}
+	if root := back.Blocks[0]; len(root.Actions) != 1 || root.Actions[0].Event != "onClick" {
+		t.Errorf("Expected only the block action on ROOT, got %+v", root.Actions)
+	}
+	if formatted := ToString(back); !strings.Contains(formatted, "    action(event = \"onLoad\") {") {
+		t.Errorf("Expected the frame action to be formatted in the frame body, got:\n%s", formatted)
+	}
+
+	frameDSL.Actions[0].Event = "onAppear"
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil || !strings.Contains(err.Error(), "invalid frame event 'onAppear'") {
+		t.Errorf("Expected an unknown frame event to fail, got %v", err)
+	}
+	if _, err := ToJsonWithOptions(*frameDSL, string(blocksJSON), string(actionsJSON), "", Options{FrameEvents: []string{"onAppear"}}); err != nil {
+		t.Errorf("Expected a configured frame event to compile, got %v", err)
+	}
+	if _, err := ToJsonWithOptions(*frameDSL, string(blocksJSON), string(actionsJSON), "", Options{FrameEvents: []string{}}); err != nil {
+		t.Errorf("Expected an empty frame event registry to allow any event, got %v", err)
+	}
+}
+
+func TestToJsonSequences(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "form", route = "/form") {
+    var status: STRING = "idle"
+
+    sequence(name = "validate") {
+        trigger(keyType = "nativeblocks/change_variable", name = "check")
+            .data(variableKey = status)
+            .prop(variableValue = "checking")
+            .then("SUCCESS") {
+                trigger(sequence = "finish")
+            }
+    }
+    sequence(name = "finish") {
+        trigger(keyType = "nativeblocks/change_variable", name = "done")
+            .data(variableKey = status)
+            .prop(variableValue = "valid")
+    }
+
+    action(event = "onLoad") {
+        trigger(sequence = "validate")
+    }
+
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(sequence = "validate")
+            trigger(keyType = "nativeblocks/change_variable", name = "submit")
+                .data(variableKey = status)
+                .prop(variableValue = "sent")
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(frameJson.Actions) != 2 {
+		t.Fatalf("Expected 2 actions, got %d", len(frameJson.Actions))
+	}
+	onLoad, onClick := frameJson.Actions[0], frameJson.Actions[1]
+	if len(onLoad.Triggers) != 2 || len(onClick.Triggers) != 3 {
+		t.Fatalf("Expected the calls to be expanded, got %+v and %+v", onLoad.Triggers, onClick.Triggers)
+	}
+	check, done, submit := onClick.Triggers[0], onClick.Triggers[1], onClick.Triggers[2]
+	if check.Name != "check" || check.ParentId != "" || check.Then != "NEXT" {
+		t.Errorf("Expected 'check' at the top of the action, got %+v", check)
+	}
+	if done.Name != "done" || done.ParentId != check.Id || done.Then != "SUCCESS" {
+		t.Errorf("Expected 'done' to run after 'check' succeeds, got %+v", done)
+	}
+	if submit.Name != "submit" || submit.ParentId != "" {
+		t.Errorf("Expected 'submit' after the expanded sequence, got %+v", submit)
+	}
+	for _, trigger := range onClick.Triggers {
+		if trigger.ActionId != onClick.Id || trigger.Id == onLoad.Triggers[0].Id || trigger.Id == onLoad.Triggers[1].Id {
+			t.Errorf("Expected every call to get its own triggers of action %s, got %+v", onClick.Id, trigger)
+		}
+	}
+	if len(frameDSL.Sequences) != 2 || frameDSL.Blocks[0].Actions[0].Triggers[0].Sequence != "validate" {
+		t.Errorf("Expected ToJson to leave the frame unchanged")
+	}
+
+	frameDSL.Sequences[1].Triggers = append(frameDSL.Sequences[1].Triggers, model.ActionTriggerDSLModel{Sequence: "validate", Then: "NEXT", Line: 18, Column: 9})
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil || !strings.Contains(err.Error(), "Trigger sequence 'validate' calls itself: validate -> finish -> validate at line 18") {
+		t.Errorf("Expected recursion between sequences to fail, got %v", err)
+	}
+
+	frameDSL.Sequences = frameDSL.Sequences[:1]
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil || !strings.Contains(err.Error(), "Unknown trigger sequence 'finish'") {
+		t.Errorf("Expected an unknown sequence to fail, got %v", err)
+	}
+}
+
+func TestToJsonConditions(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "cart", route = "/cart") {
+    var count: INT = 0
+
+    sequence(name = "reset") {
+        trigger(keyType = "nativeblocks/change_variable", name = "clear")
+            .data(variableKey = count)
+            .prop(variableValue = "0")
+    }
+
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(keyType = "nativeblocks/change_variable", name = "increase")
+                .data(variableKey = count)
+                .prop(variableValue = "1")
+                .when((count < 10))
+            if (count == 0) {
+                trigger(keyType = "nativeblocks/change_variable", name = "first")
+                    .data(variableKey = count)
+            } else {
+                trigger(sequence = "reset").when(count>5)
+            }
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	triggers := frameJson.Actions[0].Triggers
+	if len(triggers) != 5 {
+		t.Fatalf("Expected 5 triggers, got %+v", triggers)
+	}
+	increase, branch, first, guard, clear := triggers[0], triggers[1], triggers[2], triggers[3], triggers[4]
+	if increase.Condition != "count < 10" {
+		t.Errorf("Expected the canonical guard of 'increase', got %q", increase.Condition)
+	}
+	if branch.KeyType != model.ConditionKeyType || branch.Condition != "count == 0" || branch.Then != "NEXT" || branch.ParentId != "" {
+		t.Errorf("Expected an if on count == 0, got %+v", branch)
+	}
+	if first.Name != "first" || first.ParentId != branch.Id || first.Then != "SUCCESS" {
+		t.Errorf("Expected 'first' on SUCCESS of the if, got %+v", first)
+	}
+	if guard.KeyType != model.ConditionKeyType || guard.Condition != "count > 5" || guard.ParentId != branch.Id || guard.Then != "FAILURE" {
+		t.Errorf("Expected the guarded call as an if on FAILURE of the if, got %+v", guard)
+	}
+	if clear.Name != "clear" || clear.ParentId != guard.Id || clear.Then != "SUCCESS" {
+		t.Errorf("Expected the sequence on SUCCESS of the guard, got %+v", clear)
+	}
+
+	restored := ToDsl(frameJson)
+	action := restored.Blocks[0].Actions[0]
+	if action.Triggers[0].Condition != "count < 10" || action.Triggers[1].KeyType != model.ConditionKeyType ||
+		action.Triggers[1].Condition != "count == 0" || len(action.Triggers[1].Triggers) != 2 {
+		t.Errorf("Expected ToDsl to restore the conditions, got %+v", action.Triggers)
+	}
+
+	frameDSL.Blocks[0].Actions[0].Triggers[0].Condition = "count + 1"
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil || !strings.Contains(err.Error(), "increase trigger condition: condition count + 1 must be BOOLEAN, got INT") {
+		t.Errorf("Expected a condition that is not BOOLEAN to fail, got %v", err)
+	}
+}
+
+func TestToJsonStyles(t *testing.T) {
+	blocksJSON, _ := os.ReadFile("../example/blocks.json")
+	actionsJSON, _ := os.ReadFile("../example/actions.json")
+
+	dsl := `frame(name = "form", route = "/form") {
+    const radius: INT = 12
+
+    style rounded {
+        .prop(radiusTopStart = "{const:radius}", backgroundColor = "#FFFFFF")
+    }
+    style primary {
+        .style(rounded)
+        .prop(
+            backgroundColor = "#2563EB",
+            width = (mobile = "100", desktop = "200")
+        )
+    }
+
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(keyType = "nativeblocks/button", key = "submit")
+        .style(primary)
+        .prop(width = "match")
+        block(keyType = "nativeblocks/button", key = "cancel")
+        .style(primary)
+    }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frameDSL := p.ParseNBX()
+	if frameDSL == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	frameJson, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	submit, cancel := frameJson.Blocks[1], frameJson.Blocks[2]
+	if len(submit.Properties) != 3 {
+		t.Fatalf("Expected the style props on submit, got %+v", submit.Properties)
+	}
+	radius, background, width := submit.Properties[0], submit.Properties[1], submit.Properties[2]
+	if radius.Key != "radiusTopStart" || radius.ValueMobile != "12" {
+		t.Errorf("Expected the composed radius with its constant resolved, got %+v", radius)
+	}
+	if background.Key != "backgroundColor" || background.ValueMobile != "#2563EB" {
+		t.Errorf("Expected primary to override the background of rounded, got %+v", background)
+	}
+	if width.Key != "width" || width.ValueMobile != "match" || width.ValueDesktop != "match" {
+		t.Errorf("Expected the block to override the width of primary, got %+v", width)
+	}
+	if width := cancel.Properties[2]; width.ValueMobile != "100" || width.ValueTablet != "" || width.ValueDesktop != "200" {
+		t.Errorf("Expected the per-device width of primary on cancel, got %+v", width)
+	}
+	if len(frameDSL.Styles) != 2 || len(frameDSL.Blocks[0].Blocks[0].Properties) != 1 {
+		t.Errorf("Expected ToJson to leave the frame unchanged")
+	}
+
+	frameDSL.Blocks[0].Blocks[1].KeyType = "nativeblocks/text"
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil ||
+		!strings.Contains(err.Error(), "block 'cancel' uses style 'rounded' with invalid property 'radiusTopStart' for integration 'nativeblocks/text'") {
+		t.Errorf("Expected the style props to be checked against the integration, got %v", err)
+	}
+
+	frameDSL.Styles[0].Styles = []string{"primary"}
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil ||
+		!strings.Contains(err.Error(), "Style 'rounded' composes itself: rounded -> primary -> rounded at line 4") {
+		t.Errorf("Expected a cycle between styles to fail, got %v", err)
+	}
+
+	frameDSL.Styles = frameDSL.Styles[1:]
+	if _, err := ToJson(*frameDSL, string(blocksJSON), string(actionsJSON), ""); err == nil || !strings.Contains(err.Error(), "Unknown style 'rounded'") {
+		t.Errorf("Expected an unknown style to fail, got %v", err)
+	}
+}
@@ -0,0 +1,551 @@
+package compiler
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/expr"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/types"
+	"github.com/nativeblocks/nbx/internal/validator"
+)
+
+// componentPropPattern matches a reference to a component prop inside a property value.
+var componentPropPattern = regexp.MustCompile(`\{prop:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// blockKeyProperty is the trigger property that refers to another block.
+const blockKeyProperty = "blockKey"
+
+// Expansion records a component instance replaced by ExpandComponents, so issues found in the expanded
+// blocks can be reported at both the component definition and the use site.
+type Expansion struct {
+	Component string
+	// Key is the key of the instance root block. The other blocks of the instance are keyed
+	// "<Key>_<definition key>".
+	Key string
+	// Keys are all block keys generated for the instance.
+	Keys []string
+	// File is the file of the component definition, empty when it is declared in the frame itself.
+	File   string
+	Line   int
+	Column int
+
+	DefinitionLine   int
+	DefinitionColumn int
+	// positions holds the source positions of the definition's nodes copied into the instance.
+	positions map[[2]int]bool
+}
+
+// ExpandComponents replaces every component instance of the frame with a copy of the component's root
+// block and removes the component declarations, leaving ordinary blocks. Instance props replace
+// {prop:name} references, instance data replaces bindings to the component's data parameters and the
+// instance's child blocks are placed at the outlet of the same name. Instances without a key get a
+// generated one. The other blocks of an instance are keyed <instance>_<key>; an instance whose keys are
+// already taken is reported.
+func ExpandComponents(frame *model.FrameDSLModel) ([]Expansion, []*errors.Error) {
+	e := &_expander{
+		components: make(map[string]*model.ComponentDSLModel),
+		enums:      validator.EnumTypes(frame.Enums),
+		used:       make(map[string]bool),
+		counters:   make(map[string]int),
+	}
+
+	for i := range frame.Components {
+		e._declare(&frame.Components[i])
+	}
+	_collectKeys(frame.Blocks, e.used)
+
+	frame.Blocks = e._expandBlocks(frame.Blocks, nil, "")
+	frame.Components = nil
+	e._checkStrayOutlets(frame.Blocks)
+
+	return e.expansions, e.errs
+}
+
+// Expand returns a copy of the frame with its constants resolved and its component instances expanded,
+// the form ToJson compiles and the validator checks. The frame itself keeps its constants and components,
+// so it still formats back to its source.
+func Expand(frame model.FrameDSLModel) (model.FrameDSLModel, []Expansion, []*errors.Error) {
+	expanded := model.CloneFrame(frame)
+	if errs := ResolveConstants(&expanded); len(errs) > 0 {
+		return expanded, nil, errs
+	}
+	expansions, errs := ExpandComponents(&expanded)
+	return expanded, expansions, errs
+}
+
+// HasComponents reports whether the frame declares or instantiates components.
+func HasComponents(frame model.FrameDSLModel) bool {
+	return len(frame.Components) > 0 || _containsInstance(frame.Blocks)
+}
+
+// AnnotateExpansions adds a note to every issue located inside a component definition, naming the
+// instances the definition was expanded into. When the message names a block of specific instances,
+// only those are listed. Issues inside an imported component get the component's file, and their
+// SourceLine, taken from the importing source, is cleared.
+func AnnotateExpansions(issues []*errors.Error, expansions []Expansion) {
+	for _, issue := range issues {
+		var inRange, named []Expansion
+		for _, expansion := range expansions {
+			if issue.File != "" && issue.File != expansion.File || !expansion.positions[[2]int{issue.Line, issue.Column}] {
+				continue
+			}
+			inRange = append(inRange, expansion)
+			if slices.ContainsFunc(expansion.Keys, func(key string) bool { return strings.Contains(issue.Message, "'"+key+"'") }) {
+				named = append(named, expansion)
+			}
+		}
+		if len(named) > 0 {
+			inRange = named
+		}
+		if len(inRange) > 0 && issue.File != inRange[0].File {
+			issue.File = inRange[0].File
+			issue.SourceLine = ""
+		}
+		for _, expansion := range inRange {
+			issue.RelatedInfo = append(issue.RelatedInfo, fmt.Sprintf(
+				"In component '%s' instantiated as '%s' at line %d, column %d",
+				expansion.Component, expansion.Key, expansion.Line, expansion.Column,
+			))
+		}
+	}
+}
+
+type _expander struct {
+	components map[string]*model.ComponentDSLModel
+	enums      map[string]types.Type
+	// used holds the block keys taken so far, so generated instance keys stay unique.
+	used       map[string]bool
+	counters   map[string]int
+	expansions []Expansion
+	errs       []*errors.Error
+}
+
+func (e *_expander) _declare(component *model.ComponentDSLModel) {
+	if existing, exists := e.components[component.Name]; exists {
+		e._fail(component.File, component.Line, component.Column, _declaredAt(existing), "Duplicate component '%s'", component.Name)
+		return
+	}
+	e.components[component.Name] = component
+
+	for _, param := range component.Properties {
+		paramType, err := types.FromStringWith(param.Type, e.enums)
+		if err != nil {
+			e._fail(component.File, param.Line, param.Column, "", "Unknown type '%s' for prop '%s' of component '%s'", param.Type, param.Key, component.Name)
+			continue
+		}
+		if !param.Required {
+			if valid, msg := types.ValidateValue(param.Value, paramType); !valid {
+				e._fail(component.File, param.Line, param.Column, "", "Invalid default value for prop '%s' of component '%s': %s", param.Key, component.Name, msg)
+			}
+		}
+	}
+}
+
+// _expandBlocks expands the instances among blocks and their descendants. file is the file the blocks
+// are declared in.
+func (e *_expander) _expandBlocks(blocks []model.BlockDSLModel, stack []string, file string) []model.BlockDSLModel {
+	if blocks == nil {
+		return nil
+	}
+	expanded := make([]model.BlockDSLModel, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Component == "" {
+			block.Blocks = e._expandBlocks(block.Blocks, stack, file)
+			expanded = append(expanded, block)
+			continue
+		}
+		if instance, ok := e._instantiate(block, stack, file); ok {
+			expanded = append(expanded, instance)
+		}
+	}
+	return expanded
+}
+
+// _instantiate expands one instance. stack holds the components being expanded, to reject components
+// that instantiate themselves.
+func (e *_expander) _instantiate(instance model.BlockDSLModel, stack []string, file string) (model.BlockDSLModel, bool) {
+	component, ok := e.components[instance.Component]
+	if !ok {
+		e._fail(file, instance.Line, instance.Column, "", "Unknown component '%s'", instance.Component)
+		return model.BlockDSLModel{}, false
+	}
+	declared := _declaredAt(component)
+	if slices.Contains(stack, component.Name) {
+		e._fail(file, instance.Line, instance.Column, declared, "Component '%s' instantiates itself (%s -> %s)",
+			component.Name, strings.Join(stack, " -> "), component.Name)
+		return model.BlockDSLModel{}, false
+	}
+
+	key := instance.Key
+	if key == "" {
+		key = e._generateKey(component.Name)
+	}
+
+	props, data, ok := e._arguments(instance, component, declared, file)
+	if !ok {
+		return model.BlockDSLModel{}, false
+	}
+
+	keys := make(map[string]string)
+	_collectDefinitionKeys(component.Block, component.Block.Key, key, keys)
+	// The root takes the instance key; every other generated key must not be taken already, by a block
+	// of the frame or of another instance.
+	for _, definitionKey := range slices.Sorted(maps.Keys(keys)) {
+		if generated := keys[definitionKey]; definitionKey != component.Block.Key && e.used[generated] {
+			e._fail(file, instance.Line, instance.Column, declared,
+				"Block '%s' of component '%s' instance '%s' expands to key '%s', which is already used",
+				definitionKey, component.Name, key, generated)
+			ok = false
+		}
+	}
+	if !ok {
+		return model.BlockDSLModel{}, false
+	}
+	for _, generated := range keys {
+		e.used[generated] = true
+	}
+
+	binding := _binding{component: component, keys: keys, props: props, data: data, instance: instance}
+	root := model.CloneBlock(component.Block)
+	e._bind(&root, binding)
+
+	positions := make(map[[2]int]bool)
+	_collectPositions(root, positions)
+
+	root.Slot = instance.Slot
+	if instance.VisibilityKey != "" {
+		root.VisibilityKey = instance.VisibilityKey
+	}
+	for _, action := range instance.Actions {
+		action.Key = root.Key
+		root.Actions = append(root.Actions, action)
+	}
+
+	expanded := e._expandBlocks([]model.BlockDSLModel{root}, append(slices.Clone(stack), component.Name), component.File)
+	if len(expanded) == 0 {
+		return model.BlockDSLModel{}, false
+	}
+	root = expanded[0]
+
+	children := make(map[string][]model.BlockDSLModel)
+	var order []string
+	for _, child := range e._expandBlocks(instance.Blocks, stack, file) {
+		if _, exists := children[child.Slot]; !exists {
+			order = append(order, child.Slot)
+		}
+		children[child.Slot] = append(children[child.Slot], child)
+	}
+	filled := make(map[string]bool)
+	root.Blocks = _fillOutlets(root.Blocks, children, filled)
+	for _, slot := range order {
+		if !filled[slot] {
+			child := children[slot][0]
+			e._fail(file, child.Line, child.Column, declared, "Component '%s' has no outlet '%s'", component.Name, slot)
+		}
+	}
+
+	expansion := Expansion{
+		Component:        component.Name,
+		Key:              key,
+		File:             component.File,
+		Line:             instance.Line,
+		Column:           instance.Column,
+		DefinitionLine:   component.Line,
+		DefinitionColumn: component.Column,
+		positions:        positions,
+	}
+	for _, generated := range keys {
+		expansion.Keys = append(expansion.Keys, generated)
+	}
+	slices.Sort(expansion.Keys)
+	e.expansions = append(e.expansions, expansion)
+
+	return root, true
+}
+
+// _arguments checks the instance props and data against the component parameters and returns the
+// values to bind, with defaults for omitted props.
+func (e *_expander) _arguments(instance model.BlockDSLModel, component *model.ComponentDSLModel, declared, file string) (map[string]model.BlockPropertyDSLModel, map[string]string, bool) {
+	ok := true
+	props := make(map[string]model.BlockPropertyDSLModel)
+	for _, arg := range instance.Properties {
+		index := slices.IndexFunc(component.Properties, func(p model.ComponentParamDSLModel) bool { return p.Key == arg.Key })
+		if index == -1 {
+			e._fail(file, arg.Line, arg.Column, declared, "Component '%s' has no prop '%s'", component.Name, arg.Key)
+			ok = false
+			continue
+		}
+		if paramType, err := types.FromStringWith(component.Properties[index].Type, e.enums); err == nil {
+			for _, value := range []string{arg.ValueMobile, arg.ValueTablet, arg.ValueDesktop} {
+				if valid, msg := types.ValidateValue(value, paramType); !valid {
+					e._fail(file, arg.Line, arg.Column, declared, "Invalid value for prop '%s' of component '%s': %s", arg.Key, component.Name, msg)
+					ok = false
+					break
+				}
+			}
+		}
+		props[arg.Key] = arg
+	}
+	for _, param := range component.Properties {
+		if _, given := props[param.Key]; given {
+			continue
+		}
+		if param.Required {
+			e._fail(file, instance.Line, instance.Column, declared, "Component '%s' requires prop '%s'", component.Name, param.Key)
+			ok = false
+			continue
+		}
+		props[param.Key] = model.BlockPropertyDSLModel{Key: param.Key, ValueMobile: param.Value, ValueTablet: param.Value, ValueDesktop: param.Value}
+	}
+
+	data := make(map[string]string)
+	for _, arg := range instance.Data {
+		if !slices.ContainsFunc(component.Data, func(p model.ComponentParamDSLModel) bool { return p.Key == arg.Key }) {
+			e._fail(file, arg.Line, arg.Column, declared, "Component '%s' has no data '%s'", component.Name, arg.Key)
+			ok = false
+			continue
+		}
+		data[arg.Key] = strings.TrimSpace(arg.Value)
+	}
+	for _, param := range component.Data {
+		if _, given := data[param.Key]; !given {
+			e._fail(file, instance.Line, instance.Column, declared, "Component '%s' requires data '%s'", component.Name, param.Key)
+			ok = false
+		}
+	}
+
+	return props, data, ok
+}
+
+// _binding holds what replaces the parameters of a component definition for one instance.
+type _binding struct {
+	component *model.ComponentDSLModel
+	instance  model.BlockDSLModel
+	// keys maps definition block keys to instance block keys.
+	keys  map[string]string
+	props map[string]model.BlockPropertyDSLModel
+	data  map[string]string
+}
+
+func (e *_expander) _bind(block *model.BlockDSLModel, b _binding) {
+	if block.Outlet != "" {
+		return
+	}
+	if key, ok := b.keys[block.Key]; ok {
+		block.Key = key
+	}
+	block.VisibilityKey = b._bindVariable(block.VisibilityKey)
+	if block.Repeat != nil {
+		block.Repeat.Items = b._bindVariable(block.Repeat.Items)
+	}
+
+	for i := range block.Properties {
+		prop := &block.Properties[i]
+		changed := false
+		prop.ValueMobile, changed = e._substitute(prop.ValueMobile, func(arg model.BlockPropertyDSLModel) string { return arg.ValueMobile }, prop.Line, prop.Column, b)
+		prop.ValueTablet, _ = e._substitute(prop.ValueTablet, func(arg model.BlockPropertyDSLModel) string { return arg.ValueTablet }, 0, 0, b)
+		prop.ValueDesktop, _ = e._substitute(prop.ValueDesktop, func(arg model.BlockPropertyDSLModel) string { return arg.ValueDesktop }, 0, 0, b)
+		if changed {
+			prop.Type = types.InferType(prop.ValueMobile).Name()
+		}
+	}
+	for i := range block.Data {
+		block.Data[i].Value = b._bindVariable(block.Data[i].Value)
+	}
+	for i := range block.Actions {
+		if key, ok := b.keys[block.Actions[i].Key]; ok {
+			block.Actions[i].Key = key
+		}
+		e._bindTriggers(block.Actions[i].Triggers, b)
+	}
+	for i := range block.Blocks {
+		e._bind(&block.Blocks[i], b)
+	}
+}
+
+// _bindVariable replaces a data parameter bound by a visibility key or data entry, or the data parameters
+// an expression or template refers to, with the instance variables.
+func (b _binding) _bindVariable(value string) string {
+	if expr.IsExpression(value) {
+		return expr.RenameValue(value, b.data)
+	}
+	if expr.IsTemplate(value) {
+		return expr.RenameTemplate(value, b.data)
+	}
+	if _, ok := b.data[expr.Root(strings.TrimSpace(value))]; ok {
+		return expr.RenamePath(strings.TrimSpace(value), b.data)
+	}
+	return value
+}
+
+func (e *_expander) _bindTriggers(triggers []model.ActionTriggerDSLModel, b _binding) {
+	for i := range triggers {
+		trigger := &triggers[i]
+		for j := range trigger.Properties {
+			prop := &trigger.Properties[j]
+			if key, ok := b.keys[prop.Value]; ok && prop.Key == blockKeyProperty {
+				prop.Value = key
+				continue
+			}
+			if value, changed := e._substitute(prop.Value, func(arg model.BlockPropertyDSLModel) string { return arg.ValueMobile }, prop.Line, prop.Column, b); changed {
+				prop.Value = value
+				prop.Type = types.InferType(value).Name()
+			}
+		}
+		for j := range trigger.Data {
+			trigger.Data[j].Value = b._bindVariable(trigger.Data[j].Value)
+		}
+		e._bindTriggers(trigger.Triggers, b)
+	}
+}
+
+// _substitute replaces the {prop:name} references of value. Unknown references are reported at line
+// and column unless line is 0.
+func (e *_expander) _substitute(value string, device func(model.BlockPropertyDSLModel) string, line, column int, b _binding) (string, bool) {
+	changed := false
+	result := componentPropPattern.ReplaceAllStringFunc(value, func(reference string) string {
+		name := componentPropPattern.FindStringSubmatch(reference)[1]
+		arg, ok := b.props[name]
+		if !ok {
+			if line > 0 {
+				e._fail(b.component.File, line, column,
+					fmt.Sprintf("In component '%s' instantiated as '%s' at line %d, column %d", b.component.Name, b.keys[b.component.Block.Key], b.instance.Line, b.instance.Column),
+					"Component '%s' has no prop '%s'", b.component.Name, name)
+			}
+			return reference
+		}
+		changed = true
+		return device(arg)
+	})
+	return result, changed
+}
+
+// _fillOutlets replaces the outlets among blocks and their descendants with the instance's blocks of
+// the same slot. Outlets without blocks are removed.
+func _fillOutlets(blocks []model.BlockDSLModel, children map[string][]model.BlockDSLModel, filled map[string]bool) []model.BlockDSLModel {
+	if blocks == nil {
+		return nil
+	}
+	result := make([]model.BlockDSLModel, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Outlet == "" {
+			block.Blocks = _fillOutlets(block.Blocks, children, filled)
+			result = append(result, block)
+			continue
+		}
+		for _, child := range children[block.Outlet] {
+			child.Slot = block.Slot
+			result = append(result, child)
+		}
+		filled[block.Outlet] = true
+	}
+	return result
+}
+
+func (e *_expander) _checkStrayOutlets(blocks []model.BlockDSLModel) {
+	for _, block := range blocks {
+		if block.Outlet != "" {
+			e._fail("", block.Line, block.Column, "", "Outlet '%s' can only be used inside a component", block.Outlet)
+		}
+		e._checkStrayOutlets(block.Blocks)
+	}
+}
+
+func (e *_expander) _generateKey(component string) string {
+	for {
+		e.counters[component]++
+		key := fmt.Sprintf("%s_%d", component, e.counters[component])
+		if !e.used[key] {
+			e.used[key] = true
+			return key
+		}
+	}
+}
+
+func (e *_expander) _fail(file string, line, column int, related string, format string, args ...any) {
+	err := &errors.Error{
+		Severity: errors.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     line,
+		Column:   column,
+	}
+	if related != "" {
+		err.RelatedInfo = []string{related}
+	}
+	e.errs = append(e.errs, err)
+}
+
+func _declaredAt(component *model.ComponentDSLModel) string {
+	if component.File != "" {
+		return fmt.Sprintf("Component '%s' is declared in %s at line %d, column %d", component.Name, component.File, component.Line, component.Column)
+	}
+	return fmt.Sprintf("Component '%s' is declared at line %d, column %d", component.Name, component.Line, component.Column)
+}
+
+// _collectPositions records the source positions of a block and its properties, data, actions and
+// triggers.
+func _collectPositions(block model.BlockDSLModel, positions map[[2]int]bool) {
+	positions[[2]int{block.Line, block.Column}] = true
+	for _, prop := range block.Properties {
+		positions[[2]int{prop.Line, prop.Column}] = true
+	}
+	for _, data := range block.Data {
+		positions[[2]int{data.Line, data.Column}] = true
+	}
+	for _, action := range block.Actions {
+		positions[[2]int{action.Line, action.Column}] = true
+		_collectTriggerPositions(action.Triggers, positions)
+	}
+	for _, child := range block.Blocks {
+		_collectPositions(child, positions)
+	}
+}
+
+func _collectTriggerPositions(triggers []model.ActionTriggerDSLModel, positions map[[2]int]bool) {
+	for _, trigger := range triggers {
+		positions[[2]int{trigger.Line, trigger.Column}] = true
+		for _, prop := range trigger.Properties {
+			positions[[2]int{prop.Line, prop.Column}] = true
+		}
+		for _, data := range trigger.Data {
+			positions[[2]int{data.Line, data.Column}] = true
+		}
+		_collectTriggerPositions(trigger.Triggers, positions)
+	}
+}
+
+// _collectDefinitionKeys maps the keys of a component's blocks to the keys of one instance.
+func _collectDefinitionKeys(block model.BlockDSLModel, rootKey, instanceKey string, keys map[string]string) {
+	switch {
+	case block.Outlet != "":
+		return
+	case block.Key == rootKey:
+		keys[block.Key] = instanceKey
+	case block.Key != "":
+		keys[block.Key] = instanceKey + "_" + block.Key
+	}
+	for _, child := range block.Blocks {
+		_collectDefinitionKeys(child, rootKey, instanceKey, keys)
+	}
+}
+
+func _collectKeys(blocks []model.BlockDSLModel, keys map[string]bool) {
+	for _, block := range blocks {
+		if block.Key != "" {
+			keys[block.Key] = true
+		}
+		_collectKeys(block.Blocks, keys)
+	}
+}
+
+func _containsInstance(blocks []model.BlockDSLModel) bool {
+	return slices.ContainsFunc(blocks, func(block model.BlockDSLModel) bool {
+		return block.Component != "" || block.Outlet != "" || _containsInstance(block.Blocks)
+	})
+}
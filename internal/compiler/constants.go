@@ -0,0 +1,140 @@
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/types"
+	"github.com/nativeblocks/nbx/internal/validator"
+)
+
+// constantPattern matches a reference to a constant inside a property value.
+var constantPattern = regexp.MustCompile(`\{const:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ResolveConstants replaces {const:name} references in block and trigger properties, including those of
+// frame actions, trigger sequences and styles, and in component prop defaults with the constant values, then removes the constant declarations.
+func ResolveConstants(frame *model.FrameDSLModel) []*errors.Error {
+	r := &_constantResolver{values: make(map[string]model.ConstantDSLModel), enums: validator.EnumTypes(frame.Enums)}
+
+	for _, constant := range frame.Constants {
+		r._declare(constant)
+	}
+
+	for i := range frame.Components {
+		component := &frame.Components[i]
+		for j := range component.Properties {
+			param := &component.Properties[j]
+			param.Value = r._substitute(param.Value, component.File, param.Line, param.Column)
+		}
+		r._resolveBlock(&component.Block, component.File)
+	}
+	for i := range frame.Actions {
+		r._resolveTriggers(frame.Actions[i].Triggers, "")
+	}
+	for i := range frame.Sequences {
+		r._resolveTriggers(frame.Sequences[i].Triggers, "")
+	}
+	for i := range frame.Styles {
+		style := &frame.Styles[i]
+		r._resolveProperties(style.Properties, style.File)
+	}
+	for i := range frame.Blocks {
+		r._resolveBlock(&frame.Blocks[i], "")
+	}
+
+	frame.Constants = nil
+	return r.errs
+}
+
+type _constantResolver struct {
+	values map[string]model.ConstantDSLModel
+	enums  map[string]types.Type
+	errs   []*errors.Error
+}
+
+func (r *_constantResolver) _declare(constant model.ConstantDSLModel) {
+	if existing, exists := r.values[constant.Key]; exists {
+		declared := fmt.Sprintf("Constant '%s' is declared at line %d, column %d", existing.Key, existing.Line, existing.Column)
+		if existing.File != "" {
+			declared = fmt.Sprintf("Constant '%s' is declared in %s at line %d, column %d", existing.Key, existing.File, existing.Line, existing.Column)
+		}
+		r._fail(constant.File, constant.Line, constant.Column, declared, "Duplicate constant '%s'", constant.Key)
+		return
+	}
+
+	constantType, err := types.FromStringWith(constant.Type, r.enums)
+	if err != nil {
+		r._fail(constant.File, constant.Line, constant.Column, "", "Unknown type '%s' for constant '%s'", constant.Type, constant.Key)
+	} else if valid, msg := types.ValidateValue(constant.Value, constantType); !valid {
+		r._fail(constant.File, constant.Line, constant.Column, "", "Invalid value for constant '%s': %s", constant.Key, msg)
+	}
+	r.values[constant.Key] = constant
+}
+
+func (r *_constantResolver) _resolveBlock(block *model.BlockDSLModel, file string) {
+	r._resolveProperties(block.Properties, file)
+	for i := range block.Actions {
+		r._resolveTriggers(block.Actions[i].Triggers, file)
+	}
+	for i := range block.Blocks {
+		r._resolveBlock(&block.Blocks[i], file)
+	}
+}
+
+func (r *_constantResolver) _resolveProperties(props []model.BlockPropertyDSLModel, file string) {
+	for i := range props {
+		prop := &props[i]
+		mobile := r._substitute(prop.ValueMobile, file, prop.Line, prop.Column)
+		if mobile != prop.ValueMobile {
+			prop.Type = types.InferType(mobile).Name()
+		}
+		prop.ValueMobile = mobile
+		prop.ValueTablet = r._substitute(prop.ValueTablet, file, 0, 0)
+		prop.ValueDesktop = r._substitute(prop.ValueDesktop, file, 0, 0)
+	}
+}
+
+func (r *_constantResolver) _resolveTriggers(triggers []model.ActionTriggerDSLModel, file string) {
+	for i := range triggers {
+		for j := range triggers[i].Properties {
+			prop := &triggers[i].Properties[j]
+			if value := r._substitute(prop.Value, file, prop.Line, prop.Column); value != prop.Value {
+				prop.Value = value
+				prop.Type = types.InferType(value).Name()
+			}
+		}
+		r._resolveTriggers(triggers[i].Triggers, file)
+	}
+}
+
+// _substitute replaces the constant references of value. Unknown constants are reported at line and
+// column unless line is 0.
+func (r *_constantResolver) _substitute(value, file string, line, column int) string {
+	return constantPattern.ReplaceAllStringFunc(value, func(reference string) string {
+		name := constantPattern.FindStringSubmatch(reference)[1]
+		constant, ok := r.values[name]
+		if !ok {
+			if line > 0 {
+				r._fail(file, line, column, "", "Undefined constant '%s'", name)
+			}
+			return reference
+		}
+		return constant.Value
+	})
+}
+
+func (r *_constantResolver) _fail(file string, line, column int, related string, format string, args ...any) {
+	err := &errors.Error{
+		Severity: errors.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     line,
+		Column:   column,
+	}
+	if related != "" {
+		err.RelatedInfo = []string{related}
+	}
+	r.errs = append(r.errs, err)
+}
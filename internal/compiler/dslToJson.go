@@ -7,13 +7,83 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/nativeblocks/nbx/internal/expr"
+	"github.com/nativeblocks/nbx/internal/i18n"
 	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/theme"
+	"github.com/nativeblocks/nbx/internal/types"
 	"github.com/nativeblocks/nbx/internal/validator"
 )
 
+// Options configures ToJsonWithOptions.
+type Options struct {
+	// Theme resolves @group.name token references in block properties. Without a theme, references are
+	// kept as they are.
+	Theme *theme.Theme
+	// KeepTokens checks token references against Theme but keeps them in the JSON, for the client to
+	// resolve at runtime.
+	KeepTokens bool
+	// Strings replaces @string.key references with their translations. Without a bundle, references are
+	// kept as symbolic keys for the client to look up.
+	Strings *i18n.Bundle
+	// FrameEvents replaces the lifecycle events frame actions may handle. Nil keeps
+	// validator.DefaultFrameEvents; an empty, non-nil slice allows any event.
+	FrameEvents []string
+}
+
 // ToJson converts a FrameDSLModel to FrameJson with integration validation.
 // blocksJSON and actionsJSON must contain the integration definitions.
+// Constants are resolved, component instances expanded, styles applied and trigger sequence calls
+// expanded first.
 func ToJson(frameDSL model.FrameDSLModel, blocksJSON, actionsJSON, frameID string) (model.FrameJson, error) {
+	return ToJsonWithOptions(frameDSL, blocksJSON, actionsJSON, frameID, Options{})
+}
+
+// ToJsonWithOptions is ToJson with token references resolved against options.Theme and string
+// resources against options.Strings.
+func ToJsonWithOptions(frameDSL model.FrameDSLModel, blocksJSON, actionsJSON, frameID string, options Options) (model.FrameJson, error) {
+	if len(frameDSL.Constants) > 0 || HasComponents(frameDSL) || HasSequences(frameDSL) {
+		frameDSL = model.CloneFrame(frameDSL)
+		if errs := ResolveConstants(&frameDSL); len(errs) > 0 {
+			return model.FrameJson{}, fmt.Errorf("failed to resolve constants: %s", errs[0].Message)
+		}
+		if _, errs := ExpandComponents(&frameDSL); len(errs) > 0 {
+			return model.FrameJson{}, fmt.Errorf("failed to expand components: %s", errs[0].Message)
+		}
+		if errs := ExpandSequences(&frameDSL); len(errs) > 0 {
+			return model.FrameJson{}, fmt.Errorf("failed to expand trigger sequences: %s at line %d, column %d", errs[0].Message, errs[0].Line, errs[0].Column)
+		}
+	}
+
+	// validated is checked against the integrations. It is the expanded frame before styles, tokens and
+	// strings are applied, so it keeps the styles of the blocks and errors name the style a property
+	// comes from.
+	validated := frameDSL
+	if HasStyles(frameDSL) || options.Theme != nil || options.Strings != nil {
+		frameDSL = model.CloneFrame(frameDSL)
+	}
+	if HasStyles(frameDSL) {
+		if errs := ApplyStyles(&frameDSL); len(errs) > 0 {
+			return model.FrameJson{}, fmt.Errorf("failed to apply styles: %s at line %d, column %d", errs[0].Message, errs[0].Line, errs[0].Column)
+		}
+	}
+
+	if options.Theme != nil {
+		if errs := theme.Resolve(&frameDSL, options.Theme, options.KeepTokens); len(errs) > 0 {
+			message := errs[0].Message
+			if errs[0].Suggestion != "" {
+				message += ". " + errs[0].Suggestion
+			}
+			return model.FrameJson{}, fmt.Errorf("failed to resolve tokens: %s at line %d, column %d", message, errs[0].Line, errs[0].Column)
+		}
+	}
+
+	if options.Strings != nil {
+		if errs := i18n.Localize(&frameDSL, options.Strings); len(errs) > 0 {
+			return model.FrameJson{}, fmt.Errorf("failed to localize: %s at line %d, column %d", errs[0].Message, errs[0].Line, errs[0].Column)
+		}
+	}
+
 	if len(frameDSL.Blocks) > 0 && frameDSL.Blocks[0].KeyType != "ROOT" {
 		return model.FrameJson{}, errors.New("first block's keyType must be 'ROOT'")
 	}
@@ -22,24 +92,42 @@ func ToJson(frameDSL model.FrameDSLModel, blocksJSON, actionsJSON, frameID strin
 	if err != nil {
 		return model.FrameJson{}, fmt.Errorf("failed to load integrations: %w", err)
 	}
+	if options.FrameEvents != nil {
+		registry.FrameEvents = options.FrameEvents
+	}
 
 	var frameId = frameID
 	if frameID == "" {
 		frameId = _generateId()
 	}
 
+	enums := validator.EnumTypes(frameDSL.Enums)
 	var variables []model.VariableJson
 	for _, variable := range frameDSL.Variables {
-		variables = append(variables, model.VariableJson{
+		variableJson := model.VariableJson{
 			Id:      _generateId(),
 			FrameId: frameId,
 			Key:     variable.Key,
 			Value:   variable.Value,
 			Type:    variable.Type,
-		})
+		}
+		if varType, err := types.FromStringWith(variable.Type, enums); err == nil && types.WireName(varType) != varType.Name() {
+			variableJson.Type = types.WireName(varType)
+			variableJson.SourceType = varType.Name()
+			if enumType, isEnum := varType.(*types.EnumType); isEnum {
+				variableJson.Members = enumType.Members
+			}
+		}
+		variables = append(variables, variableJson)
+	}
+	if err := _compileComputed(frameDSL.Variables, variables); err != nil {
+		return model.FrameJson{}, err
 	}
 
-	var actions []model.ActionJson
+	actions, err := _processActions(frameId, model.FrameActionKey, frameDSL.Actions, variables)
+	if err != nil {
+		return model.FrameJson{}, err
+	}
 	blocks, err := _processBlocks(frameId, frameDSL.Blocks, "", []model.BlockSlotJson{}, variables, func(blockActions []model.ActionJson) {
 		actions = append(actions, blockActions...)
 	})
@@ -64,6 +152,7 @@ func ToJson(frameDSL model.FrameDSLModel, blocksJSON, actionsJSON, frameID strin
 		Route:          frameDSL.Route,
 		RouteArguments: _convertRouteArguments(frameDSL.Route),
 		Type:           frameDSL.Type,
+		IsStarter:      frameDSL.Starter,
 		Variables:      variables,
 		Blocks:         blocks,
 		Actions:        actions,
@@ -77,7 +166,7 @@ func ToJson(frameDSL model.FrameDSLModel, blocksJSON, actionsJSON, frameID strin
 	}
 
 	integrationValidator := validator.NewIntegrationValidator(registry)
-	if err := integrationValidator.ValidateFrame(&frameDSL); err != nil {
+	if err := integrationValidator.ValidateFrame(&validated); err != nil {
 		return model.FrameJson{}, err
 	}
 
@@ -123,6 +212,18 @@ func _processTriggers(actionId string, triggers []model.ActionTriggerDSLModel, p
 			Data:               []model.TriggerDataJson{},
 		}
 
+		if trigger.Condition != "" {
+			condition, err := _compileCondition(trigger.Condition, variables)
+			if err != nil {
+				label := newTrigger.Name
+				if newTrigger.KeyType == model.ConditionKeyType {
+					label = "if"
+				}
+				return nil, fmt.Errorf("%s trigger condition: %w", label, err)
+			}
+			newTrigger.Condition = condition
+		}
+
 		if newTrigger.Then == "END" && len(trigger.Triggers) > 0 {
 			return nil, errors.New("The " + newTrigger.Name + " can not have a subTrigger because it defines with \"END\" then ")
 		}
@@ -208,7 +309,27 @@ func _processBlocks(frameId string, blocks []model.BlockDSLModel, parentId strin
 			}
 		}
 
-		processedActions, err := _processActions(frameId, block.Key, block.Actions, variables)
+		scope := variables
+		if block.Repeat != nil {
+			repeat, repeatScope, err := _compileRepeat(*block.Repeat, variables, block.Key)
+			if err != nil {
+				return nil, err
+			}
+			newBlock.Repeat, scope = repeat, repeatScope
+		}
+
+		if expr.IsExpression(newBlock.VisibilityKey) {
+			value, valueType, err := _compileExpression(newBlock.VisibilityKey, scope)
+			if err != nil {
+				return nil, fmt.Errorf("%s block visibility: %w", newBlock.Key, err)
+			}
+			if valueType != types.TypeBoolean.Name() {
+				return nil, fmt.Errorf("%s block visibility: expression %s must be BOOLEAN, got %s", newBlock.Key, value, valueType)
+			}
+			newBlock.VisibilityKey = value
+		}
+
+		processedActions, err := _processActions(frameId, block.Key, block.Actions, scope)
 		if err != nil {
 			return nil, err
 		}
@@ -252,7 +373,7 @@ func _processBlocks(frameId string, blocks []model.BlockDSLModel, parentId strin
 			newBlock.Slots = append(newBlock.Slots, newSlot)
 		}
 
-		err = _findBlockVariable(variables, newBlock.Data, newBlock.Key)
+		err = _findBlockVariable(scope, newBlock.Data, newBlock.Key)
 		if err != nil {
 			return nil, err
 		}
@@ -260,7 +381,7 @@ func _processBlocks(frameId string, blocks []model.BlockDSLModel, parentId strin
 		flatBlocks = append(flatBlocks, newBlock)
 
 		if len(block.Blocks) > 0 {
-			subBlocks, err := _processBlocks(frameId, block.Blocks, newBlock.Id, newBlock.Slots, variables, onNewAction)
+			subBlocks, err := _processBlocks(frameId, block.Blocks, newBlock.Id, newBlock.Slots, scope, onNewAction)
 			if err != nil {
 				return nil, err
 			}
@@ -284,6 +405,11 @@ func _getWordsBetweenCurly(text string) []string {
 	return result
 }
 
+// RouteArguments returns the names of the {placeholders} in a route, in order of appearance.
+func RouteArguments(route string) []string {
+	return _getWordsBetweenCurly(route)
+}
+
 func _convertRouteArguments(route string) []model.RouteArgumentJson {
 	args := _getWordsBetweenCurly(route)
 	routeArguments := make([]model.RouteArgumentJson, len(args))
@@ -295,15 +421,24 @@ func _convertRouteArguments(route string) []model.RouteArgumentJson {
 }
 
 func _findBlockVariable(variables []model.VariableJson, data []model.BlockDataJson, blockKey string) error {
-	for _, dataEntry := range data {
-		found := false
-		for _, variable := range variables {
-			if variable.Key == dataEntry.Value && dataEntry.Value != "null" {
-				found = true
-				break
+	for i, dataEntry := range data {
+		if expr.IsExpression(dataEntry.Value) {
+			value, valueType, err := _compileExpression(dataEntry.Value, variables)
+			if err != nil {
+				return fmt.Errorf("%s block data entry with key %s: %w", blockKey, dataEntry.Key, err)
+			}
+			data[i].Value, data[i].Type = value, valueType
+			continue
+		}
+		if expr.IsTemplate(dataEntry.Value) {
+			template, err := _compileTemplate(dataEntry.Value, variables)
+			if err != nil {
+				return fmt.Errorf("%s block data entry with key %s: %w", blockKey, dataEntry.Key, err)
 			}
+			data[i].Template, data[i].Type = template, types.TypeString.Name()
+			continue
 		}
-		if !found && (dataEntry.Value != "" && dataEntry.Value != "null") {
+		if !_hasVariable(variables, dataEntry.Value) && dataEntry.Value != "" && dataEntry.Value != "null" {
 			return fmt.Errorf("no matching variable found for %s block in data entry with key: %s", blockKey, dataEntry.Key)
 		}
 	}
@@ -311,15 +446,24 @@ func _findBlockVariable(variables []model.VariableJson, data []model.BlockDataJs
 }
 
 func _findTriggerVariable(variables []model.VariableJson, data []model.TriggerDataJson, triggerName string) error {
-	for _, dataEntry := range data {
-		found := false
-		for _, variable := range variables {
-			if variable.Key == dataEntry.Value && dataEntry.Value != "null" {
-				found = true
-				break
+	for i, dataEntry := range data {
+		if expr.IsExpression(dataEntry.Value) {
+			value, valueType, err := _compileExpression(dataEntry.Value, variables)
+			if err != nil {
+				return fmt.Errorf("%s trigger data entry with key %s: %w", triggerName, dataEntry.Key, err)
+			}
+			data[i].Value, data[i].Type = value, valueType
+			continue
+		}
+		if expr.IsTemplate(dataEntry.Value) {
+			template, err := _compileTemplate(dataEntry.Value, variables)
+			if err != nil {
+				return fmt.Errorf("%s trigger data entry with key %s: %w", triggerName, dataEntry.Key, err)
 			}
+			data[i].Template, data[i].Type = template, types.TypeString.Name()
+			continue
 		}
-		if !found && (dataEntry.Value != "" && dataEntry.Value != "null") {
+		if !_hasVariable(variables, dataEntry.Value) && dataEntry.Value != "" && dataEntry.Value != "null" {
 			return fmt.Errorf("no matching variable found for %s trigger in data entry with key: %s", triggerName, dataEntry.Key)
 		}
 	}
@@ -367,6 +511,40 @@ func _findDuplicateVariableKey(variables []model.VariableJson) []string {
 	return duplicates
 }
 
+// ToJsonLocales compiles the frame once for every bundle and returns the FrameJson of each locale, all
+// sharing the same ids. A reference without a translation in some bundle fails the whole compilation.
+// options.Strings is ignored.
+func ToJsonLocales(frameDSL model.FrameDSLModel, blocksJSON, actionsJSON, frameID string, bundles []*i18n.Bundle, options Options) (map[string]model.FrameJson, error) {
+	expanded := model.CloneFrame(frameDSL)
+	if errs := ResolveConstants(&expanded); len(errs) > 0 {
+		return nil, fmt.Errorf("failed to resolve constants: %s", errs[0].Message)
+	}
+	if _, errs := ExpandComponents(&expanded); len(errs) > 0 {
+		return nil, fmt.Errorf("failed to expand components: %s", errs[0].Message)
+	}
+	for _, bundle := range bundles {
+		localized := model.CloneFrame(expanded)
+		if errs := ApplyStyles(&localized); len(errs) > 0 {
+			return nil, fmt.Errorf("failed to apply styles: %s at line %d, column %d", errs[0].Message, errs[0].Line, errs[0].Column)
+		}
+		if errs := i18n.Localize(&localized, bundle); len(errs) > 0 {
+			return nil, fmt.Errorf("failed to localize: %s at line %d, column %d", errs[0].Message, errs[0].Line, errs[0].Column)
+		}
+	}
+
+	options.Strings = nil
+	frameJson, err := ToJsonWithOptions(expanded, blocksJSON, actionsJSON, frameID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	locales := make(map[string]model.FrameJson, len(bundles))
+	for _, bundle := range bundles {
+		locales[bundle.Locale] = i18n.LocalizeJson(frameJson, bundle)
+	}
+	return locales, nil
+}
+
 func _generateId() string {
 	id, err := uuid.NewV7()
 	if err != nil {
@@ -0,0 +1,113 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/expr"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/types"
+)
+
+// _expressionTypes returns the declared types of the compiled variables, for checking expressions.
+func _expressionTypes(variables []model.VariableJson) map[string]types.Type {
+	enums := make(map[string]types.Type)
+	for _, variable := range variables {
+		if len(variable.Members) > 0 {
+			enums[variable.SourceType] = types.NewEnumType(variable.SourceType, variable.Members)
+		}
+	}
+	scope := make(map[string]types.Type, len(variables))
+	for _, variable := range variables {
+		typeName := variable.Type
+		if variable.SourceType != "" {
+			typeName = variable.SourceType
+		}
+		if t, err := types.FromStringWith(typeName, enums); err == nil {
+			scope[variable.Key] = t
+		}
+	}
+	return scope
+}
+
+// _compileExpression checks a wrapped expression against the variables and returns it in canonical form
+// with the wire name of its type.
+func _compileExpression(value string, variables []model.VariableJson) (string, string, error) {
+	node, err := expr.ParseValue(value)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid expression %s: %w", value, err)
+	}
+	t, err := expr.Check(node, _expressionTypes(variables))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid expression %s: %w", value, err)
+	}
+	return expr.Wrap(node), types.WireName(t), nil
+}
+
+// _compileCondition checks that the condition of a trigger is a BOOLEAN expression over the variables and
+// returns it in canonical form.
+func _compileCondition(condition string, variables []model.VariableJson) (string, error) {
+	node, err := expr.Parse(condition)
+	if err != nil {
+		return "", fmt.Errorf("invalid condition %s: %w", condition, err)
+	}
+	t, err := expr.Check(node, _expressionTypes(variables))
+	if err != nil {
+		return "", fmt.Errorf("invalid condition %s: %w", condition, err)
+	}
+	if t != types.TypeBoolean {
+		return "", fmt.Errorf("condition %s must be BOOLEAN, got %s", node.String(), t.Name())
+	}
+	return node.String(), nil
+}
+
+// _compileTemplate checks that the placeholders of a template name variables and returns its parts.
+func _compileTemplate(value string, variables []model.VariableJson) ([]model.TemplatePartJson, error) {
+	parts, err := expr.ParseTemplate(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template \"%s\": %w", value, err)
+	}
+	scope := _expressionTypes(variables)
+	template := make([]model.TemplatePartJson, 0, len(parts))
+	for _, part := range parts {
+		if part.Variable != "" {
+			if _, exists := scope[expr.Root(part.Variable)]; !exists {
+				return nil, fmt.Errorf("invalid template \"%s\": undefined variable '%s'", value, expr.Root(part.Variable))
+			}
+			if _, err := expr.PathType(part.Variable, scope, 0); err != nil {
+				return nil, fmt.Errorf("invalid template \"%s\": %w", value, err)
+			}
+		}
+		template = append(template, model.TemplatePartJson{Text: part.Text, Variable: part.Variable})
+	}
+	return template, nil
+}
+
+// _compileComputed checks the expressions of the computed variables among declared, which compiled to
+// variables in the same order, and sets their canonical Expression with its initial result as Value.
+func _compileComputed(declared []model.VariableDSLModel, variables []model.VariableJson) error {
+	if _, cycle := expr.ComputedOrder(declared); cycle != nil {
+		return fmt.Errorf("computed variable '%s' depends on itself: %s", cycle[0], strings.Join(cycle, " -> "))
+	}
+	scope := _expressionTypes(variables)
+	values := expr.Values(declared)
+	for i, variable := range declared {
+		if !variable.Computed {
+			continue
+		}
+		node, err := expr.Parse(variable.Value)
+		if err != nil {
+			return fmt.Errorf("computed variable '%s': invalid expression %s: %w", variable.Key, variable.Value, err)
+		}
+		t, err := expr.Check(node, scope)
+		if err != nil {
+			return fmt.Errorf("computed variable '%s': invalid expression %s: %w", variable.Key, variable.Value, err)
+		}
+		if declaredType, exists := scope[variable.Key]; exists && !types.IsAssignable(t, declaredType) {
+			return fmt.Errorf("computed variable '%s' is declared %s but its expression is %s", variable.Key, declaredType.Name(), t.Name())
+		}
+		variables[i].Expression = node.String()
+		variables[i].Value = expr.Format(values[variable.Key])
+	}
+	return nil
+}
@@ -4,14 +4,32 @@ import "github.com/nativeblocks/nbx/internal/model"
 
 func ToDsl(frame model.FrameJson) model.FrameDSLModel {
 	variables := make([]model.VariableDSLModel, len(frame.Variables))
+	var enums []model.EnumDSLModel
+	declared := make(map[string]bool)
 	for i, variable := range frame.Variables {
 		variables[i] = _mapVariableModelToDSL(variable)
+		// Enum variables carry their members, which declare the enum again.
+		if len(variable.Members) > 0 && !declared[variable.SourceType] {
+			declared[variable.SourceType] = true
+			enums = append(enums, model.EnumDSLModel{Name: variable.SourceType, Members: variable.Members})
+		}
+	}
+	var frameActions []model.ActionDSLModel
+	for _, action := range frame.Actions {
+		if action.Key == model.FrameActionKey {
+			frameAction := _mapActionModelToDSL(action)
+			frameAction.Key = ""
+			frameActions = append(frameActions, frameAction)
+		}
 	}
 	return model.FrameDSLModel{
 		Name:      frame.Name,
 		Route:     frame.Route,
 		Type:      frame.Type,
+		Starter:   frame.IsStarter,
+		Enums:     enums,
 		Variables: variables,
+		Actions:   frameActions,
 		Blocks:    _buildBlockTreeWithActions(frame.Blocks, frame.Actions),
 	}
 }
@@ -26,6 +44,7 @@ func _findActionTriggerChildren(triggers []model.ActionTriggerJson, parentId str
 				Then:               trigger.Then,
 				Name:               trigger.Name,
 				IntegrationVersion: trigger.IntegrationVersion,
+				Condition:          trigger.Condition,
 				Properties:         make([]model.TriggerPropertyDSLModel, len(trigger.Properties)),
 				Data:               make([]model.TriggerDataDSLModel, len(trigger.Data)),
 			}
@@ -60,6 +79,7 @@ func _buildActionTriggerTree(triggers []model.ActionTriggerJson) []model.ActionT
 				Then:               trigger.Then,
 				Name:               trigger.Name,
 				IntegrationVersion: trigger.IntegrationVersion,
+				Condition:          trigger.Condition,
 				Properties:         make([]model.TriggerPropertyDSLModel, len(trigger.Properties)),
 				Data:               make([]model.TriggerDataDSLModel, len(trigger.Data)),
 			}
@@ -99,6 +119,9 @@ func _findBlockChildren(blocks []model.BlockJson, parentId string, actions []mod
 				Properties:         make([]model.BlockPropertyDSLModel, len(block.Properties)),
 				Slots:              make([]model.BlockSlotDSLModel, len(block.Slots)),
 			}
+			if block.Repeat != nil {
+				child.Repeat = &model.RepeatDSLModel{Items: block.Repeat.Items, As: block.Repeat.As}
+			}
 
 			for i, data := range block.Data {
 				child.Data[i] = _mapBlockDataModelToDSL(data)
@@ -148,6 +171,9 @@ func _buildBlockTreeWithActions(blocks []model.BlockJson, actions []model.Action
 				Properties:         make([]model.BlockPropertyDSLModel, len(block.Properties)),
 				Slots:              make([]model.BlockSlotDSLModel, len(block.Slots)),
 			}
+			if block.Repeat != nil {
+				root.Repeat = &model.RepeatDSLModel{Items: block.Repeat.Items, As: block.Repeat.As}
+			}
 
 			for i, data := range block.Data {
 				root.Data[i] = _mapBlockDataModelToDSL(data)
@@ -184,10 +210,17 @@ func _buildBlockTreeWithActions(blocks []model.BlockJson, actions []model.Action
 }
 
 func _mapVariableModelToDSL(variable model.VariableJson) model.VariableDSLModel {
+	varType := variable.Type
+	if variable.SourceType != "" {
+		varType = variable.SourceType
+	}
+	if variable.Expression != "" {
+		return model.VariableDSLModel{Key: variable.Key, Value: variable.Expression, Type: varType, Computed: true}
+	}
 	return model.VariableDSLModel{
 		Key:   variable.Key,
 		Value: variable.Value,
-		Type:  variable.Type,
+		Type:  varType,
 	}
 }
 
@@ -0,0 +1,55 @@
+package compiler
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nativeblocks/nbx/internal/expr"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/types"
+)
+
+// _compileRepeat returns the repeat metadata of a repeated block and the variables in scope of its
+// subtree: the frame variables and the loop variable bound to the element type.
+func _compileRepeat(repeat model.RepeatDSLModel, variables []model.VariableJson, blockKey string) (*model.BlockRepeatJson, []model.VariableJson, error) {
+	itemsType, err := expr.PathType(repeat.Items, _expressionTypes(variables), 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s block repeat: %w", blockKey, err)
+	}
+	var itemType types.Type
+	switch t := itemsType.(type) {
+	case types.ListType:
+		itemType = t.Element
+	default:
+		if t != types.TypeJSON {
+			return nil, nil, fmt.Errorf("%s block repeat: items %s must be a LIST, got %s", blockKey, repeat.Items, t.Name())
+		}
+		itemType = types.TypeJSON
+	}
+	if slices.ContainsFunc(variables, func(variable model.VariableJson) bool { return variable.Key == repeat.As }) {
+		return nil, nil, fmt.Errorf("%s block repeat: %s hides a variable of the same name", blockKey, repeat.As)
+	}
+
+	item := model.VariableJson{Key: repeat.As, Type: types.WireName(itemType)}
+	if item.Type != itemType.Name() {
+		item.SourceType = itemType.Name()
+	}
+	if enumType, isEnum := itemType.(*types.EnumType); isEnum {
+		item.Members = enumType.Members
+	}
+	metadata := &model.BlockRepeatJson{Items: repeat.Items, As: repeat.As, ItemType: itemType.Name()}
+	return metadata, append(slices.Clip(variables), item), nil
+}
+
+// _hasVariable reports whether name is a variable of variables, or a field path such as item.name of one
+// whose type has fields.
+func _hasVariable(variables []model.VariableJson, name string) bool {
+	if slices.ContainsFunc(variables, func(variable model.VariableJson) bool { return variable.Key == name }) {
+		return true
+	}
+	if expr.Root(name) == name {
+		return false
+	}
+	_, err := expr.PathType(name, _expressionTypes(variables), 0)
+	return err == nil
+}
@@ -0,0 +1,163 @@
+package compiler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+// ExpandSequences replaces every trigger(sequence = "name") call with a copy of the sequence's triggers
+// and removes the sequence declarations, leaving ordinary triggers. The copied triggers run under the
+// condition of the call they replace. Sequences that call themselves, directly or through other
+// sequences, are reported.
+func ExpandSequences(frame *model.FrameDSLModel) []*errors.Error {
+	s := &_sequencer{
+		sequences: make(map[string]*model.SequenceDSLModel),
+		resolved:  make(map[string][]model.ActionTriggerDSLModel),
+	}
+
+	for i := range frame.Sequences {
+		s._declare(&frame.Sequences[i])
+	}
+	for _, sequence := range frame.Sequences {
+		s._resolve(s.sequences[sequence.Name], nil)
+	}
+
+	for i := range frame.Actions {
+		frame.Actions[i].Triggers = s._expand(frame.Actions[i].Triggers, nil)
+	}
+	s._expandBlocks(frame.Blocks)
+	frame.Sequences = nil
+
+	return s.errs
+}
+
+// HasSequences reports whether the frame declares or calls trigger sequences.
+func HasSequences(frame model.FrameDSLModel) bool {
+	if len(frame.Sequences) > 0 {
+		return true
+	}
+	return slices.ContainsFunc(frame.Actions, _callsSequence) || _blocksCallSequence(frame.Blocks)
+}
+
+type _sequencer struct {
+	sequences map[string]*model.SequenceDSLModel
+	// resolved holds the triggers of each sequence with its own calls expanded.
+	resolved map[string][]model.ActionTriggerDSLModel
+	errs     []*errors.Error
+}
+
+func (s *_sequencer) _declare(sequence *model.SequenceDSLModel) {
+	if existing, exists := s.sequences[sequence.Name]; exists {
+		s._fail(sequence.Line, sequence.Column,
+			fmt.Sprintf("Trigger sequence '%s' is declared at line %d, column %d", existing.Name, existing.Line, existing.Column),
+			"Duplicate trigger sequence '%s'", sequence.Name)
+		return
+	}
+	s.sequences[sequence.Name] = sequence
+}
+
+// _resolve returns the triggers of sequence with its calls expanded. stack holds the sequences being
+// resolved, to reject sequences that call themselves.
+func (s *_sequencer) _resolve(sequence *model.SequenceDSLModel, stack []string) []model.ActionTriggerDSLModel {
+	if triggers, ok := s.resolved[sequence.Name]; ok {
+		return triggers
+	}
+	triggers := s._expand(sequence.Triggers, append(slices.Clone(stack), sequence.Name))
+	s.resolved[sequence.Name] = triggers
+	return triggers
+}
+
+func (s *_sequencer) _expand(triggers []model.ActionTriggerDSLModel, stack []string) []model.ActionTriggerDSLModel {
+	if triggers == nil {
+		return nil
+	}
+	expanded := make([]model.ActionTriggerDSLModel, 0, len(triggers))
+	for _, trigger := range triggers {
+		if trigger.Sequence == "" {
+			trigger.Triggers = s._expand(trigger.Triggers, stack)
+			expanded = append(expanded, trigger)
+			continue
+		}
+		expanded = append(expanded, s._call(trigger, stack)...)
+	}
+	return expanded
+}
+
+// _call returns a copy of the triggers run by a sequence call, taking the call's then value. A guarded
+// call becomes an if whose branch holds the triggers, so they all run or none do.
+func (s *_sequencer) _call(call model.ActionTriggerDSLModel, stack []string) []model.ActionTriggerDSLModel {
+	sequence, ok := s.sequences[call.Sequence]
+	if !ok {
+		s._fail(call.Line, call.Column, "", "Unknown trigger sequence '%s'", call.Sequence)
+		return nil
+	}
+	if index := slices.Index(stack, sequence.Name); index != -1 {
+		s._fail(call.Line, call.Column, "", "Trigger sequence '%s' calls itself: %s -> %s",
+			sequence.Name, strings.Join(stack[index:], " -> "), sequence.Name)
+		return nil
+	}
+
+	triggers := model.CloneTriggers(s._resolve(sequence, stack))
+	then := call.Then
+	if call.Condition != "" {
+		then = "SUCCESS"
+	}
+	for i := range triggers {
+		triggers[i].Then = then
+	}
+	if call.Condition != "" {
+		return []model.ActionTriggerDSLModel{{
+			KeyType:    model.ConditionKeyType,
+			Then:       call.Then,
+			Condition:  call.Condition,
+			Properties: []model.TriggerPropertyDSLModel{},
+			Data:       []model.TriggerDataDSLModel{},
+			Triggers:   triggers,
+			Line:       call.Line,
+			Column:     call.Column,
+		}}
+	}
+	return triggers
+}
+
+func (s *_sequencer) _expandBlocks(blocks []model.BlockDSLModel) {
+	for i := range blocks {
+		for j := range blocks[i].Actions {
+			blocks[i].Actions[j].Triggers = s._expand(blocks[i].Actions[j].Triggers, nil)
+		}
+		s._expandBlocks(blocks[i].Blocks)
+	}
+}
+
+func (s *_sequencer) _fail(line, column int, related string, format string, args ...any) {
+	err := &errors.Error{
+		Severity: errors.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     line,
+		Column:   column,
+	}
+	if related != "" {
+		err.RelatedInfo = []string{related}
+	}
+	s.errs = append(s.errs, err)
+}
+
+func _blocksCallSequence(blocks []model.BlockDSLModel) bool {
+	return slices.ContainsFunc(blocks, func(block model.BlockDSLModel) bool {
+		return slices.ContainsFunc(block.Actions, _callsSequence) || _blocksCallSequence(block.Blocks)
+	})
+}
+
+func _callsSequence(action model.ActionDSLModel) bool {
+	return _triggersCallSequence(action.Triggers)
+}
+
+func _triggersCallSequence(triggers []model.ActionTriggerDSLModel) bool {
+	return slices.ContainsFunc(triggers, func(trigger model.ActionTriggerDSLModel) bool {
+		return trigger.Sequence != "" || _triggersCallSequence(trigger.Triggers)
+	})
+}
@@ -0,0 +1,145 @@
+package compiler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+// ApplyStyles copies the properties of the styles applied to every block into the block and removes the
+// style declarations, leaving ordinary properties. A style's own properties override those of the styles
+// it composes, later styles override earlier ones and the block's own properties override them all, key
+// by key. Styles that compose themselves, directly or through other styles, are reported.
+func ApplyStyles(frame *model.FrameDSLModel) []*errors.Error {
+	s := &_styler{
+		styles:   make(map[string]*model.StyleDSLModel),
+		resolved: make(map[string][]model.BlockPropertyDSLModel),
+	}
+
+	for i := range frame.Styles {
+		s._declare(&frame.Styles[i])
+	}
+	for _, style := range frame.Styles {
+		s._resolve(s.styles[style.Name], nil)
+	}
+
+	s._applyBlocks(frame.Blocks)
+	for i := range frame.Components {
+		s._applyBlock(&frame.Components[i].Block)
+	}
+	frame.Styles = nil
+
+	return s.errs
+}
+
+// HasStyles reports whether the frame declares or applies styles.
+func HasStyles(frame model.FrameDSLModel) bool {
+	if len(frame.Styles) > 0 {
+		return true
+	}
+	return _blocksApplyStyle(frame.Blocks) || slices.ContainsFunc(frame.Components, func(component model.ComponentDSLModel) bool {
+		return _blocksApplyStyle([]model.BlockDSLModel{component.Block})
+	})
+}
+
+type _styler struct {
+	styles map[string]*model.StyleDSLModel
+	// resolved holds the properties of each style with the styles it composes applied.
+	resolved map[string][]model.BlockPropertyDSLModel
+	errs     []*errors.Error
+}
+
+func (s *_styler) _declare(style *model.StyleDSLModel) {
+	if existing, exists := s.styles[style.Name]; exists {
+		declared := fmt.Sprintf("Style '%s' is declared at line %d, column %d", existing.Name, existing.Line, existing.Column)
+		if existing.File != "" {
+			declared = fmt.Sprintf("Style '%s' is declared in %s at line %d, column %d", existing.Name, existing.File, existing.Line, existing.Column)
+		}
+		s._fail(style.File, style.Line, style.Column, declared, "Duplicate style '%s'", style.Name)
+		return
+	}
+	s.styles[style.Name] = style
+}
+
+// _resolve returns the properties of style with the styles it composes applied. stack holds the styles
+// being resolved, to reject styles that compose themselves.
+func (s *_styler) _resolve(style *model.StyleDSLModel, stack []string) []model.BlockPropertyDSLModel {
+	if props, ok := s.resolved[style.Name]; ok {
+		return props
+	}
+	stack = append(slices.Clone(stack), style.Name)
+	props := s._merge(style.Styles, style.Properties, stack, style.File, style.Line, style.Column)
+	s.resolved[style.Name] = props
+	return props
+}
+
+// _merge returns the properties of the named styles, in order, overridden by own.
+func (s *_styler) _merge(names []string, own []model.BlockPropertyDSLModel, stack []string, file string, line, column int) []model.BlockPropertyDSLModel {
+	var props []model.BlockPropertyDSLModel
+	for _, name := range names {
+		style, ok := s.styles[name]
+		if !ok {
+			s._fail(file, line, column, "", "Unknown style '%s'", name)
+			continue
+		}
+		if index := slices.Index(stack, name); index != -1 {
+			s._fail(style.File, style.Line, style.Column, "", "Style '%s' composes itself: %s -> %s",
+				name, strings.Join(stack[index:], " -> "), name)
+			continue
+		}
+		props = _overrideProperties(props, s._resolve(style, stack))
+	}
+	return _overrideProperties(props, own)
+}
+
+func (s *_styler) _applyBlocks(blocks []model.BlockDSLModel) {
+	for i := range blocks {
+		s._applyBlock(&blocks[i])
+	}
+}
+
+func (s *_styler) _applyBlock(block *model.BlockDSLModel) {
+	if len(block.Styles) > 0 {
+		block.Properties = s._merge(block.Styles, block.Properties, nil, "", block.Line, block.Column)
+		block.Styles = nil
+	}
+	s._applyBlocks(block.Blocks)
+}
+
+func (s *_styler) _fail(file string, line, column int, related string, format string, args ...any) {
+	err := &errors.Error{
+		Severity: errors.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     line,
+		Column:   column,
+	}
+	if related != "" {
+		err.RelatedInfo = []string{related}
+	}
+	s.errs = append(s.errs, err)
+}
+
+// _overrideProperties returns a copy of base with the properties of overrides replacing those with the
+// same key in place and the others appended.
+func _overrideProperties(base, overrides []model.BlockPropertyDSLModel) []model.BlockPropertyDSLModel {
+	props := make([]model.BlockPropertyDSLModel, len(base), len(base)+len(overrides))
+	copy(props, base)
+	for _, prop := range overrides {
+		if index := slices.IndexFunc(props, func(p model.BlockPropertyDSLModel) bool { return p.Key == prop.Key }); index != -1 {
+			props[index] = prop
+			continue
+		}
+		props = append(props, prop)
+	}
+	return props
+}
+
+func _blocksApplyStyle(blocks []model.BlockDSLModel) bool {
+	return slices.ContainsFunc(blocks, func(block model.BlockDSLModel) bool {
+		return len(block.Styles) > 0 || _blocksApplyStyle(block.Blocks)
+	})
+}
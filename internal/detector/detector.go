@@ -13,7 +13,9 @@ const (
 
 var (
 	xmlPattern = regexp.MustCompile(`^\s*<\?xml`)
-	dslPattern = regexp.MustCompile(`^\s*frame\s*\(`)
+	dslPattern = regexp.MustCompile(`^\s*(frame\s*\(|library\s*\{)`)
+
+	libraryPattern = regexp.MustCompile(`^\s*(library\s*\{|(<\?xml[^>]*\?>\s*)?<library[\s>])`)
 )
 
 // DetectFormat detects whether the input is DSL, XML, or unknown format.
@@ -28,7 +30,7 @@ func DetectFormat(content string) string {
 	if xmlPattern.MatchString(trimmed) {
 		return FormatXML
 	}
-	if strings.HasPrefix(trimmed, "<frame") {
+	if strings.HasPrefix(trimmed, "<frame") || strings.HasPrefix(trimmed, "<library") {
 		return FormatXML
 	}
 
@@ -38,3 +40,8 @@ func DetectFormat(content string) string {
 
 	return FormatUnknown
 }
+
+// IsLibrary reports whether the input is a DSL or XML library rather than a frame.
+func IsLibrary(content string) bool {
+	return libraryPattern.MatchString(content)
+}
@@ -33,6 +33,16 @@ func TestDetectFormat(t *testing.T) {
 			content:  `frame(name = "test", route = "/test") {}`,
 			expected: FormatDSL,
 		},
+		{
+			name:     "DSL library",
+			content:  `library { var theme: STRING = "light" }`,
+			expected: FormatDSL,
+		},
+		{
+			name:     "XML library",
+			content:  `<library><var key="theme" type="STRING" value="light" /></library>`,
+			expected: FormatXML,
+		},
 		{
 			name:     "DSL with leading whitespace",
 			content:  `  frame(name = "test", route = "/test") {}`,
@@ -64,3 +74,23 @@ func TestDetectFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestIsLibrary(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected bool
+	}{
+		{`library { var theme: STRING = "light" }`, true},
+		{"<?xml version=\"1.0\"?>\n<library>\n</library>", true},
+		{`<library></library>`, true},
+		{`frame(name = "test", route = "/test") {}`, false},
+		{`<frame name="library" route="/library"></frame>`, false},
+		{`<libraryItem />`, false},
+	}
+
+	for _, tt := range tests {
+		if result := IsLibrary(tt.content); result != tt.expected {
+			t.Errorf("IsLibrary(%q) = %v, expected %v", tt.content, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,765 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+type ChangeKind string
+
+const (
+	Added     ChangeKind = "added"
+	Removed   ChangeKind = "removed"
+	Changed   ChangeKind = "changed"
+	Moved     ChangeKind = "moved"
+	Reordered ChangeKind = "reordered"
+)
+
+// Node kinds of the declarations a change can name besides the nodes Walk visits.
+const (
+	NodeConstant  walker.Kind = "const"
+	NodeEnum      walker.Kind = "enum"
+	NodeStyle     walker.Kind = "style"
+	NodeComponent walker.Kind = "component"
+)
+
+// Change is one semantic difference between two frames.
+type Change struct {
+	Kind ChangeKind  `json:"kind"`
+	Node walker.Kind `json:"node"`
+	// Path locates the node, e.g. block[button]/action[onClick]/trigger[increase]/prop[variableValue].
+	// Frame action, trigger sequence, style and component paths start at the action, sequence, style or
+	// component, e.g. action[onLoad]/trigger[fetch], sequence[validate]/trigger[checkEmail],
+	// style[card]/prop[radius] or component[card]/block[title]/prop[text].
+	Path string `json:"path"`
+	// Field is the changed attribute of a changed node, e.g. "keyType", "then" or "value".
+	Field string `json:"field,omitempty"`
+	// Device is set for changed block property values: "mobile", "tablet" or "desktop".
+	Device string `json:"device,omitempty"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+type Changes []Change
+
+// Text renders the changes one per line, prefixed with + (added), - (removed), ~ (changed),
+// > (moved) or ^ (reordered).
+func (c Changes) Text() string {
+	var builder strings.Builder
+	for _, change := range c {
+		switch change.Kind {
+		case Added:
+			builder.WriteString("+ " + change.Path)
+			if change.New != "" {
+				builder.WriteString(" " + change.New)
+			}
+		case Removed:
+			builder.WriteString("- " + change.Path)
+			if change.Old != "" {
+				builder.WriteString(" " + change.Old)
+			}
+		case Changed:
+			builder.WriteString("~ " + change.Path + " " + change.Field)
+			if change.Device != "" {
+				builder.WriteString(" (" + change.Device + ")")
+			}
+			builder.WriteString(fmt.Sprintf(": %s -> %s", strconv.Quote(change.Old), strconv.Quote(change.New)))
+		case Moved:
+			builder.WriteString(fmt.Sprintf("> %s: %s -> %s", change.Path, change.Old, change.New))
+		case Reordered:
+			builder.WriteString(fmt.Sprintf("^ %s: %s -> %s", change.Path, change.Old, change.New))
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// JSON renders the changes as an indented JSON array.
+func (c Changes) JSON() (string, error) {
+	if c == nil {
+		c = Changes{}
+	}
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// Diff compares two frames. Constants, variables and blocks are matched by key, enums, styles and
+// components by name, actions by block key
+// and event, and triggers by name within their parent. Generated IDs play no part, so two compilations of the
+// same frame have no changes.
+func Diff(a, b model.FrameDSLModel) Changes {
+	d := &differ{}
+	d.frame(a, b)
+	d.constants(a.Constants, b.Constants)
+	d.enums(a.Enums, b.Enums)
+	d.variables(a.Variables, b.Variables)
+	d.styles(a.Styles, b.Styles)
+	d.components(a.Components, b.Components)
+	d.actions("", a.Actions, b.Actions)
+	d.sequences(a.Sequences, b.Sequences)
+	d.blocks("", a, b)
+	return d.changes
+}
+
+type differ struct {
+	changes Changes
+}
+
+func (d *differ) add(change Change) {
+	d.changes = append(d.changes, change)
+}
+
+func (d *differ) field(node walker.Kind, path, field, old, new string) {
+	if old != new {
+		d.add(Change{Kind: Changed, Node: node, Path: path, Field: field, Old: old, New: new})
+	}
+}
+
+func (d *differ) frame(a, b model.FrameDSLModel) {
+	d.field(walker.KindFrame, "frame", "name", a.Name, b.Name)
+	d.field(walker.KindFrame, "frame", "route", a.Route, b.Route)
+	d.field(walker.KindFrame, "frame", "type", a.Type, b.Type)
+	d.field(walker.KindFrame, "frame", "starter", strconv.FormatBool(a.Starter), strconv.FormatBool(b.Starter))
+}
+
+func (d *differ) constants(a, b []model.ConstantDSLModel) {
+	old := make(map[string]model.ConstantDSLModel, len(a))
+	for _, constant := range a {
+		old[constant.Key] = constant
+	}
+	current := make(map[string]bool, len(b))
+	for _, constant := range b {
+		current[constant.Key] = true
+	}
+
+	for _, constant := range a {
+		if !current[constant.Key] {
+			d.add(Change{Kind: Removed, Node: NodeConstant, Path: _segment("const", constant.Key), Old: constant.Type})
+		}
+	}
+	for _, constant := range b {
+		path := _segment("const", constant.Key)
+		previous, ok := old[constant.Key]
+		if !ok {
+			d.add(Change{Kind: Added, Node: NodeConstant, Path: path, New: constant.Type})
+			continue
+		}
+		d.field(NodeConstant, path, "type", previous.Type, constant.Type)
+		d.field(NodeConstant, path, "value", previous.Value, constant.Value)
+	}
+}
+
+func (d *differ) enums(a, b []model.EnumDSLModel) {
+	old := make(map[string]model.EnumDSLModel, len(a))
+	for _, enum := range a {
+		old[enum.Name] = enum
+	}
+	current := make(map[string]bool, len(b))
+	for _, enum := range b {
+		current[enum.Name] = true
+	}
+
+	for _, enum := range a {
+		if !current[enum.Name] {
+			d.add(Change{Kind: Removed, Node: NodeEnum, Path: _segment("enum", enum.Name), Old: strings.Join(enum.Members, ", ")})
+		}
+	}
+	for _, enum := range b {
+		path := _segment("enum", enum.Name)
+		previous, ok := old[enum.Name]
+		if !ok {
+			d.add(Change{Kind: Added, Node: NodeEnum, Path: path, New: strings.Join(enum.Members, ", ")})
+			continue
+		}
+		d.field(NodeEnum, path, "members", strings.Join(previous.Members, ", "), strings.Join(enum.Members, ", "))
+	}
+}
+
+func (d *differ) variables(a, b []model.VariableDSLModel) {
+	old := make(map[string]model.VariableDSLModel, len(a))
+	for _, variable := range a {
+		old[variable.Key] = variable
+	}
+	current := make(map[string]bool, len(b))
+	for _, variable := range b {
+		current[variable.Key] = true
+	}
+
+	for _, variable := range a {
+		if !current[variable.Key] {
+			d.add(Change{Kind: Removed, Node: walker.KindVariable, Path: _segment("variable", variable.Key), Old: variable.Type})
+		}
+	}
+	for _, variable := range b {
+		path := _segment("variable", variable.Key)
+		previous, ok := old[variable.Key]
+		if !ok {
+			d.add(Change{Kind: Added, Node: walker.KindVariable, Path: path, New: variable.Type})
+			continue
+		}
+		d.field(walker.KindVariable, path, "type", previous.Type, variable.Type)
+		d.field(walker.KindVariable, path, "value", previous.Value, variable.Value)
+		d.field(walker.KindVariable, path, "computed", strconv.FormatBool(previous.Computed), strconv.FormatBool(variable.Computed))
+	}
+}
+
+func (d *differ) styles(a, b []model.StyleDSLModel) {
+	old := make(map[string]model.StyleDSLModel, len(a))
+	for _, style := range a {
+		old[style.Name] = style
+	}
+	current := make(map[string]bool, len(b))
+	for _, style := range b {
+		current[style.Name] = true
+	}
+
+	for _, style := range a {
+		if !current[style.Name] {
+			d.add(Change{Kind: Removed, Node: NodeStyle, Path: _segment("style", style.Name)})
+		}
+	}
+	for _, style := range b {
+		path := _segment("style", style.Name)
+		previous, ok := old[style.Name]
+		if !ok {
+			d.add(Change{Kind: Added, Node: NodeStyle, Path: path})
+			continue
+		}
+		d.field(NodeStyle, path, "styles", strings.Join(previous.Styles, ", "), strings.Join(style.Styles, ", "))
+		d.blockProperties(path, previous.Properties, style.Properties)
+	}
+}
+
+// components compares component declarations: their prop and data parameters, and the blocks of their
+// definitions as the blocks of a frame. A definition with a new root block is reported as a changed
+// root, which replaces the whole definition.
+func (d *differ) components(a, b []model.ComponentDSLModel) {
+	old := make(map[string]model.ComponentDSLModel, len(a))
+	for _, component := range a {
+		old[component.Name] = component
+	}
+	current := make(map[string]bool, len(b))
+	for _, component := range b {
+		current[component.Name] = true
+	}
+
+	for _, component := range a {
+		if !current[component.Name] {
+			d.add(Change{Kind: Removed, Node: NodeComponent, Path: _segment("component", component.Name), Old: component.Block.KeyType})
+		}
+	}
+	for _, component := range b {
+		path := _segment("component", component.Name)
+		previous, ok := old[component.Name]
+		if !ok {
+			d.add(Change{Kind: Added, Node: NodeComponent, Path: path, New: component.Block.KeyType})
+			continue
+		}
+		if previous.Block.Key != component.Block.Key {
+			d.field(NodeComponent, path, "root", previous.Block.Key, component.Block.Key)
+			continue
+		}
+		d.params(path, walker.KindProperty, "prop", previous.Properties, component.Properties)
+		d.params(path, walker.KindData, "data", previous.Data, component.Data)
+		d.blocks(path, model.FrameDSLModel{Blocks: []model.BlockDSLModel{previous.Block}}, model.FrameDSLModel{Blocks: []model.BlockDSLModel{component.Block}})
+	}
+}
+
+func (d *differ) params(path string, node walker.Kind, name string, a, b []model.ComponentParamDSLModel) {
+	old := make(map[string]model.ComponentParamDSLModel, len(a))
+	for _, param := range a {
+		old[param.Key] = param
+	}
+	current := make(map[string]bool, len(b))
+	for _, param := range b {
+		current[param.Key] = true
+	}
+
+	for _, param := range a {
+		if !current[param.Key] {
+			d.add(Change{Kind: Removed, Node: node, Path: path + "/" + _segment(name, param.Key), Old: param.Value})
+		}
+	}
+	for _, param := range b {
+		paramPath := path + "/" + _segment(name, param.Key)
+		previous, ok := old[param.Key]
+		if !ok {
+			d.add(Change{Kind: Added, Node: node, Path: paramPath, New: param.Value})
+			continue
+		}
+		d.field(node, paramPath, "type", previous.Type, param.Type)
+		d.field(node, paramPath, "value", previous.Value, param.Value)
+		d.field(node, paramPath, "required", strconv.FormatBool(previous.Required), strconv.FormatBool(param.Required))
+	}
+}
+
+// placement is where a block sits in the tree.
+type placement struct {
+	block  *model.BlockDSLModel
+	parent string
+	slot   string
+}
+
+func (p placement) location() string {
+	if p.parent == "" {
+		return "frame"
+	}
+	if p.slot == "" {
+		return p.parent
+	}
+	return p.parent + "/" + p.slot
+}
+
+// layout records where every block sits and the block order at each location.
+type layout struct {
+	placements map[string]placement
+	order      []string
+	children   map[string][]string
+}
+
+func _layout(frame *model.FrameDSLModel) layout {
+	l := layout{placements: make(map[string]placement), children: make(map[string][]string)}
+	walker.Walk(frame, walker.Visitor{
+		Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+			if node.Kind != walker.KindBlock {
+				return walker.Continue
+			}
+			p := placement{block: node.Block, slot: node.Block.Slot}
+			if parent := parents[len(parents)-1]; parent.Kind == walker.KindBlock {
+				p.parent = parent.Block.Key
+			}
+			if _, exists := l.placements[node.Block.Key]; !exists {
+				l.placements[node.Block.Key] = p
+				l.order = append(l.order, node.Block.Key)
+				l.children[p.location()] = append(l.children[p.location()], node.Block.Key)
+			}
+			return walker.Continue
+		},
+	})
+	return l
+}
+
+// blocks compares the block trees of two frames. prefix is the path of the component whose definitions
+// the frames hold, or empty for the frame's own blocks.
+func (d *differ) blocks(prefix string, a, b model.FrameDSLModel) {
+	old, current := _layout(&a), _layout(&b)
+
+	for _, key := range old.order {
+		if _, ok := current.placements[key]; !ok {
+			previous := old.placements[key]
+			d.add(Change{Kind: Removed, Node: walker.KindBlock, Path: _join(prefix, _segment("block", key)),
+				Old: previous.block.KeyType + " in " + previous.location()})
+		}
+	}
+
+	for _, key := range current.order {
+		path := _join(prefix, _segment("block", key))
+		now := current.placements[key]
+		previous, ok := old.placements[key]
+		if !ok {
+			d.add(Change{Kind: Added, Node: walker.KindBlock, Path: path, New: now.block.KeyType + " in " + now.location()})
+			continue
+		}
+		if previous.location() != now.location() {
+			d.add(Change{Kind: Moved, Node: walker.KindBlock, Path: path, Old: previous.location(), New: now.location()})
+		}
+		d.block(path, previous.block, now.block)
+	}
+
+	d.reorders(prefix, old, current)
+}
+
+// reorders reports blocks whose position changed among siblings that kept their location.
+// The longest common subsequence of the sibling orders stays put; every other sibling is reordered.
+func (d *differ) reorders(prefix string, old, current layout) {
+	seen := make(map[string]bool)
+	for _, key := range current.order {
+		location := current.placements[key].location()
+		if seen[location] {
+			continue
+		}
+		seen[location] = true
+
+		before := _stayed(old.children[location], location, current)
+		after := _stayed(current.children[location], location, old)
+		stable := make(map[string]bool)
+		for _, k := range _lcs(before, after) {
+			stable[k] = true
+		}
+		for i, k := range after {
+			if !stable[k] {
+				d.add(Change{Kind: Reordered, Node: walker.KindBlock, Path: _join(prefix, _segment("block", k)),
+					Old: location + "[" + strconv.Itoa(_indexOf(before, k)) + "]", New: location + "[" + strconv.Itoa(i) + "]"})
+			}
+		}
+	}
+}
+
+// _stayed filters keys to the blocks that are at the same location in other.
+func _stayed(keys []string, location string, other layout) []string {
+	var result []string
+	for _, key := range keys {
+		if p, ok := other.placements[key]; ok && p.location() == location {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+func _lcs(a, b []string) []string {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	var result []string
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, a[i])
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return result
+}
+
+func (d *differ) block(path string, a, b *model.BlockDSLModel) {
+	d.field(walker.KindBlock, path, "keyType", a.KeyType, b.KeyType)
+	d.field(walker.KindBlock, path, "visibilityKey", a.VisibilityKey, b.VisibilityKey)
+	d.field(walker.KindBlock, path, "version", strconv.Itoa(a.IntegrationVersion), strconv.Itoa(b.IntegrationVersion))
+	oldItems, oldAs := _repeat(a.Repeat)
+	newItems, newAs := _repeat(b.Repeat)
+	d.field(walker.KindBlock, path, "repeatItems", oldItems, newItems)
+	d.field(walker.KindBlock, path, "repeatAs", oldAs, newAs)
+	d.field(walker.KindBlock, path, "styles", strings.Join(a.Styles, ", "), strings.Join(b.Styles, ", "))
+
+	d.slots(path, a.Slots, b.Slots)
+	d.blockProperties(path, a.Properties, b.Properties)
+	d.blockData(path, a.Data, b.Data)
+	d.actions(path, a.Actions, b.Actions)
+}
+
+func _repeat(repeat *model.RepeatDSLModel) (string, string) {
+	if repeat == nil {
+		return "", ""
+	}
+	return repeat.Items, repeat.As
+}
+
+func (d *differ) slots(path string, a, b []model.BlockSlotDSLModel) {
+	old := make(map[string]bool, len(a))
+	for _, slot := range a {
+		old[slot.Slot] = true
+	}
+	current := make(map[string]bool, len(b))
+	for _, slot := range b {
+		current[slot.Slot] = true
+	}
+	for _, slot := range a {
+		if !current[slot.Slot] {
+			d.add(Change{Kind: Removed, Node: walker.KindSlot, Path: path + "/" + _segment("slot", slot.Slot)})
+		}
+	}
+	for _, slot := range b {
+		if !old[slot.Slot] {
+			d.add(Change{Kind: Added, Node: walker.KindSlot, Path: path + "/" + _segment("slot", slot.Slot)})
+		}
+	}
+}
+
+func (d *differ) blockProperties(path string, a, b []model.BlockPropertyDSLModel) {
+	old := make(map[string]model.BlockPropertyDSLModel, len(a))
+	for _, prop := range a {
+		old[prop.Key] = prop
+	}
+	current := make(map[string]bool, len(b))
+	for _, prop := range b {
+		current[prop.Key] = true
+	}
+
+	for _, prop := range a {
+		if !current[prop.Key] {
+			d.add(Change{Kind: Removed, Node: walker.KindProperty, Path: path + "/" + _segment("prop", prop.Key), Old: prop.ValueMobile})
+		}
+	}
+	for _, prop := range b {
+		propPath := path + "/" + _segment("prop", prop.Key)
+		previous, ok := old[prop.Key]
+		if !ok {
+			d.add(Change{Kind: Added, Node: walker.KindProperty, Path: propPath, New: prop.ValueMobile})
+			continue
+		}
+		d.field(walker.KindProperty, propPath, "type", previous.Type, prop.Type)
+		for _, device := range []struct{ name, old, new string }{
+			{"mobile", previous.ValueMobile, prop.ValueMobile},
+			{"tablet", previous.ValueTablet, prop.ValueTablet},
+			{"desktop", previous.ValueDesktop, prop.ValueDesktop},
+		} {
+			if device.old != device.new {
+				d.add(Change{Kind: Changed, Node: walker.KindProperty, Path: propPath, Field: "value",
+					Device: device.name, Old: device.old, New: device.new})
+			}
+		}
+	}
+}
+
+func (d *differ) blockData(path string, a, b []model.BlockDataDSLModel) {
+	d.keyValues(path, walker.KindData, "data", _blockDataEntries(a), _blockDataEntries(b))
+}
+
+type entry struct {
+	key       string
+	value     string
+	valueType string
+}
+
+func _blockDataEntries(data []model.BlockDataDSLModel) []entry {
+	entries := make([]entry, len(data))
+	for i, d := range data {
+		entries[i] = entry{key: d.Key, value: d.Value, valueType: d.Type}
+	}
+	return entries
+}
+
+func (d *differ) keyValues(path string, node walker.Kind, name string, a, b []entry) {
+	old := make(map[string]entry, len(a))
+	for _, e := range a {
+		old[e.key] = e
+	}
+	current := make(map[string]bool, len(b))
+	for _, e := range b {
+		current[e.key] = true
+	}
+
+	for _, e := range a {
+		if !current[e.key] {
+			d.add(Change{Kind: Removed, Node: node, Path: path + "/" + _segment(name, e.key), Old: e.value})
+		}
+	}
+	for _, e := range b {
+		entryPath := path + "/" + _segment(name, e.key)
+		previous, ok := old[e.key]
+		if !ok {
+			d.add(Change{Kind: Added, Node: node, Path: entryPath, New: e.value})
+			continue
+		}
+		d.field(node, entryPath, "type", previous.valueType, e.valueType)
+		d.field(node, entryPath, "value", previous.value, e.value)
+	}
+}
+
+func (d *differ) actions(path string, a, b []model.ActionDSLModel) {
+	old := make(map[string]model.ActionDSLModel, len(a))
+	for _, action := range a {
+		old[action.Event] = action
+	}
+	current := make(map[string]bool, len(b))
+	for _, action := range b {
+		current[action.Event] = true
+	}
+
+	for _, action := range a {
+		if !current[action.Event] {
+			d.add(Change{Kind: Removed, Node: walker.KindAction, Path: _join(path, _segment("action", action.Event))})
+		}
+	}
+	for _, action := range b {
+		actionPath := _join(path, _segment("action", action.Event))
+		previous, ok := old[action.Event]
+		if !ok {
+			d.add(Change{Kind: Added, Node: walker.KindAction, Path: actionPath})
+			continue
+		}
+		d.triggers(actionPath, previous.Triggers, action.Triggers)
+	}
+}
+
+func (d *differ) sequences(a, b []model.SequenceDSLModel) {
+	old := make(map[string]model.SequenceDSLModel, len(a))
+	for _, sequence := range a {
+		old[sequence.Name] = sequence
+	}
+	current := make(map[string]bool, len(b))
+	for _, sequence := range b {
+		current[sequence.Name] = true
+	}
+
+	for _, sequence := range a {
+		if !current[sequence.Name] {
+			d.add(Change{Kind: Removed, Node: walker.KindSequence, Path: _segment("sequence", sequence.Name)})
+		}
+	}
+	for _, sequence := range b {
+		sequencePath := _segment("sequence", sequence.Name)
+		previous, ok := old[sequence.Name]
+		if !ok {
+			d.add(Change{Kind: Added, Node: walker.KindSequence, Path: sequencePath})
+			continue
+		}
+		d.triggers(sequencePath, previous.Triggers, sequence.Triggers)
+	}
+}
+
+// TriggerIDs names triggers by their name, numbering repeated names ("log", "log#2"), as used in paths.
+// Calls of trigger sequences are named after the sequence ("sequence:validate") and if/else triggers "if".
+func TriggerIDs(triggers []model.ActionTriggerDSLModel) []string {
+	return _triggerIDs(triggers)
+}
+
+func _triggerIDs(triggers []model.ActionTriggerDSLModel) []string {
+	ids := make([]string, len(triggers))
+	counts := make(map[string]int)
+	for i, trigger := range triggers {
+		name := trigger.Name
+		if trigger.Sequence != "" {
+			name = "sequence:" + trigger.Sequence
+		} else if trigger.KeyType == model.ConditionKeyType {
+			name = "if"
+		}
+		counts[name]++
+		ids[i] = name
+		if counts[name] > 1 {
+			ids[i] += "#" + strconv.Itoa(counts[name])
+		}
+	}
+	return ids
+}
+
+func (d *differ) triggers(path string, a, b []model.ActionTriggerDSLModel) {
+	oldIDs, newIDs := _triggerIDs(a), _triggerIDs(b)
+	old := make(map[string]*model.ActionTriggerDSLModel, len(a))
+	for i := range a {
+		old[oldIDs[i]] = &a[i]
+	}
+	current := make(map[string]bool, len(b))
+	for _, id := range newIDs {
+		current[id] = true
+	}
+
+	var commonBefore, commonAfter []string
+	for i, id := range oldIDs {
+		if !current[id] {
+			d.add(Change{Kind: Removed, Node: walker.KindTrigger, Path: path + "/" + _segment("trigger", id), Old: a[i].KeyType})
+		} else {
+			commonBefore = append(commonBefore, id)
+		}
+	}
+	for i, id := range newIDs {
+		triggerPath := path + "/" + _segment("trigger", id)
+		previous, ok := old[id]
+		if !ok {
+			d.add(Change{Kind: Added, Node: walker.KindTrigger, Path: triggerPath, New: b[i].KeyType})
+			continue
+		}
+		commonAfter = append(commonAfter, id)
+
+		trigger := &b[i]
+		d.field(walker.KindTrigger, triggerPath, "keyType", previous.KeyType, trigger.KeyType)
+		d.field(walker.KindTrigger, triggerPath, "then", previous.Then, trigger.Then)
+		d.field(walker.KindTrigger, triggerPath, "version", strconv.Itoa(previous.IntegrationVersion), strconv.Itoa(trigger.IntegrationVersion))
+		d.field(walker.KindTrigger, triggerPath, "condition", previous.Condition, trigger.Condition)
+		d.keyValues(triggerPath, walker.KindProperty, "prop", _triggerPropertyEntries(previous.Properties), _triggerPropertyEntries(trigger.Properties))
+		d.keyValues(triggerPath, walker.KindData, "data", _triggerDataEntries(previous.Data), _triggerDataEntries(trigger.Data))
+		d.triggers(triggerPath, previous.Triggers, trigger.Triggers)
+	}
+
+	stable := make(map[string]bool)
+	for _, id := range _lcs(commonBefore, commonAfter) {
+		stable[id] = true
+	}
+	for i, id := range commonAfter {
+		if !stable[id] {
+			d.add(Change{Kind: Reordered, Node: walker.KindTrigger, Path: path + "/" + _segment("trigger", id),
+				Old: strconv.Itoa(_indexOf(commonBefore, id)), New: strconv.Itoa(i)})
+		}
+	}
+}
+
+func _triggerPropertyEntries(properties []model.TriggerPropertyDSLModel) []entry {
+	entries := make([]entry, len(properties))
+	for i, p := range properties {
+		entries[i] = entry{key: p.Key, value: p.Value, valueType: p.Type}
+	}
+	return entries
+}
+
+func _triggerDataEntries(data []model.TriggerDataDSLModel) []entry {
+	entries := make([]entry, len(data))
+	for i, d := range data {
+		entries[i] = entry{key: d.Key, value: d.Value, valueType: d.Type}
+	}
+	return entries
+}
+
+func _indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func _segment(kind, key string) string {
+	return kind + "[" + key + "]"
+}
+
+// _join appends segment to path. Frame actions have no parent, so their paths start at the action.
+func _join(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + "/" + segment
+}
+
+// Segment is one step of a change path, e.g. block[button].
+type Segment struct {
+	Kind string
+	Key  string
+}
+
+// ParsePath splits a change path into its segments. The path "frame" has a single segment without a key.
+func ParsePath(path string) []Segment {
+	var segments []Segment
+	for path != "" {
+		open := strings.Index(path, "[")
+		if open == -1 {
+			segments = append(segments, Segment{Kind: path})
+			break
+		}
+		rest := path[open+1:]
+		end := strings.Index(rest, "]/")
+		if end == -1 {
+			end = strings.LastIndex(rest, "]")
+		}
+		if end == -1 {
+			segments = append(segments, Segment{Kind: path[:open], Key: rest})
+			break
+		}
+		segments = append(segments, Segment{Kind: path[:open], Key: rest[:end]})
+		path = strings.TrimPrefix(rest[end+1:], "/")
+	}
+	return segments
+}
@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+func _diffFrame() model.FrameDSLModel {
+	return model.FrameDSLModel{
+		Name: "counter", Route: "/counter", Type: "FRAME",
+		Variables: []model.VariableDSLModel{
+			{Key: "count", Type: "INT", Value: "0"},
+			{Key: "label", Type: "STRING", Value: "Add"},
+		},
+		Blocks: []model.BlockDSLModel{{
+			Key: "root", KeyType: "ROOT",
+			Blocks: []model.BlockDSLModel{
+				{
+					Key: "column", KeyType: "nativeblocks/column", Slot: "content",
+					Slots: []model.BlockSlotDSLModel{{Slot: "content"}},
+					Blocks: []model.BlockDSLModel{
+						{Key: "title", KeyType: "nativeblocks/text", Slot: "content"},
+						{Key: "text", KeyType: "nativeblocks/text", Slot: "content",
+							Properties: []model.BlockPropertyDSLModel{{Key: "fontSize", ValueMobile: "14", ValueTablet: "16", ValueDesktop: "18", Type: "STRING"}},
+							Data:       []model.BlockDataDSLModel{{Key: "text", Value: "count", Type: "STRING"}},
+						},
+						{Key: "button", KeyType: "nativeblocks/button", Slot: "content",
+							Actions: []model.ActionDSLModel{{
+								Key: "button", Event: "onClick",
+								Triggers: []model.ActionTriggerDSLModel{
+									{Name: "increase", KeyType: "nativeblocks/change_variable", Then: "NEXT",
+										Data: []model.TriggerDataDSLModel{{Key: "variableKey", Value: "count"}}},
+									{Name: "log", KeyType: "nativeblocks/log", Then: "NEXT"},
+								},
+							}},
+						},
+					},
+				},
+				{Key: "footer", KeyType: "nativeblocks/row", Slot: "content"},
+			},
+		}},
+	}
+}
+
+func _has(changes Changes, kind ChangeKind, path, field string) bool {
+	for _, c := range changes {
+		if c.Kind == kind && c.Path == path && c.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDiffIdentical(t *testing.T) {
+	if changes := Diff(_diffFrame(), _diffFrame()); len(changes) != 0 {
+		t.Errorf("Expected no changes, got:\n%s", changes.Text())
+	}
+}
+
+func TestDiffChanges(t *testing.T) {
+	a, b := _diffFrame(), _diffFrame()
+
+	b.Route = "/count"
+	b.Variables = b.Variables[:1]
+	b.Variables[0].Value = "1"
+	b.Variables = append(b.Variables, model.VariableDSLModel{Key: "enabled", Type: "BOOLEAN", Value: "true"})
+
+	column := &b.Blocks[0].Blocks[0]
+	column.Blocks[1].Properties[0].ValueTablet = "20"
+	column.Blocks[1].Data[0].Value = "label"
+	trigger := &column.Blocks[2].Actions[0].Triggers[0]
+	trigger.Then = "END"
+	trigger.Properties = append(trigger.Properties, model.TriggerPropertyDSLModel{Key: "variableValue", Value: "1"})
+	column.Blocks[2].Actions[0].Triggers = column.Blocks[2].Actions[0].Triggers[:1]
+
+	// Move title into the footer and add an image.
+	title := column.Blocks[0]
+	column.Blocks = column.Blocks[1:]
+	b.Blocks[0].Blocks[1].Blocks = []model.BlockDSLModel{title}
+	column.Blocks = append(column.Blocks, model.BlockDSLModel{Key: "image", KeyType: "nativeblocks/image", Slot: "content"})
+
+	changes := Diff(a, b)
+
+	expected := []struct {
+		kind        ChangeKind
+		path, field string
+	}{
+		{Changed, "frame", "route"},
+		{Removed, "variable[label]", ""},
+		{Added, "variable[enabled]", ""},
+		{Changed, "variable[count]", "value"},
+		{Moved, "block[title]", ""},
+		{Added, "block[image]", ""},
+		{Changed, "block[text]/prop[fontSize]", "value"},
+		{Changed, "block[text]/data[text]", "value"},
+		{Changed, "block[button]/action[onClick]/trigger[increase]", "then"},
+		{Added, "block[button]/action[onClick]/trigger[increase]/prop[variableValue]", ""},
+		{Removed, "block[button]/action[onClick]/trigger[log]", ""},
+	}
+	for _, e := range expected {
+		if !_has(changes, e.kind, e.path, e.field) {
+			t.Errorf("Expected %s %s %s in:\n%s", e.kind, e.path, e.field, changes.Text())
+		}
+	}
+	if len(changes) != len(expected) {
+		t.Errorf("Expected %d changes, got %d:\n%s", len(expected), len(changes), changes.Text())
+	}
+
+	for _, c := range changes {
+		if c.Path == "block[text]/prop[fontSize]" && (c.Device != "tablet" || c.Old != "16" || c.New != "20") {
+			t.Errorf("Expected a tablet change from 16 to 20, got %+v", c)
+		}
+	}
+}
+
+func TestDiffReordered(t *testing.T) {
+	a, b := _diffFrame(), _diffFrame()
+	column := &b.Blocks[0].Blocks[0]
+	column.Blocks[0], column.Blocks[2] = column.Blocks[2], column.Blocks[0]
+
+	triggers := column.Blocks[0].Actions[0].Triggers
+	triggers[0], triggers[1] = triggers[1], triggers[0]
+
+	changes := Diff(a, b)
+	reordered := 0
+	for _, c := range changes {
+		if c.Kind != Reordered {
+			t.Errorf("Unexpected change: %+v", c)
+		}
+		reordered++
+	}
+	if reordered != 3 {
+		t.Errorf("Expected two reordered blocks and one reordered trigger, got:\n%s", changes.Text())
+	}
+	if !_has(changes, Reordered, "block[button]/action[onClick]/trigger[increase]", "") &&
+		!_has(changes, Reordered, "block[button]/action[onClick]/trigger[log]", "") {
+		t.Errorf("Expected a reordered trigger, got:\n%s", changes.Text())
+	}
+}
+
+func TestDiffRenderings(t *testing.T) {
+	a, b := _diffFrame(), _diffFrame()
+	b.Blocks[0].Blocks[0].Blocks[1].Properties[0].ValueMobile = "12"
+
+	changes := Diff(a, b)
+	if text := changes.Text(); text != "~ block[text]/prop[fontSize] value (mobile): \"14\" -> \"12\"\n" {
+		t.Errorf("Unexpected text rendering: %q", text)
+	}
+
+	content, err := changes.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]string
+	if err := json.Unmarshal([]byte(content), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0]["kind"] != "changed" || decoded[0]["device"] != "mobile" || decoded[0]["node"] != "prop" {
+		t.Errorf("Unexpected JSON rendering: %s", content)
+	}
+
+	empty, _ := Diff(a, a).JSON()
+	if strings.TrimSpace(empty) != "[]" {
+		t.Errorf("Expected an empty JSON array, got %s", empty)
+	}
+}
@@ -28,6 +28,7 @@ func (s ErrorSeverity) String() string {
 type Error struct {
 	Severity    ErrorSeverity
 	Message     string
+	File        string
 	Line        int
 	Column      int
 	SourceLine  string
@@ -41,8 +42,12 @@ func (e *Error) Format() string {
 
 	b.WriteString(fmt.Sprintf("%s: %s\n", e.Severity, e.Message))
 
-	if e.Line > 0 {
+	if e.Line > 0 && e.File != "" {
+		b.WriteString(fmt.Sprintf("  --> %s, line %d, column %d\n", e.File, e.Line, e.Column))
+	} else if e.Line > 0 {
 		b.WriteString(fmt.Sprintf("  --> line %d, column %d\n", e.Line, e.Column))
+	} else if e.File != "" {
+		b.WriteString(fmt.Sprintf("  --> %s\n", e.File))
 	}
 
 	if e.SourceLine != "" {
@@ -92,7 +97,7 @@ func NewErrorCollector(source string) *ErrorCollector {
 }
 
 func (ec *ErrorCollector) AddError(err *Error) {
-	if err.SourceLine == "" && ec.source != "" && err.Line > 0 {
+	if err.SourceLine == "" && err.File == "" && ec.source != "" && err.Line > 0 {
 		err.SourceLine = ec.getSourceLine(err.Line)
 	}
 
@@ -233,6 +238,46 @@ func UndefinedVariableError(varName string, line, column int, availableVars []st
 	return err
 }
 
+func UnknownTokenError(token string, line, column int, availableTokens []string) *Error {
+	err := &Error{
+		Severity: SeverityError,
+		Message:  fmt.Sprintf("Unknown token '%s'", token),
+		Line:     line,
+		Column:   column,
+	}
+
+	if len(availableTokens) > 0 {
+		similar := _findSimilar(token, availableTokens)
+		if len(similar) > 0 {
+			err.Suggestion = fmt.Sprintf("Did you mean '%s'?", similar[0])
+		} else {
+			err.RelatedInfo = []string{
+				fmt.Sprintf("Available tokens: %s", strings.Join(availableTokens, ", ")),
+			}
+		}
+	} else {
+		err.Suggestion = "The theme declares no tokens"
+	}
+
+	return err
+}
+
+func MissingTranslationError(reference, locale string, line, column int, availableStrings []string) *Error {
+	err := &Error{
+		Severity:   SeverityError,
+		Message:    fmt.Sprintf("Missing translation for '%s' in locale '%s'", reference, locale),
+		Line:       line,
+		Column:     column,
+		Suggestion: fmt.Sprintf("Add '%s' to the '%s' bundle", strings.TrimPrefix(reference, "@string."), locale),
+	}
+
+	if similar := _findSimilar(reference, availableStrings); len(similar) > 0 {
+		err.Suggestion = fmt.Sprintf("Did you mean '%s'?", similar[0])
+	}
+
+	return err
+}
+
 func TypeMismatchError(expected, got string, line, column int) *Error {
 	return &Error{
 		Severity:   SeverityError,
@@ -301,6 +346,20 @@ func _tokenTypeToString(tokenType lexer.TokenType) string {
 		return "'}'"
 	case lexer.TOKEN_KEYWORD:
 		return "a keyword"
+	case lexer.TOKEN_REFERENCE:
+		return "a reference"
+	case lexer.TOKEN_COLOR:
+		return "a color"
+	case lexer.TOKEN_EXPRESSION:
+		return "an expression"
+	case lexer.TOKEN_LBRACKET:
+		return "'['"
+	case lexer.TOKEN_RBRACKET:
+		return "']'"
+	case lexer.TOKEN_LT:
+		return "'<'"
+	case lexer.TOKEN_GT:
+		return "'>'"
 	default:
 		return "a token"
 	}
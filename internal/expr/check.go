@@ -0,0 +1,310 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/types"
+)
+
+// functions are the built-in functions with their number of arguments.
+var functions = map[string]int{
+	"len":      1,
+	"isEmpty":  1,
+	"contains": 2,
+	"lower":    1,
+	"upper":    1,
+	"trim":     1,
+}
+
+// numericRank orders the numeric types from narrowest to widest.
+var numericRank = map[types.Type]int{types.TypeInt: 1, types.TypeLong: 2, types.TypeFloat: 3, types.TypeDouble: 4}
+
+// Check type-checks node against the types of the variables it may refer to and returns its type.
+//
+// Arithmetic takes numbers and widens to the wider operand, + concatenates when either operand is a
+// string, comparisons take two numbers or two strings, == and != take operands of compatible types, and
+// ! && || and the condition of ?: take booleans. Comparing an enum with a string literal checks that the
+// literal is a member. len and isEmpty take strings, lists, maps and JSON; contains(list, element),
+// contains(map, key) and contains(string, substring) return whether the element is present; lower,
+// upper and trim take strings.
+func Check(node Node, variables map[string]types.Type) (types.Type, error) {
+	c := &_checker{variables: variables}
+	return c._check(node)
+}
+
+type _checker struct {
+	variables map[string]types.Type
+}
+
+func (c *_checker) _check(node Node) (types.Type, error) {
+	switch n := node.(type) {
+	case *Literal:
+		switch v := n.Value.(type) {
+		case string:
+			return types.TypeString, nil
+		case int64:
+			if v > 1<<31-1 || v < -1<<31 {
+				return types.TypeLong, nil
+			}
+			return types.TypeInt, nil
+		case float64:
+			return types.TypeDouble, nil
+		default:
+			return types.TypeBoolean, nil
+		}
+
+	case *Ident:
+		return PathType(n.Name, c.variables, n.Offset)
+
+	case *Unary:
+		x, err := c._check(n.X)
+		if err != nil {
+			return nil, err
+		}
+		if n.Op == "!" && x != types.TypeBoolean {
+			return nil, _errorf(n, "Operator ! takes a BOOLEAN, got %s", x.Name())
+		}
+		if n.Op == "-" && numericRank[x] == 0 {
+			return nil, _errorf(n, "Operator - takes a number, got %s", x.Name())
+		}
+		return x, nil
+
+	case *Binary:
+		return c._checkBinary(n)
+
+	case *Conditional:
+		cond, err := c._check(n.Cond)
+		if err != nil {
+			return nil, err
+		}
+		if cond != types.TypeBoolean {
+			return nil, _errorf(n, "The condition of ?: must be BOOLEAN, got %s", cond.Name())
+		}
+		then, err := c._check(n.Then)
+		if err != nil {
+			return nil, err
+		}
+		otherwise, err := c._check(n.Else)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case then.Name() == otherwise.Name():
+			return then, nil
+		case numericRank[then] > 0 && numericRank[otherwise] > 0:
+			return _wider(then, otherwise), nil
+		case _isString(then) && _isString(otherwise):
+			return types.TypeString, nil
+		}
+		return nil, _errorf(n, "The branches of ?: have different types %s and %s", then.Name(), otherwise.Name())
+
+	case *Call:
+		return c._checkCall(n)
+	}
+	return nil, fmt.Errorf("unknown expression %T", node)
+}
+
+// PathType returns the type of a variable or field path such as item.price, given the types of the
+// variables. offset is the position reported by its errors.
+func PathType(name string, variables map[string]types.Type, offset int) (types.Type, error) {
+	segments := strings.Split(name, ".")
+	t, exists := variables[segments[0]]
+	if !exists {
+		return nil, &Error{Message: fmt.Sprintf("Undefined variable '%s'", segments[0]), Offset: offset}
+	}
+	for i := 1; i < len(segments); i++ {
+		field, ok := types.FieldType(t)
+		if !ok {
+			return nil, &Error{
+				Message: fmt.Sprintf("'%s' is a %s and has no field '%s'", strings.Join(segments[:i], "."), t.Name(), segments[i]),
+				Offset:  offset,
+			}
+		}
+		t = field
+	}
+	return t, nil
+}
+
+func (c *_checker) _checkBinary(n *Binary) (types.Type, error) {
+	x, err := c._check(n.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := c._check(n.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Op {
+	case "&&", "||":
+		if x != types.TypeBoolean || y != types.TypeBoolean {
+			return nil, _errorf(n, "Operator %s takes BOOLEAN operands, got %s and %s", n.Op, x.Name(), y.Name())
+		}
+		return types.TypeBoolean, nil
+
+	case "+":
+		if _isString(x) || _isString(y) {
+			if !_isScalar(x) || !_isScalar(y) {
+				return nil, _errorf(n, "Cannot concatenate %s and %s", x.Name(), y.Name())
+			}
+			return types.TypeString, nil
+		}
+		fallthrough
+
+	case "-", "*", "/", "%":
+		if numericRank[x] == 0 || numericRank[y] == 0 {
+			return nil, _errorf(n, "Operator %s takes numbers, got %s and %s", n.Op, x.Name(), y.Name())
+		}
+		return _wider(x, y), nil
+
+	case "<", "<=", ">", ">=":
+		if !(numericRank[x] > 0 && numericRank[y] > 0) && !(_isString(x) && _isString(y)) {
+			return nil, _errorf(n, "Cannot compare %s with %s", x.Name(), y.Name())
+		}
+		return types.TypeBoolean, nil
+
+	default: // == and !=
+		if err := _checkEquality(n, x, y); err != nil {
+			return nil, err
+		}
+		return types.TypeBoolean, nil
+	}
+}
+
+func _checkEquality(n *Binary, x, y types.Type) error {
+	xEnum, _ := x.(*types.EnumType)
+	yEnum, _ := y.(*types.EnumType)
+	switch {
+	case xEnum != nil && yEnum != nil:
+		if xEnum.Name() != yEnum.Name() {
+			return _errorf(n, "Cannot compare %s with %s", x.Name(), y.Name())
+		}
+	case xEnum != nil || yEnum != nil:
+		enum, other := xEnum, n.Y
+		if enum == nil {
+			enum, other = yEnum, n.X
+		}
+		if literal, ok := other.(*Literal); ok {
+			if member, isString := literal.Value.(string); isString && !enum.Has(member) {
+				_, msg := types.ValidateValue(member, enum)
+				return &Error{Message: msg, Offset: literal.Offset}
+			}
+		}
+		if !_isString(x) || !_isString(y) {
+			return _errorf(n, "Cannot compare %s with %s", x.Name(), y.Name())
+		}
+	case numericRank[x] > 0 && numericRank[y] > 0, _isString(x) && _isString(y):
+	case x.Name() != y.Name():
+		return _errorf(n, "Cannot compare %s with %s", x.Name(), y.Name())
+	}
+	return nil
+}
+
+func (c *_checker) _checkCall(n *Call) (types.Type, error) {
+	count, exists := functions[n.Func]
+	if !exists {
+		names := make([]string, 0, len(functions))
+		for name := range functions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, _errorf(n, "Unknown function '%s'. Available functions: %s", n.Func, strings.Join(names, ", "))
+	}
+	if len(n.Args) != count {
+		return nil, _errorf(n, "%s takes %d argument(s), got %d", n.Func, count, len(n.Args))
+	}
+	args := make([]types.Type, len(n.Args))
+	for i, arg := range n.Args {
+		t, err := c._check(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = t
+	}
+
+	switch n.Func {
+	case "len", "isEmpty":
+		if !_isString(args[0]) && !_isCollection(args[0]) {
+			return nil, _errorf(n, "%s takes a string, list, map or JSON, got %s", n.Func, args[0].Name())
+		}
+		if n.Func == "len" {
+			return types.TypeInt, nil
+		}
+		return types.TypeBoolean, nil
+
+	case "contains":
+		switch t := args[0].(type) {
+		case types.ListType:
+			if !args[1].IsCompatible(t.Element) && !t.Element.IsCompatible(args[1]) &&
+				!(numericRank[args[1]] > 0 && numericRank[t.Element] > 0) {
+				return nil, _errorf(n, "contains on %s takes a %s element, got %s", t.Name(), t.Element.Name(), args[1].Name())
+			}
+		case types.MapType:
+			if !_isString(args[1]) {
+				return nil, _errorf(n, "contains on %s takes a STRING key, got %s", t.Name(), args[1].Name())
+			}
+		default:
+			if !_isString(args[0]) || !_isString(args[1]) {
+				return nil, _errorf(n, "contains takes a list, map or string, got %s and %s", args[0].Name(), args[1].Name())
+			}
+		}
+		return types.TypeBoolean, nil
+
+	default: // lower, upper and trim
+		if !_isString(args[0]) {
+			return nil, _errorf(n, "%s takes a STRING, got %s", n.Func, args[0].Name())
+		}
+		return types.TypeString, nil
+	}
+}
+
+// _isString reports whether values of t are plain strings: STRING, COLOR, URL and enums.
+func _isString(t types.Type) bool {
+	if _, isEnum := t.(*types.EnumType); isEnum {
+		return true
+	}
+	return t == types.TypeString || t == types.TypeColor || t == types.TypeURL
+}
+
+func _isScalar(t types.Type) bool {
+	return _isString(t) || numericRank[t] > 0 || t == types.TypeBoolean
+}
+
+func _isCollection(t types.Type) bool {
+	switch t.(type) {
+	case types.ListType, types.MapType:
+		return true
+	}
+	return t == types.TypeJSON
+}
+
+func _wider(x, y types.Type) types.Type {
+	if numericRank[y] > numericRank[x] {
+		return y
+	}
+	return x
+}
+
+func _errorf(node Node, format string, args ...any) *Error {
+	return &Error{Message: fmt.Sprintf(format, args...), Offset: _offset(node)}
+}
+
+func _offset(node Node) int {
+	switch n := node.(type) {
+	case *Literal:
+		return n.Offset
+	case *Ident:
+		return n.Offset
+	case *Unary:
+		return n.Offset
+	case *Binary:
+		return n.Offset
+	case *Conditional:
+		return n.Offset
+	case *Call:
+		return n.Offset
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package expr
+
+import "github.com/nativeblocks/nbx/internal/model"
+
+// ComputedOrder returns the computed variables in an order they can be evaluated in, each after the
+// computed variables its expression uses. When computed variables depend on each other, it returns the
+// keys of the first cycle found instead, starting and ending with the same key, such as [a b a].
+// Expressions that do not parse have no dependencies.
+func ComputedOrder(variables []model.VariableDSLModel) ([]model.VariableDSLModel, []string) {
+	computed := make(map[string]model.VariableDSLModel)
+	for _, variable := range variables {
+		if _, exists := computed[variable.Key]; variable.Computed && !exists {
+			computed[variable.Key] = variable
+		}
+	}
+
+	order := make([]model.VariableDSLModel, 0, len(computed))
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(key string) []string
+	visit = func(key string) []string {
+		switch state[key] {
+		case done:
+			return nil
+		case visiting:
+			for i, name := range path {
+				if name == key {
+					return append(append([]string(nil), path[i:]...), key)
+				}
+			}
+		}
+		state[key] = visiting
+		path = append(path, key)
+		if node, err := Parse(computed[key].Value); err == nil {
+			for _, name := range Variables(node) {
+				if _, isComputed := computed[name]; isComputed {
+					if cycle := visit(name); cycle != nil {
+						return cycle
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[key] = done
+		order = append(order, computed[key])
+		return nil
+	}
+
+	for _, variable := range variables {
+		if variable.Computed {
+			if cycle := visit(variable.Key); cycle != nil {
+				return nil, cycle
+			}
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,382 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/types"
+)
+
+// Eval evaluates node with the variable values in values, as returned by Values. Numbers are int64 or
+// float64, and lists, maps and JSON documents are decoded JSON. Expressions that passed Check only fail
+// on a division by zero or a variable without a value.
+func Eval(node Node, values map[string]any) (any, error) {
+	switch n := node.(type) {
+	case *Literal:
+		return n.Value, nil
+
+	case *Ident:
+		value, exists := Lookup(values, n.Name)
+		if !exists {
+			return nil, &Error{Message: fmt.Sprintf("Undefined variable '%s'", Root(n.Name)), Offset: n.Offset}
+		}
+		return value, nil
+
+	case *Unary:
+		x, err := Eval(n.X, values)
+		if err != nil {
+			return nil, err
+		}
+		if n.Op == "!" {
+			return !Truthy(x), nil
+		}
+		switch v := x.(type) {
+		case int64:
+			return -v, nil
+		case float64:
+			return -v, nil
+		}
+		return nil, _errorf(n, "Operator - takes a number, got '%s'", Format(x))
+
+	case *Binary:
+		return _evalBinary(n, values)
+
+	case *Conditional:
+		cond, err := Eval(n.Cond, values)
+		if err != nil {
+			return nil, err
+		}
+		if Truthy(cond) {
+			return Eval(n.Then, values)
+		}
+		return Eval(n.Else, values)
+
+	case *Call:
+		args := make([]any, len(n.Args))
+		for i, arg := range n.Args {
+			value, err := Eval(arg, values)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		return _evalCall(n, args)
+	}
+	return nil, fmt.Errorf("unknown expression %T", node)
+}
+
+// EvalValue parses and evaluates a wrapped expression such as $(count > 0).
+func EvalValue(value string, values map[string]any) (any, error) {
+	node, err := ParseValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return Eval(node, values)
+}
+
+func _evalBinary(n *Binary, values map[string]any) (any, error) {
+	x, err := Eval(n.X, values)
+	if err != nil {
+		return nil, err
+	}
+	switch n.Op {
+	case "&&":
+		if !Truthy(x) {
+			return false, nil
+		}
+		y, err := Eval(n.Y, values)
+		return Truthy(y), err
+	case "||":
+		if Truthy(x) {
+			return true, nil
+		}
+		y, err := Eval(n.Y, values)
+		return Truthy(y), err
+	}
+
+	y, err := Eval(n.Y, values)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Op {
+	case "==":
+		return _equal(x, y), nil
+	case "!=":
+		return !_equal(x, y), nil
+	}
+
+	_, xString := x.(string)
+	_, yString := y.(string)
+	if n.Op == "+" && (xString || yString) {
+		return Format(x) + Format(y), nil
+	}
+	if xString && yString {
+		a, b := x.(string), y.(string)
+		switch n.Op {
+		case "<":
+			return a < b, nil
+		case "<=":
+			return a <= b, nil
+		case ">":
+			return a > b, nil
+		case ">=":
+			return a >= b, nil
+		}
+	}
+
+	xInt, xIsInt := x.(int64)
+	yInt, yIsInt := y.(int64)
+	if xIsInt && yIsInt {
+		switch n.Op {
+		case "+":
+			return xInt + yInt, nil
+		case "-":
+			return xInt - yInt, nil
+		case "*":
+			return xInt * yInt, nil
+		case "/", "%":
+			if yInt == 0 {
+				return nil, _errorf(n, "Division by zero")
+			}
+			if n.Op == "/" {
+				return xInt / yInt, nil
+			}
+			return xInt % yInt, nil
+		}
+	}
+
+	a, aOk := _float(x)
+	b, bOk := _float(y)
+	if !aOk || !bOk {
+		return nil, _errorf(n, "Operator %s cannot be applied to '%s' and '%s'", n.Op, Format(x), Format(y))
+	}
+	switch n.Op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, _errorf(n, "Division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, _errorf(n, "Division by zero")
+		}
+		return float64(int64(a) % int64(b)), nil
+	case "<":
+		return a < b, nil
+	case "<=":
+		return a <= b, nil
+	case ">":
+		return a > b, nil
+	default:
+		return a >= b, nil
+	}
+}
+
+func _evalCall(n *Call, args []any) (any, error) {
+	switch n.Func {
+	case "len", "isEmpty":
+		var length int
+		switch v := args[0].(type) {
+		case string:
+			length = len([]rune(v))
+		case []any:
+			length = len(v)
+		case map[string]any:
+			length = len(v)
+		case nil:
+		default:
+			return nil, _errorf(n, "%s cannot be applied to '%s'", n.Func, Format(v))
+		}
+		if n.Func == "len" {
+			return int64(length), nil
+		}
+		return length == 0, nil
+
+	case "contains":
+		switch v := args[0].(type) {
+		case string:
+			return strings.Contains(v, Format(args[1])), nil
+		case []any:
+			for _, element := range v {
+				if _equal(element, args[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]any:
+			_, exists := v[Format(args[1])]
+			return exists, nil
+		}
+		return nil, _errorf(n, "contains cannot be applied to '%s'", Format(args[0]))
+
+	case "lower":
+		return strings.ToLower(Format(args[0])), nil
+	case "upper":
+		return strings.ToUpper(Format(args[0])), nil
+	case "trim":
+		return strings.TrimSpace(Format(args[0])), nil
+	}
+	return nil, _errorf(n, "Unknown function '%s'", n.Func)
+}
+
+// Lookup returns the value of a variable or field path such as item.price. A missing field of a map or
+// JSON document is nil, as runtimes read it; only a missing variable is not found.
+func Lookup(values map[string]any, name string) (any, bool) {
+	segments := strings.Split(name, ".")
+	value, exists := values[segments[0]]
+	if !exists {
+		return nil, false
+	}
+	for _, field := range segments[1:] {
+		object, isObject := value.(map[string]any)
+		if !isObject {
+			return nil, true
+		}
+		value = object[field]
+	}
+	return value, true
+}
+
+// Truthy reports whether a value counts as true: true, and any value but false, zero, "" and "false".
+func Truthy(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v != "" && v != "false"
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	case nil:
+		return false
+	}
+	return true
+}
+
+// Format returns a value as text: strings as they are, numbers and booleans as in the DSL, and lists,
+// maps and JSON documents as JSON.
+func Format(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// Values returns the values of variables for Eval, converted by their declared types. Values that do not
+// match their type, and variables of types only the frame declares such as enums, are kept as strings.
+// Lists of them, such as LIST<Status>, are decoded like any list. Computed variables hold the result of
+// their expression, or nil when it cannot be evaluated.
+func Values(variables []model.VariableDSLModel) map[string]any {
+	values := make(map[string]any, len(variables))
+	for _, variable := range variables {
+		if variable.Computed {
+			values[variable.Key] = nil
+			continue
+		}
+		values[variable.Key] = Value(variable.Value, variable.Type)
+	}
+	order, _ := ComputedOrder(variables)
+	for _, variable := range order {
+		if node, err := Parse(variable.Value); err == nil {
+			values[variable.Key], _ = Eval(node, values)
+		}
+	}
+	return values
+}
+
+// Value converts the text of a variable value of type typeName for Eval.
+func Value(value, typeName string) any {
+	trimmed := strings.TrimSpace(value)
+	t, err := types.FromString(typeName)
+	if err != nil {
+		// Collections of declared types, such as LIST<Status>, hold JSON all the same.
+		name := strings.ToUpper(strings.TrimSpace(typeName))
+		if strings.HasPrefix(name, "LIST<") || strings.HasPrefix(name, "MAP<") {
+			t = types.TypeJSON
+		} else {
+			return value
+		}
+	}
+	switch {
+	case t == types.TypeBoolean:
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			return b
+		}
+	case t == types.TypeInt || t == types.TypeLong:
+		if i, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return i
+		}
+	case t == types.TypeFloat || t == types.TypeDouble:
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return f
+		}
+	case _isCollection(t):
+		var decoded any
+		if err := json.Unmarshal([]byte(trimmed), &decoded); err == nil {
+			return _normalize(decoded)
+		}
+	}
+	return value
+}
+
+// _normalize turns the float64 numbers of decoded JSON that are whole into int64, as literals are.
+func _normalize(value any) any {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	case []any:
+		for i := range v {
+			v[i] = _normalize(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = _normalize(v[key])
+		}
+	}
+	return value
+}
+
+func _equal(x, y any) bool {
+	if a, ok := _float(x); ok {
+		if b, ok := _float(y); ok {
+			return a == b
+		}
+	}
+	return Format(x) == Format(y)
+}
+
+func _float(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,262 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Prefix and Suffix wrap an expression where a variable name is expected: visibility = $(count > 0).
+const (
+	Prefix = "$("
+	Suffix = ")"
+)
+
+// Node is a parsed expression. String returns it in canonical form, with the parentheses its
+// precedence needs only.
+type Node interface {
+	String() string
+	_precedence() int
+}
+
+// Literal is a string, number or boolean constant. Value holds a string, int64, float64 or bool.
+type Literal struct {
+	Value  any
+	Offset int
+}
+
+// Ident refers to a variable, or to a field of one through a path such as item.price.
+type Ident struct {
+	Name   string
+	Offset int
+}
+
+// Unary is !x or -x.
+type Unary struct {
+	Op     string
+	X      Node
+	Offset int
+}
+
+// Binary is x op y for the arithmetic, comparison and boolean operators.
+type Binary struct {
+	Op     string
+	X, Y   Node
+	Offset int
+}
+
+// Conditional is cond ? then : else.
+type Conditional struct {
+	Cond, Then, Else Node
+	Offset           int
+}
+
+// Call is a function call such as len(items).
+type Call struct {
+	Func   string
+	Args   []Node
+	Offset int
+}
+
+// binaryPrecedence orders the binary operators, loosest first; the conditional binds looser than all.
+var binaryPrecedence = map[string]int{
+	"||": 2, "&&": 3,
+	"==": 4, "!=": 4,
+	"<": 5, "<=": 5, ">": 5, ">=": 5,
+	"+": 6, "-": 6,
+	"*": 7, "/": 7, "%": 7,
+}
+
+const (
+	_conditionalPrecedence = 1
+	_unaryPrecedence       = 8
+	_primaryPrecedence     = 9
+)
+
+func (n *Literal) String() string {
+	switch v := n.Value.(type) {
+	case string:
+		// Strings have no escapes, so a string holding double quotes is single-quoted.
+		if strings.Contains(v, `"`) && !strings.Contains(v, "'") {
+			return "'" + v + "'"
+		}
+		return `"` + v + `"`
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (n *Ident) String() string { return n.Name }
+
+func (n *Unary) String() string { return n.Op + _operand(n.X, _unaryPrecedence) }
+
+func (n *Binary) String() string {
+	precedence := binaryPrecedence[n.Op]
+	// Operators are left-associative, so a right operand of the same precedence keeps its parentheses.
+	return _operand(n.X, precedence) + " " + n.Op + " " + _operand(n.Y, precedence+1)
+}
+
+func (n *Conditional) String() string {
+	return _operand(n.Cond, _conditionalPrecedence+1) + " ? " + n.Then.String() + " : " + n.Else.String()
+}
+
+func (n *Call) String() string {
+	args := make([]string, len(n.Args))
+	for i, arg := range n.Args {
+		args[i] = arg.String()
+	}
+	return n.Func + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (n *Literal) _precedence() int     { return _primaryPrecedence }
+func (n *Ident) _precedence() int       { return _primaryPrecedence }
+func (n *Unary) _precedence() int       { return _unaryPrecedence }
+func (n *Binary) _precedence() int      { return binaryPrecedence[n.Op] }
+func (n *Conditional) _precedence() int { return _conditionalPrecedence }
+func (n *Call) _precedence() int        { return _primaryPrecedence }
+
+func _operand(n Node, precedence int) string {
+	if n._precedence() < precedence {
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
+
+// IsExpression reports whether value is an expression wrapped in $( and ).
+func IsExpression(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, Prefix) && strings.HasSuffix(value, Suffix)
+}
+
+// Unwrap returns the source of an expression without its $( and ).
+func Unwrap(value string) (string, bool) {
+	if !IsExpression(value) {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	return value[len(Prefix) : len(value)-len(Suffix)], true
+}
+
+// Wrap returns node as a wrapped expression in canonical form.
+func Wrap(node Node) string {
+	return Prefix + node.String() + Suffix
+}
+
+// ParseValue parses a wrapped expression such as $(count > 0).
+func ParseValue(value string) (Node, error) {
+	source, ok := Unwrap(value)
+	if !ok {
+		return nil, &Error{Message: fmt.Sprintf("'%s' is not an expression. Wrap it in $( and )", value)}
+	}
+	return Parse(source)
+}
+
+// Normalize returns a wrapped expression in canonical form, or value unchanged when it is not a valid
+// expression.
+func Normalize(value string) string {
+	node, err := ParseValue(value)
+	if err != nil {
+		return value
+	}
+	return Wrap(node)
+}
+
+// Root returns the variable a name refers to: item for the field path item.price.
+func Root(name string) string {
+	root, _, _ := strings.Cut(name, ".")
+	return root
+}
+
+// Variables returns the names of the variables an expression refers to, in order of first use. Field
+// paths count as their root variable.
+func Variables(node Node) []string {
+	var names []string
+	seen := make(map[string]bool)
+	Inspect(node, func(n Node) {
+		if ident, ok := n.(*Ident); ok && !seen[Root(ident.Name)] {
+			seen[Root(ident.Name)] = true
+			names = append(names, Root(ident.Name))
+		}
+	})
+	return names
+}
+
+// ValueVariables returns the variables a wrapped expression refers to, or nil when value is not a valid
+// expression.
+func ValueVariables(value string) []string {
+	node, err := ParseValue(value)
+	if err != nil {
+		return nil
+	}
+	return Variables(node)
+}
+
+// Inspect calls visit for node and every node below it, parents first.
+func Inspect(node Node, visit func(Node)) {
+	visit(node)
+	switch n := node.(type) {
+	case *Unary:
+		Inspect(n.X, visit)
+	case *Binary:
+		Inspect(n.X, visit)
+		Inspect(n.Y, visit)
+	case *Conditional:
+		Inspect(n.Cond, visit)
+		Inspect(n.Then, visit)
+		Inspect(n.Else, visit)
+	case *Call:
+		for _, arg := range n.Args {
+			Inspect(arg, visit)
+		}
+	}
+}
+
+// Rename replaces the variables of node named in names, in place, and returns node. Field paths keep
+// their fields.
+func Rename(node Node, names map[string]string) Node {
+	Inspect(node, func(n Node) {
+		if ident, ok := n.(*Ident); ok {
+			ident.Name = RenamePath(ident.Name, names)
+		}
+	})
+	return node
+}
+
+// RenamePath renames the root variable of a name or field path named in names.
+func RenamePath(name string, names map[string]string) string {
+	root, fields, hasFields := strings.Cut(name, ".")
+	renamed, exists := names[root]
+	if !exists {
+		return name
+	}
+	if hasFields {
+		return renamed + "." + fields
+	}
+	return renamed
+}
+
+// RenameValue renames the variables of a wrapped expression. Values that are not valid expressions are
+// returned unchanged.
+func RenameValue(value string, names map[string]string) string {
+	node, err := ParseValue(value)
+	if err != nil {
+		return value
+	}
+	return Wrap(Rename(node, names))
+}
+
+// Error is an expression error at an offset in the expression source.
+type Error struct {
+	Message string
+	Offset  int
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
@@ -0,0 +1,372 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/types"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{`status=="ERROR"&&!loading`, `status == "ERROR" && !loading`},
+		{`'Hello, ' + name`, `"Hello, " + name`},
+		{`'say "hi"'`, `'say "hi"'`},
+		{`(a + b) * c`, `(a + b) * c`},
+		{`a + (b * c)`, `a + b * c`},
+		{`a - (b - c)`, `a - (b - c)`},
+		{`(a || b) && c`, `(a || b) && c`},
+		{`count > 0 ? "In stock" : "Sold out"`, `count > 0 ? "In stock" : "Sold out"`},
+		{`a ? b : c ? d : e`, `a ? b : c ? d : e`},
+		{`isEmpty( items ) || len(title) > 20`, `isEmpty(items) || len(title) > 20`},
+		{`-(1.5)`, `-1.5`},
+		{`2.0 * 3`, `2.0 * 3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			node, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if node.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, node.String())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+		offset   int
+	}{
+		{`name == "open`, "Unterminated string", 8},
+		{`a # b`, "Unexpected character '#'", 2},
+		{`len(items`, "Expected ')', but got 'end of expression'", 9},
+		{`a +`, "Unexpected end of expression", 3},
+		{`a b`, "Unexpected 'b'", 2},
+		{`a ? b`, "Expected ':', but got 'end of expression'", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			_, err := Parse(tt.source)
+			exprErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("Expected an expression error, got %v", err)
+			}
+			if exprErr.Message != tt.expected || exprErr.Offset != tt.offset {
+				t.Errorf("Expected %q at %d, got %q at %d", tt.expected, tt.offset, exprErr.Message, exprErr.Offset)
+			}
+		})
+	}
+}
+
+func TestValueHelpers(t *testing.T) {
+	if !IsExpression(" $(a > 1) ") || IsExpression("count") {
+		t.Error("Expected IsExpression to recognize wrapped expressions only")
+	}
+	if got := Normalize("$(a>1&&b)"); got != "$(a > 1 && b)" {
+		t.Errorf("Expected the canonical form, got %s", got)
+	}
+	if got := Normalize("$(a >)"); got != "$(a >)" {
+		t.Errorf("Expected an invalid expression to be kept, got %s", got)
+	}
+	if got := ValueVariables(`$(b + a > len(b))`); len(got) != 2 || got[0] != "b" || got[1] != "a" {
+		t.Errorf("Expected [b a], got %v", got)
+	}
+	if got := RenameValue(`$(title + "title")`, map[string]string{"title": "heading"}); got != `$(heading + "title")` {
+		t.Errorf("Expected the variable to be renamed, got %s", got)
+	}
+	if _, err := ParseValue("count"); err == nil {
+		t.Error("Expected an error for a value that is not wrapped")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	status := types.NewEnumType("Status", []string{"IDLE", "ERROR"})
+	variables := map[string]types.Type{
+		"count":   types.TypeInt,
+		"total":   types.TypeLong,
+		"price":   types.TypeDouble,
+		"name":    types.TypeString,
+		"loading": types.TypeBoolean,
+		"status":  status,
+		"tags":    types.ListType{Element: types.TypeString},
+		"scores":  types.MapType{Value: types.TypeInt},
+	}
+
+	valid := []struct {
+		source   string
+		expected types.Type
+	}{
+		{`status == "ERROR" && !loading`, types.TypeBoolean},
+		{`"Total: " + count`, types.TypeString},
+		{`count + total`, types.TypeLong},
+		{`count * price`, types.TypeDouble},
+		{`-count`, types.TypeInt},
+		{`name < "m"`, types.TypeBoolean},
+		{`count > 0 ? "In stock" : name`, types.TypeString},
+		{`loading ? count : price`, types.TypeDouble},
+		{`loading ? status : "IDLE"`, types.TypeString},
+		{`len(tags)`, types.TypeInt},
+		{`isEmpty(name)`, types.TypeBoolean},
+		{`contains(tags, name) || contains(scores, "a") || contains(name, "a")`, types.TypeBoolean},
+		{`upper(trim(name))`, types.TypeString},
+		{`count == 3000000000`, types.TypeBoolean},
+	}
+	for _, tt := range valid {
+		t.Run(tt.source, func(t *testing.T) {
+			node, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+			result, err := Check(node, variables)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Name() != tt.expected.Name() {
+				t.Errorf("Expected %s, got %s", tt.expected.Name(), result.Name())
+			}
+		})
+	}
+
+	invalid := []struct {
+		source   string
+		expected string
+	}{
+		{`missing > 0`, "Undefined variable 'missing'"},
+		{`!count`, "Operator ! takes a BOOLEAN, got INT"},
+		{`-name`, "Operator - takes a number, got STRING"},
+		{`loading && count`, "Operator && takes BOOLEAN operands, got BOOLEAN and INT"},
+		{`count - name`, "Operator - takes numbers, got INT and STRING"},
+		{`name + tags`, "Cannot concatenate STRING and LIST<STRING>"},
+		{`count < name`, "Cannot compare INT with STRING"},
+		{`loading == count`, "Cannot compare BOOLEAN with INT"},
+		{`status == "DONE"`, "'DONE' is not a member of Status. Expected one of IDLE, ERROR"},
+		{`status == count`, "Cannot compare Status with INT"},
+		{`count ? 1 : 2`, "The condition of ?: must be BOOLEAN, got INT"},
+		{`loading ? count : name`, "The branches of ?: have different types INT and STRING"},
+		{`size(tags)`, "Unknown function 'size'. Available functions: contains, isEmpty, len, lower, trim, upper"},
+		{`len(tags, name)`, "len takes 1 argument(s), got 2"},
+		{`len(count)`, "len takes a string, list, map or JSON, got INT"},
+		{`contains(tags, count)`, "contains on LIST<STRING> takes a STRING element, got INT"},
+		{`contains(scores, count)`, "contains on MAP<STRING,INT> takes a STRING key, got INT"},
+		{`lower(count)`, "lower takes a STRING, got INT"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.source, func(t *testing.T) {
+			node, err := Parse(tt.source)
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+			_, err = Check(node, variables)
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	values := Values([]model.VariableDSLModel{
+		{Key: "count", Type: "INT", Value: "3"},
+		{Key: "price", Type: "DOUBLE", Value: "2.5"},
+		{Key: "name", Type: "STRING", Value: " Ada "},
+		{Key: "loading", Type: "BOOLEAN", Value: "false"},
+		{Key: "status", Type: "Status", Value: "ERROR"},
+		{Key: "tags", Type: "LIST<INT>", Value: "[1, 2]"},
+		{Key: "scores", Type: "MAP<STRING, INT>", Value: `{"a": 1}`},
+	})
+
+	tests := []struct {
+		source   string
+		expected any
+	}{
+		{`status == "ERROR" && !loading`, true},
+		{`"Total: " + count`, "Total: 3"},
+		{`count * price`, 7.5},
+		{`count / 2`, int64(1)},
+		{`count % 2 == 1`, true},
+		{`count > 0 ? "In stock" : "Sold out"`, "In stock"},
+		{`len(tags) + len(trim(name))`, int64(5)},
+		{`contains(tags, 2) && contains(scores, "a") && !contains(name, "z")`, true},
+		{`upper(trim(name))`, "ADA"},
+		{`isEmpty("")`, true},
+		{`loading && missing`, false},
+		{`count == 3.0`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			result, err := EvalValue(Prefix+tt.source+Suffix, values)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %#v, got %#v", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := EvalValue("$(count / 0)", values); err == nil || err.Error() != "Division by zero" {
+		t.Errorf("Expected a division by zero error, got %v", err)
+	}
+	if _, err := EvalValue("$(missing)", values); err == nil {
+		t.Error("Expected an error for a variable without a value")
+	}
+}
+
+func TestTruthyAndFormat(t *testing.T) {
+	for _, value := range []any{false, "", "false", int64(0), 0.0, nil} {
+		if Truthy(value) {
+			t.Errorf("Expected %#v to be false", value)
+		}
+	}
+	for _, value := range []any{true, "yes", int64(2), []any{}} {
+		if !Truthy(value) {
+			t.Errorf("Expected %#v to be true", value)
+		}
+	}
+	if got := Format([]any{int64(1), "a"}); got != `[1,"a"]` {
+		t.Errorf("Expected a JSON list, got %s", got)
+	}
+	if got := Format(1.5); got != "1.5" {
+		t.Errorf("Expected 1.5, got %s", got)
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	parts, err := ParseTemplate("Hello, { username }! {{{count}}}")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []Part{
+		{Text: "Hello, ", Offset: 0},
+		{Variable: "username", Offset: 7},
+		{Text: "! {", Offset: 19},
+		{Variable: "count", Offset: 23},
+		{Text: "}", Offset: 30},
+	}
+	if len(parts) != len(expected) {
+		t.Fatalf("Expected %d parts, got %+v", len(expected), parts)
+	}
+	for i := range expected {
+		if parts[i] != expected[i] {
+			t.Errorf("Part %d: expected %+v, got %+v", i, expected[i], parts[i])
+		}
+	}
+	if got := JoinTemplate(parts); got != "Hello, {username}! {{{count}}}" {
+		t.Errorf("Expected the template to be rebuilt, got %s", got)
+	}
+
+	errs := []struct {
+		value    string
+		expected string
+		offset   int
+	}{
+		{"Hi {name", "Unclosed placeholder, expected '}'", 3},
+		{"Hi {first name}", "Invalid placeholder '{first name}'. Use {variableName}, or {{ for a literal brace", 3},
+		{"Hi {}", "Invalid placeholder '{}'. Use {variableName}, or {{ for a literal brace", 3},
+		{"a } b", "Unexpected '}'. Use }} for a literal brace", 2},
+	}
+	for _, tt := range errs {
+		_, err := ParseTemplate(tt.value)
+		exprErr, ok := err.(*Error)
+		if !ok || exprErr.Message != tt.expected || exprErr.Offset != tt.offset {
+			t.Errorf("%s: expected %q at %d, got %v", tt.value, tt.expected, tt.offset, err)
+		}
+	}
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	if !IsTemplate("Hi {name}") || IsTemplate("name") || IsTemplate(`$(name + "{")`) {
+		t.Error("Expected IsTemplate to recognize templates only")
+	}
+	if got := TemplateVariables("{a} and {b} and {a}"); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Expected [a b], got %v", got)
+	}
+	if got := RenameTemplate("{name}, name", map[string]string{"name": "username"}); got != "{username}, name" {
+		t.Errorf("Expected the placeholder to be renamed, got %s", got)
+	}
+
+	values := Values([]model.VariableDSLModel{
+		{Key: "name", Type: "STRING", Value: "Ada"},
+		{Key: "count", Type: "INT", Value: "3"},
+	})
+	if got, err := EvalTemplate("{name} has {count} items {{ok}}", values); err != nil || got != "Ada has 3 items {ok}" {
+		t.Errorf("Expected the rendered template, got %q, %v", got, err)
+	}
+	if _, err := EvalTemplate("{missing}", values); err == nil {
+		t.Error("Expected an error for a variable without a value")
+	}
+}
+
+func TestFieldPaths(t *testing.T) {
+	variables := map[string]types.Type{
+		"item":  types.MapType{Value: types.TypeDouble},
+		"count": types.TypeInt,
+	}
+	node, err := Parse(`item.price > 10`)
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+	if result, err := Check(node, variables); err != nil || result.Name() != "BOOLEAN" {
+		t.Errorf("Expected BOOLEAN, got %v, %v", result, err)
+	}
+	if names := Variables(node); len(names) != 1 || names[0] != "item" {
+		t.Errorf("Expected the root variable, got %v", names)
+	}
+	if _, err := PathType("count.value", variables, 0); err == nil || err.Error() != "'count' is a INT and has no field 'value'" {
+		t.Errorf("Expected a field error, got %v", err)
+	}
+
+	values := map[string]any{"item": map[string]any{"price": 12.5}}
+	if result, err := Eval(node, values); err != nil || result != true {
+		t.Errorf("Expected true, got %v, %v", result, err)
+	}
+	if value, exists := Lookup(values, "item.missing"); !exists || value != nil {
+		t.Errorf("Expected a missing field to be nil, got %v, %v", value, exists)
+	}
+	if _, exists := Lookup(values, "other.price"); exists {
+		t.Errorf("Expected an undefined root")
+	}
+	if result := RenamePath("item.price", map[string]string{"item": "product"}); result != "product.price" {
+		t.Errorf("Expected product.price, got %s", result)
+	}
+	if result, _ := EvalTemplate("{item.price} each", values); result != "12.5 each" {
+		t.Errorf("Expected the field value, got %s", result)
+	}
+}
+
+func TestComputedOrder(t *testing.T) {
+	variables := []model.VariableDSLModel{
+		{Key: "label", Type: "STRING", Value: `"Total: " + total`, Computed: true},
+		{Key: "total", Type: "INT", Value: "price * quantity", Computed: true},
+		{Key: "price", Type: "INT", Value: "3"},
+		{Key: "quantity", Type: "INT", Value: "2"},
+	}
+	order, cycle := ComputedOrder(variables)
+	if cycle != nil || len(order) != 2 || order[0].Key != "total" || order[1].Key != "label" {
+		t.Fatalf("Expected total before label, got %v, cycle %v", order, cycle)
+	}
+
+	values := Values(variables)
+	if values["total"] != int64(6) || values["label"] != "Total: 6" {
+		t.Errorf("Expected the computed values, got %v and %v", values["total"], values["label"])
+	}
+
+	variables[2] = model.VariableDSLModel{Key: "price", Type: "INT", Value: "label", Computed: true}
+	_, cycle = ComputedOrder(variables)
+	if strings.Join(cycle, " -> ") != "label -> total -> price -> label" {
+		t.Errorf("Expected the cycle through label, got %v", cycle)
+	}
+	if values := Values(variables); values["label"] != nil {
+		t.Errorf("Expected variables in a cycle to have no value, got %v", values["label"])
+	}
+}
@@ -0,0 +1,257 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse parses an expression:
+//
+//	status == "ERROR" && !loading
+//	"Hello, " + name
+//	count > 0 ? "In stock" : "Sold out"
+//	isEmpty(items) || len(title) > 20
+//	item.price > 10
+//
+// Strings are double- or single-quoted, so expressions fit in XML attributes.
+func Parse(source string) (Node, error) {
+	p := &_parser{source: source}
+	p._next()
+	node, err := p._conditional()
+	if err != nil {
+		return nil, err
+	}
+	if p.token.kind != _tokenEOF || p.err != nil {
+		return nil, p._unexpected()
+	}
+	return node, nil
+}
+
+type _tokenKind int
+
+const (
+	_tokenEOF _tokenKind = iota
+	_tokenIdent
+	_tokenNumber
+	_tokenString
+	_tokenOperator
+)
+
+type _token struct {
+	kind   _tokenKind
+	text   string
+	offset int
+}
+
+type _parser struct {
+	source   string
+	position int
+	token    _token
+	err      error
+}
+
+// _operators lists the operator tokens, two-character operators first.
+var _operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "+", "-", "*", "/", "%", "!", "?", ":", "(", ")", ","}
+
+func (p *_parser) _next() {
+	for p.position < len(p.source) && strings.ContainsRune(" \t\r\n", rune(p.source[p.position])) {
+		p.position++
+	}
+	start := p.position
+	if start >= len(p.source) {
+		p.token = _token{kind: _tokenEOF, offset: start}
+		return
+	}
+
+	ch := p.source[start]
+	switch {
+	case ch == '"' || ch == '\'':
+		end := strings.IndexByte(p.source[start+1:], ch)
+		if end < 0 {
+			p.err = &Error{Message: "Unterminated string", Offset: start}
+			p.token = _token{kind: _tokenEOF, offset: start}
+			p.position = len(p.source)
+			return
+		}
+		p.position = start + 1 + end + 1
+		p.token = _token{kind: _tokenString, text: p.source[start+1 : start+1+end], offset: start}
+	case _isLetter(ch):
+		// A dot followed by a letter continues a field path such as item.price.
+		for p.position < len(p.source) && (_isLetter(p.source[p.position]) || _isDigit(p.source[p.position]) ||
+			p.source[p.position] == '.' && p.position+1 < len(p.source) && _isLetter(p.source[p.position+1])) {
+			p.position++
+		}
+		p.token = _token{kind: _tokenIdent, text: p.source[start:p.position], offset: start}
+	case _isDigit(ch):
+		for p.position < len(p.source) && (_isDigit(p.source[p.position]) || p.source[p.position] == '.') {
+			p.position++
+		}
+		p.token = _token{kind: _tokenNumber, text: p.source[start:p.position], offset: start}
+	default:
+		for _, op := range _operators {
+			if strings.HasPrefix(p.source[start:], op) {
+				p.position += len(op)
+				p.token = _token{kind: _tokenOperator, text: op, offset: start}
+				return
+			}
+		}
+		p.err = &Error{Message: fmt.Sprintf("Unexpected character '%c'", ch), Offset: start}
+		p.token = _token{kind: _tokenEOF, offset: start}
+		p.position = len(p.source)
+	}
+}
+
+func (p *_parser) _is(op string) bool {
+	return p.token.kind == _tokenOperator && p.token.text == op
+}
+
+func (p *_parser) _expect(op string) error {
+	if !p._is(op) {
+		if p.err != nil {
+			return p.err
+		}
+		found := p.token.text
+		if p.token.kind == _tokenEOF {
+			found = "end of expression"
+		}
+		return &Error{Message: fmt.Sprintf("Expected '%s', but got '%s'", op, found), Offset: p.token.offset}
+	}
+	p._next()
+	return nil
+}
+
+func (p *_parser) _unexpected() error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.token.kind == _tokenEOF {
+		return &Error{Message: "Unexpected end of expression", Offset: p.token.offset}
+	}
+	return &Error{Message: fmt.Sprintf("Unexpected '%s'", p.token.text), Offset: p.token.offset}
+}
+
+func (p *_parser) _conditional() (Node, error) {
+	cond, err := p._binary(2)
+	if err != nil || !p._is("?") {
+		return cond, err
+	}
+	offset := p.token.offset
+	p._next()
+	then, err := p._conditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p._expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p._conditional()
+	if err != nil {
+		return nil, err
+	}
+	return &Conditional{Cond: cond, Then: then, Else: otherwise, Offset: offset}, nil
+}
+
+// _binary parses the binary operators of precedence and tighter.
+func (p *_parser) _binary(precedence int) (Node, error) {
+	if precedence > 7 {
+		return p._unary()
+	}
+	x, err := p._binary(precedence + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.token.kind == _tokenOperator && binaryPrecedence[p.token.text] == precedence {
+		op, offset := p.token.text, p.token.offset
+		p._next()
+		y, err := p._binary(precedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: op, X: x, Y: y, Offset: offset}
+	}
+	return x, nil
+}
+
+func (p *_parser) _unary() (Node, error) {
+	if p._is("!") || p._is("-") {
+		op, offset := p.token.text, p.token.offset
+		p._next()
+		x, err := p._unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: op, X: x, Offset: offset}, nil
+	}
+	return p._primary()
+}
+
+func (p *_parser) _primary() (Node, error) {
+	token := p.token
+	switch token.kind {
+	case _tokenString:
+		p._next()
+		return &Literal{Value: token.text, Offset: token.offset}, nil
+	case _tokenNumber:
+		p._next()
+		if !strings.Contains(token.text, ".") {
+			if i, err := strconv.ParseInt(token.text, 10, 64); err == nil {
+				return &Literal{Value: i, Offset: token.offset}, nil
+			}
+		}
+		f, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, &Error{Message: fmt.Sprintf("Invalid number '%s'", token.text), Offset: token.offset}
+		}
+		return &Literal{Value: f, Offset: token.offset}, nil
+	case _tokenIdent:
+		p._next()
+		switch token.text {
+		case "true":
+			return &Literal{Value: true, Offset: token.offset}, nil
+		case "false":
+			return &Literal{Value: false, Offset: token.offset}, nil
+		}
+		if !p._is("(") {
+			return &Ident{Name: token.text, Offset: token.offset}, nil
+		}
+		p._next()
+		call := &Call{Func: token.text, Args: make([]Node, 0), Offset: token.offset}
+		for !p._is(")") {
+			arg, err := p._conditional()
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+			if !p._is(",") {
+				break
+			}
+			p._next()
+		}
+		if err := p._expect(")"); err != nil {
+			return nil, err
+		}
+		return call, nil
+	}
+
+	if p._is("(") {
+		p._next()
+		node, err := p._conditional()
+		if err != nil {
+			return nil, err
+		}
+		if err := p._expect(")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	return nil, p._unexpected()
+}
+
+func _isLetter(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+func _isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
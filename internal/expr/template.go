@@ -0,0 +1,148 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Part is a piece of a template: literal text, or a placeholder naming the variable or field path whose
+// value is inserted. Offset is the position of the text or of the placeholder's '{' in the template.
+type Part struct {
+	Text     string
+	Variable string
+	Offset   int
+}
+
+// IsTemplate reports whether value is a template such as "Hello, {username}!" rather than a variable
+// name or an expression.
+func IsTemplate(value string) bool {
+	return !IsExpression(value) && strings.ContainsAny(value, "{}")
+}
+
+// ParseTemplate splits a template into its parts. A placeholder is a variable name or field path in
+// braces, and {{ and }} stand for literal braces.
+func ParseTemplate(value string) ([]Part, error) {
+	parts := make([]Part, 0)
+	var text strings.Builder
+	textOffset := 0
+	flush := func() {
+		if text.Len() > 0 {
+			parts = append(parts, Part{Text: text.String(), Offset: textOffset})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "{{"), strings.HasPrefix(value[i:], "}}"):
+			if text.Len() == 0 {
+				textOffset = i
+			}
+			text.WriteByte(value[i])
+			i++
+		case value[i] == '{':
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				return nil, &Error{Message: "Unclosed placeholder, expected '}'", Offset: i}
+			}
+			name := strings.TrimSpace(value[i+1 : i+end])
+			if !_isName(name) {
+				return nil, &Error{Message: fmt.Sprintf("Invalid placeholder '%s'. Use {variableName}, or {{ for a literal brace", value[i:i+end+1]), Offset: i}
+			}
+			flush()
+			parts = append(parts, Part{Variable: name, Offset: i})
+			i += end
+		case value[i] == '}':
+			return nil, &Error{Message: "Unexpected '}'. Use }} for a literal brace", Offset: i}
+		default:
+			if text.Len() == 0 {
+				textOffset = i
+			}
+			text.WriteByte(value[i])
+		}
+	}
+	flush()
+	return parts, nil
+}
+
+// TemplateVariables returns the variables a template refers to, in order of first use, or nil when value
+// is not a valid template.
+func TemplateVariables(value string) []string {
+	parts, err := ParseTemplate(value)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		if root := Root(part.Variable); root != "" && !seen[root] {
+			seen[root] = true
+			names = append(names, root)
+		}
+	}
+	return names
+}
+
+// RenameTemplate renames the placeholders of a template named in names. Values that are not valid
+// templates are returned unchanged.
+func RenameTemplate(value string, names map[string]string) string {
+	parts, err := ParseTemplate(value)
+	if err != nil {
+		return value
+	}
+	for i := range parts {
+		if parts[i].Variable != "" {
+			parts[i].Variable = RenamePath(parts[i].Variable, names)
+		}
+	}
+	return JoinTemplate(parts)
+}
+
+// JoinTemplate returns the template text of parts, escaping the braces of literal text.
+func JoinTemplate(parts []Part) string {
+	var builder strings.Builder
+	for _, part := range parts {
+		if part.Variable != "" {
+			builder.WriteString("{" + part.Variable + "}")
+			continue
+		}
+		builder.WriteString(strings.NewReplacer("{", "{{", "}", "}}").Replace(part.Text))
+	}
+	return builder.String()
+}
+
+// EvalTemplate renders a template with the variable values in values, as returned by Values.
+func EvalTemplate(value string, values map[string]any) (string, error) {
+	parts, err := ParseTemplate(value)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	for _, part := range parts {
+		if part.Variable == "" {
+			builder.WriteString(part.Text)
+			continue
+		}
+		variable, exists := Lookup(values, part.Variable)
+		if !exists {
+			return "", &Error{Message: fmt.Sprintf("Undefined variable '%s'", Root(part.Variable)), Offset: part.Offset}
+		}
+		builder.WriteString(Format(variable))
+	}
+	return builder.String(), nil
+}
+
+// _isName reports whether name is a variable name or a field path such as item.name.
+func _isName(name string) bool {
+	for _, segment := range strings.Split(name, ".") {
+		if segment == "" || _isDigit(segment[0]) {
+			return false
+		}
+		for i := 0; i < len(segment); i++ {
+			if !_isLetter(segment[i]) && !_isDigit(segment[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
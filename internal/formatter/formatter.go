@@ -1,14 +1,18 @@
 package formatter
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/expr"
 	"github.com/nativeblocks/nbx/internal/lexer"
 	"github.com/nativeblocks/nbx/internal/model"
 	"github.com/nativeblocks/nbx/internal/parser"
+	"github.com/nativeblocks/nbx/internal/types"
 )
 
 func Format(dslString string) (string, []errors.Error) {
@@ -25,20 +29,92 @@ func FormatFrameDSL(frame model.FrameDSLModel) string {
 
 	builder.WriteString("frame(\n")
 	builder.WriteString(fmt.Sprintf("    name = \"%s\",\n", frame.Name))
-	builder.WriteString(fmt.Sprintf("    route = \"%s\"\n", frame.Route))
+	if frame.Starter {
+		builder.WriteString(fmt.Sprintf("    route = \"%s\",\n", frame.Route))
+		builder.WriteString("    starter = true\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("    route = \"%s\"\n", frame.Route))
+	}
 	builder.WriteString(") {\n")
 
+	for _, imp := range frame.Imports {
+		builder.WriteString(fmt.Sprintf("    import \"%s\"\n", imp.Path))
+	}
+
+	if len(frame.Imports) > 0 {
+		builder.WriteString("\n")
+	}
+
+	enums := make(map[string]bool, len(frame.Enums))
+	for _, enum := range frame.Enums {
+		enums[enum.Name] = true
+	}
+	frame = _ownDeclarations(frame)
+
+	for _, constant := range frame.Constants {
+		builder.WriteString(fmt.Sprintf("    const %s: %s = %s\n",
+			constant.Key,
+			constant.Type,
+			_formatDeclaredValue(constant.Value, constant.Type, enums)))
+	}
+
+	if len(frame.Constants) > 0 {
+		builder.WriteString("\n")
+	}
+
+	for _, enum := range frame.Enums {
+		builder.WriteString(fmt.Sprintf("    enum %s { %s }\n", enum.Name, strings.Join(enum.Members, ", ")))
+	}
+
+	if len(frame.Enums) > 0 {
+		builder.WriteString("\n")
+	}
+
 	for _, variable := range frame.Variables {
+		if variable.Computed {
+			builder.WriteString(fmt.Sprintf("    val %s: %s = %s\n", variable.Key, variable.Type, _formatComputed(variable.Value)))
+			continue
+		}
 		builder.WriteString(fmt.Sprintf("    var %s: %s = %s\n",
 			variable.Key,
 			variable.Type,
-			_formatVariableValueConsistent(variable.Value, variable.Type)))
+			_formatDeclaredValue(variable.Value, variable.Type, enums)))
 	}
 
 	if len(frame.Variables) > 0 {
 		builder.WriteString("\n")
 	}
 
+	for _, action := range frame.Actions {
+		builder.WriteString(fmt.Sprintf("    action(event = \"%s\") {\n", action.Event))
+		for _, trigger := range action.Triggers {
+			_formatTriggerConsistent(&builder, trigger, 2)
+		}
+		builder.WriteString("    }\n\n")
+	}
+
+	for _, sequence := range frame.Sequences {
+		builder.WriteString(fmt.Sprintf("    sequence(name = \"%s\") {\n", sequence.Name))
+		for _, trigger := range sequence.Triggers {
+			_formatTriggerConsistent(&builder, trigger, 2)
+		}
+		builder.WriteString("    }\n\n")
+	}
+
+	for _, style := range frame.Styles {
+		builder.WriteString(fmt.Sprintf("    style %s {", style.Name))
+		if len(style.Styles) > 0 {
+			builder.WriteString(fmt.Sprintf("\n        .style(%s)", strings.Join(style.Styles, ", ")))
+		}
+		_formatPropsConsistent(&builder, style.Properties, 2)
+		builder.WriteString("\n    }\n\n")
+	}
+
+	for _, component := range frame.Components {
+		_formatComponentConsistent(&builder, component, 1, enums)
+		builder.WriteString("\n")
+	}
+
 	for _, block := range frame.Blocks {
 		_formatBlockConsistent(&builder, block, 1)
 	}
@@ -47,24 +123,99 @@ func FormatFrameDSL(frame model.FrameDSLModel) string {
 	return builder.String()
 }
 
+func _formatComponentConsistent(builder *strings.Builder, component model.ComponentDSLModel, indentLevel int, enums map[string]bool) {
+	indent := strings.Repeat("    ", indentLevel)
+	paramIndent := strings.Repeat("    ", indentLevel+1)
+
+	builder.WriteString(fmt.Sprintf("%scomponent(name = \"%s\") {\n", indent, component.Name))
+
+	for _, param := range component.Properties {
+		builder.WriteString(fmt.Sprintf("%sprop %s: %s", paramIndent, param.Key, param.Type))
+		if !param.Required {
+			builder.WriteString(fmt.Sprintf(" = %s", _formatDeclaredValue(param.Value, param.Type, enums)))
+		}
+		builder.WriteString("\n")
+	}
+	for _, param := range component.Data {
+		builder.WriteString(fmt.Sprintf("%sdata %s\n", paramIndent, param.Key))
+	}
+	if len(component.Properties) > 0 || len(component.Data) > 0 {
+		builder.WriteString("\n")
+	}
+
+	_formatBlockConsistent(builder, component.Block, indentLevel+1)
+	builder.WriteString(fmt.Sprintf("%s}\n", indent))
+}
+
+// _formatComputed returns the expression of a computed variable in canonical form. An expression that does
+// not parse keeps its text, wrapped in $( ) when it spans lines.
+func _formatComputed(value string) string {
+	if node, err := expr.Parse(value); err == nil {
+		return node.String()
+	}
+	if strings.Contains(value, "\n") {
+		return expr.Prefix + value + expr.Suffix
+	}
+	return value
+}
+
 func _formatVariableValueConsistent(value, valueType string) string {
 	switch valueType {
 	case "STRING":
-		return fmt.Sprintf("\"%s\"", value)
+		return _formatPropertyLiteral(value)
 	case "BOOLEAN", "INT", "LONG", "FLOAT", "DOUBLE":
 		return value
-	default:
-		return fmt.Sprintf("\"%s\"", value)
+	case "COLOR":
+		if valid, _ := types.ValidateValue(value, types.TypeColor); valid {
+			return value
+		}
+	}
+	// List, map and JSON values are written as collection literals.
+	if strings.HasPrefix(valueType, "LIST<") || strings.HasPrefix(valueType, "MAP<") || valueType == "JSON" {
+		trimmed := strings.TrimSpace(value)
+		if (strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{")) && json.Valid([]byte(trimmed)) {
+			return trimmed
+		}
+	}
+	return fmt.Sprintf("\"%s\"", value)
+}
+
+var enumMemberRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// _formatDataValue formats a data binding: a variable name, an expression in canonical form, or a quoted
+// template.
+func _formatDataValue(value string) string {
+	if expr.IsTemplate(value) {
+		return `"` + value + `"`
 	}
+	return expr.Normalize(value)
+}
+
+// _formatDeclaredValue formats a constant, variable or prop default, writing the members of a declared
+// enum bare like the DSL declares them.
+func _formatDeclaredValue(value, valueType string, enums map[string]bool) string {
+	if enums[valueType] && enumMemberRegex.MatchString(value) {
+		return value
+	}
+	return _formatVariableValueConsistent(value, valueType)
 }
 
 func _formatBlockConsistent(builder *strings.Builder, block model.BlockDSLModel, indentLevel int) {
 	indent := strings.Repeat("    ", indentLevel)
 
-	builder.WriteString(fmt.Sprintf("%sblock(keyType = \"%s\", key = \"%s\"", indent, block.KeyType, block.Key))
+	if block.Outlet != "" {
+		builder.WriteString(fmt.Sprintf("%sblock(outlet = \"%s\")\n", indent, block.Outlet))
+		return
+	}
+
+	if block.Component != "" {
+		builder.WriteString(fmt.Sprintf("%sblock(component = \"%s\", key = \"%s\"", indent, block.Component, block.Key))
+	} else {
+		builder.WriteString(fmt.Sprintf("%sblock(keyType = \"%s\", key = \"%s\"", indent, block.KeyType, block.Key))
+	}
 
 	if block.VisibilityKey != "" {
-		builder.WriteString(fmt.Sprintf(", visibility = %s", block.VisibilityKey))
+		builder.WriteString(fmt.Sprintf(", visibility = %s", expr.Normalize(block.VisibilityKey)))
 	}
 
 	if block.IntegrationVersion > 0 {
@@ -73,24 +224,16 @@ func _formatBlockConsistent(builder *strings.Builder, block model.BlockDSLModel,
 
 	builder.WriteString(")")
 
-	if len(block.Properties) > 0 {
-		builder.WriteString("\n")
-		builder.WriteString(fmt.Sprintf("%s.prop(\n", indent))
-
-		for i, prop := range block.Properties {
-			propIndent := strings.Repeat("    ", indentLevel+1)
-			value := _getSinglePropertyValueConsistent(prop)
-			builder.WriteString(fmt.Sprintf("%s%s = \"%s\"", propIndent, prop.Key, value))
-
-			if i < len(block.Properties)-1 {
-				builder.WriteString(",")
-			}
-			builder.WriteString("\n")
-		}
+	if block.Repeat != nil {
+		builder.WriteString(fmt.Sprintf("\n%s.repeat(items = %s, as = %s)", indent, block.Repeat.Items, block.Repeat.As))
+	}
 
-		builder.WriteString(fmt.Sprintf("%s)", indent))
+	if len(block.Styles) > 0 {
+		builder.WriteString(fmt.Sprintf("\n%s.style(%s)", indent, strings.Join(block.Styles, ", ")))
 	}
 
+	_formatPropsConsistent(builder, block.Properties, indentLevel)
+
 	if len(block.Data) > 0 {
 		builder.WriteString("\n")
 		builder.WriteString(fmt.Sprintf("%s.data(", indent))
@@ -99,7 +242,7 @@ func _formatBlockConsistent(builder *strings.Builder, block model.BlockDSLModel,
 			if i > 0 {
 				builder.WriteString(", ")
 			}
-			builder.WriteString(fmt.Sprintf("%s = %s", data.Key, data.Value))
+			builder.WriteString(fmt.Sprintf("%s = %s", data.Key, _formatDataValue(data.Value)))
 		}
 
 		builder.WriteString(")")
@@ -143,6 +286,57 @@ func _formatBlockConsistent(builder *strings.Builder, block model.BlockDSLModel,
 	}
 }
 
+// _formatPropsConsistent writes a .prop modifier on its own line, one property per line.
+func _formatPropsConsistent(builder *strings.Builder, props []model.BlockPropertyDSLModel, indentLevel int) {
+	if len(props) == 0 {
+		return
+	}
+	indent := strings.Repeat("    ", indentLevel)
+	propIndent := strings.Repeat("    ", indentLevel+1)
+
+	builder.WriteString("\n")
+	builder.WriteString(fmt.Sprintf("%s.prop(\n", indent))
+
+	for i, prop := range props {
+		builder.WriteString(fmt.Sprintf("%s%s = %s", propIndent, prop.Key, _formatPropertyValueConsistent(prop)))
+
+		if i < len(props)-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("%s)", indent))
+}
+
+// _formatPropertyValueConsistent formats the value of a block property, as a device triple when the
+// devices differ.
+func _formatPropertyValueConsistent(prop model.BlockPropertyDSLModel) string {
+	if prop.ValueMobile == prop.ValueTablet && prop.ValueTablet == prop.ValueDesktop {
+		return _formatPropertyLiteral(_getSinglePropertyValueConsistent(prop))
+	}
+
+	var devices []string
+	if prop.ValueMobile != "" {
+		devices = append(devices, "mobile = "+_formatPropertyLiteral(prop.ValueMobile))
+	}
+	if prop.ValueTablet != "" {
+		devices = append(devices, "tablet = "+_formatPropertyLiteral(prop.ValueTablet))
+	}
+	if prop.ValueDesktop != "" {
+		devices = append(devices, "desktop = "+_formatPropertyLiteral(prop.ValueDesktop))
+	}
+	return "(" + strings.Join(devices, ", ") + ")"
+}
+
+// _formatPropertyLiteral quotes a property value, except for references such as @color.primary.
+func _formatPropertyLiteral(value string) string {
+	if lexer.IsReference(value) {
+		return value
+	}
+	return fmt.Sprintf("\"%s\"", value)
+}
+
 func _getSinglePropertyValueConsistent(prop model.BlockPropertyDSLModel) string {
 	if prop.ValueMobile != "" {
 		return prop.ValueMobile
@@ -172,6 +366,18 @@ func _formatActionConsistent(builder *strings.Builder, action model.ActionDSLMod
 func _formatTriggerConsistent(builder *strings.Builder, trigger model.ActionTriggerDSLModel, indentLevel int) {
 	indent := strings.Repeat("    ", indentLevel)
 
+	if trigger.KeyType == model.ConditionKeyType {
+		_formatIfConsistent(builder, trigger, indentLevel)
+		return
+	}
+
+	if trigger.Sequence != "" {
+		builder.WriteString(fmt.Sprintf("%strigger(sequence = \"%s\")", indent, trigger.Sequence))
+		_formatWhen(builder, trigger, indent)
+		builder.WriteString("\n")
+		return
+	}
+
 	builder.WriteString(fmt.Sprintf("%strigger(keyType = \"%s\", name = \"%s\"",
 		indent, trigger.KeyType, trigger.Name))
 
@@ -194,7 +400,7 @@ func _formatTriggerConsistent(builder *strings.Builder, trigger model.ActionTrig
 				formattedScript := _formatScriptBlock(prop.Value, indentLevel+1)
 				builder.WriteString(fmt.Sprintf("%s = \"%s\"", prop.Key, formattedScript))
 			} else {
-				builder.WriteString(fmt.Sprintf("%s = \"%s\"", prop.Key, prop.Value))
+				builder.WriteString(fmt.Sprintf("%s = %s", prop.Key, _formatPropertyLiteral(prop.Value)))
 			}
 		}
 
@@ -209,12 +415,14 @@ func _formatTriggerConsistent(builder *strings.Builder, trigger model.ActionTrig
 			if i > 0 {
 				builder.WriteString(", ")
 			}
-			builder.WriteString(fmt.Sprintf("%s = %s", data.Key, data.Value))
+			builder.WriteString(fmt.Sprintf("%s = %s", data.Key, _formatDataValue(data.Value)))
 		}
 
 		builder.WriteString(")")
 	}
 
+	_formatWhen(builder, trigger, indent)
+
 	if len(trigger.Triggers) > 0 {
 		builder.WriteString("\n")
 		for _, nestedTrigger := range trigger.Triggers {
@@ -225,6 +433,56 @@ func _formatTriggerConsistent(builder *strings.Builder, trigger model.ActionTrig
 	builder.WriteString("\n")
 }
 
+// _formatWhen writes the .when modifier of a guarded trigger.
+func _formatWhen(builder *strings.Builder, trigger model.ActionTriggerDSLModel, indent string) {
+	if trigger.Condition != "" {
+		builder.WriteString(fmt.Sprintf("\n%s.when(%s)", indent, _formatCondition(trigger.Condition)))
+	}
+}
+
+// _formatIfConsistent writes a trigger of model.ConditionKeyType as if/else, with its SUCCESS triggers in
+// the if branch and its FAILURE triggers in the else branch. An else branch holding a single if/else is
+// written as else if.
+func _formatIfConsistent(builder *strings.Builder, trigger model.ActionTriggerDSLModel, indentLevel int) {
+	indent := strings.Repeat("    ", indentLevel)
+
+	builder.WriteString(fmt.Sprintf("%sif (%s) {\n", indent, _formatCondition(trigger.Condition)))
+	for {
+		var ifTriggers, elseTriggers []model.ActionTriggerDSLModel
+		for _, nestedTrigger := range trigger.Triggers {
+			if nestedTrigger.Then == "FAILURE" {
+				elseTriggers = append(elseTriggers, nestedTrigger)
+			} else {
+				ifTriggers = append(ifTriggers, nestedTrigger)
+			}
+		}
+		for _, nestedTrigger := range ifTriggers {
+			_formatTriggerConsistent(builder, nestedTrigger, indentLevel+1)
+		}
+		if len(elseTriggers) == 1 && elseTriggers[0].KeyType == model.ConditionKeyType {
+			trigger = elseTriggers[0]
+			builder.WriteString(fmt.Sprintf("%s} else if (%s) {\n", indent, _formatCondition(trigger.Condition)))
+			continue
+		}
+		if len(elseTriggers) > 0 {
+			builder.WriteString(fmt.Sprintf("%s} else {\n", indent))
+			for _, nestedTrigger := range elseTriggers {
+				_formatTriggerConsistent(builder, nestedTrigger, indentLevel+1)
+			}
+		}
+		break
+	}
+	builder.WriteString(fmt.Sprintf("%s}\n", indent))
+}
+
+// _formatCondition returns a condition in canonical form, or its text when it does not parse.
+func _formatCondition(condition string) string {
+	if node, err := expr.Parse(condition); err == nil {
+		return node.String()
+	}
+	return strings.TrimSpace(condition)
+}
+
 func _formatScriptBlock(script string, baseIndentLevel int) string {
 	if !strings.Contains(script, "#SCRIPT") {
 		return script
@@ -271,3 +529,14 @@ func _parseToFrameDSL(dslString string) (model.FrameDSLModel, []errors.Error) {
 	}
 	return *frame, nil
 }
+
+// _ownDeclarations returns the frame without the declarations imported from libraries, which belong to
+// the library files.
+func _ownDeclarations(frame model.FrameDSLModel) model.FrameDSLModel {
+	frame.Constants = slices.DeleteFunc(slices.Clone(frame.Constants), func(c model.ConstantDSLModel) bool { return c.File != "" })
+	frame.Enums = slices.DeleteFunc(slices.Clone(frame.Enums), func(e model.EnumDSLModel) bool { return e.File != "" })
+	frame.Variables = slices.DeleteFunc(slices.Clone(frame.Variables), func(v model.VariableDSLModel) bool { return v.File != "" })
+	frame.Styles = slices.DeleteFunc(slices.Clone(frame.Styles), func(s model.StyleDSLModel) bool { return s.File != "" })
+	frame.Components = slices.DeleteFunc(slices.Clone(frame.Components), func(c model.ComponentDSLModel) bool { return c.File != "" })
+	return frame
+}
@@ -48,6 +48,29 @@ func TestFormat(t *testing.T) {
     var username: STRING = ""
     var count: INT = 42
 
+    block(keyType = "ROOT", key = "root")
+}`,
+		},
+		{
+			name: "frame with enums",
+			input: `frame(name = "loader", route = "/loader") {
+    var status: Status = IDLE
+    enum Status {
+        IDLE,
+        LOADING,
+    }
+    var label: STRING = "IDLE"
+    block(keyType = "ROOT", key = "root")
+}`,
+			expected: `frame(
+    name = "loader",
+    route = "/loader"
+) {
+    enum Status { IDLE, LOADING }
+
+    var status: Status = IDLE
+    var label: STRING = "IDLE"
+
     block(keyType = "ROOT", key = "root")
 }`,
 		},
@@ -172,6 +195,23 @@ func TestFormatFrameDSL(t *testing.T) {
 	}
 }
 
+func TestFormatStarterFrame(t *testing.T) {
+	frame := model.FrameDSLModel{Name: "home", Route: "/home", Starter: true}
+
+	result := FormatFrameDSL(frame)
+	if !strings.Contains(result, "    route = \"/home\",\n    starter = true\n) {") {
+		t.Errorf("Expected the starter attribute in:\n%s", result)
+	}
+	if formatted, errs := Format(result); len(errs) > 0 || formatted != result {
+		t.Errorf("Expected formatting to be stable, got errors %v:\n%s", errs, formatted)
+	}
+
+	xmlResult := FormatFrameXML(frame)
+	if !strings.Contains(xmlResult, `<frame name="home" route="/home" starter="true">`) {
+		t.Errorf("Expected the starter attribute in:\n%s", xmlResult)
+	}
+}
+
 func TestFormatVariableValueConsistent(t *testing.T) {
 	tests := []struct {
 		value     string
@@ -185,6 +225,13 @@ func TestFormatVariableValueConsistent(t *testing.T) {
 		{"3.14", "FLOAT", "3.14"},
 		{"2.718", "DOUBLE", "2.718"},
 		{"custom", "CUSTOM", `"custom"`},
+		{"@string.welcome.title", "STRING", "@string.welcome.title"},
+		{"@handle", "STRING", `"@handle"`},
+		{"#2563EB", "COLOR", "#2563EB"},
+		{"https://example.com", "URL", `"https://example.com"`},
+		{`["a", "b"]`, "LIST<STRING>", `["a", "b"]`},
+		{`{"a": 1}`, "MAP<STRING,INT>", `{"a": 1}`},
+		{"{broken", "JSON", `"{broken"`},
 	}
 
 	for _, tt := range tests {
@@ -430,3 +477,113 @@ func TestComplexFrameFormatting(t *testing.T) {
 		t.Error("Expected action formatting to be preserved")
 	}
 }
+
+func TestFormatConditions(t *testing.T) {
+	input := `frame(name = "cart", route = "/cart") {
+    var count: INT = 0
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(keyType = "nativeblocks/change_variable", name = "increase").when((count<10))
+            if (count==0) {
+                trigger(keyType = "nativeblocks/change_variable", name = "first")
+            } else if (count > 5) {
+                trigger(sequence = "reset").when(!(count == 6))
+            } else { trigger(keyType = "nativeblocks/change_variable", name = "some") }
+        }
+}`
+	result, errs := Format(input)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	expected := `    .action(event = "onClick") {
+        trigger(keyType = "nativeblocks/change_variable", name = "increase")
+        .when(count < 10)
+        if (count == 0) {
+            trigger(keyType = "nativeblocks/change_variable", name = "first")
+        } else if (count > 5) {
+            trigger(sequence = "reset")
+            .when(!(count == 6))
+        } else {
+            trigger(keyType = "nativeblocks/change_variable", name = "some")
+        }
+    }`
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected:\n%s\n\nin:\n%s", expected, result)
+	}
+	if formatted, errs := Format(result); len(errs) > 0 || formatted != result {
+		t.Errorf("Expected formatting to be stable, got errors %v:\n%s", errs, formatted)
+	}
+
+	frame, _ := _parseToFrameDSL(input)
+	xmlResult := FormatFrameXML(frame)
+	for _, want := range []string{
+		`<trigger keyType="nativeblocks/change_variable" name="increase" when="count &lt; 10">`,
+		`<trigger if="count == 0">`,
+		`<else>`,
+		`<trigger if="count &gt; 5">`,
+		`<trigger sequence="reset" when="!(count == 6)" />`,
+	} {
+		if !strings.Contains(xmlResult, want) {
+			t.Errorf("Expected %s in:\n%s", want, xmlResult)
+		}
+	}
+}
+
+func TestFormatStyles(t *testing.T) {
+	input := `frame(name = "form", route = "/form") {
+    style rounded { .prop(radiusTopStart = "12") }
+    style primaryButton {
+        .style(rounded)
+        .prop(backgroundColor = @color.primary, width = (mobile = "100", desktop = "200"))
+    }
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(keyType = "nativeblocks/button", key = "submit").style(primaryButton, rounded).prop(width = "match")
+    }
+}`
+	result, errs := Format(input)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	for _, want := range []string{
+		`    style rounded {
+        .prop(
+            radiusTopStart = "12"
+        )
+    }`,
+		`    style primaryButton {
+        .style(rounded)
+        .prop(
+            backgroundColor = @color.primary,
+            width = (mobile = "100", desktop = "200")
+        )
+    }`,
+		`        block(keyType = "nativeblocks/button", key = "submit")
+        .style(primaryButton, rounded)
+        .prop(
+            width = "match"
+        )`,
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected:\n%s\n\nin:\n%s", want, result)
+		}
+	}
+	if formatted, errs := Format(result); len(errs) > 0 || formatted != result {
+		t.Errorf("Expected formatting to be stable, got errors %v:\n%s", errs, formatted)
+	}
+
+	frame, _ := _parseToFrameDSL(input)
+	xmlResult := FormatFrameXML(frame)
+	for _, want := range []string{
+		`<style name="primaryButton" style="rounded">`,
+		`<prop key="width" mobile="100" desktop="200" />`,
+		`<block keyType="nativeblocks/button" key="submit" style="primaryButton, rounded">`,
+	} {
+		if !strings.Contains(xmlResult, want) {
+			t.Errorf("Expected %s in:\n%s", want, xmlResult)
+		}
+	}
+	if formatted, errs := FormatXML(xmlResult); len(errs) > 0 || formatted != xmlResult {
+		t.Errorf("Expected XML formatting to be stable, got errors %v:\n%s", errs, formatted)
+	}
+}
@@ -22,20 +22,70 @@ func FormatXML(xmlString string) (string, []*errors.Error) {
 func FormatFrameXML(frame model.FrameDSLModel) string {
 	var builder strings.Builder
 
+	frame = _ownDeclarations(frame)
 	builder.WriteString(xml.Header)
 
 	builder.WriteString(fmt.Sprintf("<frame name=%q route=%q", frame.Name, frame.Route))
 	if frame.Type != "" && frame.Type != "FRAME" {
 		builder.WriteString(fmt.Sprintf(" type=%q", frame.Type))
 	}
+	if frame.Starter {
+		builder.WriteString(` starter="true"`)
+	}
 	builder.WriteString(">\n")
 
+	for _, i := range frame.Imports {
+		builder.WriteString(fmt.Sprintf("  <import src=%q />\n", _escapeXML(i.Path)))
+	}
+
+	for _, c := range frame.Constants {
+		builder.WriteString(fmt.Sprintf("  <const key=%q type=%q value=%q />\n",
+			c.Key, _escapeXML(c.Type), _escapeXML(c.Value)))
+	}
+
+	for _, e := range frame.Enums {
+		builder.WriteString(fmt.Sprintf("  <enum name=%q members=%q />\n",
+			e.Name, _escapeXML(strings.Join(e.Members, ", "))))
+	}
+
+	if len(frame.Imports)+len(frame.Constants)+len(frame.Enums) > 0 && len(frame.Variables) > 0 {
+		builder.WriteString("\n")
+	}
+
 	for _, v := range frame.Variables {
+		if v.Computed {
+			builder.WriteString(fmt.Sprintf("  <val key=%q type=%q value=%q />\n",
+				v.Key, _escapeXML(v.Type), _escapeXML(_formatComputed(v.Value))))
+			continue
+		}
 		builder.WriteString(fmt.Sprintf("  <var key=%q type=%q value=%q />\n",
-			v.Key, v.Type, _escapeXML(v.Value)))
+			v.Key, _escapeXML(v.Type), _escapeXML(v.Value)))
+	}
+
+	if len(frame.Variables) > 0 && (len(frame.Actions) > 0 || len(frame.Sequences) > 0 || len(frame.Styles) > 0 || len(frame.Components) > 0 || len(frame.Blocks) > 0) {
+		builder.WriteString("\n")
+	}
+
+	for _, a := range frame.Actions {
+		_formatAction(&builder, a, 1)
 	}
 
-	if len(frame.Variables) > 0 && len(frame.Blocks) > 0 {
+	if len(frame.Actions) > 0 && (len(frame.Sequences) > 0 || len(frame.Styles) > 0 || len(frame.Components) > 0 || len(frame.Blocks) > 0) {
+		builder.WriteString("\n")
+	}
+
+	for _, s := range frame.Sequences {
+		_formatSequence(&builder, s, 1)
+		builder.WriteString("\n")
+	}
+
+	for _, s := range frame.Styles {
+		_formatStyle(&builder, s, 1)
+		builder.WriteString("\n")
+	}
+
+	for _, c := range frame.Components {
+		_formatComponent(&builder, c, 1)
 		builder.WriteString("\n")
 	}
 
@@ -47,20 +97,74 @@ func FormatFrameXML(frame model.FrameDSLModel) string {
 	return builder.String()
 }
 
+func _formatStyle(builder *strings.Builder, style model.StyleDSLModel, indent int) {
+	ind := strings.Repeat("  ", indent)
+
+	builder.WriteString(fmt.Sprintf("%s<style name=%q", ind, _escapeXML(style.Name)))
+	if len(style.Styles) > 0 {
+		builder.WriteString(fmt.Sprintf(" style=%q", _escapeXML(strings.Join(style.Styles, ", "))))
+	}
+	builder.WriteString(">\n")
+
+	for _, p := range style.Properties {
+		_formatProperty(builder, p, indent+1)
+	}
+
+	builder.WriteString(fmt.Sprintf("%s</style>\n", ind))
+}
+
+func _formatComponent(builder *strings.Builder, component model.ComponentDSLModel, indent int) {
+	ind := strings.Repeat("  ", indent)
+
+	builder.WriteString(fmt.Sprintf("%s<component name=%q>\n", ind, _escapeXML(component.Name)))
+
+	for _, p := range component.Properties {
+		builder.WriteString(fmt.Sprintf("%s  <prop key=%q type=%q", ind, _escapeXML(p.Key), _escapeXML(p.Type)))
+		if !p.Required {
+			builder.WriteString(fmt.Sprintf(" value=%q", _escapeXML(p.Value)))
+		}
+		builder.WriteString(" />\n")
+	}
+	for _, d := range component.Data {
+		builder.WriteString(fmt.Sprintf("%s  <data key=%q />\n", ind, _escapeXML(d.Key)))
+	}
+
+	_formatBlock(builder, component.Block, indent+1)
+	builder.WriteString(fmt.Sprintf("%s</component>\n", ind))
+}
+
 func _formatBlock(builder *strings.Builder, block model.BlockDSLModel, indent int) {
 	ind := strings.Repeat("  ", indent)
 
-	builder.WriteString(fmt.Sprintf("%s<block keyType=%q key=%q",
-		ind, _escapeXML(block.KeyType), _escapeXML(block.Key)))
+	if block.Outlet != "" {
+		builder.WriteString(fmt.Sprintf("%s<block outlet=%q />\n", ind, _escapeXML(block.Outlet)))
+		return
+	}
+
+	if block.Component != "" {
+		builder.WriteString(fmt.Sprintf("%s<block component=%q key=%q",
+			ind, _escapeXML(block.Component), _escapeXML(block.Key)))
+	} else {
+		builder.WriteString(fmt.Sprintf("%s<block keyType=%q key=%q",
+			ind, _escapeXML(block.KeyType), _escapeXML(block.Key)))
+	}
 
 	if block.VisibilityKey != "" && block.VisibilityKey != "null" {
 		builder.WriteString(fmt.Sprintf(" visibility=%q", _escapeXML(block.VisibilityKey)))
 	}
+	if len(block.Styles) > 0 {
+		builder.WriteString(fmt.Sprintf(" style=%q", _escapeXML(strings.Join(block.Styles, ", "))))
+	}
 	if block.IntegrationVersion > 0 {
 		builder.WriteString(fmt.Sprintf(" version=%q", fmt.Sprint(block.IntegrationVersion)))
 	}
 	builder.WriteString(">\n")
 
+	if block.Repeat != nil {
+		builder.WriteString(fmt.Sprintf("%s  <repeat items=%q as=%q />\n",
+			ind, _escapeXML(block.Repeat.Items), _escapeXML(block.Repeat.As)))
+	}
+
 	for _, p := range block.Properties {
 		_formatProperty(builder, p, indent+1)
 	}
@@ -155,15 +259,38 @@ func _formatAction(builder *strings.Builder, action model.ActionDSLModel, indent
 	builder.WriteString(fmt.Sprintf("%s</action>\n", ind))
 }
 
+func _formatSequence(builder *strings.Builder, sequence model.SequenceDSLModel, indent int) {
+	ind := strings.Repeat("  ", indent)
+
+	builder.WriteString(fmt.Sprintf("%s<sequence name=%q>\n", ind, _escapeXML(sequence.Name)))
+
+	for _, trigger := range sequence.Triggers {
+		_formatTrigger(builder, trigger, indent+1, "NEXT")
+	}
+
+	builder.WriteString(fmt.Sprintf("%s</sequence>\n", ind))
+}
+
 func _formatTrigger(builder *strings.Builder, trigger model.ActionTriggerDSLModel, indent int, defaultThen string) {
 	ind := strings.Repeat("  ", indent)
 
+	if trigger.KeyType == model.ConditionKeyType {
+		_formatIf(builder, trigger, indent)
+		return
+	}
+
+	if trigger.Sequence != "" {
+		builder.WriteString(fmt.Sprintf("%s<trigger sequence=\"%s\"%s />\n", ind, _escapeXML(trigger.Sequence), _formatWhenAttr(trigger)))
+		return
+	}
+
 	builder.WriteString(fmt.Sprintf("%s<trigger keyType=%q name=%q",
 		ind, _escapeXML(trigger.KeyType), _escapeXML(trigger.Name)))
 
 	if trigger.IntegrationVersion > 0 {
 		builder.WriteString(fmt.Sprintf(" version=%q", fmt.Sprint(trigger.IntegrationVersion)))
 	}
+	builder.WriteString(_formatWhenAttr(trigger))
 	builder.WriteString(">\n")
 
 	// Properties
@@ -204,6 +331,39 @@ func _formatTrigger(builder *strings.Builder, trigger model.ActionTriggerDSLMode
 	builder.WriteString(fmt.Sprintf("%s</trigger>\n", ind))
 }
 
+// _formatWhenAttr returns the when attribute of a guarded trigger.
+func _formatWhenAttr(trigger model.ActionTriggerDSLModel) string {
+	if trigger.Condition == "" {
+		return ""
+	}
+	return fmt.Sprintf(" when=\"%s\"", _escapeXML(_formatCondition(trigger.Condition)))
+}
+
+// _formatIf writes a trigger of model.ConditionKeyType as <trigger if="...">, with its SUCCESS triggers
+// in <then> and its FAILURE triggers in <else>.
+func _formatIf(builder *strings.Builder, trigger model.ActionTriggerDSLModel, indent int) {
+	ind := strings.Repeat("  ", indent)
+
+	builder.WriteString(fmt.Sprintf("%s<trigger if=\"%s\">\n", ind, _escapeXML(_formatCondition(trigger.Condition))))
+	for _, branch := range []struct{ element, then string }{{"then", "SUCCESS"}, {"else", "FAILURE"}} {
+		var nestedTriggers []model.ActionTriggerDSLModel
+		for _, nested := range trigger.Triggers {
+			if (nested.Then == "FAILURE") == (branch.then == "FAILURE") {
+				nestedTriggers = append(nestedTriggers, nested)
+			}
+		}
+		if len(nestedTriggers) == 0 {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("%s  <%s>\n", ind, branch.element))
+		for _, nested := range nestedTriggers {
+			_formatTrigger(builder, nested, indent+2, branch.then)
+		}
+		builder.WriteString(fmt.Sprintf("%s  </%s>\n", ind, branch.element))
+	}
+	builder.WriteString(fmt.Sprintf("%s</trigger>\n", ind))
+}
+
 func _escapeXML(s string) string {
 	s = strings.ReplaceAll(s, "&", "&amp;")
 	s = strings.ReplaceAll(s, "<", "&lt;")
@@ -0,0 +1,192 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Bundle holds the translated strings of one locale, by key without the leading "@string.".
+type Bundle struct {
+	Locale  string
+	File    string
+	Strings map[string]string
+}
+
+// NewBundle creates an empty bundle for locale.
+func NewBundle(locale string) *Bundle {
+	return &Bundle{Locale: locale, Strings: make(map[string]string)}
+}
+
+// Parse reads a bundle file by its extension: .json, or .xlf and .xliff for XLIFF. The bundle keeps
+// name in File.
+func Parse(name, content string) (*Bundle, error) {
+	var bundle *Bundle
+	var err error
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		bundle, err = ParseJSON(content)
+	case ".xlf", ".xliff":
+		bundle, err = ParseXLIFF(content)
+	default:
+		return nil, fmt.Errorf("unknown bundle format '%s', expected .json, .xlf or .xliff", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	bundle.File = name
+	return bundle, nil
+}
+
+// ParseJSON reads a JSON bundle: {"locale": "fr", "strings": {"welcome": {"title": "Bienvenue"}}}.
+// Nested objects join their keys with dots, so the example declares @string.welcome.title.
+func ParseJSON(content string) (*Bundle, error) {
+	var file struct {
+		Locale  string         `json:"locale"`
+		Strings map[string]any `json:"strings"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(content)))
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid bundle JSON: %w", err)
+	}
+	if file.Locale == "" {
+		return nil, fmt.Errorf("bundle has no locale")
+	}
+
+	bundle := NewBundle(file.Locale)
+	if err := bundle._add("", file.Strings); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+func (b *Bundle) _add(prefix string, strings map[string]any) error {
+	for key, value := range strings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case string:
+			b.Strings[key] = v
+		case map[string]any:
+			if err := b._add(key, v); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("string '%s' must be a string or an object of strings", key)
+		}
+	}
+	return nil
+}
+
+type _xliff struct {
+	Version string `xml:"version,attr"`
+	// XLIFF 2.0 declares the languages on the root element.
+	SourceLanguage string `xml:"srcLang,attr"`
+	TargetLanguage string `xml:"trgLang,attr"`
+	Files          []struct {
+		// XLIFF 1.2 declares the languages on each file.
+		SourceLanguage string `xml:"source-language,attr"`
+		TargetLanguage string `xml:"target-language,attr"`
+		TransUnits     []struct {
+			ID     string  `xml:"id,attr"`
+			Source string  `xml:"source"`
+			Target *string `xml:"target"`
+		} `xml:"body>trans-unit"`
+		Units []struct {
+			ID       string `xml:"id,attr"`
+			Segments []struct {
+				Source string  `xml:"source"`
+				Target *string `xml:"target"`
+			} `xml:"segment"`
+		} `xml:"unit"`
+	} `xml:"file"`
+}
+
+// ParseXLIFF reads an XLIFF 1.2 or 2.0 bundle. The locale is the target language, or the source language
+// for a file without one. Units without a target in a file with a target language are left out, so they
+// are reported as missing translations.
+func ParseXLIFF(content string) (*Bundle, error) {
+	var file _xliff
+	if err := xml.Unmarshal([]byte(content), &file); err != nil {
+		return nil, fmt.Errorf("invalid XLIFF: %w", err)
+	}
+
+	bundle := NewBundle("")
+	_translate := func(source, target, language, id, sourceText string, targetText *string) {
+		if language == "" {
+			language = source
+		}
+		if bundle.Locale == "" {
+			bundle.Locale = language
+		}
+		switch {
+		case targetText != nil:
+			bundle.Strings[id] = *targetText
+		case target == "":
+			bundle.Strings[id] = sourceText
+		}
+	}
+
+	for _, f := range file.Files {
+		source, target := f.SourceLanguage, f.TargetLanguage
+		if source == "" {
+			source, target = file.SourceLanguage, file.TargetLanguage
+		}
+		for _, unit := range f.TransUnits {
+			_translate(source, target, target, unit.ID, unit.Source, unit.Target)
+		}
+		for _, unit := range f.Units {
+			var sourceText, targetText strings.Builder
+			translated := true
+			for _, segment := range unit.Segments {
+				sourceText.WriteString(segment.Source)
+				if segment.Target == nil {
+					translated = false
+				} else {
+					targetText.WriteString(*segment.Target)
+				}
+			}
+			var text *string
+			if translated {
+				s := targetText.String()
+				text = &s
+			}
+			_translate(source, target, target, unit.ID, sourceText.String(), text)
+		}
+	}
+
+	if bundle.Locale == "" {
+		return nil, fmt.Errorf("XLIFF has no source or target language")
+	}
+	return bundle, nil
+}
+
+// Keys returns the keys of the bundle, sorted.
+func (b *Bundle) Keys() []string {
+	keys := make([]string, 0, len(b.Strings))
+	for key := range b.Strings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// JSON returns the bundle in the JSON bundle format, with flat keys in sorted order.
+func (b *Bundle) JSON() string {
+	file := struct {
+		Locale  string            `json:"locale"`
+		Strings map[string]string `json:"strings"`
+	}{b.Locale, b.Strings}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(file)
+	return buffer.String()
+}
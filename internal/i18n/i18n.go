@@ -0,0 +1,297 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+// Prefix starts every string resource reference: @string.welcome.title refers to the key welcome.title.
+const Prefix = "@string."
+
+// textProperties are the block and trigger property keys whose literal values Extract treats as
+// user-facing text.
+var textProperties = map[string]bool{
+	"text": true, "title": true, "subtitle": true, "label": true, "placeholder": true, "hint": true,
+	"message": true, "description": true, "contentDescription": true, "caption": true, "tooltip": true,
+	"helperText": true, "errorText": true, "buttonText": true,
+}
+
+// notText matches literal values that are not translatable text: URLs, colours, routes and snake_case
+// identifiers such as "primary_button".
+var notText = regexp.MustCompile(`^(\w+://\S*|#[0-9A-Fa-f]{3,8}|/\S*|[a-z0-9]+(_[a-z0-9]+)+)$`)
+
+// Reference returns the string resource key of a value that is a string resource reference.
+func Reference(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, Prefix) || !lexer.IsReference(value) {
+		return "", false
+	}
+	return value[len(Prefix):], true
+}
+
+// Usage is a string resource reference found in a frame.
+type Usage struct {
+	Key    string
+	File   string
+	Line   int
+	Column int
+}
+
+// References returns the string resource references of the frame's variables, block properties on any
+// device and trigger properties, in declaration order.
+func References(frame *model.FrameDSLModel) []Usage {
+	var usages []Usage
+	_visit(frame, func(value *string, file string, line, column int) {
+		if key, ok := Reference(*value); ok {
+			usages = append(usages, Usage{Key: key, File: file, Line: line, Column: column})
+		}
+	})
+	return usages
+}
+
+// Localize replaces the string resource references of frame with their translations in bundle.
+// References without a translation are reported and stay in place.
+func Localize(frame *model.FrameDSLModel, bundle *Bundle) []*errors.Error {
+	var errs []*errors.Error
+	reported := make(map[string]bool)
+	_visit(frame, func(value *string, file string, line, column int) {
+		key, ok := Reference(*value)
+		if !ok {
+			return
+		}
+		if text, exists := bundle.Strings[key]; exists {
+			*value = text
+			return
+		}
+		position := fmt.Sprintf("%s:%d:%d", key, line, column)
+		if !reported[position] {
+			reported[position] = true
+			err := errors.MissingTranslationError(Prefix+key, bundle.Locale, line, column, _references(bundle))
+			err.File = file
+			errs = append(errs, err)
+		}
+	})
+	return errs
+}
+
+// Source is a frame checked against bundles and the file it was loaded from.
+type Source struct {
+	File  string
+	Frame *model.FrameDSLModel
+}
+
+// Check validates the string resources of frames against bundles: every reference must have a
+// translation in every bundle, and every key of a bundle should be referenced by some frame. Missing
+// translations are errors at the reference; unused keys are warnings in the bundle file.
+func Check(frames []Source, bundles []*Bundle) []*errors.Error {
+	var errs []*errors.Error
+	used := make(map[string]bool)
+	for _, source := range frames {
+		reported := make(map[string]bool)
+		for _, usage := range References(source.Frame) {
+			used[usage.Key] = true
+			for _, bundle := range bundles {
+				if _, exists := bundle.Strings[usage.Key]; exists || reported[bundle.Locale+" "+usage.Key] {
+					continue
+				}
+				reported[bundle.Locale+" "+usage.Key] = true
+				err := errors.MissingTranslationError(Prefix+usage.Key, bundle.Locale, usage.Line, usage.Column, _references(bundle))
+				err.File = source.File
+				if usage.File != "" {
+					err.File = usage.File
+				}
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, bundle := range bundles {
+		for _, key := range bundle.Keys() {
+			if !used[key] {
+				errs = append(errs, &errors.Error{
+					Severity:   errors.SeverityWarning,
+					Message:    fmt.Sprintf("String '%s%s' in locale '%s' is not used by any frame", Prefix, key, bundle.Locale),
+					File:       bundle.File,
+					Suggestion: "Remove the unused string from the bundle",
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// Extraction is a literal string Extract moved into a bundle.
+type Extraction struct {
+	Key    string
+	Value  string
+	Line   int
+	Column int
+}
+
+// Extract moves the literal text of frame into bundle and replaces it with string resource references.
+// Text is the value of a STRING variable or of a text property such as text, title or label that holds
+// words rather than a URL, colour or identifier; block properties only qualify with one value for every
+// device. Keys are named after the frame and the variable, or the frame, block or trigger and property:
+// home.title, home.submit.text. A key that already holds a different text gets a numbered suffix.
+func Extract(frame *model.FrameDSLModel, bundle *Bundle) []Extraction {
+	var extractions []Extraction
+	_add := func(value *string, key string, line, column int) {
+		if !_isText(*value) {
+			return
+		}
+		key = bundle._key(_keyName(key), *value)
+		bundle.Strings[key] = *value
+		extractions = append(extractions, Extraction{Key: key, Value: *value, Line: line, Column: column})
+		*value = Prefix + key
+	}
+
+	walker.Walk(frame, walker.Visitor{
+		Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+			switch {
+			case node.Variable != nil:
+				if node.Variable.Type == "STRING" && node.Variable.File == "" {
+					_add(&node.Variable.Value, frame.Name+"."+node.Variable.Key, node.Variable.Line, node.Variable.Column)
+				}
+			case node.Property != nil:
+				prop := node.Property
+				if textProperties[prop.Key] && prop.ValueTablet == prop.ValueMobile && prop.ValueDesktop == prop.ValueMobile {
+					block := parents[len(parents)-1].Block
+					_add(&prop.ValueMobile, frame.Name+"."+block.Key+"."+prop.Key, prop.Line, prop.Column)
+					prop.ValueTablet, prop.ValueDesktop = prop.ValueMobile, prop.ValueMobile
+				}
+			case node.TriggerProperty != nil:
+				prop := node.TriggerProperty
+				if textProperties[prop.Key] {
+					trigger := parents[len(parents)-1].Trigger
+					_add(&prop.Value, frame.Name+"."+trigger.Name+"."+prop.Key, prop.Line, prop.Column)
+				}
+			}
+			return walker.Continue
+		},
+	})
+	return extractions
+}
+
+// _key returns key, or key with the first free numbered suffix when the bundle holds a different text
+// for it.
+func (b *Bundle) _key(key, value string) string {
+	candidate := key
+	for i := 2; ; i++ {
+		if existing, exists := b.Strings[candidate]; !exists || existing == value {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s_%d", key, i)
+	}
+}
+
+// _keyName turns a dotted path into a valid reference key, replacing characters identifiers cannot hold.
+func _keyName(path string) string {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		var b strings.Builder
+		for _, r := range segment {
+			if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				b.WriteRune(r)
+			} else {
+				b.WriteRune('_')
+			}
+		}
+		segment = b.String()
+		if segment == "" || segment[0] >= '0' && segment[0] <= '9' {
+			segment = "_" + segment
+		}
+		segments[i] = segment
+	}
+	return strings.Join(segments, ".")
+}
+
+func _isText(value string) bool {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || strings.HasPrefix(trimmed, "@") || strings.Contains(trimmed, "#SCRIPT") {
+		return false
+	}
+	if notText.MatchString(trimmed) {
+		return false
+	}
+	return strings.IndexFunc(trimmed, func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f
+	}) >= 0
+}
+
+// _visit calls visit with every value string resource references may appear in.
+func _visit(frame *model.FrameDSLModel, visit func(value *string, file string, line, column int)) {
+	walker.Walk(frame, walker.Visitor{
+		Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+			switch {
+			case node.Variable != nil:
+				visit(&node.Variable.Value, node.Variable.File, node.Variable.Line, node.Variable.Column)
+			case node.Property != nil:
+				prop := node.Property
+				for _, value := range []*string{&prop.ValueMobile, &prop.ValueTablet, &prop.ValueDesktop} {
+					visit(value, "", prop.Line, prop.Column)
+				}
+			case node.TriggerProperty != nil:
+				visit(&node.TriggerProperty.Value, "", node.TriggerProperty.Line, node.TriggerProperty.Column)
+			}
+			return walker.Continue
+		},
+	})
+}
+
+func _references(bundle *Bundle) []string {
+	references := make([]string, 0, len(bundle.Strings))
+	for key := range bundle.Strings {
+		references = append(references, Prefix+key)
+	}
+	sort.Strings(references)
+	return references
+}
+
+// LocalizeJson returns a copy of frame with the string resource references of its variables, block
+// properties and trigger properties replaced by their translations in bundle. References without a
+// translation stay in place; Localize reports them on the frame model.
+func LocalizeJson(frame model.FrameJson, bundle *Bundle) model.FrameJson {
+	_translate := func(value *string) {
+		if key, ok := Reference(*value); ok {
+			if text, exists := bundle.Strings[key]; exists {
+				*value = text
+			}
+		}
+	}
+
+	frame.Variables = append([]model.VariableJson(nil), frame.Variables...)
+	for i := range frame.Variables {
+		_translate(&frame.Variables[i].Value)
+	}
+	frame.Blocks = append([]model.BlockJson(nil), frame.Blocks...)
+	for i := range frame.Blocks {
+		block := &frame.Blocks[i]
+		block.Properties = append([]model.BlockPropertyJson(nil), block.Properties...)
+		for j := range block.Properties {
+			prop := &block.Properties[j]
+			_translate(&prop.ValueMobile)
+			_translate(&prop.ValueTablet)
+			_translate(&prop.ValueDesktop)
+		}
+	}
+	frame.Actions = append([]model.ActionJson(nil), frame.Actions...)
+	for i := range frame.Actions {
+		action := &frame.Actions[i]
+		action.Triggers = append([]model.ActionTriggerJson(nil), action.Triggers...)
+		for j := range action.Triggers {
+			trigger := &action.Triggers[j]
+			trigger.Properties = append([]model.TriggerPropertyJson(nil), trigger.Properties...)
+			for k := range trigger.Properties {
+				_translate(&trigger.Properties[k].Value)
+			}
+		}
+	}
+	return frame
+}
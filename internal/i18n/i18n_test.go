@@ -0,0 +1,189 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/parser"
+)
+
+const welcomeFrame = `frame(name = "welcome", route = "/welcome") {
+    var title: STRING = @string.welcome.title
+    block(keyType = "ROOT", key = "root")
+        .prop(text = @string.welcome.body, padding = "16")
+        .action(event = "onClick") {
+            trigger(keyType = "SHOW_MESSAGE", name = "greet")
+                .prop(message = @string.welcome.toast)
+        }
+}`
+
+func _parseFrame(t *testing.T, input string) model.FrameDSLModel {
+	t.Helper()
+	p := parser.NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Unexpected parse errors: %s", p.ErrorCollector().FormatAll())
+	}
+	return *frame
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"fr.json": `{"locale": "fr", "strings": {"welcome": {"title": "Bienvenue", "body": "Bonjour"}}}`,
+		"fr.xlf": `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2">
+  <file source-language="en" target-language="fr" datatype="plaintext" original="welcome.nbx">
+    <body>
+      <trans-unit id="welcome.title"><source>Welcome</source><target>Bienvenue</target></trans-unit>
+      <trans-unit id="welcome.body"><source>Hello</source><target>Bonjour</target></trans-unit>
+      <trans-unit id="welcome.toast"><source>Hi</source></trans-unit>
+    </body>
+  </file>
+</xliff>`,
+		"fr.xliff": `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="fr">
+  <file id="welcome">
+    <unit id="welcome.title"><segment><source>Welcome</source><target>Bienvenue</target></segment></unit>
+    <unit id="welcome.body"><segment><source>Hello</source><target>Bonjour</target></segment></unit>
+    <unit id="welcome.toast"><segment><source>Hi</source></segment></unit>
+  </file>
+</xliff>`,
+	}
+
+	for name, content := range tests {
+		bundle, err := Parse(name, content)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if bundle.Locale != "fr" || bundle.File != name {
+			t.Errorf("%s: expected locale fr from %s, got %s from %s", name, name, bundle.Locale, bundle.File)
+		}
+		if strings.Join(bundle.Keys(), ",") != "welcome.body,welcome.title" {
+			t.Errorf("%s: expected the translated keys only, got %v", name, bundle.Keys())
+		}
+		if bundle.Strings["welcome.title"] != "Bienvenue" {
+			t.Errorf("%s: expected the target text, got %q", name, bundle.Strings["welcome.title"])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"fr.po":    "unknown bundle format 'fr.po'",
+		"fr.json":  "invalid bundle JSON",
+		"en.json":  "bundle has no locale",
+		"de.json":  "string 'welcome.count' must be a string or an object of strings",
+		"fr.xliff": "XLIFF has no source or target language",
+	}
+	contents := map[string]string{
+		"fr.po":    ``,
+		"fr.json":  `{"locale": `,
+		"en.json":  `{"strings": {}}`,
+		"de.json":  `{"locale": "de", "strings": {"welcome": {"count": 1}}}`,
+		"fr.xliff": `<xliff version="2.0"><file id="f"></file></xliff>`,
+	}
+	for name, expected := range tests {
+		if _, err := Parse(name, contents[name]); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Parse(%s): expected error %q, got %v", name, expected, err)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	frame := _parseFrame(t, welcomeFrame)
+	bundle, _ := ParseJSON(`{"locale": "fr", "strings": {"welcome": {"title": "Bienvenue", "body": "Bonjour", "toast": "Salut"}}}`)
+
+	if errs := Localize(&frame, bundle); len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %s", errs[0].Message)
+	}
+	prop := frame.Blocks[0].Properties[0]
+	if frame.Variables[0].Value != "Bienvenue" || prop.ValueMobile != "Bonjour" || prop.ValueDesktop != "Bonjour" {
+		t.Errorf("Expected the variable and property to be translated, got %q and %+v", frame.Variables[0].Value, prop)
+	}
+	if frame.Blocks[0].Actions[0].Triggers[0].Properties[0].Value != "Salut" {
+		t.Errorf("Expected the trigger property to be translated")
+	}
+
+	frame = _parseFrame(t, welcomeFrame)
+	partial, _ := ParseJSON(`{"locale": "de", "strings": {"welcome": {"titel": "Willkommen", "body": "Hallo", "toast": "Hi"}}}`)
+	errs := Localize(&frame, partial)
+	if len(errs) != 1 || errs[0].Message != "Missing translation for '@string.welcome.title' in locale 'de'" {
+		t.Fatalf("Expected one missing translation, got %v", errs)
+	}
+	if errs[0].Suggestion != "Did you mean '@string.welcome.titel'?" || errs[0].Line != 2 {
+		t.Errorf("Unexpected error: line %d, %s", errs[0].Line, errs[0].Suggestion)
+	}
+	if frame.Variables[0].Value != "@string.welcome.title" {
+		t.Errorf("Expected the missing reference to stay in place")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	frame := _parseFrame(t, welcomeFrame)
+	en, _ := ParseJSON(`{"locale": "en", "strings": {"welcome": {"title": "Welcome", "body": "Hello", "toast": "Hi", "old": "Old"}}}`)
+	fr, _ := ParseJSON(`{"locale": "fr", "strings": {"welcome": {"title": "Bienvenue", "body": "Bonjour"}}}`)
+	en.File, fr.File = "i18n/en.json", "i18n/fr.json"
+
+	var messages []string
+	for _, err := range Check([]Source{{File: "welcome.nbx", Frame: &frame}}, []*Bundle{en, fr}) {
+		messages = append(messages, err.Severity.String()+": "+err.File+": "+err.Message)
+	}
+	expected := []string{
+		"Error: welcome.nbx: Missing translation for '@string.welcome.toast' in locale 'fr'",
+		"Warning: i18n/en.json: String '@string.welcome.old' in locale 'en' is not used by any frame",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(messages, "\n"))
+	}
+}
+
+func TestExtract(t *testing.T) {
+	frame := _parseFrame(t, `frame(name = "sign-in", route = "/sign-in") {
+    var title: STRING = "Sign in"
+    var mode: STRING = "email_only"
+    var count: INT = 3
+    block(keyType = "ROOT", key = "root")
+        .prop(title = "Sign in", text = (mobile = "Hi", desktop = "Hello"), color = "Blue")
+        .slot("content") {
+            block(keyType = "TEXT", key = "footer")
+                .prop(text = "Terms apply", link = "https://example.com")
+                .action(event = "onClick") {
+                    trigger(keyType = "SHOW_MESSAGE", name = "notice")
+                        .prop(message = "Terms apply", url = "/terms")
+                }
+            block(keyType = "TEXT", key = "sign-in")
+                .prop(title = "Continue")
+        }
+}`)
+
+	bundle := NewBundle("en")
+	bundle.Strings["sign_in.root.title"] = "Log in"
+	extractions := Extract(&frame, bundle)
+
+	var keys []string
+	for _, extraction := range extractions {
+		keys = append(keys, extraction.Key+"="+extraction.Value)
+	}
+	expected := []string{
+		"sign_in.title=Sign in",
+		"sign_in.root.title_2=Sign in",
+		"sign_in.footer.text=Terms apply",
+		"sign_in.notice.message=Terms apply",
+		"sign_in.sign_in.title=Continue",
+	}
+	if strings.Join(keys, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected extractions:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(keys, "\n"))
+	}
+
+	if frame.Variables[0].Value != "@string.sign_in.title" || frame.Variables[1].Value != "email_only" {
+		t.Errorf("Expected only text variables to be replaced, got %q and %q", frame.Variables[0].Value, frame.Variables[1].Value)
+	}
+	root := frame.Blocks[0]
+	if root.Properties[0].ValueDesktop != "@string.sign_in.root.title_2" || root.Properties[1].ValueMobile != "Hi" {
+		t.Errorf("Expected per-device values to stay in place, got %+v", root.Properties[:2])
+	}
+	if len(bundle.Strings) != 6 {
+		t.Errorf("Expected the bundle to hold the extracted strings, got %v", bundle.Keys())
+	}
+}
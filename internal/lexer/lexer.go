@@ -1,5 +1,7 @@
 package lexer
 
+import "strings"
+
 type TokenType int
 
 const (
@@ -15,15 +17,23 @@ const (
 	TOKEN_FLOAT   // 123.456
 	TOKEN_DOUBLE  // 123.456789 (higher precision)
 
+	TOKEN_REFERENCE  // @color.primary
+	TOKEN_COLOR      // #2563EB
+	TOKEN_EXPRESSION // $(count > 0)
+
 	// Operators and delimiters
-	TOKEN_ASSIGN // =
-	TOKEN_COLON  // :
-	TOKEN_COMMA  // ,
-	TOKEN_DOT    // .
-	TOKEN_LPAREN // (
-	TOKEN_RPAREN // )
-	TOKEN_LBRACE // {
-	TOKEN_RBRACE // }
+	TOKEN_ASSIGN   // =
+	TOKEN_COLON    // :
+	TOKEN_COMMA    // ,
+	TOKEN_DOT      // .
+	TOKEN_LPAREN   // (
+	TOKEN_RPAREN   // )
+	TOKEN_LBRACE   // {
+	TOKEN_RBRACE   // }
+	TOKEN_LBRACKET // [
+	TOKEN_RBRACKET // ]
+	TOKEN_LT       // <
+	TOKEN_GT       // >
 
 	// Keywords
 	TOKEN_KEYWORD // keywords: frame, var, slot, trigger, etc.
@@ -77,14 +87,31 @@ func (l *Lexer) NextToken() Token {
 		return l._newToken(TOKEN_LBRACE, string(l.ch))
 	case '}':
 		return l._newToken(TOKEN_RBRACE, string(l.ch))
+	case '[':
+		return l._newToken(TOKEN_LBRACKET, string(l.ch))
+	case ']':
+		return l._newToken(TOKEN_RBRACKET, string(l.ch))
+	case '<':
+		return l._newToken(TOKEN_LT, string(l.ch))
+	case '>':
+		return l._newToken(TOKEN_GT, string(l.ch))
 	case '"':
 		return l._readString()
 	case 0:
 		return l._newToken(TOKEN_EOF, "")
 	default:
 		if _isLetter(l.ch) {
+			start := l.position
 			literal := l._readIdentifier()
 			tokenType := _lookupKeyword(literal)
+			if tokenType == TOKEN_IDENT {
+				// A dot followed by a letter continues a field path such as item.name.
+				for l.ch == '.' && _isLetter(l._peekChar()) {
+					l._readChar()
+					l._readIdentifier()
+				}
+				literal = l.input[start:l.position]
+			}
 			return Token{
 				Type:    tokenType,
 				Literal: literal,
@@ -93,6 +120,12 @@ func (l *Lexer) NextToken() Token {
 			}
 		} else if _isDigit(l.ch) || l.ch == '.' {
 			return l._readNumber()
+		} else if l.ch == '@' && _isLetter(l._peekChar()) && !l._followsIdentifier() {
+			return l._readReference()
+		} else if l.ch == '#' && _isHexDigit(l._peekChar()) {
+			return l._readColor()
+		} else if l.ch == '$' && l._peekChar() == '(' {
+			return l._readExpression()
 		}
 		tok := Token{
 			Type:    TOKEN_ILLEGAL,
@@ -169,6 +202,158 @@ func (l *Lexer) _readIdentifier() string {
 	return l.input[start:l.position]
 }
 
+// _readReference reads a reference such as @color.primary: '@' followed by dot-separated identifiers.
+func (l *Lexer) _readReference() Token {
+	startLine, startCol := l.line, l.column
+	start := l.position
+	l._readChar() // skip '@'
+	l._readIdentifier()
+	for l.ch == '.' && _isLetter(l._peekChar()) {
+		l._readChar()
+		l._readIdentifier()
+	}
+	return Token{
+		Type:    TOKEN_REFERENCE,
+		Literal: l.input[start:l.position],
+		Line:    startLine,
+		Column:  startCol,
+	}
+}
+
+// _readColor reads a color literal such as #2563EB: '#' followed by letters and digits. The digits are
+// checked by the COLOR type, not by the lexer.
+func (l *Lexer) _readColor() Token {
+	startLine, startCol := l.line, l.column
+	start := l.position
+	l._readChar() // skip '#'
+	for _isLetter(l.ch) || _isDigit(l.ch) {
+		l._readChar()
+	}
+	return Token{
+		Type:    TOKEN_COLOR,
+		Literal: l.input[start:l.position],
+		Line:    startLine,
+		Column:  startCol,
+	}
+}
+
+// RestOfLine returns the source text of line after column, without a trailing comment. The parser reads
+// the expression of a computed variable with it, since expressions are not made of DSL tokens.
+func (l *Lexer) RestOfLine(line, column int) string {
+	lines := strings.Split(l.input, "\n")
+	if line < 1 || line > len(lines) || column > len(lines[line-1]) {
+		return ""
+	}
+	text := lines[line-1][column:]
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			if text[i] == quote {
+				quote = 0
+			}
+		case text[i] == '"' || text[i] == '\'':
+			quote = text[i]
+		case strings.HasPrefix(text[i:], "//"):
+			text = text[:i]
+		}
+	}
+	return strings.TrimSpace(text)
+}
+
+// Enclosed returns the raw text between the '(' at line and column and its matching ')', which may be on a
+// later line, with the position of that ')'. Parentheses inside quoted strings do not count. The returned
+// line is 0 when the '(' is not closed.
+func (l *Lexer) Enclosed(line, column int) (string, int, int) {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(l.input[offset:], '\n')
+		if next == -1 {
+			return "", 0, 0
+		}
+		offset += next + 1
+	}
+	start := offset + column - 1
+	if start < 0 || start >= len(l.input) || l.input[start] != '(' {
+		return "", 0, 0
+	}
+
+	depth := 0
+	var quote byte
+	for i := start; i < len(l.input); i++ {
+		ch := l.input[i]
+		if i > start {
+			if ch == '\n' {
+				line, column = line+1, 0
+			} else {
+				column++
+			}
+		}
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 {
+				return l.input[start+1 : i], line, column
+			}
+		}
+	}
+	return "", 0, 0
+}
+
+// _readExpression reads an expression such as $(count > 0 && !loading) up to its matching ')'. Parentheses
+// inside quoted strings do not count. The expression itself is parsed by the expr package.
+func (l *Lexer) _readExpression() Token {
+	startLine, startCol := l.line, l.column
+	start := l.position
+	l._readChar() // skip '$'
+	depth := 0
+	for l.ch != 0 {
+		switch l.ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '"', '\'':
+			quote := l.ch
+			l._readChar()
+			for l.ch != quote && l.ch != 0 {
+				l._readChar()
+			}
+		}
+		if l.ch == 0 {
+			break
+		}
+		l._readChar()
+		if depth == 0 {
+			break
+		}
+	}
+	return Token{
+		Type:    TOKEN_EXPRESSION,
+		Literal: l.input[start:l.position],
+		Line:    startLine,
+		Column:  startCol,
+	}
+}
+
+// _followsIdentifier reports whether the current character directly follows an identifier character, as
+// the '@' in invalid@name does.
+func (l *Lexer) _followsIdentifier() bool {
+	if l.position == 0 {
+		return false
+	}
+	prev := l.input[l.position-1]
+	return _isLetter(prev) || _isDigit(prev)
+}
+
 func (l *Lexer) _readString() Token {
 	startLine, startCol := l.line, l.column
 	l._readChar() // skip initial quote
@@ -254,3 +439,30 @@ func _isLetter(ch byte) bool {
 func _isDigit(ch byte) bool {
 	return ch >= '0' && ch <= '9'
 }
+
+func _isHexDigit(ch byte) bool {
+	return _isDigit(ch) || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+// IsReference reports whether value is a reference such as @color.primary or @string.welcome: '@'
+// followed by two or more dot-separated identifiers.
+func IsReference(value string) bool {
+	if len(value) < 2 || value[0] != '@' {
+		return false
+	}
+	segments := strings.Split(value[1:], ".")
+	if len(segments) < 2 {
+		return false
+	}
+	for _, segment := range segments {
+		if segment == "" || !_isLetter(segment[0]) {
+			return false
+		}
+		for i := 1; i < len(segment); i++ {
+			if !_isLetter(segment[i]) && !_isDigit(segment[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -341,6 +341,53 @@ frame(name = "numbers") {
 	}
 }
 
+func TestLexer_References(t *testing.T) {
+	input := `.prop(color = @color.primary, size = (mobile = @typography.body.size), at = @ 1)`
+
+	l := NewLexer(input)
+	var references []string
+	illegal := 0
+	for {
+		tok := l.NextToken()
+		if tok.Type == TOKEN_EOF {
+			break
+		}
+		if tok.Type == TOKEN_REFERENCE {
+			references = append(references, tok.Literal)
+		}
+		if tok.Type == TOKEN_ILLEGAL {
+			illegal++
+		}
+	}
+
+	if len(references) != 2 || references[0] != "@color.primary" || references[1] != "@typography.body.size" {
+		t.Errorf("Expected two references, got %v", references)
+	}
+	if illegal != 1 {
+		t.Errorf("Expected a lone '@' to be illegal, got %d illegal tokens", illegal)
+	}
+}
+
+func TestLexer_CollectionsAndColors(t *testing.T) {
+	input := `var tags: MAP<STRING, LIST<INT>> = {"a": [1]} #2563EB #`
+	expected := []TokenType{
+		TOKEN_KEYWORD, TOKEN_IDENT, TOKEN_COLON, TOKEN_IDENT, TOKEN_LT, TOKEN_IDENT, TOKEN_COMMA, TOKEN_IDENT,
+		TOKEN_LT, TOKEN_IDENT, TOKEN_GT, TOKEN_GT, TOKEN_ASSIGN, TOKEN_LBRACE, TOKEN_STRING, TOKEN_COLON,
+		TOKEN_LBRACKET, TOKEN_INT, TOKEN_RBRACKET, TOKEN_RBRACE, TOKEN_COLOR, TOKEN_ILLEGAL, TOKEN_EOF,
+	}
+
+	l := NewLexer(input)
+	for i, tokenType := range expected {
+		tok := l.NextToken()
+		if tok.Type != tokenType {
+			t.Fatalf("Token %d (%q): expected type %d, got %d", i, tok.Literal, tokenType, tok.Type)
+		}
+		if tok.Type == TOKEN_COLOR && tok.Literal != "#2563EB" {
+			t.Errorf("Expected the color literal, got %q", tok.Literal)
+		}
+	}
+}
+
 func TestLexer_NestedStructures(t *testing.T) {
 	input := `
 frame(name = "nested") {
@@ -385,3 +432,102 @@ frame(name = "nested") {
 		t.Fatalf("Expected deeply nested structure with depth >= 5, got %d", maxDepth)
 	}
 }
+
+func TestLexer_Expression(t *testing.T) {
+	input := `visibility = $(len(items) > 0 && title != ")") text = $(a`
+	expected := []struct {
+		tokenType TokenType
+		literal   string
+	}{
+		{TOKEN_IDENT, "visibility"},
+		{TOKEN_ASSIGN, "="},
+		{TOKEN_EXPRESSION, `$(len(items) > 0 && title != ")")`},
+		{TOKEN_IDENT, "text"},
+		{TOKEN_ASSIGN, "="},
+		{TOKEN_EXPRESSION, "$(a"},
+		{TOKEN_EOF, ""},
+	}
+
+	l := NewLexer(input)
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != want.tokenType || tok.Literal != want.literal {
+			t.Fatalf("Token %d: expected %d %q, got %d %q", i, want.tokenType, want.literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestLexer_FieldPath(t *testing.T) {
+	input := `.repeat(items = products, as = product) .data(text = product.name)`
+	expected := []struct {
+		tokenType TokenType
+		literal   string
+	}{
+		{TOKEN_DOT, "."},
+		{TOKEN_IDENT, "repeat"},
+		{TOKEN_LPAREN, "("},
+		{TOKEN_IDENT, "items"},
+		{TOKEN_ASSIGN, "="},
+		{TOKEN_IDENT, "products"},
+		{TOKEN_COMMA, ","},
+		{TOKEN_IDENT, "as"},
+		{TOKEN_ASSIGN, "="},
+		{TOKEN_IDENT, "product"},
+		{TOKEN_RPAREN, ")"},
+		{TOKEN_DOT, "."},
+		{TOKEN_KEYWORD, "data"},
+		{TOKEN_LPAREN, "("},
+		{TOKEN_IDENT, "text"},
+		{TOKEN_ASSIGN, "="},
+		{TOKEN_IDENT, "product.name"},
+		{TOKEN_RPAREN, ")"},
+		{TOKEN_EOF, ""},
+	}
+
+	l := NewLexer(input)
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != want.tokenType || tok.Literal != want.literal {
+			t.Fatalf("Token %d: expected %d %q, got %d %q", i, want.tokenType, want.literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestLexer_Enclosed(t *testing.T) {
+	input := "if (count > (1) && label != \")\"\n    || done) {"
+	l := NewLexer(input)
+	l.NextToken() // if
+	open := l.NextToken()
+	text, line, column := l.Enclosed(open.Line, open.Column)
+	if text != "count > (1) && label != \")\"\n    || done" {
+		t.Fatalf("Unexpected text %q", text)
+	}
+
+	var closing Token
+	for tok := l.NextToken(); tok.Type != TOKEN_EOF; tok = l.NextToken() {
+		if tok.Type == TOKEN_RPAREN {
+			closing = tok
+		}
+	}
+	if line != 2 || closing.Line != line || closing.Column != column {
+		t.Errorf("Expected the last ')' at %d:%d, got %d:%d", closing.Line, closing.Column, line, column)
+	}
+
+	if _, line, _ := l.Enclosed(1, 4); line == 0 {
+		t.Errorf("Expected '(' at 1:4")
+	}
+	if _, line, _ := NewLexer("when(a").Enclosed(1, 5); line != 0 {
+		t.Errorf("Expected an unclosed condition")
+	}
+	if _, line, _ := NewLexer("when(a)").Enclosed(1, 1); line != 0 {
+		t.Errorf("Expected no '(' at 1:1")
+	}
+}
+
+func TestLexer_RepeatIsNotReserved(t *testing.T) {
+	l := NewLexer(`var repeat: STRING = ""`)
+	l.NextToken()
+	if tok := l.NextToken(); tok.Type != TOKEN_IDENT || tok.Literal != "repeat" {
+		t.Errorf("Expected 'repeat' to lex as an identifier, got %d %q", tok.Type, tok.Literal)
+	}
+}
@@ -0,0 +1,204 @@
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/detector"
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/parser"
+)
+
+// Load parses the frame at name in fsys together with the libraries it imports, directly or through
+// other libraries. Import paths are resolved relative to the importing file; a leading "/" resolves
+// from the root of fsys. Each library is loaded once. Imported constants, enums, variables, styles and components
+// are placed before the frame's own declarations, in import order, and keep their file in File. The
+// frame keeps its imports, and the formatters leave out the imported declarations, so a loaded frame
+// formats back to its source.
+//
+// The returned frame is not validated. sources maps every loaded file to its content, and errors found
+// in a file name it in Error.File.
+func Load(fsys fs.FS, name string) (frame model.FrameDSLModel, sources map[string]string, errs []*errors.Error) {
+	l := &_loader{
+		fsys:    fsys,
+		sources: make(map[string]string),
+		loaded:  map[string]bool{name: true},
+		stack:   []string{name},
+	}
+
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return model.FrameDSLModel{}, l.sources, []*errors.Error{{
+			Severity: errors.SeverityError,
+			Message:  fmt.Sprintf("Cannot read frame: %v", err),
+			File:     name,
+		}}
+	}
+	l.sources[name] = string(content)
+
+	parsed, parseErrs := _parseFrame(string(content))
+	if len(parseErrs) > 0 {
+		return model.FrameDSLModel{}, l.sources, _inFile(name, parseErrs)
+	}
+
+	l._import(name, parsed.Imports)
+
+	parsed.Constants = append(l.constants, parsed.Constants...)
+	parsed.Enums = append(l.enums, parsed.Enums...)
+	parsed.Variables = append(l.variables, parsed.Variables...)
+	parsed.Styles = append(l.styles, parsed.Styles...)
+	parsed.Components = append(l.components, parsed.Components...)
+
+	return parsed, l.sources, l.errs
+}
+
+type _loader struct {
+	fsys    fs.FS
+	sources map[string]string
+	loaded  map[string]bool
+	// stack holds the files being imported, to report import cycles.
+	stack []string
+
+	constants  []model.ConstantDSLModel
+	enums      []model.EnumDSLModel
+	variables  []model.VariableDSLModel
+	styles     []model.StyleDSLModel
+	components []model.ComponentDSLModel
+	errs       []*errors.Error
+}
+
+// _import loads the libraries imported by the file from, dependencies first.
+func (l *_loader) _import(from string, imports []model.ImportDSLModel) {
+	for _, imp := range imports {
+		target, ok := _resolve(from, imp.Path)
+		if !ok {
+			l._fail(from, imp, "Invalid import path '%s'", imp.Path)
+			continue
+		}
+		if slices.Contains(l.stack, target) {
+			l._fail(from, imp, "Import cycle: %s -> %s", strings.Join(l.stack, " -> "), target)
+			continue
+		}
+		if l.loaded[target] {
+			continue
+		}
+		l.loaded[target] = true
+
+		content, err := fs.ReadFile(l.fsys, target)
+		if err != nil {
+			l._fail(from, imp, "Cannot import '%s': %v", imp.Path, err)
+			continue
+		}
+		l.sources[target] = string(content)
+
+		library, parseErrs := _parseLibrary(string(content))
+		if len(parseErrs) > 0 {
+			l.errs = append(l.errs, _inFile(target, parseErrs)...)
+			continue
+		}
+
+		l.stack = append(l.stack, target)
+		l._import(target, library.Imports)
+		l.stack = l.stack[:len(l.stack)-1]
+
+		for _, constant := range library.Constants {
+			constant.File = target
+			l.constants = append(l.constants, constant)
+		}
+		for _, enum := range library.Enums {
+			enum.File = target
+			l.enums = append(l.enums, enum)
+		}
+		for _, variable := range library.Variables {
+			variable.File = target
+			l.variables = append(l.variables, variable)
+		}
+		for _, style := range library.Styles {
+			style.File = target
+			l.styles = append(l.styles, style)
+		}
+		for _, component := range library.Components {
+			component.File = target
+			l.components = append(l.components, component)
+		}
+	}
+}
+
+func (l *_loader) _fail(file string, imp model.ImportDSLModel, format string, args ...any) {
+	l.errs = append(l.errs, &errors.Error{
+		Severity: errors.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     imp.Line,
+		Column:   imp.Column,
+	})
+}
+
+// _resolve returns the path of an import in the file system.
+func _resolve(from, importPath string) (string, bool) {
+	var target string
+	if strings.HasPrefix(importPath, "/") {
+		target = path.Clean(strings.TrimPrefix(importPath, "/"))
+	} else {
+		target = path.Join(path.Dir(from), importPath)
+	}
+	return target, fs.ValidPath(target)
+}
+
+func _parseFrame(content string) (model.FrameDSLModel, []*errors.Error) {
+	switch detector.DetectFormat(content) {
+	case detector.FormatXML:
+		frame, errs := parser.ParseXML(content)
+		if _hasErrors(errs) {
+			return model.FrameDSLModel{}, errs
+		}
+		return frame, nil
+	case detector.FormatDSL:
+		p := parser.NewParser(lexer.NewLexer(content), content)
+		frame := p.ParseNBX()
+		if frame == nil || p.ErrorCollector().HasErrors() {
+			return model.FrameDSLModel{}, p.ErrorCollector().Errors()
+		}
+		return *frame, nil
+	default:
+		return model.FrameDSLModel{}, []*errors.Error{{
+			Severity: errors.SeverityError,
+			Message:  "Unable to detect format. Content must start with 'frame(' for DSL or '<frame' for XML",
+		}}
+	}
+}
+
+func _parseLibrary(content string) (model.LibraryDSLModel, []*errors.Error) {
+	switch detector.DetectFormat(content) {
+	case detector.FormatXML:
+		return parser.ParseXMLLibrary(content)
+	case detector.FormatDSL:
+		p := parser.NewParser(lexer.NewLexer(content), content)
+		library := p.ParseLibrary()
+		if library == nil || p.ErrorCollector().HasErrors() {
+			return model.LibraryDSLModel{}, p.ErrorCollector().Errors()
+		}
+		return *library, nil
+	default:
+		return model.LibraryDSLModel{}, []*errors.Error{{
+			Severity: errors.SeverityError,
+			Message:  "Unable to detect format. A library must start with 'library {' for DSL or '<library' for XML",
+		}}
+	}
+}
+
+func _hasErrors(errs []*errors.Error) bool {
+	return slices.ContainsFunc(errs, func(err *errors.Error) bool { return err.Severity == errors.SeverityError })
+}
+
+func _inFile(file string, errs []*errors.Error) []*errors.Error {
+	for _, err := range errs {
+		err.File = file
+	}
+	return errs
+}
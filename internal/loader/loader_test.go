@@ -0,0 +1,210 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nativeblocks/nbx/internal/compiler"
+	"github.com/nativeblocks/nbx/internal/validator"
+)
+
+var libraryFS = fstest.MapFS{
+	"frames/home.nbx": {Data: []byte(`frame(name = "home", route = "/home") {
+    import "../shared/theme.nbx"
+    import "../shared/colors.nbx"
+
+    var title: STRING = "Home"
+
+    block(keyType = "ROOT", key = "root")
+        .slot("content") {
+            block(component = "header", key = "top")
+                .prop(text = "{const:greeting}")
+                .data(label = title)
+        }
+}`)},
+	"shared/theme.nbx": {Data: []byte(`library {
+    import "colors.nbx"
+
+    const greeting: STRING = "Welcome"
+    var theme: STRING = "light"
+
+    component(name = "header") {
+        prop text: STRING
+        data label
+
+        block(keyType = "nativeblocks/text", key = "header")
+            .prop(text = "{prop:text}", color = "{const:primary}")
+            .data(text = label)
+    }
+}`)},
+	"shared/colors.xml": {Data: []byte(`<library>
+  <const key="primary" type="STRING" value="#0A84FF" />
+</library>`)},
+	"shared/colors.nbx": {Data: []byte(`library {
+    const primary: STRING = "#0A84FF"
+}`)},
+}
+
+func TestLoad(t *testing.T) {
+	frame, sources, errs := Load(libraryFS, "frames/home.nbx")
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %s", errs[0].Format())
+	}
+	if len(sources) != 3 {
+		t.Errorf("Expected the frame and two libraries to be loaded once, got %d sources", len(sources))
+	}
+	if len(frame.Imports) != 2 {
+		t.Errorf("Expected the frame to keep its imports, got %+v", frame.Imports)
+	}
+
+	if len(frame.Constants) != 2 || frame.Constants[0].Key != "primary" || frame.Constants[0].File != "shared/colors.nbx" {
+		t.Errorf("Expected dependencies first, got %+v", frame.Constants)
+	}
+	if len(frame.Variables) != 2 || frame.Variables[0].File != "shared/theme.nbx" || frame.Variables[1].File != "" {
+		t.Errorf("Expected imported variables before the frame's own, got %+v", frame.Variables)
+	}
+	if len(frame.Components) != 1 || frame.Components[0].File != "shared/theme.nbx" {
+		t.Fatalf("Expected the imported component, got %+v", frame.Components)
+	}
+
+	if errs := compiler.ResolveConstants(&frame); len(errs) > 0 {
+		t.Fatalf("Unexpected constant errors: %s", errs[0].Message)
+	}
+	if _, errs := compiler.ExpandComponents(&frame); len(errs) > 0 {
+		t.Fatalf("Unexpected expansion errors: %s", errs[0].Message)
+	}
+	header := frame.Blocks[0].Blocks[0]
+	if header.Properties[0].ValueMobile != "Welcome" || header.Properties[1].ValueMobile != "#0A84FF" {
+		t.Errorf("Expected constants to be resolved, got %+v", header.Properties)
+	}
+
+	collector, _ := validator.Validate(&frame)
+	if collector.HasErrors() || collector.HasWarnings() {
+		t.Errorf("Expected imported unused variables to pass silently:\n%s", collector.FormatAll())
+	}
+}
+
+func TestLoadXMLLibrary(t *testing.T) {
+	fsys := fstest.MapFS{
+		"home.xml": {Data: []byte(`<frame name="home" route="/home">
+  <import src="shared/colors.xml" />
+</frame>`)},
+		"shared/colors.xml": libraryFS["shared/colors.xml"],
+	}
+
+	frame, _, errs := Load(fsys, "home.xml")
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %s", errs[0].Format())
+	}
+	if len(frame.Constants) != 1 || frame.Constants[0].Value != "#0A84FF" {
+		t.Errorf("Expected the XML library constant, got %+v", frame.Constants)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		file     string
+		expected string
+	}{
+		{
+			name: "cycle",
+			files: fstest.MapFS{
+				"home.nbx": {Data: []byte(`frame(name = "home", route = "/home") { import "a.nbx" }`)},
+				"a.nbx":    {Data: []byte(`library { import "b.nbx" }`)},
+				"b.nbx":    {Data: []byte(`library { import "a.nbx" }`)},
+			},
+			file:     "b.nbx",
+			expected: "Import cycle: home.nbx -> a.nbx -> b.nbx -> a.nbx",
+		},
+		{
+			name: "missing file",
+			files: fstest.MapFS{
+				"home.nbx": {Data: []byte(`frame(name = "home", route = "/home") { import "missing.nbx" }`)},
+			},
+			file:     "home.nbx",
+			expected: "Cannot import 'missing.nbx'",
+		},
+		{
+			name: "outside the file system",
+			files: fstest.MapFS{
+				"home.nbx": {Data: []byte(`frame(name = "home", route = "/home") { import "../up.nbx" }`)},
+			},
+			file:     "home.nbx",
+			expected: "Invalid import path '../up.nbx'",
+		},
+		{
+			name: "library syntax error",
+			files: fstest.MapFS{
+				"home.nbx": {Data: []byte(`frame(name = "home", route = "/home") { import "lib.nbx" }`)},
+				"lib.nbx":  {Data: []byte("library {\n    block(keyType = \"ROOT\", key = \"root\")\n}")},
+			},
+			file:     "lib.nbx",
+			expected: "Unexpected token 'block' in library body",
+		},
+		{
+			name: "frame imported as library",
+			files: fstest.MapFS{
+				"home.nbx":  {Data: []byte(`frame(name = "home", route = "/home") { import "other.nbx" }`)},
+				"other.nbx": {Data: []byte(`frame(name = "other", route = "/other") {}`)},
+			},
+			file:     "other.nbx",
+			expected: "Library must start with a library declaration",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, errs := Load(tt.files, "home.nbx")
+			if len(errs) == 0 {
+				t.Fatalf("Expected an error containing %q", tt.expected)
+			}
+			if !strings.Contains(errs[0].Message, tt.expected) || errs[0].File != tt.file {
+				t.Errorf("Expected %q in %s, got %q in %s", tt.expected, tt.file, errs[0].Message, errs[0].File)
+			}
+		})
+	}
+}
+
+func TestImportedComponentErrorsCiteLibrary(t *testing.T) {
+	fsys := fstest.MapFS{
+		"home.nbx": {Data: []byte(`frame(name = "home", route = "/home") {
+    import "lib.nbx"
+
+    block(keyType = "ROOT", key = "root")
+        .slot("content") {
+            block(component = "badge", key = "badge")
+        }
+}`)},
+		"lib.nbx": {Data: []byte(`library {
+    component(name = "badge") {
+        block(keyType = "nativeblocks/text", key = "badge")
+            .data(text = missing)
+    }
+}`)},
+	}
+
+	frame, _, errs := Load(fsys, "home.nbx")
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %s", errs[0].Format())
+	}
+	expansions, errs := compiler.ExpandComponents(&frame)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected expansion errors: %s", errs[0].Message)
+	}
+	collector, _ := validator.Validate(&frame)
+	compiler.AnnotateExpansions(collector.Errors(), expansions)
+
+	if !collector.HasErrors() {
+		t.Fatal("Expected an undefined variable error")
+	}
+	err := collector.Errors()[0]
+	if err.File != "lib.nbx" || err.Line != 4 {
+		t.Errorf("Expected the error in lib.nbx line 4, got %s line %d", err.File, err.Line)
+	}
+	if !strings.Contains(strings.Join(err.RelatedInfo, "\n"), "instantiated as 'badge' at line 6") {
+		t.Errorf("Expected a note for the use site, got %v", err.RelatedInfo)
+	}
+}
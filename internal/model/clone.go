@@ -0,0 +1,105 @@
+package model
+
+import "slices"
+
+// CloneFrame deep-copies a frame so it can be edited without affecting the original.
+func CloneFrame(frame FrameDSLModel) FrameDSLModel {
+	frame.Imports = slices.Clone(frame.Imports)
+	frame.Constants = slices.Clone(frame.Constants)
+	if frame.Enums != nil {
+		enums := make([]EnumDSLModel, len(frame.Enums))
+		for i, enum := range frame.Enums {
+			enum.Members = slices.Clone(enum.Members)
+			enums[i] = enum
+		}
+		frame.Enums = enums
+	}
+	frame.Variables = slices.Clone(frame.Variables)
+	frame.Actions = _cloneActions(frame.Actions)
+	if frame.Sequences != nil {
+		sequences := make([]SequenceDSLModel, len(frame.Sequences))
+		for i, sequence := range frame.Sequences {
+			sequence.Triggers = _cloneTriggers(sequence.Triggers)
+			sequences[i] = sequence
+		}
+		frame.Sequences = sequences
+	}
+	if frame.Styles != nil {
+		styles := make([]StyleDSLModel, len(frame.Styles))
+		for i, style := range frame.Styles {
+			style.Styles = slices.Clone(style.Styles)
+			style.Properties = slices.Clone(style.Properties)
+			styles[i] = style
+		}
+		frame.Styles = styles
+	}
+	if frame.Components != nil {
+		components := make([]ComponentDSLModel, len(frame.Components))
+		for i, component := range frame.Components {
+			component.Properties = slices.Clone(component.Properties)
+			component.Data = slices.Clone(component.Data)
+			component.Block = CloneBlock(component.Block)
+			components[i] = component
+		}
+		frame.Components = components
+	}
+	frame.Blocks = _cloneBlocks(frame.Blocks)
+	return frame
+}
+
+// CloneBlock deep-copies a block with its subtree.
+func CloneBlock(block BlockDSLModel) BlockDSLModel {
+	return _cloneBlocks([]BlockDSLModel{block})[0]
+}
+
+func _cloneBlocks(blocks []BlockDSLModel) []BlockDSLModel {
+	if blocks == nil {
+		return nil
+	}
+	cloned := make([]BlockDSLModel, len(blocks))
+	for i, block := range blocks {
+		if block.Repeat != nil {
+			repeat := *block.Repeat
+			block.Repeat = &repeat
+		}
+		block.Styles = slices.Clone(block.Styles)
+		block.Data = slices.Clone(block.Data)
+		block.Properties = slices.Clone(block.Properties)
+		block.Slots = slices.Clone(block.Slots)
+		block.Blocks = _cloneBlocks(block.Blocks)
+		block.Actions = _cloneActions(block.Actions)
+		cloned[i] = block
+	}
+	return cloned
+}
+
+func _cloneActions(actions []ActionDSLModel) []ActionDSLModel {
+	if actions == nil {
+		return nil
+	}
+	cloned := make([]ActionDSLModel, len(actions))
+	for i, action := range actions {
+		action.Triggers = _cloneTriggers(action.Triggers)
+		cloned[i] = action
+	}
+	return cloned
+}
+
+// CloneTriggers deep-copies triggers with their nested triggers.
+func CloneTriggers(triggers []ActionTriggerDSLModel) []ActionTriggerDSLModel {
+	return _cloneTriggers(triggers)
+}
+
+func _cloneTriggers(triggers []ActionTriggerDSLModel) []ActionTriggerDSLModel {
+	if triggers == nil {
+		return nil
+	}
+	cloned := make([]ActionTriggerDSLModel, len(triggers))
+	for i, trigger := range triggers {
+		trigger.Properties = slices.Clone(trigger.Properties)
+		trigger.Data = slices.Clone(trigger.Data)
+		trigger.Triggers = _cloneTriggers(trigger.Triggers)
+		cloned[i] = trigger
+	}
+	return cloned
+}
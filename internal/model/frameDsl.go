@@ -4,33 +4,106 @@ type FrameDSLModel struct {
 	Name      string             `json:"name"`
 	Route     string             `json:"route"`
 	Type      string             `json:"type"`
+	Starter   bool               `json:"starter,omitempty"`
+	Imports   []ImportDSLModel   `json:"imports,omitempty"`
+	Constants []ConstantDSLModel `json:"constants,omitempty"`
+	Enums     []EnumDSLModel     `json:"enums,omitempty"`
 	Variables []VariableDSLModel `json:"variables"`
-	Blocks    []BlockDSLModel    `json:"blocks"`
-	Line      int                `json:"-"`
-	Column    int                `json:"-"`
+	// Actions respond to frame lifecycle events such as onLoad, rather than to events of a block.
+	Actions []ActionDSLModel `json:"actions,omitempty"`
+	// Sequences are named trigger lists that actions run with trigger(sequence = "name").
+	Sequences []SequenceDSLModel `json:"sequences,omitempty"`
+	// Styles are named sets of block properties that blocks apply with .style(name).
+	Styles     []StyleDSLModel     `json:"styles,omitempty"`
+	Components []ComponentDSLModel `json:"components,omitempty"`
+	Blocks     []BlockDSLModel     `json:"blocks"`
+	Line       int                 `json:"-"`
+	Column     int                 `json:"-"`
 }
 
 type VariableDSLModel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+	// Computed is set for variables declared with val, whose Value is an expression over other variables.
+	Computed bool   `json:"computed,omitempty"`
+	File     string `json:"-"`
+	Line     int    `json:"-"`
+	Column   int    `json:"-"`
+}
+
+type LibraryDSLModel struct {
+	Imports    []ImportDSLModel    `json:"imports"`
+	Constants  []ConstantDSLModel  `json:"constants"`
+	Enums      []EnumDSLModel      `json:"enums,omitempty"`
+	Variables  []VariableDSLModel  `json:"variables"`
+	Styles     []StyleDSLModel     `json:"styles,omitempty"`
+	Components []ComponentDSLModel `json:"components"`
+	Line       int                 `json:"-"`
+	Column     int                 `json:"-"`
+}
+
+type ImportDSLModel struct {
+	Path   string `json:"path"`
+	Line   int    `json:"-"`
+	Column int    `json:"-"`
+}
+
+type ConstantDSLModel struct {
 	Key    string `json:"key"`
 	Value  string `json:"value"`
 	Type   string `json:"type"`
+	File   string `json:"-"`
 	Line   int    `json:"-"`
 	Column int    `json:"-"`
 }
 
+type EnumDSLModel struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+	File    string   `json:"-"`
+	Line    int      `json:"-"`
+	Column  int      `json:"-"`
+}
+
 type BlockDSLModel struct {
-	KeyType            string                  `json:"keyType"`
-	Key                string                  `json:"key"`
-	VisibilityKey      string                  `json:"visibilityKey"`
-	Slot               string                  `json:"slot,omitempty"`
-	IntegrationVersion int                     `json:"integrationVersion"`
-	Data               []BlockDataDSLModel     `json:"data"`
-	Properties         []BlockPropertyDSLModel `json:"properties"`
-	Slots              []BlockSlotDSLModel     `json:"slots"`
-	Blocks             []BlockDSLModel         `json:"blocks"`
-	Actions            []ActionDSLModel        `json:"actions"`
-	Line               int                     `json:"-"`
-	Column             int                     `json:"-"`
+	KeyType            string          `json:"keyType"`
+	Key                string          `json:"key"`
+	VisibilityKey      string          `json:"visibilityKey"`
+	Slot               string          `json:"slot,omitempty"`
+	Component          string          `json:"component,omitempty"`
+	Outlet             string          `json:"outlet,omitempty"`
+	IntegrationVersion int             `json:"integrationVersion"`
+	Repeat             *RepeatDSLModel `json:"repeat,omitempty"`
+	// Styles are the styles applied to the block, in order. Its own Properties override them.
+	Styles     []string                `json:"styles,omitempty"`
+	Data       []BlockDataDSLModel     `json:"data"`
+	Properties []BlockPropertyDSLModel `json:"properties"`
+	Slots      []BlockSlotDSLModel     `json:"slots"`
+	Blocks     []BlockDSLModel         `json:"blocks"`
+	Actions    []ActionDSLModel        `json:"actions"`
+	Line       int                     `json:"-"`
+	Column     int                     `json:"-"`
+}
+
+// RepeatDSLModel repeats a block once per element of the LIST variable Items. The block and its subtree
+// refer to the element as As, and to its fields as As.field.
+type RepeatDSLModel struct {
+	Items  string `json:"items"`
+	As     string `json:"as"`
+	Line   int    `json:"-"`
+	Column int    `json:"-"`
+}
+
+// StyleDSLModel is a named set of block properties. A style composes the styles in Styles, in order, and
+// its own Properties override theirs.
+type StyleDSLModel struct {
+	Name       string                  `json:"name"`
+	Styles     []string                `json:"styles,omitempty"`
+	Properties []BlockPropertyDSLModel `json:"properties"`
+	File       string                  `json:"-"`
+	Line       int                     `json:"-"`
+	Column     int                     `json:"-"`
 }
 
 type BlockPropertyDSLModel struct {
@@ -44,11 +117,13 @@ type BlockPropertyDSLModel struct {
 }
 
 type BlockDataDSLModel struct {
-	Key    string `json:"key"`
-	Value  string `json:"value"`
-	Type   string `json:"type"`
-	Line   int    `json:"-"`
-	Column int    `json:"-"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type"`
+	Line        int    `json:"-"`
+	Column      int    `json:"-"`
+	ValueLine   int    `json:"-"`
+	ValueColumn int    `json:"-"`
 }
 
 type BlockSlotDSLModel struct {
@@ -66,15 +141,32 @@ type ActionDSLModel struct {
 }
 
 type ActionTriggerDSLModel struct {
-	KeyType            string                    `json:"keyType"`
-	Then               string                    `json:"then"`
-	Name               string                    `json:"name"`
-	IntegrationVersion int                       `json:"integrationVersion"`
-	Properties         []TriggerPropertyDSLModel `json:"properties"`
-	Data               []TriggerDataDSLModel     `json:"data"`
-	Triggers           []ActionTriggerDSLModel   `json:"triggers"`
-	Line               int                       `json:"-"`
-	Column             int                       `json:"-"`
+	KeyType            string `json:"keyType"`
+	Then               string `json:"then"`
+	Name               string `json:"name"`
+	IntegrationVersion int    `json:"integrationVersion"`
+	// Sequence is set for a call of a trigger sequence, which stands for the sequence's triggers.
+	Sequence string `json:"sequence,omitempty"`
+	// Condition is an expression over the variables: the trigger runs only when it holds. For a trigger
+	// of ConditionKeyType it picks the branch instead.
+	Condition  string                    `json:"condition,omitempty"`
+	Properties []TriggerPropertyDSLModel `json:"properties"`
+	Data       []TriggerDataDSLModel     `json:"data"`
+	Triggers   []ActionTriggerDSLModel   `json:"triggers"`
+	Line       int                       `json:"-"`
+	Column     int                       `json:"-"`
+}
+
+// ConditionKeyType is the keyType of the built-in trigger an if/else compiles to. It finishes with
+// SUCCESS when its Condition holds and FAILURE otherwise, so its nested triggers of those then values
+// form the two branches.
+const ConditionKeyType = "$if"
+
+type SequenceDSLModel struct {
+	Name     string                  `json:"name"`
+	Triggers []ActionTriggerDSLModel `json:"triggers"`
+	Line     int                     `json:"-"`
+	Column   int                     `json:"-"`
 }
 
 type TriggerPropertyDSLModel struct {
@@ -86,9 +178,30 @@ type TriggerPropertyDSLModel struct {
 }
 
 type TriggerDataDSLModel struct {
-	Key    string `json:"key"`
-	Value  string `json:"value"`
-	Type   string `json:"type"`
-	Line   int    `json:"-"`
-	Column int    `json:"-"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type"`
+	Line        int    `json:"-"`
+	Column      int    `json:"-"`
+	ValueLine   int    `json:"-"`
+	ValueColumn int    `json:"-"`
+}
+
+type ComponentDSLModel struct {
+	Name       string                   `json:"name"`
+	Properties []ComponentParamDSLModel `json:"properties"`
+	Data       []ComponentParamDSLModel `json:"data"`
+	Block      BlockDSLModel            `json:"block"`
+	File       string                   `json:"-"`
+	Line       int                      `json:"-"`
+	Column     int                      `json:"-"`
+}
+
+type ComponentParamDSLModel struct {
+	Key      string `json:"key"`
+	Type     string `json:"type,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Line     int    `json:"-"`
+	Column   int    `json:"-"`
 }
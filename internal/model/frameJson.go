@@ -24,6 +24,13 @@ type VariableJson struct {
 	Key     string `json:"key"`
 	Value   string `json:"value"`
 	Type    string `json:"type"`
+	// SourceType is the declared type when Type is its primitive wire form, such as LIST<INT> for STRING.
+	SourceType string `json:"sourceType,omitempty"`
+	// Members lists the members of an enum SourceType.
+	Members []string `json:"members,omitempty"`
+	// Expression is the expression of a computed variable, which runtimes evaluate again when the variables
+	// it uses change. Value holds its result for the initial values.
+	Expression string `json:"expression,omitempty"`
 }
 
 type BlockJson struct {
@@ -36,6 +43,7 @@ type BlockJson struct {
 	Slot                        string              `json:"slot"`
 	IntegrationVersion          int                 `json:"integrationVersion"`
 	ParentId                    string              `json:"parentId"`
+	Repeat                      *BlockRepeatJson    `json:"repeat,omitempty"`
 	Data                        []BlockDataJson     `json:"data"`
 	Properties                  []BlockPropertyJson `json:"properties"`
 	Slots                       []BlockSlotJson     `json:"slots"`
@@ -43,6 +51,14 @@ type BlockJson struct {
 	IntegrationDeprecatedReason string              `json:"integrationDeprecatedReason"`
 }
 
+// BlockRepeatJson marks a template block that runtimes render once per element of the LIST variable
+// Items, binding the element to As. ItemType is the declared type of the elements.
+type BlockRepeatJson struct {
+	Items    string `json:"items"`
+	As       string `json:"as"`
+	ItemType string `json:"itemType"`
+}
+
 type BlockPropertyJson struct {
 	BlockId            string `json:"blockId"`
 	Key                string `json:"key"`
@@ -59,13 +75,21 @@ type BlockPropertyJson struct {
 }
 
 type BlockDataJson struct {
-	BlockId          string `json:"blockId"`
-	Key              string `json:"key"`
-	Value            string `json:"value"`
-	Type             string `json:"type"`
-	Description      string `json:"description"`
-	Deprecated       bool   `json:"deprecated"`
-	DeprecatedReason string `json:"deprecatedReason"`
+	BlockId          string             `json:"blockId"`
+	Key              string             `json:"key"`
+	Value            string             `json:"value"`
+	Type             string             `json:"type"`
+	Template         []TemplatePartJson `json:"template,omitempty"`
+	Description      string             `json:"description"`
+	Deprecated       bool               `json:"deprecated"`
+	DeprecatedReason string             `json:"deprecatedReason"`
+}
+
+// TemplatePartJson is a piece of an interpolated data value: literal text, or the variable whose value
+// is inserted.
+type TemplatePartJson struct {
+	Text     string `json:"text,omitempty"`
+	Variable string `json:"variable,omitempty"`
 }
 
 type BlockSlotJson struct {
@@ -76,6 +100,10 @@ type BlockSlotJson struct {
 	DeprecatedReason string `json:"deprecatedReason"`
 }
 
+// FrameActionKey is the key of actions that belong to the frame itself, such as onLoad, rather than to
+// a block.
+const FrameActionKey = "$frame"
+
 type ActionJson struct {
 	Id       string              `json:"id"`
 	FrameId  string              `json:"frameId"`
@@ -85,13 +113,16 @@ type ActionJson struct {
 }
 
 type ActionTriggerJson struct {
-	Id                          string                `json:"id"`
-	ActionId                    string                `json:"actionId"`
-	ParentId                    string                `json:"parentId"`
-	KeyType                     string                `json:"keyType"`
-	Then                        string                `json:"then"`
-	Name                        string                `json:"name"`
-	IntegrationVersion          int                   `json:"integrationVersion"`
+	Id                 string `json:"id"`
+	ActionId           string `json:"actionId"`
+	ParentId           string `json:"parentId"`
+	KeyType            string `json:"keyType"`
+	Then               string `json:"then"`
+	Name               string `json:"name"`
+	IntegrationVersion int    `json:"integrationVersion"`
+	// Condition is the canonical expression guarding the trigger, or choosing the branch of a
+	// ConditionKeyType trigger.
+	Condition                   string                `json:"condition,omitempty"`
 	Properties                  []TriggerPropertyJson `json:"properties"`
 	Data                        []TriggerDataJson     `json:"data"`
 	IntegrationDeprecated       bool                  `json:"integrationDeprecated"`
@@ -112,11 +143,12 @@ type TriggerPropertyJson struct {
 }
 
 type TriggerDataJson struct {
-	ActionTriggerId  string `json:"actionTriggerId"`
-	Key              string `json:"key"`
-	Value            string `json:"value"`
-	Type             string `json:"type"`
-	Description      string `json:"description"`
-	Deprecated       bool   `json:"deprecated"`
-	DeprecatedReason string `json:"deprecatedReason"`
+	ActionTriggerId  string             `json:"actionTriggerId"`
+	Key              string             `json:"key"`
+	Value            string             `json:"value"`
+	Type             string             `json:"type"`
+	Template         []TemplatePartJson `json:"template,omitempty"`
+	Description      string             `json:"description"`
+	Deprecated       bool               `json:"deprecated"`
+	DeprecatedReason string             `json:"deprecatedReason"`
 }
@@ -3,12 +3,41 @@ package model
 import "encoding/xml"
 
 type XMLFrame struct {
-	XMLName   xml.Name      `xml:"frame"`
-	Name      string        `xml:"name,attr"`
-	Route     string        `xml:"route,attr"`
-	Type      string        `xml:"type,attr"`
-	Variables []XMLVariable `xml:"var"`
-	Blocks    []XMLBlock    `xml:"block"`
+	XMLName    xml.Name       `xml:"frame"`
+	Name       string         `xml:"name,attr"`
+	Route      string         `xml:"route,attr"`
+	Type       string         `xml:"type,attr"`
+	Starter    bool           `xml:"starter,attr,omitempty"`
+	Imports    []XMLImport    `xml:"import"`
+	Constants  []XMLVariable  `xml:"const"`
+	Enums      []XMLEnum      `xml:"enum"`
+	Variables  []XMLVariable  `xml:"var"`
+	Computed   []XMLVariable  `xml:"val"`
+	Actions    []XMLAction    `xml:"action"`
+	Sequences  []XMLSequence  `xml:"sequence"`
+	Styles     []XMLStyle     `xml:"style"`
+	Components []XMLComponent `xml:"component"`
+	Blocks     []XMLBlock     `xml:"block"`
+}
+
+type XMLLibrary struct {
+	XMLName    xml.Name       `xml:"library"`
+	Imports    []XMLImport    `xml:"import"`
+	Constants  []XMLVariable  `xml:"const"`
+	Enums      []XMLEnum      `xml:"enum"`
+	Variables  []XMLVariable  `xml:"var"`
+	Computed   []XMLVariable  `xml:"val"`
+	Styles     []XMLStyle     `xml:"style"`
+	Components []XMLComponent `xml:"component"`
+}
+
+type XMLImport struct {
+	Src string `xml:"src,attr"`
+}
+
+type XMLEnum struct {
+	Name    string `xml:"name,attr"`
+	Members string `xml:"members,attr"`
 }
 
 type XMLVariable struct {
@@ -21,13 +50,22 @@ type XMLBlock struct {
 	KeyType    string        `xml:"keyType,attr"`
 	Key        string        `xml:"key,attr"`
 	Visibility string        `xml:"visibility,attr"`
+	Component  string        `xml:"component,attr"`
+	Outlet     string        `xml:"outlet,attr"`
 	Version    int           `xml:"version,attr"`
+	Style      string        `xml:"style,attr"`
+	Repeat     *XMLRepeat    `xml:"repeat"`
 	Properties []XMLProperty `xml:"prop"`
 	Data       []XMLData     `xml:"data"`
 	Slots      []XMLSlot     `xml:"slot"`
 	Actions    []XMLAction   `xml:"action"`
 }
 
+type XMLRepeat struct {
+	Items string `xml:"items,attr"`
+	As    string `xml:"as,attr"`
+}
+
 type XMLProperty struct {
 	Key     string `xml:"key,attr"`
 	Value   string `xml:"value,attr"`
@@ -55,12 +93,42 @@ type XMLTrigger struct {
 	KeyType    string        `xml:"keyType,attr"`
 	Name       string        `xml:"name,attr"`
 	Version    int           `xml:"version,attr"`
+	Sequence   string        `xml:"sequence,attr"`
+	When       string        `xml:"when,attr"`
+	If         string        `xml:"if,attr"`
+	Repeat     *XMLRepeat    `xml:"repeat"`
 	Properties []XMLProperty `xml:"prop"`
 	Data       []XMLData     `xml:"data"`
 	Then       []XMLThen     `xml:"then"`
+	Else       *XMLThen      `xml:"else"`
+}
+
+// XMLStyle declares a style. Style lists the styles it composes, separated by spaces or commas.
+type XMLStyle struct {
+	Name       string        `xml:"name,attr"`
+	Style      string        `xml:"style,attr"`
+	Properties []XMLProperty `xml:"prop"`
+}
+
+type XMLSequence struct {
+	Name     string       `xml:"name,attr"`
+	Triggers []XMLTrigger `xml:"trigger"`
 }
 
 type XMLThen struct {
 	Value    string       `xml:"value,attr"`
 	Triggers []XMLTrigger `xml:"trigger"`
 }
+
+type XMLComponent struct {
+	Name       string              `xml:"name,attr"`
+	Properties []XMLComponentParam `xml:"prop"`
+	Data       []XMLComponentParam `xml:"data"`
+	Blocks     []XMLBlock          `xml:"block"`
+}
+
+type XMLComponentParam struct {
+	Key   string  `xml:"key,attr"`
+	Type  string  `xml:"type,attr"`
+	Value *string `xml:"value,attr"`
+}
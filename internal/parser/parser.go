@@ -1,10 +1,14 @@
 package parser
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/expr"
 	"github.com/nativeblocks/nbx/internal/lexer"
 	"github.com/nativeblocks/nbx/internal/model"
 	"github.com/nativeblocks/nbx/internal/types"
@@ -146,15 +150,18 @@ func (p *Parser) _parseBlockData() []model.BlockDataDSLModel {
 		}
 		p._nextToken()
 		value := p.curToken.Literal
+		valueLine, valueColumn := p._valuePosition()
 
 		inferredType := p._inferTypeFromToken(p.curToken)
 
 		dataList = append(dataList, model.BlockDataDSLModel{
-			Key:    key,
-			Value:  value,
-			Type:   inferredType.Name(),
-			Line:   keyLine,
-			Column: keyColumn,
+			Key:         key,
+			Value:       value,
+			Type:        inferredType.Name(),
+			Line:        keyLine,
+			Column:      keyColumn,
+			ValueLine:   valueLine,
+			ValueColumn: valueColumn,
 		})
 
 		if p._peekTokenIs(lexer.TOKEN_COMMA) {
@@ -199,14 +206,17 @@ func (p *Parser) _parseTriggerData() []model.TriggerDataDSLModel {
 		}
 		p._nextToken()
 		value := p.curToken.Literal
+		valueLine, valueColumn := p._valuePosition()
 
 		inferredType := p._inferTypeFromToken(p.curToken)
 		dataList = append(dataList, model.TriggerDataDSLModel{
-			Key:    key,
-			Value:  value,
-			Type:   inferredType.Name(),
-			Line:   keyLine,
-			Column: keyColumn,
+			Key:         key,
+			Value:       value,
+			Type:        inferredType.Name(),
+			Line:        keyLine,
+			Column:      keyColumn,
+			ValueLine:   valueLine,
+			ValueColumn: valueColumn,
 		})
 
 		if p._peekTokenIs(lexer.TOKEN_COMMA) {
@@ -507,13 +517,7 @@ func (p *Parser) _parseThen(trigger *model.ActionTriggerDSLModel) {
 	p._nextToken() // move to first token inside the then block
 
 	for !p._curTokenIs(lexer.TOKEN_RBRACE) && !p._curTokenIs(lexer.TOKEN_EOF) {
-		if p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "trigger" {
-			nestedTrigger := p._parseTrigger(thenValue)
-			if nestedTrigger != nil {
-				trigger.Triggers = append(trigger.Triggers, *nestedTrigger)
-				trigger.Triggers = _enforceSliceCap(trigger.Triggers)
-			}
-		}
+		p._parseStatement(&trigger.Triggers, thenValue)
 		p._nextToken()
 	}
 }
@@ -537,9 +541,25 @@ func (p *Parser) _parseFrame() *model.FrameDSLModel {
 	frame.Name = frameAttrs["name"]
 	frame.Route = frameAttrs["route"]
 
+	if starter, ok := frameAttrs["starter"]; ok {
+		switch starter {
+		case "true":
+			frame.Starter = true
+		case "false":
+		default:
+			p.errorCollector.AddError(&errors.Error{
+				Severity:   errors.SeverityError,
+				Message:    fmt.Sprintf("Invalid value '%s' for frame attribute 'starter'", starter),
+				Line:       frame.Line,
+				Column:     frame.Column,
+				Suggestion: "Use starter = true or starter = false",
+			})
+		}
+	}
+
 	for key := range frameAttrs {
-		if key != "name" && key != "route" {
-			validAttrs := []string{"name", "route"}
+		if key != "name" && key != "route" && key != "starter" {
+			validAttrs := []string{"name", "route", "starter"}
 			p.errorCollector.AddError(errors.UnknownAttributeError(
 				key, "frame", frame.Line, frame.Column, validAttrs,
 			))
@@ -565,17 +585,57 @@ func (p *Parser) _parseFrame() *model.FrameDSLModel {
 					frame.Variables = append(frame.Variables, *varDecl)
 					frame.Variables = _enforceSliceCap(frame.Variables)
 				}
+			} else if p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "action" {
+				action := p._parseAction()
+				frame.Actions = append(frame.Actions, action)
+				frame.Actions = _enforceSliceCap(frame.Actions)
 			} else if p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "block" {
 				block := p._parseBlock()
 				if block != nil {
 					frame.Blocks = append(frame.Blocks, *block)
 					frame.Blocks = _enforceSliceCap(frame.Blocks)
 				}
+			} else if p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "sequence" {
+				if sequence := p._parseSequence(); sequence != nil {
+					frame.Sequences = append(frame.Sequences, *sequence)
+					frame.Sequences = _enforceSliceCap(frame.Sequences)
+				}
+			} else if p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "style" {
+				if style := p._parseStyle(); style != nil {
+					frame.Styles = append(frame.Styles, *style)
+					frame.Styles = _enforceSliceCap(frame.Styles)
+				}
+			} else if p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "component" {
+				component := p._parseComponent()
+				if component != nil {
+					frame.Components = append(frame.Components, *component)
+					frame.Components = _enforceSliceCap(frame.Components)
+				}
+			} else if p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "import" {
+				if imp := p._parseImport(); imp != nil {
+					frame.Imports = append(frame.Imports, *imp)
+					frame.Imports = _enforceSliceCap(frame.Imports)
+				}
+			} else if p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "const" {
+				if constant := p._parseConstant(); constant != nil {
+					frame.Constants = append(frame.Constants, *constant)
+					frame.Constants = _enforceSliceCap(frame.Constants)
+				}
+			} else if p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "val" {
+				if computed := p._parseComputed(); computed != nil {
+					frame.Variables = append(frame.Variables, *computed)
+					frame.Variables = _enforceSliceCap(frame.Variables)
+				}
+			} else if p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "enum" {
+				if enum := p._parseEnum(); enum != nil {
+					frame.Enums = append(frame.Enums, *enum)
+					frame.Enums = _enforceSliceCap(frame.Enums)
+				}
 			} else {
 				p.errorCollector.AddTokenError(
 					fmt.Sprintf("Unexpected token '%s' in frame body", p.curToken.Literal),
 					p.curToken,
-					"Expected 'var' or 'block' declaration",
+					"Expected 'import', 'const', 'enum', 'var', 'val', 'action', 'sequence', 'style', 'component' or 'block' declaration",
 				)
 			}
 			p._nextToken()
@@ -589,26 +649,150 @@ func (p *Parser) _parseFrame() *model.FrameDSLModel {
 	return frame
 }
 
-func (p *Parser) _parseVariable() *model.VariableDSLModel {
-	varLine, varColumn := p.curToken.Line, p.curToken.Column
+// ParseLibrary parses a library file: imports, constants, variables and components shared by frames,
+// wrapped in "library { ... }".
+func (p *Parser) ParseLibrary() *model.LibraryDSLModel {
+	if !p._curTokenIs(lexer.TOKEN_IDENT) || p.curToken.Literal != "library" {
+		p.errorCollector.AddTokenError(
+			"Library must start with a library declaration",
+			p.curToken,
+			"Add 'library { ... }' around the shared declarations",
+		)
+		return nil
+	}
 
-	if !p._expectPeek(lexer.TOKEN_IDENT) {
+	library := &model.LibraryDSLModel{
+		Imports:    make([]model.ImportDSLModel, 0),
+		Constants:  make([]model.ConstantDSLModel, 0),
+		Variables:  make([]model.VariableDSLModel, 0),
+		Components: make([]model.ComponentDSLModel, 0),
+		Line:       p.curToken.Line,
+		Column:     p.curToken.Column,
+	}
+
+	if !p._expectPeek(lexer.TOKEN_LBRACE) {
 		return nil
 	}
-	key := p.curToken.Literal
-	if !p._expectPeek(lexer.TOKEN_COLON) {
+	p._nextToken() // move to first token inside the library
+
+	for !p._curTokenIs(lexer.TOKEN_RBRACE) && !p._curTokenIs(lexer.TOKEN_EOF) {
+		switch {
+		case p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "import":
+			if imp := p._parseImport(); imp != nil {
+				library.Imports = append(library.Imports, *imp)
+			}
+		case p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "const":
+			if constant := p._parseConstant(); constant != nil {
+				library.Constants = append(library.Constants, *constant)
+			}
+		case p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "enum":
+			if enum := p._parseEnum(); enum != nil {
+				library.Enums = append(library.Enums, *enum)
+			}
+		case p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "var":
+			if variable := p._parseVariable(); variable != nil {
+				library.Variables = append(library.Variables, *variable)
+			}
+		case p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "val":
+			if computed := p._parseComputed(); computed != nil {
+				library.Variables = append(library.Variables, *computed)
+			}
+		case p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "style":
+			if style := p._parseStyle(); style != nil {
+				library.Styles = append(library.Styles, *style)
+			}
+		case p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "component":
+			if component := p._parseComponent(); component != nil {
+				library.Components = append(library.Components, *component)
+			}
+		default:
+			p.errorCollector.AddTokenError(
+				fmt.Sprintf("Unexpected token '%s' in library body", p.curToken.Literal),
+				p.curToken,
+				"Expected 'import', 'const', 'enum', 'var', 'val', 'style' or 'component' declaration",
+			)
+		}
+		p._nextToken()
+	}
+
+	if p.errorCollector.HasErrors() {
+		return nil
+	}
+
+	return library
+}
+
+// _parseImport parses 'import "path"'.
+func (p *Parser) _parseImport() *model.ImportDSLModel {
+	imp := &model.ImportDSLModel{
+		Line:   p.curToken.Line,
+		Column: p.curToken.Column,
+	}
+	if !p._expectPeek(lexer.TOKEN_STRING) {
+		return nil
+	}
+	imp.Path = p.curToken.Literal
+	return imp
+}
+
+// _parseConstant parses "const name: TYPE = value", which has the form of a variable declaration.
+func (p *Parser) _parseConstant() *model.ConstantDSLModel {
+	variable := p._parseVariable()
+	if variable == nil {
 		return nil
 	}
+	return &model.ConstantDSLModel{
+		Key:    variable.Key,
+		Value:  variable.Value,
+		Type:   variable.Type,
+		Line:   variable.Line,
+		Column: variable.Column,
+	}
+}
+
+// _parseEnum parses "enum Name { MEMBER, MEMBER }". A trailing comma after the last member is allowed.
+func (p *Parser) _parseEnum() *model.EnumDSLModel {
+	enum := &model.EnumDSLModel{
+		Members: make([]string, 0),
+		Line:    p.curToken.Line,
+		Column:  p.curToken.Column,
+	}
 	if !p._expectPeek(lexer.TOKEN_IDENT) {
 		return nil
 	}
-	typ := p.curToken.Literal
-	if !p._expectPeek(lexer.TOKEN_ASSIGN) {
+	enum.Name = p.curToken.Literal
+	if !p._expectPeek(lexer.TOKEN_LBRACE) {
+		return nil
+	}
+	for !p._peekTokenIs(lexer.TOKEN_RBRACE) {
+		if !p._expectPeek(lexer.TOKEN_IDENT) {
+			return nil
+		}
+		enum.Members = append(enum.Members, p.curToken.Literal)
+		if !p._peekTokenIs(lexer.TOKEN_COMMA) {
+			break
+		}
+		p._nextToken()
+	}
+	if !p._expectPeek(lexer.TOKEN_RBRACE) {
+		return nil
+	}
+	return enum
+}
+
+func (p *Parser) _parseVariable() *model.VariableDSLModel {
+	varLine, varColumn := p.curToken.Line, p.curToken.Column
+
+	key, typ, ok := p._parseDeclaration()
+	if !ok {
 		return nil
 	}
 	p._nextToken()
 
-	value := p.curToken.Literal
+	value, ok := p._parseValue()
+	if !ok {
+		return nil
+	}
 
 	return &model.VariableDSLModel{
 		Key:    key,
@@ -619,6 +803,72 @@ func (p *Parser) _parseVariable() *model.VariableDSLModel {
 	}
 }
 
+// _parseDeclaration parses "name: TYPE =" after var, val or const, leaving the current token on '='.
+func (p *Parser) _parseDeclaration() (string, string, bool) {
+	if !p._expectPeek(lexer.TOKEN_IDENT) {
+		return "", "", false
+	}
+	key := p.curToken.Literal
+	if !p._expectPeek(lexer.TOKEN_COLON) {
+		return "", "", false
+	}
+	if !p._expectPeek(lexer.TOKEN_IDENT) {
+		return "", "", false
+	}
+	typ, ok := p._parseType()
+	if !ok || !p._expectPeek(lexer.TOKEN_ASSIGN) {
+		return "", "", false
+	}
+	return key, typ, true
+}
+
+// _parseComputed parses "val name: TYPE = expression", a variable derived from other variables. The
+// expression runs to the end of the line, or may be wrapped in $( ) to span lines.
+func (p *Parser) _parseComputed() *model.VariableDSLModel {
+	valLine, valColumn := p.curToken.Line, p.curToken.Column
+
+	key, typ, ok := p._parseDeclaration()
+	if !ok {
+		return nil
+	}
+
+	var value string
+	if p._peekTokenIs(lexer.TOKEN_EXPRESSION) {
+		p._nextToken()
+		value, _ = expr.Unwrap(p.curToken.Literal)
+	} else {
+		line := p.curToken.Line
+		value = p.l.RestOfLine(line, p.curToken.Column)
+		for !p._peekTokenIs(lexer.TOKEN_EOF) && _tokenLine(p.peekToken) == line {
+			p._nextToken()
+		}
+	}
+	if strings.TrimSpace(value) == "" {
+		p.errorCollector.AddSimpleError(
+			fmt.Sprintf("Computed variable '%s' requires an expression", key), valLine, valColumn,
+		)
+		return nil
+	}
+
+	return &model.VariableDSLModel{
+		Key:      key,
+		Type:     typ,
+		Value:    strings.TrimSpace(value),
+		Computed: true,
+		Line:     valLine,
+		Column:   valColumn,
+	}
+}
+
+// _tokenLine returns the line of a token. Identifiers are positioned after their last character, which is
+// column 0 of the next line for an identifier that ends a line.
+func _tokenLine(token lexer.Token) int {
+	if token.Type == lexer.TOKEN_IDENT && token.Column == 0 {
+		return token.Line - 1
+	}
+	return token.Line
+}
+
 func (p *Parser) _parseBlock() *model.BlockDSLModel {
 	blockLine, blockColumn := p.curToken.Line, p.curToken.Column
 
@@ -640,12 +890,24 @@ func (p *Parser) _parseBlock() *model.BlockDSLModel {
 	block.KeyType = blockAttrs["keyType"]
 	block.Key = blockAttrs["key"]
 	block.VisibilityKey = blockAttrs["visibility"]
+	block.Component = blockAttrs["component"]
+	block.Outlet = blockAttrs["outlet"]
 	if version, ok := blockAttrs["version"]; ok {
 		block.IntegrationVersion, _ = strconv.Atoi(version)
 	}
 
 	for p._peekTokenIs(lexer.TOKEN_DOT) {
 		p._nextToken()
+		if p._peekTokenIs(lexer.TOKEN_IDENT) && p.peekToken.Literal == "style" {
+			p._nextToken()
+			block.Styles = append(block.Styles, p._parseStyleNames()...)
+			continue
+		}
+		if p._peekTokenIs(lexer.TOKEN_IDENT) && p.peekToken.Literal == "repeat" {
+			p._nextToken()
+			p._parseRepeat(block)
+			continue
+		}
 		if p._expectPeek(lexer.TOKEN_KEYWORD) {
 			switch p.curToken.Literal {
 			case "data":
@@ -668,6 +930,329 @@ func (p *Parser) _parseBlock() *model.BlockDSLModel {
 	return block
 }
 
+// _parseRepeat parses .repeat(items = products, as = item), which repeats the block once per element of
+// a LIST variable.
+// _parseStyle parses "style name { ... }", a named set of block properties. Its body holds .prop modifiers
+// and .style modifiers naming the styles it composes.
+func (p *Parser) _parseStyle() *model.StyleDSLModel {
+	style := &model.StyleDSLModel{
+		Properties: make([]model.BlockPropertyDSLModel, 0),
+		Line:       p.curToken.Line,
+		Column:     p.curToken.Column,
+	}
+	if !p._expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	style.Name = p.curToken.Literal
+	if !p._expectPeek(lexer.TOKEN_LBRACE) {
+		return nil
+	}
+	p._nextToken() // move to first token inside the style
+
+	for !p._curTokenIs(lexer.TOKEN_RBRACE) && !p._curTokenIs(lexer.TOKEN_EOF) {
+		switch {
+		case p._curTokenIs(lexer.TOKEN_DOT) && p._peekTokenIs(lexer.TOKEN_KEYWORD) && p.peekToken.Literal == "prop":
+			p._nextToken()
+			style.Properties = append(style.Properties, p._parseBlockProperty()...)
+			style.Properties = _enforceSliceCap(style.Properties)
+		case p._curTokenIs(lexer.TOKEN_DOT) && p._peekTokenIs(lexer.TOKEN_IDENT) && p.peekToken.Literal == "style":
+			p._nextToken()
+			style.Styles = append(style.Styles, p._parseStyleNames()...)
+		default:
+			p.errorCollector.AddTokenError(
+				fmt.Sprintf("Unexpected token '%s' in style body", p.curToken.Literal),
+				p.curToken,
+				"Expected '.prop(...)' or '.style(...)'",
+			)
+		}
+		p._nextToken()
+	}
+	return style
+}
+
+// _parseStyleNames parses the names of a .style modifier, such as .style(primaryButton, rounded).
+func (p *Parser) _parseStyleNames() []string {
+	var names []string
+	if !p._expectPeek(lexer.TOKEN_LPAREN) {
+		return names
+	}
+	for !p._peekTokenIs(lexer.TOKEN_RPAREN) {
+		p._nextToken()
+		if !p._curTokenIs(lexer.TOKEN_IDENT) && !p._curTokenIs(lexer.TOKEN_STRING) {
+			p.errorCollector.AddTokenError(
+				"Expected style name in .style modifier",
+				p.curToken,
+				"Use format: .style(name, ...)",
+			)
+			return names
+		}
+		names = append(names, p.curToken.Literal)
+		if !p._peekTokenIs(lexer.TOKEN_COMMA) {
+			break
+		}
+		p._nextToken()
+	}
+	p._expectPeek(lexer.TOKEN_RPAREN)
+	return names
+}
+
+func (p *Parser) _parseRepeat(block *model.BlockDSLModel) {
+	repeat := &model.RepeatDSLModel{Line: p.curToken.Line, Column: p.curToken.Column}
+	if !p._expectPeek(lexer.TOKEN_LPAREN) {
+		return
+	}
+
+	repeatAttrs := p._parseKeyValuePairs()
+	repeat.Items = repeatAttrs["items"]
+	repeat.As = repeatAttrs["as"]
+	for key := range repeatAttrs {
+		if key != "items" && key != "as" {
+			p.errorCollector.AddError(errors.UnknownAttributeError(
+				key, "repeat", repeat.Line, repeat.Column, []string{"items", "as"},
+			))
+		}
+	}
+	if repeat.Items == "" || repeat.As == "" {
+		p.errorCollector.AddSimpleError("Repeat requires 'items' and 'as' attributes", repeat.Line, repeat.Column)
+	}
+	if block.Repeat != nil {
+		p.errorCollector.AddSimpleError(
+			fmt.Sprintf("Block '%s' has more than one repeat", block.Key), repeat.Line, repeat.Column,
+		)
+	}
+	block.Repeat = repeat
+}
+
+// _parseComponent parses a component declaration: its prop and data parameters followed by the single
+// root block of the component.
+func (p *Parser) _parseComponent() *model.ComponentDSLModel {
+	component := &model.ComponentDSLModel{
+		Properties: make([]model.ComponentParamDSLModel, 0),
+		Data:       make([]model.ComponentParamDSLModel, 0),
+		Line:       p.curToken.Line,
+		Column:     p.curToken.Column,
+	}
+
+	if !p._expectPeek(lexer.TOKEN_LPAREN) {
+		return nil
+	}
+
+	componentAttrs := p._parseKeyValuePairs()
+	component.Name = componentAttrs["name"]
+	for key := range componentAttrs {
+		if key != "name" {
+			p.errorCollector.AddError(errors.UnknownAttributeError(
+				key, "component", component.Line, component.Column, []string{"name"},
+			))
+		}
+	}
+	if component.Name == "" {
+		p.errorCollector.AddSimpleError("Component is missing required 'name' attribute", component.Line, component.Column)
+	}
+
+	if !p._expectPeek(lexer.TOKEN_LBRACE) {
+		return nil
+	}
+	p._nextToken() // move to first token inside the component
+
+	hasBlock := false
+	for !p._curTokenIs(lexer.TOKEN_RBRACE) && !p._curTokenIs(lexer.TOKEN_EOF) {
+		switch {
+		case p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "prop":
+			if param := p._parseComponentParam(true); param != nil {
+				component.Properties = append(component.Properties, *param)
+				component.Properties = _enforceSliceCap(component.Properties)
+			}
+		case p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "data":
+			if param := p._parseComponentParam(false); param != nil {
+				component.Data = append(component.Data, *param)
+				component.Data = _enforceSliceCap(component.Data)
+			}
+		case p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "block":
+			blockToken := p.curToken
+			block := p._parseBlock()
+			if hasBlock {
+				p.errorCollector.AddTokenError(
+					fmt.Sprintf("Component '%s' must have a single root block", component.Name),
+					blockToken,
+					"Wrap the blocks in a container block",
+				)
+			} else if block != nil {
+				component.Block = *block
+				hasBlock = true
+			}
+		default:
+			p.errorCollector.AddTokenError(
+				fmt.Sprintf("Unexpected token '%s' in component body", p.curToken.Literal),
+				p.curToken,
+				"Expected 'prop', 'data' or 'block' declaration",
+			)
+		}
+		p._nextToken()
+	}
+
+	if !hasBlock {
+		p.errorCollector.AddSimpleError(
+			fmt.Sprintf("Component '%s' must have a root block", component.Name),
+			component.Line, component.Column,
+		)
+	}
+	return component
+}
+
+// _parseComponentParam parses "prop name: TYPE = default" or "data name". A prop without a default
+// value is required.
+func (p *Parser) _parseComponentParam(typed bool) *model.ComponentParamDSLModel {
+	param := &model.ComponentParamDSLModel{
+		Required: true,
+		Line:     p.curToken.Line,
+		Column:   p.curToken.Column,
+	}
+
+	if !p._expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	param.Key = p.curToken.Literal
+	if !typed {
+		return param
+	}
+
+	if !p._expectPeek(lexer.TOKEN_COLON) {
+		return nil
+	}
+	if !p._expectPeek(lexer.TOKEN_IDENT) {
+		return nil
+	}
+	typ, ok := p._parseType()
+	if !ok {
+		return nil
+	}
+	param.Type = typ
+	if p._peekTokenIs(lexer.TOKEN_ASSIGN) {
+		p._nextToken()
+		p._nextToken()
+		if param.Value, ok = p._parseValue(); !ok {
+			return nil
+		}
+		param.Required = false
+	}
+	return param
+}
+
+// _parseType parses the type name at the current identifier and its parameters in angle brackets, as in
+// LIST<MAP<STRING, INT>>. The type is returned without spaces.
+func (p *Parser) _parseType() (string, bool) {
+	name := p.curToken.Literal
+	if !p._peekTokenIs(lexer.TOKEN_LT) {
+		return name, true
+	}
+	p._nextToken()
+
+	var parameters []string
+	for {
+		if !p._expectPeek(lexer.TOKEN_IDENT) {
+			return "", false
+		}
+		parameter, ok := p._parseType()
+		if !ok {
+			return "", false
+		}
+		parameters = append(parameters, parameter)
+		if !p._peekTokenIs(lexer.TOKEN_COMMA) {
+			break
+		}
+		p._nextToken()
+	}
+	if !p._expectPeek(lexer.TOKEN_GT) {
+		return "", false
+	}
+	return name + "<" + strings.Join(parameters, ",") + ">", true
+}
+
+// _parseValue parses the value at the current token. A list literal [1, 2] or a map literal {"a": 1}
+// is returned as JSON, any other token as its literal.
+func (p *Parser) _parseValue() (string, bool) {
+	if !p._curTokenIs(lexer.TOKEN_LBRACKET) && !p._curTokenIs(lexer.TOKEN_LBRACE) {
+		return p.curToken.Literal, true
+	}
+	var b strings.Builder
+	if !p._parseCollection(&b) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+func _jsonString(s string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// _parseCollection writes the list or map literal at the current token as JSON. Elements are strings,
+// numbers, booleans, colors, null and nested literals; a trailing comma is allowed.
+func (p *Parser) _parseCollection(b *strings.Builder) bool {
+	isMap := p._curTokenIs(lexer.TOKEN_LBRACE)
+	closing := lexer.TOKEN_RBRACKET
+	if isMap {
+		closing = lexer.TOKEN_RBRACE
+	}
+	b.WriteString(p.curToken.Literal)
+
+	for first := true; !p._peekTokenIs(closing); first = false {
+		if !first {
+			if !p._expectPeek(lexer.TOKEN_COMMA) {
+				return false
+			}
+			if p._peekTokenIs(closing) {
+				break
+			}
+			b.WriteString(", ")
+		}
+		p._nextToken()
+
+		if isMap {
+			if !p._curTokenIs(lexer.TOKEN_STRING) && !p._curTokenIs(lexer.TOKEN_IDENT) {
+				p.errorCollector.AddTokenError("Expected a string key in map literal", p.curToken,
+					"Use format: {\"key\": value}")
+				return false
+			}
+			b.WriteString(_jsonString(p.curToken.Literal))
+			if !p._expectPeek(lexer.TOKEN_COLON) {
+				return false
+			}
+			b.WriteString(": ")
+			p._nextToken()
+		}
+
+		switch p.curToken.Type {
+		case lexer.TOKEN_LBRACKET, lexer.TOKEN_LBRACE:
+			if !p._parseCollection(b) {
+				return false
+			}
+		case lexer.TOKEN_STRING, lexer.TOKEN_COLOR:
+			b.WriteString(_jsonString(p.curToken.Literal))
+		case lexer.TOKEN_INT, lexer.TOKEN_LONG, lexer.TOKEN_FLOAT, lexer.TOKEN_DOUBLE, lexer.TOKEN_BOOLEAN:
+			b.WriteString(p.curToken.Literal)
+		default:
+			if p.curToken.Literal != "null" {
+				p.errorCollector.AddTokenError(
+					fmt.Sprintf("Unexpected '%s' in collection literal", p.curToken.Literal),
+					p.curToken,
+					"Elements are strings, numbers, booleans, colors, null, lists or maps",
+				)
+				return false
+			}
+			b.WriteString("null")
+		}
+	}
+
+	p._nextToken()
+	b.WriteString(p.curToken.Literal)
+	return true
+}
+
 func (p *Parser) _parseAction() model.ActionDSLModel {
 	actionLine, actionColumn := p.curToken.Line, p.curToken.Column
 
@@ -690,18 +1275,56 @@ func (p *Parser) _parseAction() model.ActionDSLModel {
 	p._nextToken() // move to first token inside the action block
 
 	for !p._curTokenIs(lexer.TOKEN_RBRACE) && !p._curTokenIs(lexer.TOKEN_EOF) {
-		if p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "trigger" {
-			trigger := p._parseTrigger("NEXT")
-			if trigger != nil {
-				action.Triggers = append(action.Triggers, *trigger)
-				action.Triggers = _enforceSliceCap(action.Triggers)
-			}
-		}
+		p._parseStatement(&action.Triggers, "NEXT")
 		p._nextToken()
 	}
 	return action
 }
 
+// _parseSequence parses a trigger sequence declaration: a name and the triggers that every call of the
+// sequence runs.
+func (p *Parser) _parseSequence() *model.SequenceDSLModel {
+	sequence := &model.SequenceDSLModel{
+		Triggers: make([]model.ActionTriggerDSLModel, 0),
+		Line:     p.curToken.Line,
+		Column:   p.curToken.Column,
+	}
+
+	if !p._expectPeek(lexer.TOKEN_LPAREN) {
+		return nil
+	}
+
+	sequenceAttrs := p._parseKeyValuePairs()
+	sequence.Name = sequenceAttrs["name"]
+	for key := range sequenceAttrs {
+		if key != "name" {
+			p.errorCollector.AddError(errors.UnknownAttributeError(
+				key, "sequence", sequence.Line, sequence.Column, []string{"name"},
+			))
+		}
+	}
+	if sequence.Name == "" {
+		p.errorCollector.AddSimpleError("Trigger sequence is missing required 'name' attribute", sequence.Line, sequence.Column)
+	}
+
+	if !p._expectPeek(lexer.TOKEN_LBRACE) {
+		return nil
+	}
+	p._nextToken() // move to first token inside the sequence
+
+	for !p._curTokenIs(lexer.TOKEN_RBRACE) && !p._curTokenIs(lexer.TOKEN_EOF) {
+		if !p._parseStatement(&sequence.Triggers, "NEXT") {
+			p.errorCollector.AddTokenError(
+				fmt.Sprintf("Unexpected token '%s' in sequence body", p.curToken.Literal),
+				p.curToken,
+				"Expected 'trigger' declaration or 'if'",
+			)
+		}
+		p._nextToken()
+	}
+	return sequence
+}
+
 func (p *Parser) _parseTrigger(defaultThen string) *model.ActionTriggerDSLModel {
 	triggerLine, triggerColumn := p.curToken.Line, p.curToken.Column
 
@@ -727,10 +1350,11 @@ func (p *Parser) _parseTrigger(defaultThen string) *model.ActionTriggerDSLModel
 	if version, ok := triggerAttrs["version"]; ok {
 		trigger.IntegrationVersion, _ = strconv.Atoi(version)
 	}
+	trigger.Sequence = triggerAttrs["sequence"]
 
 	for key := range triggerAttrs {
-		if key != "keyType" && key != "name" && key != "then" && key != "version" {
-			validAttrs := []string{"keyType", "name", "then", "version"}
+		if key != "keyType" && key != "name" && key != "then" && key != "version" && key != "sequence" {
+			validAttrs := []string{"keyType", "name", "then", "version", "sequence"}
 			p.errorCollector.AddError(errors.UnknownAttributeError(
 				key, "trigger", trigger.Line, trigger.Column, validAttrs,
 			))
@@ -739,6 +1363,11 @@ func (p *Parser) _parseTrigger(defaultThen string) *model.ActionTriggerDSLModel
 
 	for p._peekTokenIs(lexer.TOKEN_DOT) {
 		p._nextToken()
+		if p._peekTokenIs(lexer.TOKEN_IDENT) && p.peekToken.Literal == "when" {
+			p._nextToken()
+			trigger.Condition, _ = p._parseCondition()
+			continue
+		}
 		if p._expectPeek(lexer.TOKEN_KEYWORD) {
 			switch p.curToken.Literal {
 			case "data":
@@ -757,6 +1386,125 @@ func (p *Parser) _parseTrigger(defaultThen string) *model.ActionTriggerDSLModel
 	return trigger
 }
 
+// _parseStatement parses the trigger or if/else at the current token into triggers, reporting whether the
+// token starts one.
+func (p *Parser) _parseStatement(triggers *[]model.ActionTriggerDSLModel, defaultThen string) bool {
+	var trigger *model.ActionTriggerDSLModel
+	switch {
+	case p._curTokenIs(lexer.TOKEN_KEYWORD) && p.curToken.Literal == "trigger":
+		trigger = p._parseTrigger(defaultThen)
+	case p._curTokenIs(lexer.TOKEN_IDENT) && p.curToken.Literal == "if":
+		trigger = p._parseIf(defaultThen)
+	default:
+		return false
+	}
+	if trigger != nil {
+		*triggers = append(*triggers, *trigger)
+		*triggers = _enforceSliceCap(*triggers)
+	}
+	return true
+}
+
+// _parseIf parses "if (condition) { ... }" with an optional "else { ... }" or "else if", into a trigger of
+// model.ConditionKeyType whose SUCCESS triggers are the if branch and FAILURE triggers the else branch.
+func (p *Parser) _parseIf(defaultThen string) *model.ActionTriggerDSLModel {
+	trigger := &model.ActionTriggerDSLModel{
+		KeyType:    model.ConditionKeyType,
+		Then:       defaultThen,
+		Properties: make([]model.TriggerPropertyDSLModel, 0),
+		Data:       make([]model.TriggerDataDSLModel, 0),
+		Triggers:   make([]model.ActionTriggerDSLModel, 0),
+		Line:       p.curToken.Line,
+		Column:     p.curToken.Column,
+	}
+
+	condition, ok := p._parseCondition()
+	if !ok {
+		return nil
+	}
+	trigger.Condition = condition
+	if !p._parseBranch(trigger, "SUCCESS") {
+		return nil
+	}
+
+	if p._peekTokenIs(lexer.TOKEN_IDENT) && p.peekToken.Literal == "else" {
+		p._nextToken()
+		if p._peekTokenIs(lexer.TOKEN_IDENT) && p.peekToken.Literal == "if" {
+			p._nextToken()
+			p._parseStatement(&trigger.Triggers, "FAILURE")
+		} else {
+			p._parseBranch(trigger, "FAILURE")
+		}
+	}
+	return trigger
+}
+
+// _parseBranch parses a braced branch of an if/else into the nested triggers of trigger, with then as
+// their default then value.
+func (p *Parser) _parseBranch(trigger *model.ActionTriggerDSLModel, then string) bool {
+	if !p._expectPeek(lexer.TOKEN_LBRACE) {
+		return false
+	}
+	p._nextToken() // move to first token inside the branch
+
+	for !p._curTokenIs(lexer.TOKEN_RBRACE) && !p._curTokenIs(lexer.TOKEN_EOF) {
+		p._parseStatement(&trigger.Triggers, then)
+		p._nextToken()
+	}
+	return true
+}
+
+// _parseCondition parses the parenthesized condition after when or if, such as (count > 0 && !loading),
+// and returns its text. The condition is an expression over the variables and may span lines. It
+// reports false when the parentheses are missing or unbalanced.
+func (p *Parser) _parseCondition() (string, bool) {
+	keyword := p.curToken
+	if !p._expectPeek(lexer.TOKEN_LPAREN) {
+		return "", false
+	}
+
+	text, endLine, endColumn := p.l.Enclosed(p.curToken.Line, p.curToken.Column)
+	if endLine == 0 {
+		p.errorCollector.AddTokenError(
+			fmt.Sprintf("Unclosed condition of '%s'", keyword.Literal),
+			p.curToken,
+			"Close the condition with ')'",
+		)
+		return "", false
+	}
+	for !p._peekTokenIs(lexer.TOKEN_EOF) && _tokenBefore(p.peekToken, endLine, endColumn) {
+		p._nextToken()
+	}
+	if !p._expectPeek(lexer.TOKEN_RPAREN) {
+		return "", false
+	}
+
+	if strings.TrimSpace(text) == "" {
+		p.errorCollector.AddSimpleError(
+			fmt.Sprintf("'%s' requires a condition", keyword.Literal), keyword.Line, keyword.Column,
+		)
+	}
+	return strings.TrimSpace(text), true
+}
+
+// _tokenBefore reports whether a token starts before line and column. Identifiers are positioned after
+// their last character, so an identifier that ends right before the position counts too.
+func _tokenBefore(token lexer.Token, line, column int) bool {
+	if token.Line != line {
+		return token.Line < line
+	}
+	return token.Column < column || token.Type == lexer.TOKEN_IDENT && token.Column == column
+}
+
+// _valuePosition returns the position of the first character of the current value token, inside the
+// quotes of a string.
+func (p *Parser) _valuePosition() (int, int) {
+	if p._curTokenIs(lexer.TOKEN_STRING) {
+		return p.curToken.Line, p.curToken.Column + 1
+	}
+	return p.curToken.Line, p.curToken.Column
+}
+
 func (p *Parser) _inferTypeFromToken(token lexer.Token) types.Type {
 	switch token.Type {
 	case lexer.TOKEN_STRING:
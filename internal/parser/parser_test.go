@@ -1,9 +1,11 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
 )
 
 func TestParser_FrameOnly(t *testing.T) {
@@ -32,6 +34,26 @@ frame(
 	}
 }
 
+func TestParser_StarterFrame(t *testing.T) {
+	input := `frame(name = "home", route = "/home", starter = true) {}`
+
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+	if !frame.Starter {
+		t.Errorf("Expected frame to be the starter")
+	}
+
+	input = `frame(name = "home", route = "/home", starter = "yes") {}`
+	p = NewParser(lexer.NewLexer(input), input)
+	p.ParseNBX()
+	if !p.ErrorCollector().HasErrors() || p.ErrorCollector().Errors()[0].Message != "Invalid value 'yes' for frame attribute 'starter'" {
+		t.Errorf("Expected an invalid starter error, got %v", p.ErrorCollector().FormatAll())
+	}
+}
+
 func TestParser_FrameWithVariables(t *testing.T) {
 	input := `
 frame(
@@ -330,6 +352,398 @@ frame(
 	}
 }
 
+func TestParser_CollectionTypes(t *testing.T) {
+	input := `frame(name = "catalog", route = "/catalog") {
+    var brand: COLOR = #2563EB
+    var tags: LIST<STRING> = ["new", "sale",]
+    var prices: MAP<STRING, LIST<DOUBLE>> = {"eur": [9.99], usd: []}
+    var payload: JSON = {"items": [{"id": 1, "gift": true, "note": null}]}
+    block(keyType = "ROOT", key = "root")
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+
+	expected := [][2]string{
+		{"COLOR", "#2563EB"},
+		{"LIST<STRING>", `["new", "sale"]`},
+		{"MAP<STRING,LIST<DOUBLE>>", `{"eur": [9.99], "usd": []}`},
+		{"JSON", `{"items": [{"id": 1, "gift": true, "note": null}]}`},
+	}
+	for i, variable := range frame.Variables {
+		if variable.Type != expected[i][0] || variable.Value != expected[i][1] {
+			t.Errorf("Expected %s to be %s = %s, got %s = %s", variable.Key, expected[i][0], expected[i][1], variable.Type, variable.Value)
+		}
+	}
+
+	for input, message := range map[string]string{
+		`frame(name = "a", route = "/a") { var tags: LIST<STRING = [] }`:         "Expected '>', but got '='",
+		`frame(name = "a", route = "/a") { var tags: LIST<STRING> = [1 2] }`:     "Expected ',', but got '2'",
+		`frame(name = "a", route = "/a") { var tags: MAP<STRING,INT> = {1: 2} }`: "Expected a string key in map literal",
+		`frame(name = "a", route = "/a") { var tags: LIST<STRING> = [title] }`:   "Unexpected 'title' in collection literal",
+	} {
+		p := NewParser(lexer.NewLexer(input), input)
+		p.ParseNBX()
+		if !p.ErrorCollector().HasErrors() || p.ErrorCollector().Errors()[0].Message != message {
+			t.Errorf("Expected %q for %s, got %v", message, input, p.ErrorCollector().FormatAll())
+		}
+	}
+}
+
+func TestParser_Enums(t *testing.T) {
+	input := `frame(name = "loader", route = "/loader") {
+    enum Status { IDLE, LOADING, ERROR, }
+    var status: Status = IDLE
+    var history: LIST<Status> = ["IDLE"]
+    block(keyType = "ROOT", key = "root")
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+
+	if len(frame.Enums) != 1 || frame.Enums[0].Name != "Status" || strings.Join(frame.Enums[0].Members, ",") != "IDLE,LOADING,ERROR" {
+		t.Fatalf("Expected the Status enum, got %+v", frame.Enums)
+	}
+	if frame.Enums[0].Line != 2 {
+		t.Errorf("Expected the enum at line 2, got %d", frame.Enums[0].Line)
+	}
+	if frame.Variables[0].Type != "Status" || frame.Variables[0].Value != "IDLE" || frame.Variables[1].Type != "LIST<Status>" {
+		t.Errorf("Unexpected variables: %+v", frame.Variables)
+	}
+
+	for input, message := range map[string]string{
+		`frame(name = "a", route = "/a") { enum { IDLE } }`:                "Expected an identifier, but got '{'",
+		`frame(name = "a", route = "/a") { enum Status { IDLE LOADING } }`: "Expected '}', but got 'LOADING'",
+		`frame(name = "a", route = "/a") { enum Status { "IDLE" } }`:       "Expected an identifier, but got 'IDLE'",
+	} {
+		p := NewParser(lexer.NewLexer(input), input)
+		p.ParseNBX()
+		if !p.ErrorCollector().HasErrors() || p.ErrorCollector().Errors()[0].Message != message {
+			t.Errorf("Expected %q for %s, got %v", message, input, p.ErrorCollector().FormatAll())
+		}
+	}
+}
+
+func TestParser_Repeat(t *testing.T) {
+	input := `frame(name = "shop", route = "/shop") {
+    var products: LIST<MAP<STRING, STRING>> = [{"name": "Tea"}]
+    block(keyType = "nativeblocks/column", key = "list")
+        .repeat(items = products, as = product)
+        .data(title = product.name, label = "Buy {product.name}")
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+
+	block := frame.Blocks[0]
+	if block.Repeat == nil || block.Repeat.Items != "products" || block.Repeat.As != "product" || block.Repeat.Line != 4 {
+		t.Fatalf("Expected the repeat at line 4, got %+v", block.Repeat)
+	}
+	if block.Data[0].Value != "product.name" || block.Data[1].Value != "Buy {product.name}" {
+		t.Errorf("Expected field path bindings, got %+v", block.Data)
+	}
+
+	for input, message := range map[string]string{
+		`frame(name = "a", route = "/a") { block(keyType = "ROOT", key = "root").repeat(items = list) }`:                                      "Repeat requires 'items' and 'as' attributes",
+		`frame(name = "a", route = "/a") { block(keyType = "ROOT", key = "root").repeat(items = list, as = x).repeat(items = list, as = y) }`: "Block 'root' has more than one repeat",
+	} {
+		p := NewParser(lexer.NewLexer(input), input)
+		p.ParseNBX()
+		if !p.ErrorCollector().HasErrors() || p.ErrorCollector().Errors()[0].Message != message {
+			t.Errorf("Expected %q for %s, got %v", message, input, p.ErrorCollector().FormatAll())
+		}
+	}
+}
+
+func TestParser_RepeatAsName(t *testing.T) {
+	input := `frame(name = "shop", route = "/shop") {
+    var repeat: LIST<STRING> = ["a"]
+    block(keyType = "nativeblocks/column", key = "list", visibility = repeat)
+        .prop(repeat = "x")
+        .repeat(items = repeat, as = item)
+        .data(text = repeat)
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected 'repeat' to be usable as a name: %v", p.ErrorCollector().FormatAll())
+	}
+
+	block := frame.Blocks[0]
+	if frame.Variables[0].Key != "repeat" || block.VisibilityKey != "repeat" {
+		t.Errorf("Expected the variable 'repeat' as visibility, got %+v", block)
+	}
+	if block.Properties[0].Key != "repeat" || block.Data[0].Value != "repeat" {
+		t.Errorf("Expected 'repeat' as prop key and data binding, got %+v and %+v", block.Properties, block.Data)
+	}
+	if block.Repeat == nil || block.Repeat.Items != "repeat" || block.Repeat.As != "item" {
+		t.Errorf("Expected the repeat modifier over 'repeat', got %+v", block.Repeat)
+	}
+}
+
+func TestParser_Computed(t *testing.T) {
+	input := `frame(name = "cart", route = "/cart") {
+    var price: INT = 3
+    var quantity: INT = 2
+    val total: INT = price * quantity // recomputed when either changes
+    val summary: STRING = $("Total: " +
+        total)
+    block(keyType = "ROOT", key = "root")
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+	if len(frame.Variables) != 4 || len(frame.Blocks) != 1 {
+		t.Fatalf("Expected 4 variables and 1 block, got %d and %d", len(frame.Variables), len(frame.Blocks))
+	}
+
+	total, summary := frame.Variables[2], frame.Variables[3]
+	if !total.Computed || total.Type != "INT" || total.Value != "price * quantity" || total.Line != 4 {
+		t.Errorf("Expected the computed variable 'total' at line 4, got %+v", total)
+	}
+	if !summary.Computed || summary.Value != "\"Total: \" +\n        total" {
+		t.Errorf("Expected the wrapped expression of 'summary', got %+v", summary)
+	}
+	if frame.Variables[0].Computed {
+		t.Errorf("Expected 'price' not to be computed")
+	}
+
+	input = `frame(name = "a", route = "/a") {
+    val total: INT =
+    block(keyType = "ROOT", key = "root")
+}`
+	p = NewParser(lexer.NewLexer(input), input)
+	p.ParseNBX()
+	if !p.ErrorCollector().HasErrors() || p.ErrorCollector().Errors()[0].Message != "Computed variable 'total' requires an expression" {
+		t.Errorf("Expected a missing expression error, got %v", p.ErrorCollector().FormatAll())
+	}
+}
+
+func TestParser_FrameActions(t *testing.T) {
+	input := `frame(name = "feed", route = "/feed") {
+    var status: STRING = "idle"
+    action(event = "onLoad") {
+        trigger(keyType = "nativeblocks/change_variable", name = "load")
+            .data(variableKey = status)
+    }
+    block(keyType = "ROOT", key = "root")
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+	if len(frame.Actions) != 1 || len(frame.Blocks) != 1 {
+		t.Fatalf("Expected 1 frame action and 1 block, got %d and %d", len(frame.Actions), len(frame.Blocks))
+	}
+	action := frame.Actions[0]
+	if action.Event != "onLoad" || action.Key != "" || action.Line != 3 {
+		t.Errorf("Expected the frame action 'onLoad' at line 3, got %+v", action)
+	}
+	if len(action.Triggers) != 1 || action.Triggers[0].Name != "load" {
+		t.Errorf("Expected the 'load' trigger, got %+v", action.Triggers)
+	}
+	if len(frame.Blocks[0].Actions) != 0 {
+		t.Errorf("Expected no actions on the ROOT block, got %+v", frame.Blocks[0].Actions)
+	}
+}
+
+func TestParser_Sequences(t *testing.T) {
+	input := `frame(name = "form", route = "/form") {
+    var status: STRING = "idle"
+    sequence(name = "validate") {
+        trigger(keyType = "nativeblocks/change_variable", name = "check")
+            .data(variableKey = status)
+    }
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(sequence = "validate")
+        }
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+	if len(frame.Sequences) != 1 {
+		t.Fatalf("Expected 1 sequence, got %d", len(frame.Sequences))
+	}
+	sequence := frame.Sequences[0]
+	if sequence.Name != "validate" || sequence.Line != 3 || len(sequence.Triggers) != 1 || sequence.Triggers[0].Name != "check" {
+		t.Errorf("Expected the sequence 'validate' at line 3 with the 'check' trigger, got %+v", sequence)
+	}
+	call := frame.Blocks[0].Actions[0].Triggers[0]
+	if call.Sequence != "validate" || call.KeyType != "" || call.Then != "NEXT" {
+		t.Errorf("Expected a call of 'validate', got %+v", call)
+	}
+
+	input = `frame(name = "a", route = "/a") {
+    sequence(title = "validate") {
+        block
+    }
+}`
+	p = NewParser(lexer.NewLexer(input), input)
+	p.ParseNBX()
+	var messages []string
+	for _, err := range p.ErrorCollector().Errors() {
+		messages = append(messages, err.Message)
+	}
+	expected := []string{
+		"Unknown attribute 'title' in sequence",
+		"Trigger sequence is missing required 'name' attribute",
+		"Unexpected token 'block' in sequence body",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestParser_Conditions(t *testing.T) {
+	input := `frame(name = "cart", route = "/cart") {
+    var count: INT = 0
+    var label: STRING = "("
+    sequence(name = "reset") {
+        if (count > 0) {
+            trigger(keyType = "nativeblocks/change_variable", name = "clear")
+        }
+    }
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(keyType = "nativeblocks/change_variable", name = "increase")
+                .when(count < 10 &&
+                      label != ")")
+            if (count == 0) {
+                trigger(keyType = "nativeblocks/change_variable", name = "first")
+            } else if (count > (5)) {
+                trigger(keyType = "nativeblocks/change_variable", name = "many")
+            } else {
+                trigger(keyType = "nativeblocks/change_variable", name = "some")
+                trigger(sequence = "reset").when(label == "")
+            }
+        }
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+
+	triggers := frame.Blocks[0].Actions[0].Triggers
+	if len(triggers) != 2 {
+		t.Fatalf("Expected a trigger and an if, got %+v", triggers)
+	}
+	if triggers[0].Name != "increase" || triggers[0].Condition != "count < 10 &&\n                      label != \")\"" {
+		t.Errorf("Expected the guard of 'increase', got %q", triggers[0].Condition)
+	}
+
+	branch := triggers[1]
+	if branch.KeyType != model.ConditionKeyType || branch.Condition != "count == 0" || branch.Then != "NEXT" || len(branch.Triggers) != 2 {
+		t.Fatalf("Expected an if on count == 0 with two branches, got %+v", branch)
+	}
+	if first := branch.Triggers[0]; first.Name != "first" || first.Then != "SUCCESS" {
+		t.Errorf("Expected 'first' in the if branch, got %+v", first)
+	}
+	elseIf := branch.Triggers[1]
+	if elseIf.KeyType != model.ConditionKeyType || elseIf.Condition != "count > (5)" || elseIf.Then != "FAILURE" || len(elseIf.Triggers) != 3 {
+		t.Fatalf("Expected else if on count > (5), got %+v", elseIf)
+	}
+	for i, want := range []struct{ name, then string }{{"many", "SUCCESS"}, {"some", "FAILURE"}, {"", "FAILURE"}} {
+		if got := elseIf.Triggers[i]; got.Name != want.name || got.Then != want.then {
+			t.Errorf("Trigger %d: expected %s on %s, got %+v", i, want.name, want.then, got)
+		}
+	}
+	if call := elseIf.Triggers[2]; call.Sequence != "reset" || call.Condition != `label == ""` {
+		t.Errorf("Expected a guarded call of 'reset', got %+v", call)
+	}
+	if sequence := frame.Sequences[0]; len(sequence.Triggers) != 1 || sequence.Triggers[0].KeyType != model.ConditionKeyType {
+		t.Errorf("Expected an if in the sequence, got %+v", sequence.Triggers)
+	}
+
+	input = `frame(name = "a", route = "/a") {
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            if () {
+            }
+            trigger(keyType = "nativeblocks/change_variable", name = "set")
+                .when(count > 0
+        }
+}`
+	p = NewParser(lexer.NewLexer(input), input)
+	p.ParseNBX()
+	var messages []string
+	for _, err := range p.ErrorCollector().Errors() {
+		messages = append(messages, err.Message)
+	}
+	expected := []string{"'if' requires a condition", "Unclosed condition of 'when'"}
+	if len(messages) < 2 || messages[0] != expected[0] || messages[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, messages)
+	}
+}
+
+func TestParser_Styles(t *testing.T) {
+	input := `frame(name = "form", route = "/form") {
+    style rounded {
+        .prop(cornerRadius = "12")
+    }
+    style primaryButton {
+        .style(rounded)
+        .prop(
+            backgroundColor = "#2563EB",
+            padding = (mobile = "8", desktop = "16")
+        )
+    }
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(keyType = "nativeblocks/button", key = "submit")
+        .style(primaryButton, "rounded")
+        .prop(padding = "12")
+    }
+}`
+	p := NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Expected frame to be parsed: %v", p.ErrorCollector().FormatAll())
+	}
+
+	if len(frame.Styles) != 2 {
+		t.Fatalf("Expected 2 styles, got %+v", frame.Styles)
+	}
+	rounded, primary := frame.Styles[0], frame.Styles[1]
+	if rounded.Name != "rounded" || len(rounded.Properties) != 1 || rounded.Line != 2 {
+		t.Errorf("Unexpected style rounded: %+v", rounded)
+	}
+	if primary.Name != "primaryButton" || strings.Join(primary.Styles, ",") != "rounded" || len(primary.Properties) != 2 {
+		t.Fatalf("Unexpected style primaryButton: %+v", primary)
+	}
+	if padding := primary.Properties[1]; padding.ValueMobile != "8" || padding.ValueTablet != "" || padding.ValueDesktop != "16" {
+		t.Errorf("Expected per-device padding, got %+v", padding)
+	}
+
+	submit := frame.Blocks[0].Blocks[0]
+	if strings.Join(submit.Styles, ",") != "primaryButton,rounded" || len(submit.Properties) != 1 {
+		t.Errorf("Expected styles and own props on submit, got %+v", submit)
+	}
+
+	input = `frame(name = "form", route = "/form") {
+    style broken {
+        trigger(keyType = "x")
+    }
+}`
+	p = NewParser(lexer.NewLexer(input), input)
+	p.ParseNBX()
+	if !strings.Contains(p.ErrorCollector().FormatAll(), "Unexpected token 'trigger' in style body") {
+		t.Errorf("Expected style body error, got %v", p.ErrorCollector().FormatAll())
+	}
+}
+
 func TestParser_ComplexFrame(t *testing.T) {
 	input := `
 frame(
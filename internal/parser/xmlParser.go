@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/expr"
 	"github.com/nativeblocks/nbx/internal/model"
 	"github.com/nativeblocks/nbx/internal/types"
 )
@@ -47,6 +48,18 @@ func ParseXML(xmlString string) (model.FrameDSLModel, []*errors.Error) {
 		})
 	}
 
+	for _, xc := range xmlFrame.Components {
+		if len(xc.Blocks) != 1 {
+			pos := posTracker.FindElementPosition("component", xc.Name)
+			errorCollector.AddError(&errors.Error{
+				Severity: errors.SeverityError,
+				Message:  fmt.Sprintf("Component '%s' must have a single root block", xc.Name),
+				Line:     pos.Line,
+				Column:   pos.Column,
+			})
+		}
+	}
+
 	if xmlFrame.Route == "" {
 		errorCollector.AddError(&errors.Error{
 			Severity: errors.SeverityError,
@@ -72,6 +85,7 @@ func _toFrameDSLModel(xf model.XMLFrame, tracker *PositionTracker) model.FrameDS
 		Name:      xf.Name,
 		Route:     xf.Route,
 		Type:      xf.Type,
+		Starter:   xf.Starter,
 		Variables: make([]model.VariableDSLModel, 0, len(xf.Variables)),
 		Blocks:    make([]model.BlockDSLModel, 0, len(xf.Blocks)),
 		Line:      pos.Line,
@@ -82,22 +96,260 @@ func _toFrameDSLModel(xf model.XMLFrame, tracker *PositionTracker) model.FrameDS
 		frame.Type = "FRAME"
 	}
 
-	for _, xv := range xf.Variables {
+	frame.Imports = _toImportDSLModels(xf.Imports, tracker)
+	frame.Constants = _toConstantDSLModels(xf.Constants, tracker)
+	frame.Enums = _toEnumDSLModels(xf.Enums, tracker)
+	frame.Variables = append(frame.Variables, _toVariableDSLModels(xf.Variables, tracker)...)
+	frame.Variables = append(frame.Variables, _toComputedDSLModels(xf.Computed, tracker)...)
+	if len(xf.Actions) > 0 {
+		frame.Actions = _toActionDSLModels(xf.Actions, "", tracker)
+	}
+	for _, xs := range xf.Sequences {
+		frame.Sequences = append(frame.Sequences, _toSequenceDSLModel(xs, tracker))
+	}
+	frame.Styles = _toStyleDSLModels(xf.Styles, tracker)
+
+	for _, xc := range xf.Components {
+		frame.Components = append(frame.Components, _toComponentDSLModel(xc, tracker))
+	}
+
+	for _, xb := range xf.Blocks {
+		frame.Blocks = append(frame.Blocks, _toBlockDSLModel(xb, tracker))
+	}
+
+	return frame
+}
+
+// ParseXMLLibrary parses an XML library: imports, constants, variables, styles and components shared by frames,
+// inside a <library> element.
+func ParseXMLLibrary(xmlString string) (model.LibraryDSLModel, []*errors.Error) {
+	posTracker := NewPositionTracker(xmlString)
+
+	var xmlLibrary model.XMLLibrary
+	if err := xml.NewDecoder(strings.NewReader(xmlString)).Decode(&xmlLibrary); err != nil {
+		return model.LibraryDSLModel{}, []*errors.Error{{
+			Severity: errors.SeverityError,
+			Message:  fmt.Sprintf("Failed to parse XML: %v", err),
+		}}
+	}
+
+	pos := posTracker.FindElementPosition("library", "")
+	library := model.LibraryDSLModel{
+		Imports:    _toImportDSLModels(xmlLibrary.Imports, posTracker),
+		Constants:  _toConstantDSLModels(xmlLibrary.Constants, posTracker),
+		Enums:      _toEnumDSLModels(xmlLibrary.Enums, posTracker),
+		Variables:  append(_toVariableDSLModels(xmlLibrary.Variables, posTracker), _toComputedDSLModels(xmlLibrary.Computed, posTracker)...),
+		Styles:     _toStyleDSLModels(xmlLibrary.Styles, posTracker),
+		Components: make([]model.ComponentDSLModel, 0, len(xmlLibrary.Components)),
+		Line:       pos.Line,
+		Column:     pos.Column,
+	}
+
+	var errs []*errors.Error
+	for _, xc := range xmlLibrary.Components {
+		if len(xc.Blocks) != 1 {
+			componentPos := posTracker.FindElementPosition("component", xc.Name)
+			errs = append(errs, &errors.Error{
+				Severity: errors.SeverityError,
+				Message:  fmt.Sprintf("Component '%s' must have a single root block", xc.Name),
+				Line:     componentPos.Line,
+				Column:   componentPos.Column,
+			})
+		}
+		library.Components = append(library.Components, _toComponentDSLModel(xc, posTracker))
+	}
+
+	return library, errs
+}
+
+func _toImportDSLModels(xis []model.XMLImport, tracker *PositionTracker) []model.ImportDSLModel {
+	imports := make([]model.ImportDSLModel, 0, len(xis))
+	for _, xi := range xis {
+		pos := tracker.FindElementPosition("import", xi.Src)
+		imports = append(imports, model.ImportDSLModel{Path: xi.Src, Line: pos.Line, Column: pos.Column})
+	}
+	return imports
+}
+
+func _toConstantDSLModels(xcs []model.XMLVariable, tracker *PositionTracker) []model.ConstantDSLModel {
+	constants := make([]model.ConstantDSLModel, 0, len(xcs))
+	for _, xc := range xcs {
+		pos := tracker.FindElementPosition("const", xc.Key)
+		constants = append(constants, model.ConstantDSLModel{
+			Key:    xc.Key,
+			Type:   _xmlTypeName(xc.Type),
+			Value:  xc.Value,
+			Line:   pos.Line,
+			Column: pos.Column,
+		})
+	}
+	return constants
+}
+
+// _toEnumDSLModels converts <enum name="Status" members="IDLE, LOADING" /> declarations.
+func _toEnumDSLModels(xes []model.XMLEnum, tracker *PositionTracker) []model.EnumDSLModel {
+	var enums []model.EnumDSLModel
+	for _, xe := range xes {
+		pos := tracker.FindElementPosition("enum", xe.Name)
+		enum := model.EnumDSLModel{Name: xe.Name, Members: make([]string, 0), Line: pos.Line, Column: pos.Column}
+		for _, member := range strings.Split(xe.Members, ",") {
+			if member = strings.TrimSpace(member); member != "" {
+				enum.Members = append(enum.Members, member)
+			}
+		}
+		enums = append(enums, enum)
+	}
+	return enums
+}
+
+// _xmlTypeName returns a type attribute as a type name: built-in types are case-insensitive, declared
+// types such as enums keep their case.
+func _xmlTypeName(typeName string) string {
+	if _, err := types.FromString(typeName); err != nil {
+		return typeName
+	}
+	return strings.ToUpper(typeName)
+}
+
+func _toVariableDSLModels(xvs []model.XMLVariable, tracker *PositionTracker) []model.VariableDSLModel {
+	variables := make([]model.VariableDSLModel, 0, len(xvs))
+	for _, xv := range xvs {
 		varPos := tracker.FindElementPosition("var", xv.Key)
-		frame.Variables = append(frame.Variables, model.VariableDSLModel{
+		variables = append(variables, model.VariableDSLModel{
 			Key:    xv.Key,
-			Type:   strings.ToUpper(xv.Type),
+			Type:   _xmlTypeName(xv.Type),
 			Value:  xv.Value,
 			Line:   varPos.Line,
 			Column: varPos.Column,
 		})
 	}
+	return variables
+}
 
-	for _, xb := range xf.Blocks {
-		frame.Blocks = append(frame.Blocks, _toBlockDSLModel(xb, tracker))
+// _toComputedDSLModels converts <val> elements, whose value is the expression of a computed variable,
+// written with or without $( ).
+func _toComputedDSLModels(xvs []model.XMLVariable, tracker *PositionTracker) []model.VariableDSLModel {
+	variables := make([]model.VariableDSLModel, 0, len(xvs))
+	for _, xv := range xvs {
+		valPos := tracker.FindElementPosition("val", xv.Key)
+		value := strings.TrimSpace(xv.Value)
+		if source, ok := expr.Unwrap(value); ok {
+			value = strings.TrimSpace(source)
+		}
+		variables = append(variables, model.VariableDSLModel{
+			Key:      xv.Key,
+			Type:     _xmlTypeName(xv.Type),
+			Value:    value,
+			Computed: true,
+			Line:     valPos.Line,
+			Column:   valPos.Column,
+		})
 	}
+	return variables
+}
 
-	return frame
+func _toComponentDSLModel(xc model.XMLComponent, tracker *PositionTracker) model.ComponentDSLModel {
+	pos := tracker.FindElementPosition("component", xc.Name)
+
+	component := model.ComponentDSLModel{
+		Name:       xc.Name,
+		Properties: make([]model.ComponentParamDSLModel, 0, len(xc.Properties)),
+		Data:       make([]model.ComponentParamDSLModel, 0, len(xc.Data)),
+		Line:       pos.Line,
+		Column:     pos.Column,
+	}
+
+	for _, xp := range xc.Properties {
+		paramPos := tracker.FindElementPosition("prop", xp.Key)
+		param := model.ComponentParamDSLModel{
+			Key:      xp.Key,
+			Type:     _xmlTypeName(xp.Type),
+			Required: xp.Value == nil,
+			Line:     paramPos.Line,
+			Column:   paramPos.Column,
+		}
+		if xp.Value != nil {
+			param.Value = *xp.Value
+		}
+		component.Properties = append(component.Properties, param)
+	}
+
+	for _, xd := range xc.Data {
+		paramPos := tracker.FindElementPosition("data", xd.Key)
+		component.Data = append(component.Data, model.ComponentParamDSLModel{
+			Key:      xd.Key,
+			Required: true,
+			Line:     paramPos.Line,
+			Column:   paramPos.Column,
+		})
+	}
+
+	if len(xc.Blocks) > 0 {
+		component.Block = _toBlockDSLModel(xc.Blocks[0], tracker)
+	}
+
+	return component
+}
+
+// _toStyleDSLModels converts <style> elements.
+func _toStyleDSLModels(xss []model.XMLStyle, tracker *PositionTracker) []model.StyleDSLModel {
+	var styles []model.StyleDSLModel
+	for _, xs := range xss {
+		pos := tracker.FindElementPosition("style", xs.Name)
+		style := model.StyleDSLModel{
+			Name:       xs.Name,
+			Styles:     _styleNames(xs.Style),
+			Properties: make([]model.BlockPropertyDSLModel, 0, len(xs.Properties)),
+			Line:       pos.Line,
+			Column:     pos.Column,
+		}
+		for _, xp := range xs.Properties {
+			style.Properties = append(style.Properties, _toBlockPropertyDSLModel(xp, tracker))
+		}
+		styles = append(styles, style)
+	}
+	return styles
+}
+
+// _styleNames splits a style attribute such as "primaryButton, rounded" into style names.
+func _styleNames(attr string) []string {
+	var names []string
+	for _, name := range strings.FieldsFunc(attr, func(r rune) bool { return r == ',' || r == ' ' }) {
+		names = append(names, name)
+	}
+	return names
+}
+
+func _toBlockPropertyDSLModel(xp model.XMLProperty, tracker *PositionTracker) model.BlockPropertyDSLModel {
+	propPos := tracker.FindElementPosition("prop", xp.Key)
+
+	mobile := xp.Mobile
+	tablet := xp.Tablet
+	desktop := xp.Desktop
+
+	if xp.Value != "" {
+		mobile = xp.Value
+		tablet = xp.Value
+		desktop = xp.Value
+	}
+
+	inferValue := mobile
+	if inferValue == "" {
+		inferValue = tablet
+	}
+	if inferValue == "" {
+		inferValue = desktop
+	}
+
+	return model.BlockPropertyDSLModel{
+		Key:          xp.Key,
+		ValueMobile:  mobile,
+		ValueTablet:  tablet,
+		ValueDesktop: desktop,
+		Type:         types.InferType(inferValue).Name(),
+		Line:         propPos.Line,
+		Column:       propPos.Column,
+	}
 }
 
 func _toBlockDSLModel(xb model.XMLBlock, tracker *PositionTracker) model.BlockDSLModel {
@@ -107,6 +359,9 @@ func _toBlockDSLModel(xb model.XMLBlock, tracker *PositionTracker) model.BlockDS
 		KeyType:            xb.KeyType,
 		Key:                xb.Key,
 		VisibilityKey:      xb.Visibility,
+		Styles:             _styleNames(xb.Style),
+		Component:          xb.Component,
+		Outlet:             xb.Outlet,
 		IntegrationVersion: xb.Version,
 		Properties:         make([]model.BlockPropertyDSLModel, 0),
 		Data:               make([]model.BlockDataDSLModel, 0),
@@ -117,36 +372,18 @@ func _toBlockDSLModel(xb model.XMLBlock, tracker *PositionTracker) model.BlockDS
 		Column:             pos.Column,
 	}
 
-	for _, xp := range xb.Properties {
-		propPos := tracker.FindElementPosition("prop", xp.Key)
-
-		mobile := xp.Mobile
-		tablet := xp.Tablet
-		desktop := xp.Desktop
-
-		if xp.Value != "" {
-			mobile = xp.Value
-			tablet = xp.Value
-			desktop = xp.Value
-		}
-
-		inferValue := mobile
-		if inferValue == "" {
-			inferValue = tablet
-		}
-		if inferValue == "" {
-			inferValue = desktop
+	if xb.Repeat != nil {
+		repeatPos := tracker.FindElementPosition("repeat", xb.Repeat.Items)
+		block.Repeat = &model.RepeatDSLModel{
+			Items:  xb.Repeat.Items,
+			As:     xb.Repeat.As,
+			Line:   repeatPos.Line,
+			Column: repeatPos.Column,
 		}
+	}
 
-		block.Properties = append(block.Properties, model.BlockPropertyDSLModel{
-			Key:          xp.Key,
-			ValueMobile:  mobile,
-			ValueTablet:  tablet,
-			ValueDesktop: desktop,
-			Type:         types.InferType(inferValue).Name(),
-			Line:         propPos.Line,
-			Column:       propPos.Column,
-		})
+	for _, xp := range xb.Properties {
+		block.Properties = append(block.Properties, _toBlockPropertyDSLModel(xp, tracker))
 	}
 
 	for _, xd := range xb.Data {
@@ -175,10 +412,17 @@ func _toBlockDSLModel(xb model.XMLBlock, tracker *PositionTracker) model.BlockDS
 		}
 	}
 
-	for _, xa := range xb.Actions {
+	block.Actions = append(block.Actions, _toActionDSLModels(xb.Actions, xb.Key, tracker)...)
+
+	return block
+}
+
+func _toActionDSLModels(xas []model.XMLAction, key string, tracker *PositionTracker) []model.ActionDSLModel {
+	actions := make([]model.ActionDSLModel, 0, len(xas))
+	for _, xa := range xas {
 		actionPos := tracker.FindElementPosition("action", xa.Event)
 		action := model.ActionDSLModel{
-			Key:      xb.Key,
+			Key:      key,
 			Event:    xa.Event,
 			Triggers: make([]model.ActionTriggerDSLModel, 0),
 			Line:     actionPos.Line,
@@ -189,20 +433,43 @@ func _toBlockDSLModel(xb model.XMLBlock, tracker *PositionTracker) model.BlockDS
 			action.Triggers = append(action.Triggers, _toTriggerDSLModel(xt, tracker, "NEXT"))
 		}
 
-		block.Actions = append(block.Actions, action)
+		actions = append(actions, action)
 	}
+	return actions
+}
 
-	return block
+func _toSequenceDSLModel(xs model.XMLSequence, tracker *PositionTracker) model.SequenceDSLModel {
+	pos := tracker.FindElementPosition("sequence", xs.Name)
+	sequence := model.SequenceDSLModel{
+		Name:     xs.Name,
+		Triggers: make([]model.ActionTriggerDSLModel, 0, len(xs.Triggers)),
+		Line:     pos.Line,
+		Column:   pos.Column,
+	}
+	for _, xt := range xs.Triggers {
+		sequence.Triggers = append(sequence.Triggers, _toTriggerDSLModel(xt, tracker, "NEXT"))
+	}
+	return sequence
 }
 
 func _toTriggerDSLModel(xt model.XMLTrigger, tracker *PositionTracker, defaultThen string) model.ActionTriggerDSLModel {
-	pos := tracker.FindElementPosition("trigger", xt.Name)
+	if xt.If != "" {
+		return _toIfDSLModel(xt, tracker, defaultThen)
+	}
+
+	name := xt.Name
+	if name == "" {
+		name = xt.Sequence
+	}
+	pos := tracker.FindElementPosition("trigger", name)
 
 	trigger := model.ActionTriggerDSLModel{
 		KeyType:            xt.KeyType,
 		Name:               xt.Name,
 		Then:               defaultThen,
 		IntegrationVersion: xt.Version,
+		Sequence:           xt.Sequence,
+		Condition:          strings.TrimSpace(xt.When),
 		Properties:         make([]model.TriggerPropertyDSLModel, 0),
 		Data:               make([]model.TriggerDataDSLModel, 0),
 		Triggers:           make([]model.ActionTriggerDSLModel, 0),
@@ -247,3 +514,34 @@ func _toTriggerDSLModel(xt model.XMLTrigger, tracker *PositionTracker, defaultTh
 
 	return trigger
 }
+
+// _toIfDSLModel converts <trigger if="..."> into a trigger of model.ConditionKeyType, with the triggers of
+// its <then> as the SUCCESS branch and those of its <else> as the FAILURE branch.
+func _toIfDSLModel(xt model.XMLTrigger, tracker *PositionTracker, defaultThen string) model.ActionTriggerDSLModel {
+	pos := tracker.FindElementPosition("trigger", "if=")
+	trigger := model.ActionTriggerDSLModel{
+		KeyType:    model.ConditionKeyType,
+		Then:       defaultThen,
+		Condition:  strings.TrimSpace(xt.If),
+		Properties: make([]model.TriggerPropertyDSLModel, 0),
+		Data:       make([]model.TriggerDataDSLModel, 0),
+		Triggers:   make([]model.ActionTriggerDSLModel, 0),
+		Line:       pos.Line,
+		Column:     pos.Column,
+	}
+	if trigger.Then == "" {
+		trigger.Then = "NEXT"
+	}
+
+	for _, th := range xt.Then {
+		for _, nestedTrigger := range th.Triggers {
+			trigger.Triggers = append(trigger.Triggers, _toTriggerDSLModel(nestedTrigger, tracker, "SUCCESS"))
+		}
+	}
+	if xt.Else != nil {
+		for _, nestedTrigger := range xt.Else.Triggers {
+			trigger.Triggers = append(trigger.Triggers, _toTriggerDSLModel(nestedTrigger, tracker, "FAILURE"))
+		}
+	}
+	return trigger
+}
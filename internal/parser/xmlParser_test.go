@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/nativeblocks/nbx/internal/model"
 )
 
 func TestParseXML_Simple(t *testing.T) {
@@ -294,6 +297,201 @@ func TestParseXML_WithConditionalFlow(t *testing.T) {
 	}
 }
 
+func TestParseXML_Enums(t *testing.T) {
+	xmlInput := `<frame name="loader" route="/loader">
+  <enum name="Status" members="IDLE, LOADING,ERROR" />
+  <var key="status" type="Status" value="IDLE" />
+  <var key="count" type="int" value="0" />
+</frame>`
+
+	frame, errs := ParseXML(xmlInput)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	if len(frame.Enums) != 1 || frame.Enums[0].Name != "Status" || len(frame.Enums[0].Members) != 3 || frame.Enums[0].Members[2] != "ERROR" {
+		t.Fatalf("Expected the Status enum, got %+v", frame.Enums)
+	}
+	if frame.Enums[0].Line != 2 {
+		t.Errorf("Expected the enum at line 2, got %d", frame.Enums[0].Line)
+	}
+	if frame.Variables[0].Type != "Status" || frame.Variables[1].Type != "INT" {
+		t.Errorf("Expected enum type names to keep their case, got %s and %s", frame.Variables[0].Type, frame.Variables[1].Type)
+	}
+}
+
+func TestParseXML_Repeat(t *testing.T) {
+	xmlInput := `<frame name="shop" route="/shop">
+  <var key="products" type="LIST&lt;STRING&gt;" value="[]" />
+  <block keyType="nativeblocks/column" key="list">
+    <repeat items="products" as="product" />
+    <data key="text" value="product" />
+  </block>
+</frame>`
+
+	frame, errs := ParseXML(xmlInput)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	repeat := frame.Blocks[0].Repeat
+	if repeat == nil || repeat.Items != "products" || repeat.As != "product" || repeat.Line != 4 {
+		t.Errorf("Expected the repeat at line 4, got %+v", repeat)
+	}
+}
+
+func TestParseXML_Computed(t *testing.T) {
+	xmlInput := `<frame name="cart" route="/cart">
+  <var key="price" type="INT" value="3" />
+  <val key="total" type="INT" value="price * 2" />
+  <val key="label" type="STRING" value="$('Total: ' + total)" />
+  <block keyType="ROOT" key="root" />
+</frame>`
+
+	frame, errs := ParseXML(xmlInput)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if len(frame.Variables) != 3 {
+		t.Fatalf("Expected 3 variables, got %d", len(frame.Variables))
+	}
+	total, label := frame.Variables[1], frame.Variables[2]
+	if !total.Computed || total.Value != "price * 2" || total.Line != 3 {
+		t.Errorf("Expected the computed variable 'total' at line 3, got %+v", total)
+	}
+	if !label.Computed || label.Value != "'Total: ' + total" {
+		t.Errorf("Expected the unwrapped expression of 'label', got %+v", label)
+	}
+}
+
+func TestParseXML_FrameActions(t *testing.T) {
+	xmlInput := `<frame name="feed" route="/feed">
+  <var key="status" type="STRING" value="idle" />
+  <action event="onResume">
+    <trigger keyType="nativeblocks/change_variable" name="refresh">
+      <data key="variableKey" value="status" />
+    </trigger>
+  </action>
+  <block keyType="ROOT" key="root" />
+</frame>`
+
+	frame, errs := ParseXML(xmlInput)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if len(frame.Actions) != 1 {
+		t.Fatalf("Expected 1 frame action, got %d", len(frame.Actions))
+	}
+	action := frame.Actions[0]
+	if action.Event != "onResume" || action.Key != "" || action.Line != 3 {
+		t.Errorf("Expected the frame action 'onResume' at line 3, got %+v", action)
+	}
+	if len(action.Triggers) != 1 || action.Triggers[0].Data[0].Value != "status" {
+		t.Errorf("Expected the 'refresh' trigger, got %+v", action.Triggers)
+	}
+}
+
+func TestParseXML_Sequences(t *testing.T) {
+	xmlInput := `<frame name="form" route="/form">
+  <var key="status" type="STRING" value="idle" />
+  <sequence name="validate">
+    <trigger keyType="nativeblocks/change_variable" name="check">
+      <data key="variableKey" value="status" />
+    </trigger>
+  </sequence>
+  <block keyType="ROOT" key="root">
+    <action event="onClick">
+      <trigger sequence="validate" />
+    </action>
+  </block>
+</frame>`
+
+	frame, errs := ParseXML(xmlInput)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if len(frame.Sequences) != 1 {
+		t.Fatalf("Expected 1 sequence, got %d", len(frame.Sequences))
+	}
+	sequence := frame.Sequences[0]
+	if sequence.Name != "validate" || sequence.Line != 3 || len(sequence.Triggers) != 1 || sequence.Triggers[0].Name != "check" {
+		t.Errorf("Expected the sequence 'validate' at line 3 with the 'check' trigger, got %+v", sequence)
+	}
+	call := frame.Blocks[0].Actions[0].Triggers[0]
+	if call.Sequence != "validate" || call.Line != 10 {
+		t.Errorf("Expected a call of 'validate' at line 10, got %+v", call)
+	}
+}
+
+func TestParseXML_Conditions(t *testing.T) {
+	xmlInput := `<frame name="cart" route="/cart">
+  <var key="count" type="INT" value="0" />
+  <block keyType="ROOT" key="root">
+    <action event="onClick">
+      <trigger keyType="nativeblocks/change_variable" name="increase" when="count &lt; 10" />
+      <trigger if="count == 0">
+        <then>
+          <trigger keyType="nativeblocks/change_variable" name="first" />
+        </then>
+        <else>
+          <trigger keyType="nativeblocks/change_variable" name="more" />
+        </else>
+      </trigger>
+    </action>
+  </block>
+</frame>`
+
+	frame, errs := ParseXML(xmlInput)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	triggers := frame.Blocks[0].Actions[0].Triggers
+	if len(triggers) != 2 || triggers[0].Condition != "count < 10" {
+		t.Fatalf("Expected the guarded 'increase' and an if, got %+v", triggers)
+	}
+	branch := triggers[1]
+	if branch.KeyType != model.ConditionKeyType || branch.Condition != "count == 0" || branch.Line != 6 || len(branch.Triggers) != 2 {
+		t.Fatalf("Expected an if on count == 0 at line 6, got %+v", branch)
+	}
+	if branch.Triggers[0].Name != "first" || branch.Triggers[0].Then != "SUCCESS" ||
+		branch.Triggers[1].Name != "more" || branch.Triggers[1].Then != "FAILURE" {
+		t.Errorf("Expected 'first' on SUCCESS and 'more' on FAILURE, got %+v", branch.Triggers)
+	}
+}
+
+func TestParseXML_Styles(t *testing.T) {
+	xmlInput := `<frame name="form" route="/form">
+  <style name="rounded">
+    <prop key="cornerRadius" value="12" />
+  </style>
+  <style name="primaryButton" style="rounded">
+    <prop key="padding" mobile="8" desktop="16" />
+  </style>
+  <block keyType="ROOT" key="root">
+    <slot name="content">
+      <block keyType="nativeblocks/button" key="submit" style="primaryButton, rounded" />
+    </slot>
+  </block>
+</frame>`
+
+	frame, errs := ParseXML(xmlInput)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if len(frame.Styles) != 2 {
+		t.Fatalf("Expected 2 styles, got %+v", frame.Styles)
+	}
+	primary := frame.Styles[1]
+	if primary.Name != "primaryButton" || strings.Join(primary.Styles, ",") != "rounded" || primary.Line != 5 {
+		t.Errorf("Unexpected style primaryButton: %+v", primary)
+	}
+	if len(primary.Properties) != 1 || primary.Properties[0].ValueMobile != "8" || primary.Properties[0].ValueDesktop != "16" {
+		t.Errorf("Expected per-device padding, got %+v", primary.Properties)
+	}
+	if styles := frame.Blocks[0].Blocks[0].Styles; strings.Join(styles, ",") != "primaryButton,rounded" {
+		t.Errorf("Expected styles on submit, got %v", styles)
+	}
+}
+
 func TestParseXML_MissingRequiredFields(t *testing.T) {
 	// Missing name
 	xmlInput1 := `<frame route="/test"></frame>`
@@ -0,0 +1,211 @@
+package patch
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/compiler"
+	"github.com/nativeblocks/nbx/internal/diff"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/validator"
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+// Conflict is a change made on both sides that cannot be merged automatically.
+type Conflict struct {
+	Path   string `json:"path"`
+	Ours   string `json:"ours"`
+	Theirs string `json:"theirs"`
+}
+
+// Merge performs a three-way merge of two frames derived from base. Changes to different blocks,
+// properties, data or triggers are combined; identical changes on both sides are applied once.
+// Conflicting changes, including different nodes placed at the same position, keep our side and are
+// reported with their paths.
+func Merge(base, ours, theirs model.FrameDSLModel) (model.FrameDSLModel, []Conflict) {
+	oursPatch := Make(base, ours)
+	theirsPatch := Make(base, theirs)
+	parents := _parentsOf(base, ours, theirs)
+
+	var conflicts []Conflict
+	var kept []Op
+	for _, t := range theirsPatch.Ops {
+		duplicate, conflicting := false, false
+		for _, o := range oursPatch.Ops {
+			switch {
+			case _sameTarget(o, t):
+				if _equalOps(o, t) {
+					duplicate = true
+				} else {
+					conflicting = true
+				}
+			case _overlaps(o, t, parents) || _overlaps(t, o, parents) || _samePosition(o, t):
+				conflicting = true
+			}
+			if conflicting {
+				conflicts = append(conflicts, Conflict{Path: t.Path, Ours: _describe(o), Theirs: _describe(t)})
+				break
+			}
+		}
+		if !duplicate && !conflicting {
+			kept = append(kept, t)
+		}
+	}
+
+	merged := model.CloneFrame(ours)
+	for _, op := range kept {
+		if err := _apply(&merged, op); err != nil {
+			conflicts = append(conflicts, Conflict{Path: op.Path, Theirs: _describe(op) + ": " + err.Error()})
+		}
+	}
+
+	conflicts = append(conflicts, _validationConflicts(ours, theirs, merged)...)
+	return merged, conflicts
+}
+
+// _validationConflicts reports errors of the merged frame that neither side had, such as a binding to a
+// variable or a reference to a constant removed on the other side.
+func _validationConflicts(ours, theirs, merged model.FrameDSLModel) []Conflict {
+	existing := make(map[string]bool)
+	for _, frame := range []model.FrameDSLModel{ours, theirs} {
+		for _, message := range _compileErrors(frame) {
+			existing[message] = true
+		}
+	}
+
+	var conflicts []Conflict
+	for _, message := range _compileErrors(merged) {
+		if !existing[message] {
+			conflicts = append(conflicts, Conflict{Path: "frame", Ours: "merged result", Theirs: message})
+		}
+	}
+	return conflicts
+}
+
+// _compileErrors returns the messages of the errors found when the frame is compiled: those of
+// resolving its constants and expanding its components, then the validation errors of the result.
+func _compileErrors(frame model.FrameDSLModel) []string {
+	expanded, _, errs := compiler.Expand(frame)
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Message)
+	}
+	collector, _ := validator.Validate(&expanded)
+	for _, err := range collector.Errors() {
+		messages = append(messages, err.Message)
+	}
+	return messages
+}
+
+func _sameTarget(a, b Op) bool {
+	return a.Path == b.Path && a.Field == b.Field && a.Device == b.Device
+}
+
+// _equalOps compares two ops by their JSON form, which leaves out source positions.
+func _equalOps(a, b Op) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	a.Old, b.Old = "", ""
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return string(left) == string(right)
+}
+
+// _overlaps reports whether removal r invalidates the other op x. A component definition with a new
+// root block is replaced as a whole, so it invalidates every other op inside the component.
+func _overlaps(r, x Op, parents map[string]string) bool {
+	if r.Kind == diff.Changed && r.Node == diff.NodeComponent {
+		return x.Path != r.Path && _isUnder(x.Path, r.Path)
+	}
+	if r.Kind != diff.Removed || x.Kind == diff.Removed {
+		return false
+	}
+	if r.Node != walker.KindBlock {
+		return _isUnder(x.Path, r.Path)
+	}
+
+	segments := diff.ParsePath(r.Path)
+	if segments[0].Kind == "component" {
+		// The removal of a block reports its removed descendants too, so only blocks added into it remain.
+		removed := segments[len(segments)-1].Key
+		return _isUnder(x.Path, r.Path) || (_isUnder(x.Path, _componentPath(segments[0].Key)) && x.Parent == removed)
+	}
+	if diff.ParsePath(x.Path)[0].Kind == "component" {
+		return false
+	}
+
+	removed := segments[0].Key
+	if key := _pathBlock(x.Path); key != "" && _isDescendant(key, removed, parents) {
+		return true
+	}
+	return x.Parent != "" && _isDescendant(x.Parent, removed, parents)
+}
+
+// _samePosition reports whether two ops place different nodes at the same position, so the merged order
+// would depend on the side applied first.
+func _samePosition(a, b Op) bool {
+	if a.Path == b.Path || !_isPlacement(a) || !_isPlacement(b) || a.Node != b.Node || a.After != b.After {
+		return false
+	}
+	switch a.Node {
+	case walker.KindBlock:
+		return _componentOf(a.Path) == _componentOf(b.Path) && a.Parent == b.Parent && a.Slot == b.Slot
+	case walker.KindTrigger:
+		return a.Path[:strings.LastIndex(a.Path, "/")+1] == b.Path[:strings.LastIndex(b.Path, "/")+1]
+	case diff.NodeConstant, diff.NodeEnum, walker.KindVariable, diff.NodeStyle, walker.KindSequence, diff.NodeComponent:
+		return true
+	}
+	return false
+}
+
+func _isPlacement(op Op) bool {
+	return op.Kind == diff.Added || op.Kind == diff.Moved || op.Kind == diff.Reordered
+}
+
+// _componentOf returns the name of the component a path is inside of, or "" for the frame's own nodes.
+func _componentOf(path string) string {
+	if segment := diff.ParsePath(path)[0]; segment.Kind == "component" {
+		return segment.Key
+	}
+	return ""
+}
+
+func _isDescendant(key, ancestor string, parents map[string]string) bool {
+	for seen := 0; key != "" && seen <= len(parents); seen++ {
+		if key == ancestor {
+			return true
+		}
+		key = parents[key]
+	}
+	return false
+}
+
+// _parentsOf maps every block key to its parent key, preferring base and adding blocks that only
+// exist on one side.
+func _parentsOf(frames ...model.FrameDSLModel) map[string]string {
+	parents := make(map[string]string)
+	for _, frame := range frames {
+		walker.Walk(&frame, walker.Visitor{
+			Enter: func(node *walker.Node, ancestors []*walker.Node) walker.Result {
+				if node.Kind != walker.KindBlock {
+					return walker.Continue
+				}
+				if _, exists := parents[node.Block.Key]; !exists {
+					parent := ancestors[len(ancestors)-1]
+					if parent.Kind == walker.KindBlock {
+						parents[node.Block.Key] = parent.Block.Key
+					} else {
+						parents[node.Block.Key] = ""
+					}
+				}
+				return walker.Continue
+			},
+		})
+	}
+	return parents
+}
+
+func _describe(op Op) string {
+	return strings.TrimSpace(diff.Changes{op.Change}.Text())
+}
@@ -0,0 +1,1092 @@
+package patch
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/diff"
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+// Op is one structural edit. It mirrors a diff.Change and carries what is needed to replay it:
+// the added node, and where added or moved blocks, constants, enums, variables, sequences, styles,
+// components and triggers go.
+type Op struct {
+	diff.Change
+
+	// Parent and Slot place added and moved blocks. Parent is empty for top-level blocks.
+	Parent string `json:"parent,omitempty"`
+	Slot   string `json:"slot,omitempty"`
+	// After is the key of the preceding sibling, or empty to insert first.
+	After string `json:"after,omitempty"`
+
+	Block           *model.BlockDSLModel           `json:"block,omitempty"`
+	Constant        *model.ConstantDSLModel        `json:"constant,omitempty"`
+	Enum            *model.EnumDSLModel            `json:"enum,omitempty"`
+	Variable        *model.VariableDSLModel        `json:"variable,omitempty"`
+	Property        *model.BlockPropertyDSLModel   `json:"property,omitempty"`
+	Data            *model.BlockDataDSLModel       `json:"data,omitempty"`
+	Action          *model.ActionDSLModel          `json:"action,omitempty"`
+	Sequence        *model.SequenceDSLModel        `json:"sequence,omitempty"`
+	Style           *model.StyleDSLModel           `json:"style,omitempty"`
+	Component       *model.ComponentDSLModel       `json:"component,omitempty"`
+	Param           *model.ComponentParamDSLModel  `json:"param,omitempty"`
+	Trigger         *model.ActionTriggerDSLModel   `json:"trigger,omitempty"`
+	TriggerProperty *model.TriggerPropertyDSLModel `json:"triggerProperty,omitempty"`
+	TriggerData     *model.TriggerDataDSLModel     `json:"triggerData,omitempty"`
+}
+
+// Patch is an ordered list of edits that turns one frame into another.
+type Patch struct {
+	Ops []Op `json:"ops"`
+}
+
+// Make returns the patch that turns a into b.
+func Make(a, b model.FrameDSLModel) Patch {
+	return FromDiff(diff.Diff(a, b), b)
+}
+
+// FromDiff builds a patch from the changes between two frames, taking added nodes and new positions
+// from b, the newer frame. Ops are ordered so they can be applied in sequence: additions and moves in
+// the document order of b, so every block is placed after its already placed sibling, then field
+// changes, the changes to the blocks of component definitions and finally removals.
+func FromDiff(changes diff.Changes, b model.FrameDSLModel) Patch {
+	order := make(map[string]int)
+	previous := make(map[string]string)
+	parents := make(map[string]*model.BlockDSLModel)
+	walker.Walk(&b, walker.Visitor{
+		Enter: func(node *walker.Node, ancestors []*walker.Node) walker.Result {
+			if node.Kind != walker.KindBlock {
+				return walker.Continue
+			}
+			order[node.Block.Key] = len(order)
+			var siblings []model.BlockDSLModel
+			if parent := ancestors[len(ancestors)-1]; parent.Kind == walker.KindBlock {
+				parents[node.Block.Key] = parent.Block
+				siblings = parent.Block.Blocks
+			} else {
+				siblings = b.Blocks
+			}
+			previous[node.Block.Key] = _previousSibling(siblings, node.Block.Key)
+			return walker.Continue
+		},
+	})
+
+	var placements, fields, definitions, removals []Op
+	componentChanges := make(map[string]diff.Changes)
+	var components []string
+	for _, change := range changes {
+		op := Op{Change: change}
+		segments := diff.ParsePath(change.Path)
+		last := segments[len(segments)-1]
+
+		if len(segments) > 1 && segments[0].Kind == "component" && segments[1].Kind == "block" {
+			if _, seen := componentChanges[segments[0].Key]; !seen {
+				components = append(components, segments[0].Key)
+			}
+			change.Path = strings.TrimPrefix(change.Path, _componentPath(segments[0].Key)+"/")
+			componentChanges[segments[0].Key] = append(componentChanges[segments[0].Key], change)
+			continue
+		}
+
+		switch change.Kind {
+		case diff.Added:
+			_fillAdded(&op, segments, b, parents, previous)
+			placements = append(placements, op)
+		case diff.Moved, diff.Reordered:
+			if change.Node == walker.KindBlock {
+				op.Slot = _findBlock(b.Blocks, last.Key).Slot
+				if parent := parents[last.Key]; parent != nil {
+					op.Parent = parent.Key
+				}
+				op.After = previous[last.Key]
+			} else {
+				siblings := _triggerContainer(b, segments[:len(segments)-1])
+				ids := diff.TriggerIDs(siblings)
+				if index := slices.Index(ids, last.Key); index > 0 {
+					op.After = ids[index-1]
+				}
+			}
+			placements = append(placements, op)
+		case diff.Changed:
+			if change.Node == diff.NodeComponent {
+				component := *_findComponent(b.Components, last.Key)
+				op.Component = &component
+			}
+			fields = append(fields, op)
+		case diff.Removed:
+			removals = append(removals, op)
+		}
+	}
+
+	slices.SortStableFunc(placements, func(x, y Op) int { return _blockOrder(order, x) - _blockOrder(order, y) })
+
+	// The blocks of a component definition are patched as the blocks of a frame holding its root block.
+	for _, name := range components {
+		definition := model.FrameDSLModel{Blocks: []model.BlockDSLModel{_findComponent(b.Components, name).Block}}
+		for _, op := range FromDiff(componentChanges[name], definition).Ops {
+			op.Path = _componentPath(name) + "/" + op.Path
+			definitions = append(definitions, op)
+		}
+	}
+
+	var ops []Op
+	ops = append(ops, placements...)
+	ops = append(ops, fields...)
+	ops = append(ops, definitions...)
+	ops = append(ops, removals...)
+	return Patch{Ops: ops}
+}
+
+func _blockOrder(order map[string]int, op Op) int {
+	segments := diff.ParsePath(op.Path)
+	if segments[0].Kind != "block" {
+		return -1
+	}
+	if op.Node != walker.KindBlock {
+		// Nested additions come after the blocks that contain them.
+		return order[segments[0].Key] + len(order)
+	}
+	return order[segments[0].Key]
+}
+
+func _fillAdded(op *Op, segments []diff.Segment, b model.FrameDSLModel, parents map[string]*model.BlockDSLModel, previous map[string]string) {
+	last := segments[len(segments)-1]
+	switch op.Node {
+	case diff.NodeConstant:
+		index := slices.IndexFunc(b.Constants, func(c model.ConstantDSLModel) bool { return c.Key == last.Key })
+		constant := b.Constants[index]
+		op.Constant = &constant
+		if index > 0 {
+			op.After = b.Constants[index-1].Key
+		}
+		return
+	case diff.NodeEnum:
+		index := slices.IndexFunc(b.Enums, func(e model.EnumDSLModel) bool { return e.Name == last.Key })
+		enum := b.Enums[index]
+		op.Enum = &enum
+		if index > 0 {
+			op.After = b.Enums[index-1].Name
+		}
+		return
+	case walker.KindVariable:
+		index := slices.IndexFunc(b.Variables, func(v model.VariableDSLModel) bool { return v.Key == last.Key })
+		variable := b.Variables[index]
+		op.Variable = &variable
+		if index > 0 {
+			op.After = b.Variables[index-1].Key
+		}
+		return
+	case walker.KindSequence:
+		index := slices.IndexFunc(b.Sequences, func(s model.SequenceDSLModel) bool { return s.Name == last.Key })
+		sequence := b.Sequences[index]
+		op.Sequence = &sequence
+		if index > 0 {
+			op.After = b.Sequences[index-1].Name
+		}
+		return
+	case diff.NodeComponent:
+		index := slices.IndexFunc(b.Components, func(c model.ComponentDSLModel) bool { return c.Name == last.Key })
+		component := b.Components[index]
+		op.Component = &component
+		if index > 0 {
+			op.After = b.Components[index-1].Name
+		}
+		return
+	case diff.NodeStyle:
+		index := slices.IndexFunc(b.Styles, func(s model.StyleDSLModel) bool { return s.Name == last.Key })
+		style := b.Styles[index]
+		op.Style = &style
+		if index > 0 {
+			op.After = b.Styles[index-1].Name
+		}
+		return
+	case walker.KindBlock:
+		block := *_findBlock(b.Blocks, last.Key)
+		block.Blocks = nil
+		op.Block = &block
+		op.Slot = block.Slot
+		if parent := parents[last.Key]; parent != nil {
+			op.Parent = parent.Key
+		}
+		op.After = previous[last.Key]
+		return
+	}
+
+	if op.Node == walker.KindAction && segments[0].Kind == "action" {
+		index := slices.IndexFunc(b.Actions, func(a model.ActionDSLModel) bool { return a.Event == last.Key })
+		op.Action = &b.Actions[index]
+		return
+	}
+
+	if segments[0].Kind == "component" {
+		component := _findComponent(b.Components, segments[0].Key)
+		params := component.Properties
+		if op.Node == walker.KindData {
+			params = component.Data
+		}
+		index := slices.IndexFunc(params, func(p model.ComponentParamDSLModel) bool { return p.Key == last.Key })
+		op.Param = &params[index]
+		return
+	}
+
+	if segments[0].Kind == "style" {
+		style := _findStyle(b.Styles, segments[0].Key)
+		index := slices.IndexFunc(style.Properties, func(p model.BlockPropertyDSLModel) bool { return p.Key == last.Key })
+		op.Property = &style.Properties[index]
+		return
+	}
+
+	block := _findBlock(b.Blocks, segments[0].Key)
+	if len(segments) == 2 && segments[0].Kind == "block" {
+		switch op.Node {
+		case walker.KindProperty:
+			index := slices.IndexFunc(block.Properties, func(p model.BlockPropertyDSLModel) bool { return p.Key == last.Key })
+			op.Property = &block.Properties[index]
+		case walker.KindData:
+			index := slices.IndexFunc(block.Data, func(d model.BlockDataDSLModel) bool { return d.Key == last.Key })
+			op.Data = &block.Data[index]
+		case walker.KindAction:
+			index := slices.IndexFunc(block.Actions, func(a model.ActionDSLModel) bool { return a.Event == last.Key })
+			op.Action = &block.Actions[index]
+		}
+		return
+	}
+
+	if op.Node == walker.KindTrigger {
+		siblings := _triggerContainer(b, segments[:len(segments)-1])
+		ids := diff.TriggerIDs(siblings)
+		index := slices.Index(ids, last.Key)
+		op.Trigger = &siblings[index]
+		if index > 0 {
+			op.After = ids[index-1]
+		}
+		return
+	}
+
+	trigger := _findTrigger(_triggerContainer(b, segments[:len(segments)-2]), segments[len(segments)-2].Key)
+	switch op.Node {
+	case walker.KindProperty:
+		index := slices.IndexFunc(trigger.Properties, func(p model.TriggerPropertyDSLModel) bool { return p.Key == last.Key })
+		op.TriggerProperty = &trigger.Properties[index]
+	case walker.KindData:
+		index := slices.IndexFunc(trigger.Data, func(d model.TriggerDataDSLModel) bool { return d.Key == last.Key })
+		op.TriggerData = &trigger.Data[index]
+	}
+}
+
+// Apply applies the patch to the frame in order. The frame is only changed when every op applies;
+// an op fails when its target is missing or a changed field no longer holds its old value.
+func Apply(frame *model.FrameDSLModel, p Patch) []*errors.Error {
+	result := model.CloneFrame(*frame)
+	var errs []*errors.Error
+	for _, op := range p.Ops {
+		if err := _apply(&result, op); err != nil {
+			errs = append(errs, &errors.Error{
+				Severity: errors.SeverityError,
+				Message:  fmt.Sprintf("Cannot apply %s %s: %v", op.Kind, op.Path, err),
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	*frame = result
+	return nil
+}
+
+func _apply(frame *model.FrameDSLModel, op Op) error {
+	segments := diff.ParsePath(op.Path)
+	last := segments[len(segments)-1]
+
+	switch last.Kind {
+	case "frame":
+		return _applyFrame(frame, op)
+	case "const":
+		return _applyConstant(frame, op, last.Key)
+	case "enum":
+		return _applyEnum(frame, op, last.Key)
+	case "variable":
+		return _applyVariable(frame, op, last.Key)
+	case "sequence":
+		return _applySequence(frame, op, last.Key)
+	case "style":
+		return _applyStyle(frame, op, last.Key)
+	case "component":
+		return _applyComponent(frame, op, last.Key)
+	}
+
+	if segments[0].Kind == "component" {
+		component := _findComponent(frame.Components, segments[0].Key)
+		if component == nil {
+			return fmt.Errorf("component '%s' does not exist", segments[0].Key)
+		}
+		switch {
+		case len(segments) == 2 && op.Node == walker.KindProperty:
+			return _applyParam(&component.Properties, op, last.Key)
+		case len(segments) == 2 && op.Node == walker.KindData:
+			return _applyParam(&component.Data, op, last.Key)
+		}
+		definition := model.FrameDSLModel{Blocks: []model.BlockDSLModel{component.Block}}
+		op.Path = strings.TrimPrefix(op.Path, _componentPath(component.Name)+"/")
+		err := _apply(&definition, op)
+		component.Block = definition.Blocks[0]
+		return err
+	}
+
+	if segments[0].Kind == "style" {
+		style := _findStyle(frame.Styles, segments[0].Key)
+		if style == nil {
+			return fmt.Errorf("style '%s' does not exist", segments[0].Key)
+		}
+		return _applyBlockProperty(&style.Properties, op, last.Key)
+	}
+
+	if op.Node == walker.KindBlock {
+		return _applyBlock(frame, op, last.Key)
+	}
+
+	if segments[0].Kind == "action" && len(segments) == 1 {
+		return _applyAction(&frame.Actions, "", op, last.Key)
+	}
+
+	if segments[0].Kind == "block" {
+		block := _findBlock(frame.Blocks, segments[0].Key)
+		if block == nil {
+			return fmt.Errorf("block '%s' does not exist", segments[0].Key)
+		}
+		if len(segments) == 2 {
+			switch op.Node {
+			case walker.KindSlot:
+				return _applySlot(block, op, last.Key)
+			case walker.KindProperty:
+				return _applyBlockProperty(&block.Properties, op, last.Key)
+			case walker.KindData:
+				return _applyBlockData(block, op, last.Key)
+			case walker.KindAction:
+				return _applyAction(&block.Actions, block.Key, op, last.Key)
+			}
+			return fmt.Errorf("unsupported change")
+		}
+	}
+
+	if op.Node == walker.KindTrigger {
+		siblings := _triggerContainerPtr(frame, segments[:len(segments)-1])
+		if siblings == nil {
+			return fmt.Errorf("parent of trigger '%s' does not exist", last.Key)
+		}
+		return _applyTrigger(siblings, op, last.Key)
+	}
+
+	container := _triggerContainerPtr(frame, segments[:len(segments)-2])
+	if container == nil {
+		return fmt.Errorf("trigger '%s' does not exist", segments[len(segments)-2].Key)
+	}
+	trigger := _findTrigger(*container, segments[len(segments)-2].Key)
+	if trigger == nil {
+		return fmt.Errorf("trigger '%s' does not exist", segments[len(segments)-2].Key)
+	}
+	switch op.Node {
+	case walker.KindProperty:
+		return _applyTriggerProperty(trigger, op, last.Key)
+	case walker.KindData:
+		return _applyTriggerData(trigger, op, last.Key)
+	}
+	return fmt.Errorf("unsupported change")
+}
+
+func _set(field *string, op Op) error {
+	if *field != op.Old {
+		return fmt.Errorf("%s is %q, expected %q", op.Field, *field, op.Old)
+	}
+	*field = op.New
+	return nil
+}
+
+// _setRepeat sets the items or loop variable of a block's repeat, removing the repeat once both are empty.
+func _setRepeat(block *model.BlockDSLModel, op Op) error {
+	if block.Repeat == nil {
+		block.Repeat = &model.RepeatDSLModel{}
+	}
+	field := &block.Repeat.Items
+	if op.Field == "repeatAs" {
+		field = &block.Repeat.As
+	}
+	err := _set(field, op)
+	if block.Repeat.Items == "" && block.Repeat.As == "" {
+		block.Repeat = nil
+	}
+	return err
+}
+
+// _setNames sets a list of names, such as the styles of a block or the members of an enum, from their
+// comma separated form.
+func _setNames(field *[]string, op Op) error {
+	names := strings.Join(*field, ", ")
+	if err := _set(&names, op); err != nil {
+		return err
+	}
+	*field = nil
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			*field = append(*field, name)
+		}
+	}
+	return nil
+}
+
+func _setInt(field *int, op Op) error {
+	value := strconv.Itoa(*field)
+	if err := _set(&value, op); err != nil {
+		return err
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", op.Field, value)
+	}
+	*field = parsed
+	return nil
+}
+
+func _setBool(field *bool, op Op) error {
+	value := strconv.FormatBool(*field)
+	if err := _set(&value, op); err != nil {
+		return err
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", op.Field, value)
+	}
+	*field = parsed
+	return nil
+}
+
+func _applyFrame(frame *model.FrameDSLModel, op Op) error {
+	switch op.Field {
+	case "name":
+		return _set(&frame.Name, op)
+	case "route":
+		return _set(&frame.Route, op)
+	case "type":
+		return _set(&frame.Type, op)
+	case "starter":
+		return _setBool(&frame.Starter, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+func _applyConstant(frame *model.FrameDSLModel, op Op, key string) error {
+	index := slices.IndexFunc(frame.Constants, func(c model.ConstantDSLModel) bool { return c.Key == key })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("constant '%s' already exists", key)
+		}
+		at := slices.IndexFunc(frame.Constants, func(c model.ConstantDSLModel) bool { return c.Key == op.After }) + 1
+		if op.After != "" && at == 0 {
+			at = len(frame.Constants)
+		}
+		frame.Constants = slices.Insert(frame.Constants, at, *op.Constant)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			frame.Constants = slices.Delete(frame.Constants, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("constant '%s' does not exist", key)
+	}
+	switch op.Field {
+	case "type":
+		return _set(&frame.Constants[index].Type, op)
+	case "value":
+		return _set(&frame.Constants[index].Value, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+func _applyEnum(frame *model.FrameDSLModel, op Op, name string) error {
+	index := slices.IndexFunc(frame.Enums, func(e model.EnumDSLModel) bool { return e.Name == name })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("enum '%s' already exists", name)
+		}
+		at := slices.IndexFunc(frame.Enums, func(e model.EnumDSLModel) bool { return e.Name == op.After }) + 1
+		if op.After != "" && at == 0 {
+			at = len(frame.Enums)
+		}
+		frame.Enums = slices.Insert(frame.Enums, at, *op.Enum)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			frame.Enums = slices.Delete(frame.Enums, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("enum '%s' does not exist", name)
+	}
+	if op.Field == "members" {
+		return _setNames(&frame.Enums[index].Members, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+func _applyVariable(frame *model.FrameDSLModel, op Op, key string) error {
+	index := slices.IndexFunc(frame.Variables, func(v model.VariableDSLModel) bool { return v.Key == key })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("variable '%s' already exists", key)
+		}
+		at := slices.IndexFunc(frame.Variables, func(v model.VariableDSLModel) bool { return v.Key == op.After }) + 1
+		if op.After != "" && at == 0 {
+			at = len(frame.Variables)
+		}
+		frame.Variables = slices.Insert(frame.Variables, at, *op.Variable)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			frame.Variables = slices.Delete(frame.Variables, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("variable '%s' does not exist", key)
+	}
+	switch op.Field {
+	case "type":
+		return _set(&frame.Variables[index].Type, op)
+	case "value":
+		return _set(&frame.Variables[index].Value, op)
+	case "computed":
+		return _setBool(&frame.Variables[index].Computed, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+func _applySequence(frame *model.FrameDSLModel, op Op, name string) error {
+	index := slices.IndexFunc(frame.Sequences, func(s model.SequenceDSLModel) bool { return s.Name == name })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("sequence '%s' already exists", name)
+		}
+		at := slices.IndexFunc(frame.Sequences, func(s model.SequenceDSLModel) bool { return s.Name == op.After }) + 1
+		if op.After != "" && at == 0 {
+			at = len(frame.Sequences)
+		}
+		frame.Sequences = slices.Insert(frame.Sequences, at, *op.Sequence)
+	case diff.Removed:
+		if index != -1 {
+			frame.Sequences = slices.Delete(frame.Sequences, index, index+1)
+		}
+	}
+	return nil
+}
+
+func _applyStyle(frame *model.FrameDSLModel, op Op, name string) error {
+	index := slices.IndexFunc(frame.Styles, func(s model.StyleDSLModel) bool { return s.Name == name })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("style '%s' already exists", name)
+		}
+		at := slices.IndexFunc(frame.Styles, func(s model.StyleDSLModel) bool { return s.Name == op.After }) + 1
+		if op.After != "" && at == 0 {
+			at = len(frame.Styles)
+		}
+		frame.Styles = slices.Insert(frame.Styles, at, *op.Style)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			frame.Styles = slices.Delete(frame.Styles, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("style '%s' does not exist", name)
+	}
+	if op.Field == "styles" {
+		return _setNames(&frame.Styles[index].Styles, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+func _applyComponent(frame *model.FrameDSLModel, op Op, name string) error {
+	index := slices.IndexFunc(frame.Components, func(c model.ComponentDSLModel) bool { return c.Name == name })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("component '%s' already exists", name)
+		}
+		at := slices.IndexFunc(frame.Components, func(c model.ComponentDSLModel) bool { return c.Name == op.After }) + 1
+		if op.After != "" && at == 0 {
+			at = len(frame.Components)
+		}
+		frame.Components = slices.Insert(frame.Components, at, *op.Component)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			frame.Components = slices.Delete(frame.Components, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("component '%s' does not exist", name)
+	}
+	if op.Field != "root" {
+		return fmt.Errorf("unknown field %s", op.Field)
+	}
+	// A new root block replaces the whole definition.
+	root := frame.Components[index].Block.Key
+	if err := _set(&root, op); err != nil {
+		return err
+	}
+	frame.Components[index] = *op.Component
+	return nil
+}
+
+func _applyParam(params *[]model.ComponentParamDSLModel, op Op, key string) error {
+	index := slices.IndexFunc(*params, func(p model.ComponentParamDSLModel) bool { return p.Key == key })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("parameter '%s' already exists", key)
+		}
+		*params = append(*params, *op.Param)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			*params = slices.Delete(*params, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("parameter '%s' does not exist", key)
+	}
+	param := &(*params)[index]
+	switch op.Field {
+	case "type":
+		return _set(&param.Type, op)
+	case "value":
+		return _set(&param.Value, op)
+	case "required":
+		return _setBool(&param.Required, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+func _applyBlock(frame *model.FrameDSLModel, op Op, key string) error {
+	switch op.Kind {
+	case diff.Added:
+		if _findBlock(frame.Blocks, key) != nil {
+			return fmt.Errorf("block '%s' already exists", key)
+		}
+		block := *op.Block
+		block.Blocks = nil
+		return _insertBlock(frame, block, op)
+	case diff.Removed:
+		_removeBlock(&frame.Blocks, key)
+		return nil
+	case diff.Moved, diff.Reordered:
+		if _findBlock(frame.Blocks, key) == nil {
+			return fmt.Errorf("block '%s' does not exist", key)
+		}
+		if op.Parent != "" {
+			moved := _findBlock(frame.Blocks, key)
+			if moved.Key == op.Parent || _findBlock(moved.Blocks, op.Parent) != nil {
+				return fmt.Errorf("block '%s' cannot be moved into itself", key)
+			}
+		}
+		block, _ := _removeBlock(&frame.Blocks, key)
+		block.Slot = op.Slot
+		return _insertBlock(frame, block, op)
+	}
+
+	block := _findBlock(frame.Blocks, key)
+	if block == nil {
+		return fmt.Errorf("block '%s' does not exist", key)
+	}
+	switch op.Field {
+	case "keyType":
+		return _set(&block.KeyType, op)
+	case "visibilityKey":
+		return _set(&block.VisibilityKey, op)
+	case "version":
+		return _setInt(&block.IntegrationVersion, op)
+	case "repeatItems", "repeatAs":
+		return _setRepeat(block, op)
+	case "styles":
+		return _setNames(&block.Styles, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+// _insertBlock places a block after op.After among the children of op.Parent. When the preceding
+// sibling is gone the block is appended.
+func _insertBlock(frame *model.FrameDSLModel, block model.BlockDSLModel, op Op) error {
+	siblings := &frame.Blocks
+	if op.Parent != "" {
+		parent := _findBlock(frame.Blocks, op.Parent)
+		if parent == nil {
+			return fmt.Errorf("parent block '%s' does not exist", op.Parent)
+		}
+		siblings = &parent.Blocks
+	}
+
+	at := 0
+	if op.After != "" {
+		at = slices.IndexFunc(*siblings, func(b model.BlockDSLModel) bool { return b.Key == op.After }) + 1
+		if at == 0 {
+			at = len(*siblings)
+		}
+	}
+	*siblings = slices.Insert(*siblings, at, block)
+	return nil
+}
+
+func _applySlot(block *model.BlockDSLModel, op Op, name string) error {
+	index := slices.IndexFunc(block.Slots, func(s model.BlockSlotDSLModel) bool { return s.Slot == name })
+	switch op.Kind {
+	case diff.Added:
+		if index == -1 {
+			block.Slots = append(block.Slots, model.BlockSlotDSLModel{Slot: name})
+		}
+	case diff.Removed:
+		if index != -1 {
+			block.Slots = slices.Delete(block.Slots, index, index+1)
+		}
+	}
+	return nil
+}
+
+// _applyBlockProperty applies op to the properties of a block or style.
+func _applyBlockProperty(properties *[]model.BlockPropertyDSLModel, op Op, key string) error {
+	index := slices.IndexFunc(*properties, func(p model.BlockPropertyDSLModel) bool { return p.Key == key })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("property '%s' already exists", key)
+		}
+		*properties = append(*properties, *op.Property)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			*properties = slices.Delete(*properties, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("property '%s' does not exist", key)
+	}
+	prop := &(*properties)[index]
+	if op.Field == "type" {
+		return _set(&prop.Type, op)
+	}
+	switch op.Device {
+	case "mobile":
+		return _set(&prop.ValueMobile, op)
+	case "tablet":
+		return _set(&prop.ValueTablet, op)
+	case "desktop":
+		return _set(&prop.ValueDesktop, op)
+	}
+	return fmt.Errorf("unknown device %q", op.Device)
+}
+
+func _applyBlockData(block *model.BlockDSLModel, op Op, key string) error {
+	index := slices.IndexFunc(block.Data, func(d model.BlockDataDSLModel) bool { return d.Key == key })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("data '%s' already exists", key)
+		}
+		block.Data = append(block.Data, *op.Data)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			block.Data = slices.Delete(block.Data, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("data '%s' does not exist", key)
+	}
+	switch op.Field {
+	case "type":
+		return _set(&block.Data[index].Type, op)
+	case "value":
+		return _set(&block.Data[index].Value, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+// _applyAction applies op to the actions of the block named key, or to the frame actions when key is empty.
+func _applyAction(actions *[]model.ActionDSLModel, key string, op Op, event string) error {
+	index := slices.IndexFunc(*actions, func(a model.ActionDSLModel) bool { return a.Event == event })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("action '%s' already exists", event)
+		}
+		action := *op.Action
+		action.Key = key
+		*actions = append(*actions, action)
+	case diff.Removed:
+		if index != -1 {
+			*actions = slices.Delete(*actions, index, index+1)
+		}
+	}
+	return nil
+}
+
+func _applyTrigger(siblings *[]model.ActionTriggerDSLModel, op Op, id string) error {
+	ids := diff.TriggerIDs(*siblings)
+	index := slices.Index(ids, id)
+
+	switch op.Kind {
+	case diff.Added, diff.Reordered:
+		trigger := op.Trigger
+		if op.Kind == diff.Reordered {
+			if index == -1 {
+				return fmt.Errorf("trigger '%s' does not exist", id)
+			}
+			moved := (*siblings)[index]
+			trigger = &moved
+			*siblings = slices.Delete(*siblings, index, index+1)
+			ids = slices.Delete(ids, index, index+1)
+		} else if index != -1 {
+			return fmt.Errorf("trigger '%s' already exists", id)
+		}
+		at := 0
+		if op.After != "" {
+			at = slices.Index(ids, op.After) + 1
+			if at == 0 {
+				at = len(*siblings)
+			}
+		}
+		*siblings = slices.Insert(*siblings, at, *trigger)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			*siblings = slices.Delete(*siblings, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("trigger '%s' does not exist", id)
+	}
+	trigger := &(*siblings)[index]
+	switch op.Field {
+	case "keyType":
+		return _set(&trigger.KeyType, op)
+	case "then":
+		return _set(&trigger.Then, op)
+	case "version":
+		return _setInt(&trigger.IntegrationVersion, op)
+	case "condition":
+		return _set(&trigger.Condition, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+func _applyTriggerProperty(trigger *model.ActionTriggerDSLModel, op Op, key string) error {
+	index := slices.IndexFunc(trigger.Properties, func(p model.TriggerPropertyDSLModel) bool { return p.Key == key })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("property '%s' already exists", key)
+		}
+		trigger.Properties = append(trigger.Properties, *op.TriggerProperty)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			trigger.Properties = slices.Delete(trigger.Properties, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("property '%s' does not exist", key)
+	}
+	switch op.Field {
+	case "type":
+		return _set(&trigger.Properties[index].Type, op)
+	case "value":
+		return _set(&trigger.Properties[index].Value, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+func _applyTriggerData(trigger *model.ActionTriggerDSLModel, op Op, key string) error {
+	index := slices.IndexFunc(trigger.Data, func(d model.TriggerDataDSLModel) bool { return d.Key == key })
+	switch op.Kind {
+	case diff.Added:
+		if index != -1 {
+			return fmt.Errorf("data '%s' already exists", key)
+		}
+		trigger.Data = append(trigger.Data, *op.TriggerData)
+		return nil
+	case diff.Removed:
+		if index != -1 {
+			trigger.Data = slices.Delete(trigger.Data, index, index+1)
+		}
+		return nil
+	}
+
+	if index == -1 {
+		return fmt.Errorf("data '%s' does not exist", key)
+	}
+	switch op.Field {
+	case "type":
+		return _set(&trigger.Data[index].Type, op)
+	case "value":
+		return _set(&trigger.Data[index].Value, op)
+	}
+	return fmt.Errorf("unknown field %s", op.Field)
+}
+
+// _triggerContainer returns the trigger list addressed by a block[..]/action[..](/trigger[..])* path, an
+// action[..](/trigger[..])* path for frame actions or a sequence[..](/trigger[..])* path.
+func _triggerContainer(frame model.FrameDSLModel, segments []diff.Segment) []model.ActionTriggerDSLModel {
+	if container := _triggerContainerPtr(&frame, segments); container != nil {
+		return *container
+	}
+	return nil
+}
+
+func _triggerContainerPtr(frame *model.FrameDSLModel, segments []diff.Segment) *[]model.ActionTriggerDSLModel {
+	if len(segments) > 0 && segments[0].Kind == "sequence" {
+		index := slices.IndexFunc(frame.Sequences, func(s model.SequenceDSLModel) bool { return s.Name == segments[0].Key })
+		if index == -1 {
+			return nil
+		}
+		return _nestedTriggers(&frame.Sequences[index].Triggers, segments[1:])
+	}
+
+	actions := &frame.Actions
+	if len(segments) > 0 && segments[0].Kind == "block" {
+		block := _findBlock(frame.Blocks, segments[0].Key)
+		if block == nil {
+			return nil
+		}
+		actions = &block.Actions
+		segments = segments[1:]
+	}
+	if len(segments) < 1 || segments[0].Kind != "action" {
+		return nil
+	}
+	index := slices.IndexFunc(*actions, func(a model.ActionDSLModel) bool { return a.Event == segments[0].Key })
+	if index == -1 {
+		return nil
+	}
+
+	return _nestedTriggers(&(*actions)[index].Triggers, segments[1:])
+}
+
+// _nestedTriggers follows the trigger[..] segments down from container.
+func _nestedTriggers(container *[]model.ActionTriggerDSLModel, segments []diff.Segment) *[]model.ActionTriggerDSLModel {
+	for _, segment := range segments {
+		trigger := _findTrigger(*container, segment.Key)
+		if trigger == nil {
+			return nil
+		}
+		container = &trigger.Triggers
+	}
+	return container
+}
+
+func _findTrigger(triggers []model.ActionTriggerDSLModel, id string) *model.ActionTriggerDSLModel {
+	index := slices.Index(diff.TriggerIDs(triggers), id)
+	if index == -1 {
+		return nil
+	}
+	return &triggers[index]
+}
+
+func _findComponent(components []model.ComponentDSLModel, name string) *model.ComponentDSLModel {
+	index := slices.IndexFunc(components, func(c model.ComponentDSLModel) bool { return c.Name == name })
+	if index == -1 {
+		return nil
+	}
+	return &components[index]
+}
+
+// _componentPath is the path of a component declaration, which prefixes the paths of its blocks.
+func _componentPath(name string) string {
+	return "component[" + name + "]"
+}
+
+func _findStyle(styles []model.StyleDSLModel, name string) *model.StyleDSLModel {
+	index := slices.IndexFunc(styles, func(s model.StyleDSLModel) bool { return s.Name == name })
+	if index == -1 {
+		return nil
+	}
+	return &styles[index]
+}
+
+func _previousSibling(siblings []model.BlockDSLModel, key string) string {
+	previous := ""
+	for _, sibling := range siblings {
+		if sibling.Key == key {
+			return previous
+		}
+		previous = sibling.Key
+	}
+	return previous
+}
+
+func _findBlock(blocks []model.BlockDSLModel, key string) *model.BlockDSLModel {
+	for i := range blocks {
+		if blocks[i].Key == key {
+			return &blocks[i]
+		}
+		if found := _findBlock(blocks[i].Blocks, key); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func _removeBlock(blocks *[]model.BlockDSLModel, key string) (model.BlockDSLModel, bool) {
+	for i := range *blocks {
+		if (*blocks)[i].Key == key {
+			removed := (*blocks)[i]
+			*blocks = slices.Delete(*blocks, i, i+1)
+			return removed, true
+		}
+		if removed, ok := _removeBlock(&(*blocks)[i].Blocks, key); ok {
+			return removed, true
+		}
+	}
+	return model.BlockDSLModel{}, false
+}
+
+// _pathBlock returns the key of the block a path starts at, or "" for frame and variable paths.
+func _pathBlock(path string) string {
+	segments := diff.ParsePath(path)
+	if segments[0].Kind != "block" {
+		return ""
+	}
+	return segments[0].Key
+}
+
+func _isUnder(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
@@ -0,0 +1,567 @@
+package patch
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/diff"
+	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/parser"
+)
+
+const patchBase = `frame(name = "counter", route = "/counter") {
+    var visible: BOOLEAN = true
+    var count: INT = 0
+    var label: STRING = "Add"
+
+    block(keyType = "ROOT", key = "root", visibility = visible)
+    .slot("content") {
+        block(keyType = "nativeblocks/column", key = "column", visibility = visible, version = 1)
+        .prop(width = "match", paddingTop = "12")
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "title", visibility = visible, version = 1)
+            .prop(fontSize = "24")
+            .data(text = label)
+            block(keyType = "nativeblocks/text", key = "counter", visibility = visible, version = 1)
+            .data(text = count)
+            block(keyType = "nativeblocks/button", key = "button", visibility = visible, version = 1)
+            .prop(backgroundColor = "#2563EB")
+            .data(text = label)
+            .action(event = "onClick") {
+                trigger(keyType = "nativeblocks/change_variable", name = "increase")
+                .prop(variableValue = "1")
+                .data(variableKey = count)
+                .then("SUCCESS") {
+                    trigger(keyType = "nativeblocks/log", name = "done")
+                }
+                trigger(keyType = "nativeblocks/log", name = "log")
+            }
+        }
+        block(keyType = "nativeblocks/row", key = "footer", visibility = visible, version = 1)
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "note", visibility = visible, version = 1)
+            .data(text = label)
+        }
+    }
+}`
+
+func _parse(t *testing.T, source string) model.FrameDSLModel {
+	t.Helper()
+	p := parser.NewParser(lexer.NewLexer(source), source)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse frame:\n%s", p.ErrorCollector().FormatAll())
+	}
+	return *frame
+}
+
+// _edit applies replacements to the base source; each pair is old, new.
+func _edit(t *testing.T, replacements ...string) model.FrameDSLModel {
+	t.Helper()
+	source := patchBase
+	for i := 0; i < len(replacements); i += 2 {
+		if !strings.Contains(source, replacements[i]) {
+			t.Fatalf("Base source does not contain %q", replacements[i])
+		}
+		source = strings.Replace(source, replacements[i], replacements[i+1], 1)
+	}
+	return _parse(t, source)
+}
+
+const counterBlock = `            block(keyType = "nativeblocks/text", key = "counter", visibility = visible, version = 1)
+            .data(text = count)
+`
+
+func TestMakeAndApply(t *testing.T) {
+	base := _parse(t, patchBase)
+	target := _edit(t,
+		`var label: STRING = "Add"`, `var label: STRING = "Plus"
+    var enabled: BOOLEAN = true`,
+		`.prop(width = "match", paddingTop = "12")`, `.prop(width = "wrap", paddingTop = "12", paddingBottom = "8")`,
+		counterBlock, ``,
+		`.slot("content") {
+            block(keyType = "nativeblocks/text", key = "note"`, `.slot("content") {
+`+counterBlock+`            block(keyType = "nativeblocks/image", key = "icon", visibility = visible, version = 1)
+            .slot("badge") {
+                block(keyType = "nativeblocks/text", key = "badgeText", visibility = visible, version = 1)
+            }
+            block(keyType = "nativeblocks/text", key = "note"`,
+		`.prop(variableValue = "1")`, `.prop(variableValue = "2")`,
+		`                trigger(keyType = "nativeblocks/log", name = "log")
+`, ``,
+		`.prop(backgroundColor = "#2563EB")`, `.prop(backgroundColor = "#2563EB")
+            .action(event = "onLongClick") {
+                trigger(keyType = "nativeblocks/log", name = "log")
+            }`,
+	)
+
+	p := Make(base, target)
+	if errs := Apply(&base, p); errs != nil {
+		t.Fatalf("Unexpected apply errors: %s", errs[0].Message)
+	}
+	if changes := diff.Diff(base, target); len(changes) != 0 {
+		t.Errorf("Expected patched frame to equal target, remaining changes:\n%s", changes.Text())
+	}
+}
+
+func TestApplyReorder(t *testing.T) {
+	base := _parse(t, patchBase)
+	target := _edit(t, counterBlock, ``,
+		`        block(keyType = "nativeblocks/row", key = "footer"`, `        block(keyType = "nativeblocks/row", key = "footer"`)
+	target.Blocks[0].Blocks[0].Blocks = append(target.Blocks[0].Blocks[0].Blocks[:0:0],
+		target.Blocks[0].Blocks[0].Blocks[1], target.Blocks[0].Blocks[0].Blocks[0])
+	counter := _parse(t, patchBase).Blocks[0].Blocks[0].Blocks[1]
+	target.Blocks[0].Blocks[0].Blocks = append([]model.BlockDSLModel{counter}, target.Blocks[0].Blocks[0].Blocks...)
+
+	if errs := Apply(&base, Make(base, target)); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	var keys []string
+	for _, b := range base.Blocks[0].Blocks[0].Blocks {
+		keys = append(keys, b.Key)
+	}
+	if strings.Join(keys, ",") != "counter,button,title" {
+		t.Errorf("Unexpected order after patch: %v", keys)
+	}
+}
+
+func TestApplyFrameActions(t *testing.T) {
+	withLoad := `var label: STRING = "Add"
+
+    action(event = "onLoad") {
+        trigger(keyType = "nativeblocks/change_variable", name = "reset")
+        .prop(variableValue = "0")
+        .data(variableKey = count)
+    }`
+	base := _edit(t, `var label: STRING = "Add"`, withLoad)
+	target := _edit(t, `var label: STRING = "Add"`, strings.Replace(withLoad, `variableValue = "0"`, `variableValue = "1"`, 1)+`
+    action(event = "onResume") {
+        trigger(keyType = "nativeblocks/log", name = "log")
+    }`)
+
+	p := Make(base, target)
+	paths := make([]string, len(p.Ops))
+	for i, op := range p.Ops {
+		paths[i] = op.Path
+	}
+	if strings.Join(paths, ",") != "action[onResume],action[onLoad]/trigger[reset]/prop[variableValue]" {
+		t.Errorf("Unexpected frame action paths: %v", paths)
+	}
+	if errs := Apply(&base, p); errs != nil {
+		t.Fatalf("Unexpected apply errors: %s", errs[0].Message)
+	}
+	if changes := diff.Diff(base, target); len(changes) != 0 {
+		t.Errorf("Expected patched frame to equal target, remaining changes:\n%s", changes.Text())
+	}
+}
+
+func TestApplySequences(t *testing.T) {
+	withSequence := `var label: STRING = "Add"
+
+    sequence(name = "reset") {
+        trigger(keyType = "nativeblocks/change_variable", name = "clear")
+        .data(variableKey = count)
+    }`
+	base := _edit(t, `var label: STRING = "Add"`, withSequence)
+	target := _edit(t,
+		`var label: STRING = "Add"`, withSequence+`
+
+    sequence(name = "notify") {
+        trigger(keyType = "nativeblocks/log", name = "log")
+    }`,
+		`.data(variableKey = count)
+    }`, `.data(variableKey = count)
+        trigger(sequence = "notify")
+    }`,
+		`trigger(keyType = "nativeblocks/log", name = "log")
+            }`, `trigger(sequence = "reset")
+            }`,
+	)
+
+	p := Make(base, target)
+	if errs := Apply(&base, p); errs != nil {
+		t.Fatalf("Unexpected apply errors: %s", errs[0].Message)
+	}
+	if changes := diff.Diff(base, target); len(changes) != 0 {
+		t.Errorf("Expected patched frame to equal target, remaining changes:\n%s", changes.Text())
+	}
+	if len(base.Sequences) != 2 || base.Sequences[0].Triggers[1].Sequence != "notify" {
+		t.Errorf("Expected the new sequence and its call, got %+v", base.Sequences)
+	}
+}
+
+func TestApplyConditions(t *testing.T) {
+	base := _edit(t, `.data(variableKey = count)
+                .then("SUCCESS")`, `.data(variableKey = count)
+                .when(count < 10)
+                .then("SUCCESS")`)
+	target := _edit(t,
+		`.data(variableKey = count)
+                .then("SUCCESS")`, `.data(variableKey = count)
+                .when(count < 5)
+                .then("SUCCESS")`,
+		`                trigger(keyType = "nativeblocks/log", name = "log")
+            }`, `                trigger(keyType = "nativeblocks/log", name = "log")
+                if (count == 0) {
+                    trigger(keyType = "nativeblocks/log", name = "empty")
+                } else {
+                    trigger(keyType = "nativeblocks/log", name = "full")
+                }
+            }`,
+	)
+
+	changes := diff.Diff(base, target)
+	if len(changes) != 2 {
+		t.Fatalf("Expected the changed guard and the added if, got:\n%s", changes.Text())
+	}
+	p := Make(base, target)
+	if errs := Apply(&base, p); errs != nil {
+		t.Fatalf("Unexpected apply errors: %s", errs[0].Message)
+	}
+	if changes := diff.Diff(base, target); len(changes) != 0 {
+		t.Errorf("Expected patched frame to equal target, remaining changes:\n%s", changes.Text())
+	}
+}
+
+func TestApplyStyles(t *testing.T) {
+	base := _parse(t, patchBase)
+	target := _edit(t, `.prop(fontSize = "24")`, `.style(heading, bold)
+                .prop(fontSize = "24")`)
+
+	changes := diff.Diff(base, target)
+	if len(changes) != 1 || changes[0].Field != "styles" || changes[0].New != "heading, bold" {
+		t.Fatalf("Expected the changed styles, got:\n%s", changes.Text())
+	}
+	p := Make(base, target)
+	if errs := Apply(&base, p); errs != nil {
+		t.Fatalf("Unexpected apply errors: %s", errs[0].Message)
+	}
+	if changes := diff.Diff(base, target); len(changes) != 0 {
+		t.Errorf("Expected patched frame to equal target, remaining changes:\n%s", changes.Text())
+	}
+}
+
+func TestMergeStyleDefinitions(t *testing.T) {
+	withStyle := `var label: STRING = "Add"
+
+    style heading {
+        .prop(fontSize = "24", fontWeight = "bold")
+    }`
+	base := _edit(t, `var label: STRING = "Add"`, withStyle)
+	ours := _edit(t, `var label: STRING = "Add"`, strings.Replace(withStyle, `fontSize = "24"`, `fontSize = "28"`, 1))
+	theirs := _edit(t,
+		`var label: STRING = "Add"`, withStyle+`
+
+    style subtle {
+        .style(heading)
+        .prop(color = "#6B7280")
+    }`,
+		`.prop(fontSize = "24")`, `.style(subtle)`,
+	)
+
+	changes := diff.Diff(base, ours)
+	if len(changes) != 3 || changes[0].Path != "style[heading]/prop[fontSize]" {
+		t.Fatalf("Expected the changed style property for each device, got:\n%s", changes.Text())
+	}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %+v", conflicts)
+	}
+	expected := _edit(t,
+		`var label: STRING = "Add"`, strings.Replace(withStyle, `fontSize = "24"`, `fontSize = "28"`, 1)+`
+
+    style subtle {
+        .style(heading)
+        .prop(color = "#6B7280")
+    }`,
+		`.prop(fontSize = "24")`, `.style(subtle)`,
+	)
+	if changes := diff.Diff(merged, expected); len(changes) != 0 {
+		t.Errorf("Unexpected merge result:\n%s", changes.Text())
+	}
+
+	// Removing a style conflicts with editing it on the other side.
+	removed := _parse(t, patchBase)
+	_, conflicts = Merge(base, removed, ours)
+	if len(conflicts) == 0 || conflicts[0].Path != "style[heading]/prop[fontSize]" {
+		t.Errorf("Expected a style conflict, got %+v", conflicts)
+	}
+
+	composed := _edit(t, `var label: STRING = "Add"`, strings.Replace(withStyle, "style heading {", `style heading {
+        .style(title, bold)`, 1))
+	if errs := Apply(&base, Make(base, composed)); errs != nil {
+		t.Fatalf("Unexpected apply errors: %s", errs[0].Message)
+	}
+	if styles := base.Styles[0].Styles; len(styles) != 2 || styles[1] != "bold" {
+		t.Errorf("Expected the composed styles to be patched, got %v", styles)
+	}
+}
+
+func TestMergeEnumDeclarations(t *testing.T) {
+	base := _parse(t, patchBase)
+	ours := _edit(t, `.prop(fontSize = "24")`, `.prop(fontSize = "28")`)
+	theirs := _edit(t, `    var visible: BOOLEAN = true`, `    enum Status { IDLE, BUSY }
+
+    var visible: BOOLEAN = true
+    var status: Status = IDLE`)
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %+v", conflicts)
+	}
+	if len(merged.Enums) != 1 || merged.Variables[1].Key != "status" {
+		t.Errorf("Expected the enum and the variable of its type to be merged, got %+v and %+v", merged.Enums, merged.Variables)
+	}
+
+	changed := _edit(t, `    var visible: BOOLEAN = true`, `    enum Status { IDLE, BUSY, DONE }
+
+    var visible: BOOLEAN = true
+    var status: Status = IDLE`)
+	changes := diff.Diff(theirs, changed)
+	if len(changes) != 1 || changes[0].Path != "enum[Status]" || changes[0].New != "IDLE, BUSY, DONE" {
+		t.Fatalf("Expected the changed members, got:\n%s", changes.Text())
+	}
+	if errs := Apply(&theirs, Make(theirs, changed)); errs != nil {
+		t.Fatalf("Unexpected apply errors: %s", errs[0].Message)
+	}
+	if changes := diff.Diff(theirs, changed); len(changes) != 0 {
+		t.Errorf("Expected patched frame to equal target, remaining changes:\n%s", changes.Text())
+	}
+}
+
+func TestMergeConstantDeclarations(t *testing.T) {
+	withConstant := `var label: STRING = "Add"
+
+    const accent: STRING = "#2563EB"`
+	base := _edit(t, `var label: STRING = "Add"`, withConstant)
+	ours := _edit(t, `var label: STRING = "Add"`, strings.Replace(withConstant, `"#2563EB"`, `"#1D4ED8"`, 1))
+	theirs := _edit(t,
+		`var label: STRING = "Add"`, withConstant+`
+    const spacing: INT = 16`,
+		`paddingTop = "12"`, `paddingTop = "{const:spacing}"`,
+	)
+
+	changes := diff.Diff(base, ours)
+	if len(changes) != 1 || changes[0].Path != "const[accent]" || changes[0].Field != "value" {
+		t.Fatalf("Expected the changed constant value, got:\n%s", changes.Text())
+	}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %+v", conflicts)
+	}
+	if len(merged.Constants) != 2 || merged.Constants[0].Value != "#1D4ED8" || merged.Constants[1].Key != "spacing" {
+		t.Errorf("Expected both constant changes to be merged, got %+v", merged.Constants)
+	}
+
+	// Removing a constant that the other side refers to is reported after compilation.
+	removed := _parse(t, patchBase)
+	referenced := _edit(t, `var label: STRING = "Add"`, withConstant, `backgroundColor = "#2563EB"`, `backgroundColor = "{const:accent}"`)
+	_, conflicts = Merge(base, removed, referenced)
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0].Theirs, "Undefined constant 'accent'") {
+		t.Errorf("Expected a dangling constant conflict, got %+v", conflicts)
+	}
+}
+
+func TestMergeComponentDeclarations(t *testing.T) {
+	withComponent := `var label: STRING = "Add"
+
+    component(name = "card") {
+        prop title: STRING = "Untitled"
+        data text
+
+        block(keyType = "nativeblocks/column", key = "card")
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "heading")
+            .prop(text = "{prop:title}")
+            block(keyType = "nativeblocks/text", key = "body")
+            .data(text = text)
+        }
+    }`
+	subtitle := `.prop(text = "{prop:title}")
+            block(keyType = "nativeblocks/text", key = "subtitle")
+            .prop(fontSize = "12")`
+	badge := `
+
+    component(name = "badge") {
+        block(keyType = "nativeblocks/text", key = "badge")
+    }`
+
+	base := _edit(t, `var label: STRING = "Add"`, withComponent)
+	ours := _edit(t, `var label: STRING = "Add"`, strings.Replace(withComponent, `"Untitled"`, `"Card"`, 1))
+	theirs := _edit(t, `var label: STRING = "Add"`, strings.Replace(withComponent, `.prop(text = "{prop:title}")`, subtitle, 1)+badge)
+
+	changes := diff.Diff(base, theirs)
+	if !slices.ContainsFunc(changes, func(c diff.Change) bool { return c.Path == "component[card]/block[subtitle]" }) {
+		t.Fatalf("Expected the block added to the definition, got:\n%s", changes.Text())
+	}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %+v", conflicts)
+	}
+	expected := _edit(t, `var label: STRING = "Add"`, strings.Replace(strings.Replace(withComponent, `"Untitled"`, `"Card"`, 1), `.prop(text = "{prop:title}")`, subtitle, 1)+badge)
+	if changes := diff.Diff(merged, expected); len(changes) != 0 {
+		t.Errorf("Unexpected merge result:\n%s", changes.Text())
+	}
+	if blocks := merged.Components[0].Block.Blocks; len(blocks) != 3 || blocks[1].Key != "subtitle" {
+		t.Errorf("Expected the subtitle after the heading, got %+v", blocks)
+	}
+
+	// Removing a block of the definition conflicts with editing it on the other side.
+	removed := _edit(t, `var label: STRING = "Add"`, strings.Replace(withComponent, `            block(keyType = "nativeblocks/text", key = "heading")
+            .prop(text = "{prop:title}")
+`, "", 1))
+	edited := _edit(t, `var label: STRING = "Add"`, strings.Replace(withComponent, `.prop(text = "{prop:title}")`, `.prop(text = "{prop:title}", fontSize = "20")`, 1))
+	_, conflicts = Merge(base, removed, edited)
+	if len(conflicts) != 1 || conflicts[0].Path != "component[card]/block[heading]/prop[fontSize]" {
+		t.Errorf("Expected a conflict on the removed block, got %+v", conflicts)
+	}
+
+	// A new root block replaces the definition and conflicts with edits inside it.
+	rerooted := _edit(t, `var label: STRING = "Add"`, strings.Replace(withComponent, `key = "card")`, `key = "panel")`, 1))
+	_, conflicts = Merge(base, rerooted, edited)
+	if len(conflicts) == 0 || !strings.HasPrefix(conflicts[0].Path, "component[card]/") {
+		t.Errorf("Expected a conflict with the replaced definition, got %+v", conflicts)
+	}
+	if errs := Apply(&base, Make(base, rerooted)); errs != nil {
+		t.Fatalf("Unexpected apply errors: %s", errs[0].Message)
+	}
+	if base.Components[0].Block.Key != "panel" {
+		t.Errorf("Expected the definition to be replaced, got %+v", base.Components[0].Block)
+	}
+}
+
+func TestApplyRejectsStalePatch(t *testing.T) {
+	base := _parse(t, patchBase)
+	target := _edit(t, `.prop(fontSize = "24")`, `.prop(fontSize = "28")`)
+	p := Make(base, target)
+
+	other := _edit(t, `.prop(fontSize = "24")`, `.prop(fontSize = "20")`)
+	errs := Apply(&other, p)
+	if len(errs) == 0 || !strings.Contains(errs[0].Message, "block[title]/prop[fontSize]") {
+		t.Fatalf("Expected a stale patch to be rejected, got %v", errs)
+	}
+	if other.Blocks[0].Blocks[0].Blocks[0].Properties[0].ValueMobile != "20" {
+		t.Error("Expected the frame to be unchanged after a failed apply")
+	}
+}
+
+func TestPatchJSONRoundTrip(t *testing.T) {
+	base := _parse(t, patchBase)
+	target := _edit(t, `.data(text = count)`, `.data(text = count)
+            .prop(fontSize = "18")`)
+
+	content, err := json.Marshal(Make(base, target))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Patch
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if errs := Apply(&base, decoded); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	if changes := diff.Diff(base, target); len(changes) != 0 {
+		t.Errorf("Expected decoded patch to apply cleanly, remaining:\n%s", changes.Text())
+	}
+}
+
+func TestMergeNonOverlapping(t *testing.T) {
+	base := _parse(t, patchBase)
+	ours := _edit(t, `.prop(fontSize = "24")`, `.prop(fontSize = "28")`,
+		`.prop(variableValue = "1")`, `.prop(variableValue = "5")`)
+	theirs := _edit(t, `.prop(backgroundColor = "#2563EB")`, `.prop(backgroundColor = "#000000")`,
+		`.then("SUCCESS")`, `.then("FAILURE")`,
+		`.prop(fontSize = "24")`, `.prop(fontSize = "28")`,
+		`var label: STRING = "Add"`, `var label: STRING = "Add"
+    var title: STRING = "Hi"`)
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %+v", conflicts)
+	}
+
+	expected := _edit(t, `.prop(fontSize = "24")`, `.prop(fontSize = "28")`,
+		`.prop(variableValue = "1")`, `.prop(variableValue = "5")`,
+		`.prop(backgroundColor = "#2563EB")`, `.prop(backgroundColor = "#000000")`,
+		`.then("SUCCESS")`, `.then("FAILURE")`,
+		`var label: STRING = "Add"`, `var label: STRING = "Add"
+    var title: STRING = "Hi"`)
+	if changes := diff.Diff(merged, expected); len(changes) != 0 {
+		t.Errorf("Unexpected merge result:\n%s", changes.Text())
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	base := _parse(t, patchBase)
+
+	ours := _edit(t, `.prop(fontSize = "24")`, `.prop(fontSize = "28")`)
+	theirs := _edit(t, `.prop(fontSize = "24")`, `.prop(fontSize = "32")`)
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 3 {
+		t.Fatalf("Expected a fontSize conflict for each device, got %+v", conflicts)
+	}
+	for _, conflict := range conflicts {
+		if conflict.Path != "block[title]/prop[fontSize]" {
+			t.Errorf("Unexpected conflict path %s", conflict.Path)
+		}
+	}
+	if merged.Blocks[0].Blocks[0].Blocks[0].Properties[0].ValueMobile != "28" {
+		t.Error("Expected conflicts to keep our side")
+	}
+
+	// Removing a block conflicts with edits inside it on the other side.
+	ours = _edit(t, `        block(keyType = "nativeblocks/row", key = "footer", visibility = visible, version = 1)
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "note", visibility = visible, version = 1)
+            .data(text = label)
+        }
+`, ``)
+	theirs = _edit(t, `key = "note", visibility = visible, version = 1)`, `key = "note", visibility = visible, version = 2)`)
+	_, conflicts = Merge(base, ours, theirs)
+	if len(conflicts) == 0 || conflicts[0].Path != "block[note]" {
+		t.Errorf("Expected removing a block to conflict with edits to its children, got %+v", conflicts)
+	}
+
+	// Removing a trigger conflicts with changing it.
+	ours = _edit(t, `                trigger(keyType = "nativeblocks/log", name = "log")
+`, ``)
+	theirs = _edit(t, `trigger(keyType = "nativeblocks/log", name = "log")`, `trigger(keyType = "nativeblocks/log", name = "log")
+                .prop(level = "debug")`)
+	_, conflicts = Merge(base, ours, theirs)
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0].Path, "trigger[log]") {
+		t.Errorf("Expected a trigger conflict, got %+v", conflicts)
+	}
+
+	// Different blocks inserted at the same position would merge in an arbitrary order.
+	ours = _edit(t, `            .data(text = label)
+            block(keyType = "nativeblocks/text", key = "counter"`, `            .data(text = label)
+            block(keyType = "nativeblocks/text", key = "subtitle", visibility = visible, version = 1)
+            block(keyType = "nativeblocks/text", key = "counter"`)
+	theirs = _edit(t, `            .data(text = label)
+            block(keyType = "nativeblocks/text", key = "counter"`, `            .data(text = label)
+            block(keyType = "nativeblocks/image", key = "avatar", visibility = visible, version = 1)
+            block(keyType = "nativeblocks/text", key = "counter"`)
+	merged, conflicts = Merge(base, ours, theirs)
+	if len(conflicts) != 1 || conflicts[0].Path != "block[avatar]" {
+		t.Errorf("Expected insertions at the same position to conflict, got %+v", conflicts)
+	}
+	if _findBlock(merged.Blocks, "avatar") != nil {
+		t.Error("Expected the conflicting insertion to keep our side")
+	}
+
+	// Removing a variable that the other side binds to is reported after validation.
+	ours = _edit(t, `    var label: STRING = "Add"
+`, ``, `.data(text = label)`, `.data(text = count)`, `.data(text = label)`, `.data(text = count)`, `.data(text = label)`, `.data(text = count)`)
+	theirs = _edit(t, `.data(variableKey = count)`, `.data(variableKey = label)`)
+	_, conflicts = Merge(base, ours, theirs)
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0].Theirs, "Undefined variable 'label'") {
+		t.Errorf("Expected a dangling variable conflict, got %+v", conflicts)
+	}
+}
@@ -0,0 +1,190 @@
+package preview
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+func renderRoot(ctx *BlockContext) string {
+	return _element("div", "nbx-root", ctx, ctx.Style(), ctx.Children())
+}
+
+func renderColumn(ctx *BlockContext) string {
+	styles := []string{ctx.Style()}
+	if align := _cssAlign(ctx.Prop("horizontalAlignment")); align != "" {
+		styles = append(styles, "align-items:"+align)
+	}
+	if justify := _cssJustify(ctx.Prop("verticalArrangement")); justify != "" {
+		styles = append(styles, "justify-content:"+justify)
+	}
+	return _element("div", "nbx-column", ctx, _joinStyles(styles), ctx.Children())
+}
+
+func renderRow(ctx *BlockContext) string {
+	styles := []string{ctx.Style()}
+	if align := _cssAlign(ctx.Prop("verticalAlignment")); align != "" {
+		styles = append(styles, "align-items:"+align)
+	}
+	if justify := _cssJustify(ctx.Prop("horizontalArrangement")); justify != "" {
+		styles = append(styles, "justify-content:"+justify)
+	}
+	return _element("div", "nbx-row", ctx, _joinStyles(styles), ctx.Children())
+}
+
+func renderBox(ctx *BlockContext) string {
+	styles := []string{ctx.Style()}
+	if align := _cssAlign(ctx.Prop("verticalAlignment")); align != "" {
+		styles = append(styles, "justify-content:"+align)
+	}
+	return _element("div", "nbx-box", ctx, _joinStyles(styles), ctx.Children())
+}
+
+func renderText(ctx *BlockContext) string {
+	return _element("span", "nbx-text", ctx, ctx.Style(), html.EscapeString(ctx.Data("text")))
+}
+
+func renderImage(ctx *BlockContext) string {
+	alt := ctx.Data("contentDescription")
+	return fmt.Sprintf("<img class=\"nbx-image\" data-key=\"%s\" src=\"%s\" alt=\"%s\" style=\"%s\">\n",
+		html.EscapeString(ctx.Block.Key),
+		html.EscapeString(ctx.Data("imageUrl")),
+		html.EscapeString(alt),
+		html.EscapeString(ctx.Style()))
+}
+
+func renderButton(ctx *BlockContext) string {
+	content := ctx.Slot("leadingIcon") + html.EscapeString(ctx.Data("text")) + ctx.Slot("trailingIcon")
+	if ctx.Data("enable") == "false" {
+		return _element("button disabled", "nbx-button", ctx, ctx.Style(), content)
+	}
+	return _element("button", "nbx-button", ctx, ctx.Style(), content)
+}
+
+func renderSpacer(ctx *BlockContext) string {
+	return _element("div", "nbx-spacer", ctx, ctx.Style(), "")
+}
+
+func renderTextField(ctx *BlockContext) string {
+	return fmt.Sprintf("<input class=\"nbx-text-field\" data-key=\"%s\" value=\"%s\" placeholder=\"%s\" style=\"%s\">\n",
+		html.EscapeString(ctx.Block.Key),
+		html.EscapeString(ctx.Data("text")),
+		html.EscapeString(ctx.Data("placeholder")),
+		html.EscapeString(ctx.Style()))
+}
+
+func renderPlaceholder(ctx *BlockContext) string {
+	label := fmt.Sprintf("<span class=\"nbx-placeholder-label\">%s (%s)</span>\n",
+		html.EscapeString(ctx.Block.KeyType), html.EscapeString(ctx.Block.Key))
+	return _element("div", "nbx-placeholder", ctx, ctx.Style(), label+ctx.Children())
+}
+
+// _element writes an element with the common data-key attribute. tag may carry extra boolean attributes.
+func _element(tag, class string, ctx *BlockContext, style, content string) string {
+	name := strings.Fields(tag)[0]
+	attrs := fmt.Sprintf(" class=\"%s\" data-key=\"%s\"", class, html.EscapeString(ctx.Block.Key))
+	if style != "" {
+		attrs += fmt.Sprintf(" style=\"%s\"", html.EscapeString(style))
+	}
+	return fmt.Sprintf("<%s%s>\n%s</%s>\n", tag, attrs, _ensureNewline(content), name)
+}
+
+func _ensureNewline(content string) string {
+	if content == "" || strings.HasSuffix(content, "\n") {
+		return content
+	}
+	return content + "\n"
+}
+
+func _joinStyles(styles []string) string {
+	parts := make([]string, 0, len(styles))
+	for _, style := range styles {
+		if style != "" {
+			parts = append(parts, style)
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+func _cssSize(value string) string {
+	switch value {
+	case "":
+		return ""
+	case "match", "fill":
+		return "100%"
+	case "wrap":
+		return "auto"
+	default:
+		return _cssLength(value)
+	}
+}
+
+func _cssLength(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "0"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value + "px"
+	}
+	return value
+}
+
+// _cssColor converts nativeblocks colors (#RGB, #ARGB, #RRGGBB or #AARRGGBB) to CSS. CSS puts alpha
+// last, so only the forms with alpha need converting.
+func _cssColor(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "#") {
+		return value
+	}
+	hex := value[1:]
+	if len(hex) == 4 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2], hex[3], hex[3]})
+	}
+	if len(hex) != 8 {
+		return value
+	}
+	alpha, err := strconv.ParseUint(hex[0:2], 16, 8)
+	if err != nil {
+		return value
+	}
+	rgb, err := strconv.ParseUint(hex[2:], 16, 32)
+	if err != nil {
+		return value
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%s)", rgb>>16&0xff, rgb>>8&0xff, rgb&0xff,
+		strconv.FormatFloat(float64(alpha)/255, 'f', 2, 64))
+}
+
+func _cssAlign(value string) string {
+	switch value {
+	case "centerHorizontally", "centerVertically", "center":
+		return "center"
+	case "start", "top":
+		return "flex-start"
+	case "end", "bottom":
+		return "flex-end"
+	default:
+		return ""
+	}
+}
+
+func _cssJustify(value string) string {
+	switch value {
+	case "spaceAround":
+		return "space-around"
+	case "spaceBetween":
+		return "space-between"
+	case "spaceEvenly":
+		return "space-evenly"
+	case "center":
+		return "center"
+	case "start", "top":
+		return "flex-start"
+	case "end", "bottom":
+		return "flex-end"
+	default:
+		return ""
+	}
+}
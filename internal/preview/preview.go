@@ -0,0 +1,380 @@
+package preview
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/expr"
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+type Device string
+
+const (
+	DeviceMobile  Device = "mobile"
+	DeviceTablet  Device = "tablet"
+	DeviceDesktop Device = "desktop"
+)
+
+// Devices lists the supported device classes in a stable order.
+var Devices = []Device{DeviceMobile, DeviceTablet, DeviceDesktop}
+
+// DeviceFromString returns the device class for the given name.
+func DeviceFromString(name string) (Device, error) {
+	switch strings.ToLower(name) {
+	case "mobile":
+		return DeviceMobile, nil
+	case "tablet":
+		return DeviceTablet, nil
+	case "desktop":
+		return DeviceDesktop, nil
+	default:
+		return "", fmt.Errorf("unknown device: %s. Valid devices: mobile, tablet, desktop", name)
+	}
+}
+
+// Width returns the viewport width in pixels used for the device class.
+func (d Device) Width() int {
+	switch d {
+	case DeviceTablet:
+		return 820
+	case DeviceDesktop:
+		return 1440
+	default:
+		return 390
+	}
+}
+
+// Height returns the viewport height in pixels used for the device class.
+func (d Device) Height() int {
+	switch d {
+	case DeviceTablet:
+		return 1180
+	case DeviceDesktop:
+		return 900
+	default:
+		return 844
+	}
+}
+
+// PropertyValue returns the value of a block property for the given device class.
+func PropertyValue(prop model.BlockPropertyDSLModel, device Device) string {
+	switch device {
+	case DeviceTablet:
+		return prop.ValueTablet
+	case DeviceDesktop:
+		return prop.ValueDesktop
+	default:
+		return prop.ValueMobile
+	}
+}
+
+// BlockRenderer renders a single block to HTML.
+type BlockRenderer func(ctx *BlockContext) string
+
+// BlockContext gives a BlockRenderer access to the block, its resolved values and its rendered slots.
+type BlockContext struct {
+	Block    model.BlockDSLModel
+	Device   Device
+	renderer *Renderer
+	slots    map[string]string
+}
+
+// Prop returns the device specific value of a property, or an empty string.
+func (c *BlockContext) Prop(key string) string {
+	for _, prop := range c.Block.Properties {
+		if prop.Key == key {
+			return PropertyValue(prop, c.Device)
+		}
+	}
+	return ""
+}
+
+// Data returns the value of a data entry, resolving variable bindings to their current value.
+func (c *BlockContext) Data(key string) string {
+	for _, data := range c.Block.Data {
+		if data.Key == key {
+			return c.renderer._resolve(data.Value)
+		}
+	}
+	return ""
+}
+
+// Slot returns the rendered HTML of all children placed in the named slot.
+func (c *BlockContext) Slot(name string) string {
+	return c.slots[name]
+}
+
+// Children returns the rendered HTML of all slots, declared slots first.
+func (c *BlockContext) Children() string {
+	var builder strings.Builder
+	written := make(map[string]bool)
+	for _, slot := range c.Block.Slots {
+		builder.WriteString(c.slots[slot.Slot])
+		written[slot.Slot] = true
+	}
+	for _, child := range c.Block.Blocks {
+		name := _slotName(child.Slot)
+		if !written[name] {
+			builder.WriteString(c.slots[name])
+			written[name] = true
+		}
+	}
+	return builder.String()
+}
+
+// Style returns inline CSS for the common layout props (size, padding, colors, border and radius).
+func (c *BlockContext) Style() string {
+	var styles []string
+
+	if width := _cssSize(c.Prop("width")); width != "" {
+		styles = append(styles, "width:"+width)
+	}
+	if height := _cssSize(c.Prop("height")); height != "" {
+		styles = append(styles, "height:"+height)
+	}
+	if weight := strings.TrimSuffix(strings.TrimSuffix(c.Prop("weight"), "f"), "F"); weight != "" && weight != "0" && weight != "0.0" {
+		styles = append(styles, "flex:"+weight)
+	}
+
+	padding := []string{
+		c._firstProp("paddingTop"),
+		c._firstProp("paddingEnd", "paddingTrailing"),
+		c._firstProp("paddingBottom"),
+		c._firstProp("paddingStart", "paddingLeading"),
+	}
+	if strings.Join(padding, "") != "" {
+		for i, p := range padding {
+			padding[i] = _cssLength(p)
+		}
+		styles = append(styles, "padding:"+strings.Join(padding, " "))
+	}
+
+	if color := _cssColor(c.Prop("backgroundColor")); color != "" {
+		styles = append(styles, "background-color:"+color)
+	}
+	if color := _cssColor(c._firstProp("textColor", "foregroundColor", "contentColor")); color != "" {
+		styles = append(styles, "color:"+color)
+	}
+	if size := c.Prop("fontSize"); size != "" {
+		styles = append(styles, "font-size:"+_cssLength(size))
+	}
+	if align := c.Prop("textAlign"); align != "" {
+		styles = append(styles, "text-align:"+align)
+	}
+
+	if width := c.Prop("borderWidth"); width != "" && width != "0" {
+		border := "border:" + _cssLength(width) + " solid"
+		if color := _cssColor(c.Prop("borderColor")); color != "" {
+			border += " " + color
+		}
+		styles = append(styles, border)
+	}
+
+	radius := []string{
+		c.Prop("radiusTopStart"),
+		c.Prop("radiusTopEnd"),
+		c.Prop("radiusBottomEnd"),
+		c.Prop("radiusBottomStart"),
+	}
+	if strings.Join(radius, "") != "" {
+		for i, r := range radius {
+			radius[i] = _cssLength(r)
+		}
+		styles = append(styles, "border-radius:"+strings.Join(radius, " "))
+	}
+
+	return strings.Join(styles, ";")
+}
+
+func (c *BlockContext) _firstProp(keys ...string) string {
+	for _, key := range keys {
+		if value := c.Prop(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+type Renderer struct {
+	device    Device
+	renderers map[string]BlockRenderer
+	variables map[string]model.VariableDSLModel
+	values    map[string]any
+}
+
+// NewRenderer creates a Renderer for the given device class with the default nativeblocks mappings registered.
+func NewRenderer(device Device) *Renderer {
+	r := &Renderer{
+		device:    device,
+		renderers: make(map[string]BlockRenderer),
+		variables: make(map[string]model.VariableDSLModel),
+	}
+
+	r.Register("ROOT", renderRoot)
+	for _, keyType := range []string{"nativeblocks/vstack", "nativeblocks/column", "nativeblocks/lazy_column"} {
+		r.Register(keyType, renderColumn)
+	}
+	for _, keyType := range []string{"nativeblocks/hstack", "nativeblocks/row", "nativeblocks/lazy_row"} {
+		r.Register(keyType, renderRow)
+	}
+	r.Register("nativeblocks/box", renderBox)
+	r.Register("nativeblocks/text", renderText)
+	r.Register("nativeblocks/image", renderImage)
+	r.Register("nativeblocks/button", renderButton)
+	r.Register("nativeblocks/spacer", renderSpacer)
+	r.Register("nativeblocks/text_field", renderTextField)
+
+	return r
+}
+
+// Register sets the renderer used for blocks of the given keyType, replacing any existing mapping.
+func (r *Renderer) Register(keyType string, fn BlockRenderer) {
+	r.renderers[keyType] = fn
+}
+
+// Render renders the frame into a standalone HTML document.
+func (r *Renderer) Render(frame model.FrameDSLModel) string {
+	r.variables = make(map[string]model.VariableDSLModel, len(frame.Variables))
+	for _, variable := range frame.Variables {
+		// Computed variables are only known from their evaluated values.
+		if !variable.Computed {
+			r.variables[variable.Key] = variable
+		}
+	}
+	r.values = expr.Values(frame.Variables)
+
+	var builder strings.Builder
+	builder.WriteString("<!DOCTYPE html>\n")
+	builder.WriteString("<html>\n<head>\n")
+	builder.WriteString("<meta charset=\"utf-8\">\n")
+	builder.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(frame.Name)))
+	builder.WriteString("<style>\n")
+	builder.WriteString(baseStyle)
+	builder.WriteString("</style>\n")
+	builder.WriteString("</head>\n<body>\n")
+	builder.WriteString(fmt.Sprintf("<div class=\"nbx-frame nbx-%s\" data-route=\"%s\" style=\"width:%dpx;min-height:%dpx\">\n",
+		r.device, html.EscapeString(frame.Route), r.device.Width(), r.device.Height()))
+
+	for _, block := range frame.Blocks {
+		builder.WriteString(r._renderBlock(block))
+	}
+
+	builder.WriteString("</div>\n</body>\n</html>\n")
+	return builder.String()
+}
+
+func (r *Renderer) _renderBlock(block model.BlockDSLModel) string {
+	if block.Repeat != nil {
+		return r._renderRepeat(block)
+	}
+	if !r._isVisible(block) {
+		return ""
+	}
+
+	ctx := &BlockContext{
+		Block:    block,
+		Device:   r.device,
+		renderer: r,
+		slots:    make(map[string]string),
+	}
+
+	for _, child := range block.Blocks {
+		ctx.slots[_slotName(child.Slot)] += r._renderBlock(child)
+	}
+
+	fn, exists := r.renderers[block.KeyType]
+	if !exists {
+		fn = renderPlaceholder
+	}
+	return fn(ctx)
+}
+
+// _renderRepeat renders a repeated block once per element of its items, with the element bound to the
+// loop variable.
+func (r *Renderer) _renderRepeat(block model.BlockDSLModel) string {
+	repeat := *block.Repeat
+	items, _ := expr.Lookup(r.values, repeat.Items)
+	elements, _ := items.([]any)
+
+	previous, shadowed := r.values[repeat.As]
+	block.Repeat = nil
+	var builder strings.Builder
+	for _, element := range elements {
+		r.values[repeat.As] = element
+		builder.WriteString(r._renderBlock(block))
+	}
+	if shadowed {
+		r.values[repeat.As] = previous
+	} else {
+		delete(r.values, repeat.As)
+	}
+	return builder.String()
+}
+
+func (r *Renderer) _isVisible(block model.BlockDSLModel) bool {
+	if block.VisibilityKey == "" {
+		return true
+	}
+	if expr.IsExpression(block.VisibilityKey) {
+		value, err := expr.EvalValue(block.VisibilityKey, r.values)
+		return err != nil || expr.Truthy(value)
+	}
+	if variable, exists := r.variables[block.VisibilityKey]; exists {
+		return variable.Value != "false"
+	}
+	if value, exists := expr.Lookup(r.values, block.VisibilityKey); exists {
+		return expr.Truthy(value)
+	}
+	return true
+}
+
+func (r *Renderer) _resolve(value string) string {
+	if expr.IsExpression(value) {
+		if result, err := expr.EvalValue(value, r.values); err == nil {
+			return expr.Format(result)
+		}
+		return value
+	}
+	if expr.IsTemplate(value) {
+		if result, err := expr.EvalTemplate(value, r.values); err == nil {
+			return result
+		}
+		return value
+	}
+	if variable, exists := r.variables[value]; exists {
+		return variable.Value
+	}
+	if result, exists := expr.Lookup(r.values, value); exists {
+		return expr.Format(result)
+	}
+	if value == "null" {
+		return ""
+	}
+	return value
+}
+
+func _slotName(slot string) string {
+	if slot == "" || slot == "null" {
+		return "content"
+	}
+	return slot
+}
+
+// Render renders a frame for the device class using the default block mappings.
+func Render(frame model.FrameDSLModel, device Device) string {
+	return NewRenderer(device).Render(frame)
+}
+
+const baseStyle = `body { margin: 0; padding: 24px; background: #f3f4f6; font-family: -apple-system, "Segoe UI", Roboto, sans-serif; }
+.nbx-frame { box-sizing: border-box; background: #ffffff; display: flex; flex-direction: column; overflow: hidden; }
+.nbx-frame * { box-sizing: border-box; }
+.nbx-root, .nbx-column, .nbx-box { display: flex; flex-direction: column; }
+.nbx-row { display: flex; flex-direction: row; }
+.nbx-root { flex: 1; }
+.nbx-button { border: none; padding: 8px 16px; background: #2563EB; color: #ffffff; }
+.nbx-image { display: block; object-fit: contain; }
+.nbx-placeholder { border: 1px dashed #9ca3af; color: #6b7280; padding: 8px; font-size: 12px; }
+.nbx-placeholder-label { font-family: monospace; }
+`
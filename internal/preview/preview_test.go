@@ -0,0 +1,166 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+func _previewFrame() model.FrameDSLModel {
+	return model.FrameDSLModel{
+		Name:  "welcome",
+		Route: "/welcome",
+		Type:  "FRAME",
+		Variables: []model.VariableDSLModel{
+			{Key: "visible", Type: "BOOLEAN", Value: "true"},
+			{Key: "hidden", Type: "BOOLEAN", Value: "false"},
+			{Key: "title", Type: "STRING", Value: "Hello <World>"},
+		},
+		Blocks: []model.BlockDSLModel{
+			{
+				KeyType: "ROOT",
+				Key:     "root",
+				Slots:   []model.BlockSlotDSLModel{{Slot: "content"}},
+				Blocks: []model.BlockDSLModel{
+					{
+						KeyType:       "nativeblocks/column",
+						Key:           "main",
+						Slot:          "content",
+						VisibilityKey: "visible",
+						Properties: []model.BlockPropertyDSLModel{
+							{Key: "width", ValueMobile: "match", ValueTablet: "match", ValueDesktop: "match"},
+							{Key: "paddingTop", ValueMobile: "8", ValueTablet: "16", ValueDesktop: "32"},
+						},
+						Slots: []model.BlockSlotDSLModel{{Slot: "content"}},
+						Blocks: []model.BlockDSLModel{
+							{
+								KeyType: "nativeblocks/text",
+								Key:     "titleText",
+								Slot:    "content",
+								Data:    []model.BlockDataDSLModel{{Key: "text", Value: "title"}},
+							},
+							{
+								KeyType:       "nativeblocks/text",
+								Key:           "hiddenText",
+								Slot:          "content",
+								VisibilityKey: "hidden",
+							},
+							{
+								KeyType: "acme/chart",
+								Key:     "chart",
+								Slot:    "content",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRenderDeviceValues(t *testing.T) {
+	frame := _previewFrame()
+
+	mobile := Render(frame, DeviceMobile)
+	desktop := Render(frame, DeviceDesktop)
+
+	if !strings.Contains(mobile, "padding:8px 0 0 0") {
+		t.Errorf("Expected mobile padding in output:\n%s", mobile)
+	}
+	if !strings.Contains(desktop, "padding:32px 0 0 0") {
+		t.Errorf("Expected desktop padding in output:\n%s", desktop)
+	}
+	if !strings.Contains(desktop, "width:1440px") {
+		t.Errorf("Expected desktop viewport width in output")
+	}
+}
+
+func TestRenderDataBindingAndVisibility(t *testing.T) {
+	output := Render(_previewFrame(), DeviceMobile)
+
+	if !strings.Contains(output, "Hello &lt;World&gt;") {
+		t.Errorf("Expected escaped variable value in output:\n%s", output)
+	}
+	if strings.Contains(output, "hiddenText") {
+		t.Errorf("Expected hidden block to be skipped:\n%s", output)
+	}
+}
+
+func TestRenderExpressions(t *testing.T) {
+	frame := _previewFrame()
+	main := &frame.Blocks[0].Blocks[0]
+	main.Blocks[0].Data[0].Value = `$(upper(title) + "!")`
+	main.Blocks[1].VisibilityKey = `$(!hidden && len(title) > 3)`
+
+	output := Render(frame, DeviceMobile)
+	if !strings.Contains(output, "HELLO &lt;WORLD&gt;!") {
+		t.Errorf("Expected the evaluated data expression in output:\n%s", output)
+	}
+	if !strings.Contains(output, "hiddenText") {
+		t.Errorf("Expected the block whose visibility expression is true to render:\n%s", output)
+	}
+}
+
+func TestRenderPlaceholder(t *testing.T) {
+	output := Render(_previewFrame(), DeviceMobile)
+
+	if !strings.Contains(output, "nbx-placeholder") || !strings.Contains(output, "acme/chart (chart)") {
+		t.Errorf("Expected labelled placeholder for unknown block:\n%s", output)
+	}
+}
+
+func TestRegisterCustomRenderer(t *testing.T) {
+	renderer := NewRenderer(DeviceMobile)
+	renderer.Register("acme/chart", func(ctx *BlockContext) string {
+		return "<canvas id=\"" + ctx.Block.Key + "\"></canvas>\n"
+	})
+
+	output := renderer.Render(_previewFrame())
+	if !strings.Contains(output, "<canvas id=\"chart\"></canvas>") {
+		t.Errorf("Expected custom renderer output:\n%s", output)
+	}
+	if strings.Contains(output, "class=\"nbx-placeholder\"") {
+		t.Errorf("Expected no placeholder when renderer is registered")
+	}
+}
+
+func TestRenderIsDeterministic(t *testing.T) {
+	frame := _previewFrame()
+	if Render(frame, DeviceTablet) != Render(frame, DeviceTablet) {
+		t.Error("Expected identical output for identical input")
+	}
+}
+
+func TestCSSColor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"#2563EB", "#2563EB"},
+		{"#FF2563EB", "rgba(37,99,235,1.00)"},
+		{"#00000000", "rgba(0,0,0,0.00)"},
+		{"#FFF", "#FFF"},
+		{"#F25E", "rgba(34,85,238,1.00)"},
+		{"red", "red"},
+	}
+
+	for _, tt := range tests {
+		if result := _cssColor(tt.input); result != tt.expected {
+			t.Errorf("_cssColor(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestRenderRepeat(t *testing.T) {
+	frame := _previewFrame()
+	frame.Variables = append(frame.Variables, model.VariableDSLModel{Key: "names", Type: "LIST<STRING>", Value: `["Tea", "Coffee"]`})
+	title := &frame.Blocks[0].Blocks[0].Blocks[0]
+	title.Repeat = &model.RepeatDSLModel{Items: "names", As: "name"}
+	title.Data[0].Value = "Buy {name}"
+
+	output := Render(frame, DeviceMobile)
+	if !strings.Contains(output, "Buy Tea") || !strings.Contains(output, "Buy Coffee") {
+		t.Errorf("Expected the block to render once per element:\n%s", output)
+	}
+}
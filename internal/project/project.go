@@ -0,0 +1,500 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/compiler"
+	"github.com/nativeblocks/nbx/internal/detector"
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/i18n"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/theme"
+	"github.com/nativeblocks/nbx/internal/validator"
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+const (
+	// BlocksFile and ActionsFile are the integration registries read from the root of a project.
+	BlocksFile  = "blocks.json"
+	ActionsFile = "actions.json"
+	// ThemeFile is the theme of design tokens read from the root of a project.
+	ThemeFile = "theme.json"
+	// BundlesDir holds the locale bundles of string resources, one .json, .xlf or .xliff file per locale.
+	BundlesDir = "i18n"
+)
+
+// routeParameter matches a route segment that is a {placeholder}.
+var routeParameter = regexp.MustCompile(`^\{[^{}/]+\}$`)
+
+// Frame is a frame of a project and the file it was loaded from.
+type Frame struct {
+	File  string
+	Frame model.FrameDSLModel
+}
+
+// Edge is a navigation found in a trigger that navigates to a route. To is the name of the frame the
+// route leads to, empty when no frame matches the route.
+type Edge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Route   string `json:"route"`
+	Trigger string `json:"trigger"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// Project is a set of frames with their navigation graph and, when the project provides them, the
+// integration registry, theme and locale bundles the frames are validated against.
+type Project struct {
+	Frames   []Frame
+	Registry *validator.IntegrationRegistry
+	Theme    *theme.Theme
+	Bundles  []*i18n.Bundle
+	Edges    []Edge
+}
+
+// ParseFunc parses the frame file at name in fsys together with its imports.
+type ParseFunc func(fsys fs.FS, name string) (model.FrameDSLModel, []*errors.Error)
+
+// Load parses every frame file (.nbx and .xml) in fsys with parse, skipping libraries. It loads the
+// integration registry from blocks.json and actions.json, the theme from theme.json and the locale
+// bundles from the i18n directory at the root when they exist, and checks the project. Frames that fail
+// to parse are left out of the project.
+func Load(fsys fs.FS, parse ParseFunc) (*Project, []*errors.Error) {
+	var frames []Frame
+	var errs []*errors.Error
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(path.Ext(name))
+		if entry.IsDir() && name == BundlesDir {
+			return fs.SkipDir
+		}
+		if entry.IsDir() || (ext != ".nbx" && ext != ".xml") {
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if detector.IsLibrary(string(content)) {
+			return nil
+		}
+
+		frame, frameErrs := parse(fsys, name)
+		errs = append(errs, frameErrs...)
+		if !_hasErrors(frameErrs) {
+			frames = append(frames, Frame{File: name, Frame: frame})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, append(errs, &errors.Error{
+			Severity: errors.SeverityError,
+			Message:  fmt.Sprintf("Cannot read project: %v", err),
+		})
+	}
+
+	registry, registryErrs := _loadRegistry(fsys)
+	errs = append(errs, registryErrs...)
+
+	p := New(frames, registry)
+	if content, err := fs.ReadFile(fsys, ThemeFile); err == nil {
+		if p.Theme, err = theme.Parse(string(content)); err != nil {
+			errs = append(errs, &errors.Error{
+				Severity: errors.SeverityError,
+				Message:  err.Error(),
+				File:     ThemeFile,
+			})
+		}
+	}
+	bundles, bundleErrs := _loadBundles(fsys)
+	p.Bundles = bundles
+	errs = append(errs, bundleErrs...)
+	errs = append(errs, p.Check()...)
+	return p, _withSourceLines(fsys, errs)
+}
+
+// New creates a project of frames and builds its navigation graph. registry may be nil.
+func New(frames []Frame, registry *validator.IntegrationRegistry) *Project {
+	p := &Project{Frames: frames, Registry: registry}
+	p.Edges = p._navigation()
+	return p
+}
+
+// Frame returns the frame named name, or nil.
+func (p *Project) Frame(name string) *Frame {
+	for i := range p.Frames {
+		if p.Frames[i].Frame.Name == name {
+			return &p.Frames[i]
+		}
+	}
+	return nil
+}
+
+// Match returns the frame whose route matches route, or nil. A {placeholder} segment of a frame route
+// matches any segment, and a segment of route containing a placeholder matches any segment too. When
+// several frames match, the one whose segments match most exactly wins: /user/me prefers a /user/me
+// frame and /user/{userId} a /user/{id} frame.
+func (p *Project) Match(route string) *Frame {
+	var best *Frame
+	bestScore := -1
+	for i := range p.Frames {
+		score, ok := _matchRoute(p.Frames[i].Frame.Route, route)
+		if ok && score > bestScore {
+			best, bestScore = &p.Frames[i], score
+		}
+	}
+	return best
+}
+
+// Starters returns the frames marked with starter = true.
+func (p *Project) Starters() []*Frame {
+	var starters []*Frame
+	for i := range p.Frames {
+		if p.Frames[i].Frame.Starter {
+			starters = append(starters, &p.Frames[i])
+		}
+	}
+	return starters
+}
+
+// Reachable returns the names of the frames reachable from the frame named from, including itself.
+func (p *Project) Reachable(from string) map[string]bool {
+	reached := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, edge := range p.Edges {
+			if edge.From == name && edge.To != "" && !reached[edge.To] {
+				reached[edge.To] = true
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	return reached
+}
+
+// Check validates the project: frame names and routes are unique, no two routes match the same paths,
+// every navigation leads to a frame, exactly one frame is the starter and every frame can be reached
+// from it. With a registry, a theme and bundles, every frame is also validated against the integrations
+// and its token and string resource references checked.
+func (p *Project) Check() []*errors.Error {
+	var errs []*errors.Error
+
+	names := make(map[string]*Frame)
+	routes := make(map[string]*Frame)
+	patterns := make(map[string]*Frame)
+	for i := range p.Frames {
+		frame := &p.Frames[i]
+
+		if first, exists := names[frame.Frame.Name]; exists {
+			errs = append(errs, _frameError(frame, _declaredIn(fmt.Sprintf("Frame '%s'", first.Frame.Name), first),
+				"Duplicate frame name '%s'", frame.Frame.Name))
+		} else {
+			names[frame.Frame.Name] = frame
+		}
+
+		if first, exists := routes[frame.Frame.Route]; exists {
+			errs = append(errs, _frameError(frame, _declaredIn(fmt.Sprintf("Route '%s'", first.Frame.Route), first),
+				"Duplicate route '%s' in frames '%s' and '%s'", frame.Frame.Route, first.Frame.Name, frame.Frame.Name))
+			continue
+		}
+		routes[frame.Frame.Route] = frame
+
+		pattern := _routePattern(frame.Frame.Route)
+		if first, exists := patterns[pattern]; exists {
+			errs = append(errs, _frameError(frame, _declaredIn(fmt.Sprintf("Route '%s'", first.Frame.Route), first),
+				"Route '%s' of frame '%s' conflicts with route '%s' of frame '%s'",
+				frame.Frame.Route, frame.Frame.Name, first.Frame.Route, first.Frame.Name))
+		} else {
+			patterns[pattern] = frame
+		}
+	}
+
+	for _, edge := range p.Edges {
+		if edge.To == "" {
+			errs = append(errs, &errors.Error{
+				Severity:   errors.SeverityError,
+				Message:    fmt.Sprintf("Route '%s' of trigger '%s' does not match any frame", edge.Route, edge.Trigger),
+				File:       edge.File,
+				Line:       edge.Line,
+				Column:     edge.Column,
+				Suggestion: "Navigate to the route of a frame in the project",
+			})
+		}
+	}
+
+	starters := p.Starters()
+	switch {
+	case len(starters) == 0 && len(p.Frames) > 0:
+		errs = append(errs, &errors.Error{
+			Severity:   errors.SeverityError,
+			Message:    "No starter frame",
+			Suggestion: "Add starter = true to the frame the app opens with",
+		})
+	case len(starters) > 1:
+		for _, starter := range starters[1:] {
+			errs = append(errs, _frameError(starter, _declaredIn(fmt.Sprintf("Starter frame '%s'", starters[0].Frame.Name), starters[0]),
+				"Multiple starter frames: '%s' and '%s'", starters[0].Frame.Name, starter.Frame.Name))
+		}
+	case len(starters) == 1:
+		reached := p.Reachable(starters[0].Frame.Name)
+		for i := range p.Frames {
+			frame := &p.Frames[i]
+			if !reached[frame.Frame.Name] {
+				err := _frameError(frame, "", "Frame '%s' is not reachable from the starter frame '%s'",
+					frame.Frame.Name, starters[0].Frame.Name)
+				err.Severity = errors.SeverityWarning
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if p.Theme != nil {
+		for i := range p.Frames {
+			frame := _expanded(p.Frames[i])
+			for _, err := range theme.Resolve(&frame, p.Theme, true) {
+				err.File = p.Frames[i].File
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(p.Bundles) > 0 {
+		sources := make([]i18n.Source, len(p.Frames))
+		for i := range p.Frames {
+			expanded := _expanded(p.Frames[i])
+			sources[i] = i18n.Source{File: p.Frames[i].File, Frame: &expanded}
+		}
+		errs = append(errs, i18n.Check(sources, p.Bundles)...)
+	}
+
+	if p.Registry != nil {
+		integrations := validator.NewIntegrationValidator(p.Registry)
+		for i := range p.Frames {
+			frame := &p.Frames[i]
+			expanded := _expanded(*frame)
+			if err := integrations.ValidateFrame(&expanded); err != nil {
+				errs = append(errs, &errors.Error{
+					Severity: errors.SeverityError,
+					Message:  err.Error(),
+					File:     frame.File,
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// _expanded returns a copy of the frame with its constants resolved and its components expanded, as it
+// is compiled. Expansion errors are reported when the frame is parsed.
+func _expanded(frame Frame) model.FrameDSLModel {
+	expanded, _, _ := compiler.Expand(frame.Frame)
+	return expanded
+}
+
+// IsNavigation reports whether a trigger key type navigates, by the last segment of the key type:
+// NAVIGATE, nativeblocks/navigate and navigate_to all do.
+func IsNavigation(keyType string) bool {
+	name := strings.ToLower(keyType[strings.LastIndex(keyType, "/")+1:])
+	return strings.HasPrefix(name, "navigate") || strings.HasPrefix(name, "navigation")
+}
+
+// _navigation collects the edges of navigation triggers with a route property, in frame order.
+func (p *Project) _navigation() []Edge {
+	var edges []Edge
+	for i := range p.Frames {
+		frame := &p.Frames[i]
+		expanded := _expanded(*frame)
+		walker.Walk(&expanded, walker.Visitor{
+			Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+				if node.Kind != walker.KindTrigger || !IsNavigation(node.Trigger.KeyType) {
+					return walker.Continue
+				}
+				for _, prop := range node.Trigger.Properties {
+					if prop.Key != "route" {
+						continue
+					}
+					edge := Edge{
+						From:    frame.Frame.Name,
+						Route:   prop.Value,
+						Trigger: node.Trigger.Name,
+						File:    frame.File,
+						Line:    prop.Line,
+						Column:  prop.Column,
+					}
+					if target := p.Match(prop.Value); target != nil {
+						edge.To = target.Frame.Name
+					}
+					edges = append(edges, edge)
+				}
+				return walker.Continue
+			},
+		})
+	}
+	return edges
+}
+
+// _matchRoute reports whether target matches the frame route pattern and how many segments matched
+// exactly: equal literals, or a placeholder for a placeholder.
+func _matchRoute(pattern, target string) (int, bool) {
+	if i := strings.IndexAny(target, "?#"); i >= 0 {
+		target = target[:i]
+	}
+	patternSegments := _segments(pattern)
+	targetSegments := _segments(target)
+	if len(patternSegments) != len(targetSegments) {
+		return 0, false
+	}
+
+	score := 0
+	for i, segment := range patternSegments {
+		parameter := routeParameter.MatchString(segment)
+		placeholder := strings.Contains(targetSegments[i], "{")
+		switch {
+		case parameter == placeholder && (parameter || segment == targetSegments[i]):
+			score++
+		case parameter || placeholder:
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// _routePattern returns the route with every placeholder replaced by {}, so that routes matching the
+// same paths have the same pattern.
+func _routePattern(route string) string {
+	segments := _segments(route)
+	for i, segment := range segments {
+		if routeParameter.MatchString(segment) {
+			segments[i] = "{}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func _segments(route string) []string {
+	trimmed := strings.Trim(route, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
+
+func _loadRegistry(fsys fs.FS) (*validator.IntegrationRegistry, []*errors.Error) {
+	blocks, blocksErr := fs.ReadFile(fsys, BlocksFile)
+	actions, actionsErr := fs.ReadFile(fsys, ActionsFile)
+	if blocksErr != nil && actionsErr != nil {
+		return nil, nil
+	}
+	if blocksErr != nil {
+		blocks = []byte("{}")
+	}
+	if actionsErr != nil {
+		actions = []byte("{}")
+	}
+
+	registry, err := validator.LoadIntegrations(string(blocks), string(actions))
+	if err != nil {
+		return nil, []*errors.Error{{
+			Severity: errors.SeverityError,
+			Message:  err.Error(),
+		}}
+	}
+	return registry, nil
+}
+
+func _loadBundles(fsys fs.FS) ([]*i18n.Bundle, []*errors.Error) {
+	entries, err := fs.ReadDir(fsys, BundlesDir)
+	if err != nil {
+		return nil, nil
+	}
+
+	var bundles []*i18n.Bundle
+	var errs []*errors.Error
+	locales := make(map[string]string)
+	for _, entry := range entries {
+		name := path.Join(BundlesDir, entry.Name())
+		ext := strings.ToLower(path.Ext(name))
+		if entry.IsDir() || (ext != ".json" && ext != ".xlf" && ext != ".xliff") {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err == nil {
+			var bundle *i18n.Bundle
+			if bundle, err = i18n.Parse(name, string(content)); err == nil {
+				if first, exists := locales[bundle.Locale]; exists {
+					err = fmt.Errorf("locale '%s' is already declared in %s", bundle.Locale, first)
+				} else {
+					locales[bundle.Locale] = name
+					bundles = append(bundles, bundle)
+					continue
+				}
+			}
+		}
+		errs = append(errs, &errors.Error{
+			Severity: errors.SeverityError,
+			Message:  err.Error(),
+			File:     name,
+		})
+	}
+	return bundles, errs
+}
+
+func _frameError(frame *Frame, related string, format string, args ...any) *errors.Error {
+	err := &errors.Error{
+		Severity: errors.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+		File:     frame.File,
+		Line:     frame.Frame.Line,
+		Column:   frame.Frame.Column,
+	}
+	if related != "" {
+		err.RelatedInfo = []string{related}
+	}
+	return err
+}
+
+func _declaredIn(subject string, frame *Frame) string {
+	return fmt.Sprintf("%s is declared in %s at line %d, column %d", subject, frame.File, frame.Frame.Line, frame.Frame.Column)
+}
+
+func _hasErrors(errs []*errors.Error) bool {
+	for _, err := range errs {
+		if err.Severity == errors.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// _withSourceLines fills in the source lines of errors found in the project's files.
+func _withSourceLines(fsys fs.FS, errs []*errors.Error) []*errors.Error {
+	sources := make(map[string][]string)
+	for _, err := range errs {
+		if err.SourceLine != "" || err.File == "" || err.Line < 1 {
+			continue
+		}
+		lines, ok := sources[err.File]
+		if !ok {
+			content, _ := fs.ReadFile(fsys, err.File)
+			lines = strings.Split(string(content), "\n")
+			sources[err.File] = lines
+		}
+		if err.Line <= len(lines) {
+			err.SourceLine = lines[err.Line-1]
+		}
+	}
+	return errs
+}
@@ -0,0 +1,250 @@
+package project
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/loader"
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+func _parse(fsys fs.FS, name string) (model.FrameDSLModel, []*errors.Error) {
+	frame, _, errs := loader.Load(fsys, name)
+	return frame, errs
+}
+
+func _frame(name, route string, starter bool, navigations ...string) string {
+	var b strings.Builder
+	b.WriteString(`frame(name = "` + name + `", route = "` + route + `"`)
+	if starter {
+		b.WriteString(`, starter = true`)
+	}
+	b.WriteString(") {\n    block(keyType = \"ROOT\", key = \"root\")\n")
+	for _, route := range navigations {
+		b.WriteString("        .action(event = \"onClick\") {\n")
+		b.WriteString("            trigger(keyType = \"NAVIGATE\", name = \"open\")\n")
+		b.WriteString("                .prop(route = \"" + route + "\")\n")
+		b.WriteString("        }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"home.nbx":           {Data: []byte(_frame("home", "/home", true, "/profile/{userId}", "/settings?tab=privacy"))},
+		"profile/view.nbx":   {Data: []byte(_frame("profile", "/profile/{id}", false, "/profile/me", "/missing"))},
+		"profile/me.nbx":     {Data: []byte(_frame("me", "/profile/me", false))},
+		"settings.nbx":       {Data: []byte(_frame("settings", "/settings", false))},
+		"orphan.nbx":         {Data: []byte(_frame("orphan", "/orphan", false))},
+		"shared/theme.nbx":   {Data: []byte(`library { const brand: STRING = "#000000" }`)},
+		"blocks.json":        {Data: []byte(`{}`)},
+		"docs/readme.md":     {Data: []byte(`# frames`)},
+		"profile/broken.nbx": {Data: []byte(`frame(name = "broken", route = "/broken") { var }`)},
+	}
+
+	p, errs := Load(fsys, _parse)
+	if len(p.Frames) != 5 {
+		t.Fatalf("Expected 5 frames, got %d", len(p.Frames))
+	}
+	if p.Registry == nil {
+		t.Errorf("Expected the registry to be loaded from blocks.json")
+	}
+
+	edges := map[string]string{}
+	for _, edge := range p.Edges {
+		edges[edge.From+" "+edge.Route] = edge.To
+	}
+	for key, to := range map[string]string{
+		"home /profile/{userId}":     "profile",
+		"home /settings?tab=privacy": "settings",
+		"profile /profile/me":        "me",
+		"profile /missing":           "",
+	} {
+		if got, ok := edges[key]; !ok || got != to {
+			t.Errorf("Expected edge %s to lead to %q, got %q", key, to, got)
+		}
+	}
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Severity.String()+": "+err.File+": "+err.Message)
+	}
+	all := strings.Join(messages, "\n")
+	for _, message := range []string{
+		"Error: profile/broken.nbx: Expected an identifier",
+		"Error: profile/view.nbx: Route '/missing' of trigger 'open' does not match any frame",
+		"Warning: orphan.nbx: Frame 'orphan' is not reachable from the starter frame 'home'",
+	} {
+		if !strings.Contains(all, message) {
+			t.Errorf("Expected %q in:\n%s", message, all)
+		}
+	}
+	if len(errs) != 3 {
+		t.Errorf("Expected 3 issues, got:\n%s", all)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		frames   map[string]string
+		expected []string
+	}{
+		{
+			name: "duplicate name",
+			frames: map[string]string{
+				"a.nbx": _frame("home", "/a", true),
+				"b.nbx": _frame("home", "/b", false),
+			},
+			expected: []string{"Duplicate frame name 'home'"},
+		},
+		{
+			name: "duplicate route",
+			frames: map[string]string{
+				"a.nbx": _frame("a", "/home", true, "/home"),
+				"b.nbx": _frame("b", "/home", false),
+			},
+			expected: []string{"Duplicate route '/home' in frames 'a' and 'b'"},
+		},
+		{
+			name: "conflicting routes",
+			frames: map[string]string{
+				"a.nbx": _frame("byId", "/user/{id}", true, "/user/{name}"),
+				"b.nbx": _frame("byName", "/user/{name}", false),
+			},
+			expected: []string{"Route '/user/{name}' of frame 'byName' conflicts with route '/user/{id}' of frame 'byId'"},
+		},
+		{
+			name: "no starter",
+			frames: map[string]string{
+				"a.nbx": _frame("a", "/a", false),
+			},
+			expected: []string{"No starter frame"},
+		},
+		{
+			name: "multiple starters",
+			frames: map[string]string{
+				"a.nbx": _frame("a", "/a", true),
+				"b.nbx": _frame("b", "/b", true),
+				"c.nbx": _frame("c", "/c", true),
+			},
+			expected: []string{"Multiple starter frames: 'a' and 'b'", "Multiple starter frames: 'a' and 'c'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for name, content := range tt.frames {
+				fsys[name] = &fstest.MapFile{Data: []byte(content)}
+			}
+			_, errs := Load(fsys, _parse)
+
+			var messages []string
+			for _, err := range errs {
+				if err.Severity == errors.SeverityError {
+					messages = append(messages, err.Message)
+				}
+			}
+			if strings.Join(messages, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("Expected errors:\n%s\ngot:\n%s", strings.Join(tt.expected, "\n"), strings.Join(messages, "\n"))
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	p := New([]Frame{
+		{File: "user.nbx", Frame: model.FrameDSLModel{Name: "user", Route: "/user/{id}"}},
+		{File: "me.nbx", Frame: model.FrameDSLModel{Name: "me", Route: "/user/me"}},
+		{File: "home.nbx", Frame: model.FrameDSLModel{Name: "home", Route: "/"}},
+	}, nil)
+
+	tests := map[string]string{
+		"/user/42":      "user",
+		"/user/me":      "me",
+		"/user/{other}": "user",
+		"/":             "home",
+		"/user/42/edit": "",
+		"/users/42":     "",
+	}
+	for route, expected := range tests {
+		got := ""
+		if frame := p.Match(route); frame != nil {
+			got = frame.Frame.Name
+		}
+		if got != expected {
+			t.Errorf("Match(%q) = %q, expected %q", route, got, expected)
+		}
+	}
+}
+
+func TestIsNavigation(t *testing.T) {
+	for keyType, expected := range map[string]bool{
+		"NAVIGATE":                     true,
+		"nativeblocks/navigate":        true,
+		"acme/navigation_push":         true,
+		"nativeblocks/change_variable": false,
+		"SHOW_MESSAGE":                 false,
+	} {
+		if IsNavigation(keyType) != expected {
+			t.Errorf("IsNavigation(%q) = %v, expected %v", keyType, !expected, expected)
+		}
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	fsys := fstest.MapFS{
+		"theme.json": {Data: []byte(`{"color": {"primary": "#2563EB"}}`)},
+		"home.nbx": {Data: []byte(`frame(name = "home", route = "/home", starter = true) {
+    block(keyType = "ROOT", key = "root")
+        .prop(background = @color.primary, border = @color.primay)
+}`)},
+	}
+
+	p, errs := Load(fsys, _parse)
+	if p.Theme == nil {
+		t.Fatal("Expected the theme to be loaded from theme.json")
+	}
+	if len(errs) != 1 || errs[0].Message != "Unknown token '@color.primay'" || errs[0].File != "home.nbx" {
+		t.Fatalf("Expected an unknown token error in home.nbx, got %v", errs)
+	}
+	if p.Frames[0].Frame.Blocks[0].Properties[0].ValueMobile != "@color.primary" {
+		t.Errorf("Expected the frames to keep their references")
+	}
+}
+
+func TestLoadBundles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"i18n/en.json": {Data: []byte(`{"locale": "en", "strings": {"home": {"title": "Home", "unused": "Unused"}}}`)},
+		"i18n/fr.xlf": {Data: []byte(`<xliff version="1.2"><file source-language="en" target-language="fr"><body>
+<trans-unit id="home.title"><source>Home</source></trans-unit>
+</body></file></xliff>`)},
+		"home.nbx": {Data: []byte(`frame(name = "home", route = "/home", starter = true) {
+    var title: STRING = @string.home.title
+    block(keyType = "ROOT", key = "root")
+        .prop(text = @string.home.title)
+}`)},
+	}
+
+	p, errs := Load(fsys, _parse)
+	if len(p.Bundles) != 2 {
+		t.Fatalf("Expected the bundles to be loaded from the i18n directory, got %d", len(p.Bundles))
+	}
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Severity.String()+": "+err.File+": "+err.Message)
+	}
+	expected := []string{
+		"Error: home.nbx: Missing translation for '@string.home.title' in locale 'fr'",
+		"Warning: i18n/en.json: String '@string.home.unused' in locale 'en' is not used by any frame",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(messages, "\n"))
+	}
+}
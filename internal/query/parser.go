@@ -0,0 +1,323 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+type combinator int
+
+const (
+	combinatorNone combinator = iota
+	combinatorDescendant
+	combinatorChild
+)
+
+type operator string
+
+const (
+	opExists   operator = ""
+	opEquals   operator = "="
+	opNot      operator = "!="
+	opPrefix   operator = "^="
+	opSuffix   operator = "$="
+	opContains operator = "*="
+)
+
+type attribute struct {
+	name  string
+	op    operator
+	value string
+}
+
+// compound is a single step of a selector, such as block[keyType="nativeblocks/text"]:has(trigger).
+type compound struct {
+	kind       walker.Kind // empty matches any kind
+	attributes []attribute
+	has        []*Selector
+	// combinator joins this step to the previous one; it is combinatorNone for the first step.
+	combinator combinator
+}
+
+// Selector is a compiled query. A selector list such as "block, trigger" matches nodes matching any part.
+type Selector struct {
+	source string
+	parts  [][]compound
+}
+
+func (s *Selector) String() string {
+	return s.source
+}
+
+var kinds = map[string]walker.Kind{
+	"frame":    walker.KindFrame,
+	"variable": walker.KindVariable,
+	"var":      walker.KindVariable,
+	"block":    walker.KindBlock,
+	"slot":     walker.KindSlot,
+	"prop":     walker.KindProperty,
+	"data":     walker.KindData,
+	"action":   walker.KindAction,
+	"sequence": walker.KindSequence,
+	"trigger":  walker.KindTrigger,
+}
+
+// Compile parses a selector.
+//
+// A selector is a list of steps joined by whitespace (descendant) or ">" (child). A step is a node kind
+// (frame, variable, block, slot, prop, data, action, sequence, trigger or *) followed by any number of filters:
+//
+//	[attr]            the attribute is set
+//	[attr=value]      also !=, ^= (prefix), $= (suffix) and *= (contains)
+//	:has(selector)    some descendant matches selector
+//
+// .slot("name") is a step of its own matching the slot named name; the blocks placed in a slot are its
+// children. Values may be quoted or bare. Selectors can be combined with commas.
+func Compile(source string) (*Selector, error) {
+	p := &selectorParser{input: source}
+	selector, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return selector, nil
+}
+
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) parseList() (*Selector, error) {
+	start := p.pos
+	selector := &Selector{}
+	for {
+		part, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selector.parts = append(selector.parts, part)
+
+		p.skipSpaces()
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	selector.source = strings.TrimSpace(p.input[start:p.pos])
+	return selector, nil
+}
+
+func (p *selectorParser) parseSelector() ([]compound, error) {
+	var steps []compound
+	next := combinatorNone
+	for {
+		hadSpace := p.skipSpaces()
+		if p.done() || p.peek() == ',' || p.peek() == ')' {
+			break
+		}
+
+		if p.peek() == '>' {
+			if len(steps) == 0 || next == combinatorChild {
+				return nil, p.errorf("unexpected '>'")
+			}
+			p.pos++
+			next = combinatorChild
+			continue
+		}
+		if len(steps) > 0 && next == combinatorNone {
+			if !hadSpace {
+				return nil, p.errorf("unexpected %q", p.peek())
+			}
+			next = combinatorDescendant
+		}
+
+		step, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		step.combinator = next
+		steps = append(steps, step)
+		next = combinatorNone
+	}
+
+	if len(steps) == 0 {
+		return nil, p.errorf("expected a selector")
+	}
+	if next == combinatorChild {
+		return nil, p.errorf("expected a selector after '>'")
+	}
+	return steps, nil
+}
+
+func (p *selectorParser) parseCompound() (compound, error) {
+	var step compound
+
+	switch {
+	case p.peek() == '*':
+		p.pos++
+	case p.peek() == '.':
+		p.pos++
+		name := p.readName()
+		if name != "slot" {
+			return step, p.errorf("unknown function '.%s', expected '.slot(\"name\")'", name)
+		}
+		if !p.consume('(') {
+			return step, p.errorf("expected '(' after '.slot'")
+		}
+		p.skipSpaces()
+		value, err := p.readValue()
+		if err != nil {
+			return step, err
+		}
+		p.skipSpaces()
+		if !p.consume(')') {
+			return step, p.errorf("expected ')'")
+		}
+		step.kind = walker.KindSlot
+		step.attributes = append(step.attributes, attribute{name: "slot", op: opEquals, value: value})
+	case _isNameChar(p.peek()):
+		name := p.readName()
+		kind, ok := kinds[name]
+		if !ok {
+			return step, p.errorf("unknown node kind '%s'", name)
+		}
+		step.kind = kind
+	case p.peek() != '[' && p.peek() != ':':
+		return step, p.errorf("unexpected %q", p.peek())
+	}
+
+	for !p.done() {
+		switch p.peek() {
+		case '[':
+			p.pos++
+			attr, err := p.parseAttribute()
+			if err != nil {
+				return step, err
+			}
+			step.attributes = append(step.attributes, attr)
+		case ':':
+			p.pos++
+			name := p.readName()
+			if name != "has" {
+				return step, p.errorf("unknown pseudo-class ':%s', expected ':has'", name)
+			}
+			if !p.consume('(') {
+				return step, p.errorf("expected '(' after ':has'")
+			}
+			inner, err := p.parseList()
+			if err != nil {
+				return step, err
+			}
+			p.skipSpaces()
+			if !p.consume(')') {
+				return step, p.errorf("expected ')'")
+			}
+			step.has = append(step.has, inner)
+		default:
+			return step, nil
+		}
+	}
+	return step, nil
+}
+
+func (p *selectorParser) parseAttribute() (attribute, error) {
+	p.skipSpaces()
+	name := p.readName()
+	if name == "" {
+		return attribute{}, p.errorf("expected an attribute name")
+	}
+	attr := attribute{name: name}
+
+	p.skipSpaces()
+	for _, op := range []operator{opNot, opPrefix, opSuffix, opContains, opEquals} {
+		if strings.HasPrefix(p.input[p.pos:], string(op)) {
+			attr.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if attr.op != opExists {
+		p.skipSpaces()
+		value, err := p.readValue()
+		if err != nil {
+			return attribute{}, err
+		}
+		attr.value = value
+		p.skipSpaces()
+	}
+
+	if !p.consume(']') {
+		return attribute{}, p.errorf("expected ']'")
+	}
+	return attr, nil
+}
+
+func (p *selectorParser) readValue() (string, error) {
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		value := p.readName()
+		if value == "" {
+			return "", p.errorf("expected a value")
+		}
+		return value, nil
+	}
+
+	p.pos++
+	end := strings.IndexByte(p.input[p.pos:], quote)
+	if end == -1 {
+		return "", p.errorf("unterminated string")
+	}
+	value := p.input[p.pos : p.pos+end]
+	p.pos += end + 1
+	return value, nil
+}
+
+func (p *selectorParser) readName() string {
+	start := p.pos
+	for !p.done() && _isNameChar(p.peek()) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *selectorParser) skipSpaces() bool {
+	start := p.pos
+	for !p.done() && strings.IndexByte(" \t\n\r", p.peek()) != -1 {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *selectorParser) consume(c byte) bool {
+	if p.peek() != c {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *selectorParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *selectorParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *selectorParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid selector at column %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func _isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '/' || c == '.' || c == '#' || c == '@'
+}
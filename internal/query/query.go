@@ -0,0 +1,280 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+// Match is a node selected by a query.
+type Match struct {
+	Node *walker.Node
+	// Path is the chain of node keys from the frame down to the node, joined by "/".
+	Path   string
+	Line   int
+	Column int
+}
+
+// Query compiles the selector and returns the matching nodes of the frame in document order.
+func Query(frame *model.FrameDSLModel, selector string) ([]Match, error) {
+	compiled, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Match(frame), nil
+}
+
+// Match returns the nodes of the frame matching the selector in document order.
+func (s *Selector) Match(frame *model.FrameDSLModel) []Match {
+	var matches []Match
+	for _, n := range _buildTree(frame) {
+		if !s.matches(n) {
+			continue
+		}
+		line, column := n.Position()
+		matches = append(matches, Match{Node: n.Node, Path: n.path(), Line: line, Column: column})
+	}
+	return matches
+}
+
+// node is a walker node placed in the query tree. Child blocks are placed under the slot they fill,
+// so ".slot("content") block" selects the blocks of a slot.
+type node struct {
+	*walker.Node
+	parent   *node
+	children []*node
+}
+
+func (n *node) path() string {
+	var keys []string
+	for current := n; current != nil; current = current.parent {
+		keys = append(keys, current.Key())
+	}
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return strings.Join(keys, "/")
+}
+
+// _buildTree returns every node of the frame in document order.
+func _buildTree(frame *model.FrameDSLModel) []*node {
+	var nodes []*node
+	var stack []*node
+	// slots maps a block to its slot nodes, including slots used by child blocks without a declaration.
+	slots := make(map[*model.BlockDSLModel]map[string]*node)
+
+	walker.Walk(frame, walker.Visitor{
+		Enter: func(wn *walker.Node, parents []*walker.Node) walker.Result {
+			n := &node{Node: wn}
+			if len(stack) > 0 {
+				n.parent = stack[len(stack)-1]
+			}
+
+			switch {
+			case wn.Kind == walker.KindSlot:
+				owner := n.parent.Block
+				if slots[owner] == nil {
+					slots[owner] = make(map[string]*node)
+				}
+				slots[owner][wn.Slot.Slot] = n
+			case wn.Kind == walker.KindBlock && wn.Block.Slot != "" && n.parent.Kind == walker.KindBlock:
+				owner := n.parent.Block
+				slot, ok := slots[owner][wn.Block.Slot]
+				if !ok {
+					slot = &node{
+						Node: &walker.Node{Kind: walker.KindSlot, Slot: &model.BlockSlotDSLModel{
+							Slot: wn.Block.Slot, Line: wn.Block.Line, Column: wn.Block.Column,
+						}},
+						parent: n.parent,
+					}
+					if slots[owner] == nil {
+						slots[owner] = make(map[string]*node)
+					}
+					slots[owner][wn.Block.Slot] = slot
+					slot.parent.children = append(slot.parent.children, slot)
+					nodes = append(nodes, slot)
+				}
+				n.parent = slot
+			}
+
+			if n.parent != nil {
+				n.parent.children = append(n.parent.children, n)
+			}
+
+			nodes = append(nodes, n)
+			stack = append(stack, n)
+			return walker.Continue
+		},
+		Leave: func(wn *walker.Node, parents []*walker.Node) walker.Result {
+			stack = stack[:len(stack)-1]
+			return walker.Continue
+		},
+	})
+	return nodes
+}
+
+func (s *Selector) matches(n *node) bool {
+	for _, part := range s.parts {
+		if _matchSteps(part, len(part)-1, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// _matchSteps reports whether steps[:i+1] match with steps[i] matching n.
+func _matchSteps(steps []compound, i int, n *node) bool {
+	if !_matchCompound(steps[i], n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+
+	switch steps[i].combinator {
+	case combinatorChild:
+		return n.parent != nil && _matchSteps(steps, i-1, n.parent)
+	default:
+		for ancestor := n.parent; ancestor != nil; ancestor = ancestor.parent {
+			if _matchSteps(steps, i-1, ancestor) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func _matchCompound(step compound, n *node) bool {
+	if step.kind != "" && step.kind != n.Kind {
+		return false
+	}
+	for _, attr := range step.attributes {
+		if !_matchAttribute(attr, n.Node) {
+			return false
+		}
+	}
+	for _, has := range step.has {
+		if !_hasDescendant(has, n) {
+			return false
+		}
+	}
+	return true
+}
+
+func _hasDescendant(selector *Selector, n *node) bool {
+	for _, child := range n.children {
+		if selector.matches(child) || _hasDescendant(selector, child) {
+			return true
+		}
+	}
+	return false
+}
+
+func _matchAttribute(attr attribute, n *walker.Node) bool {
+	value, ok := _attributeValue(n, attr.name)
+	switch attr.op {
+	case opExists:
+		return ok && value != ""
+	case opEquals:
+		return ok && value == attr.value
+	case opNot:
+		return !ok || value != attr.value
+	case opPrefix:
+		return ok && strings.HasPrefix(value, attr.value)
+	case opSuffix:
+		return ok && strings.HasSuffix(value, attr.value)
+	case opContains:
+		return ok && strings.Contains(value, attr.value)
+	}
+	return false
+}
+
+// _attributeValue returns the named attribute of a node. Blocks and triggers also expose their
+// properties as "prop.<key>" (the mobile value for blocks) and their data as "data.<key>".
+func _attributeValue(n *walker.Node, name string) (string, bool) {
+	if name == "kind" {
+		return string(n.Kind), true
+	}
+	if name == "line" || name == "column" {
+		line, column := n.Position()
+		if name == "line" {
+			return strconv.Itoa(line), true
+		}
+		return strconv.Itoa(column), true
+	}
+
+	switch {
+	case n.Frame != nil:
+		return _lookup(name, map[string]string{"key": n.Frame.Name, "name": n.Frame.Name, "route": n.Frame.Route, "type": n.Frame.Type, "starter": strconv.FormatBool(n.Frame.Starter)})
+	case n.Variable != nil:
+		return _lookup(name, map[string]string{"key": n.Variable.Key, "value": n.Variable.Value, "type": n.Variable.Type})
+	case n.Block != nil:
+		if key, ok := strings.CutPrefix(name, "prop."); ok {
+			for _, prop := range n.Block.Properties {
+				if prop.Key == key {
+					return prop.ValueMobile, true
+				}
+			}
+			return "", false
+		}
+		if key, ok := strings.CutPrefix(name, "data."); ok {
+			for _, data := range n.Block.Data {
+				if data.Key == key {
+					return data.Value, true
+				}
+			}
+			return "", false
+		}
+		return _lookup(name, map[string]string{
+			"key": n.Block.Key, "keyType": n.Block.KeyType, "visibilityKey": n.Block.VisibilityKey,
+			"slot": n.Block.Slot, "version": strconv.Itoa(n.Block.IntegrationVersion),
+		})
+	case n.Slot != nil:
+		return _lookup(name, map[string]string{"key": n.Slot.Slot, "slot": n.Slot.Slot, "name": n.Slot.Slot})
+	case n.Property != nil:
+		return _lookup(name, map[string]string{
+			"key": n.Property.Key, "value": n.Property.ValueMobile, "valueMobile": n.Property.ValueMobile,
+			"valueTablet": n.Property.ValueTablet, "valueDesktop": n.Property.ValueDesktop, "type": n.Property.Type,
+		})
+	case n.Data != nil:
+		return _lookup(name, map[string]string{"key": n.Data.Key, "value": n.Data.Value, "type": n.Data.Type})
+	case n.Action != nil:
+		return _lookup(name, map[string]string{"key": n.Action.Event, "event": n.Action.Event, "block": n.Action.Key})
+	case n.Sequence != nil:
+		return _lookup(name, map[string]string{"key": n.Sequence.Name, "name": n.Sequence.Name})
+	case n.Trigger != nil:
+		if key, ok := strings.CutPrefix(name, "prop."); ok {
+			for _, prop := range n.Trigger.Properties {
+				if prop.Key == key {
+					return prop.Value, true
+				}
+			}
+			return "", false
+		}
+		if key, ok := strings.CutPrefix(name, "data."); ok {
+			for _, data := range n.Trigger.Data {
+				if data.Key == key {
+					return data.Value, true
+				}
+			}
+			return "", false
+		}
+		return _lookup(name, map[string]string{
+			"key": n.Trigger.Name, "name": n.Trigger.Name, "keyType": n.Trigger.KeyType,
+			"then": n.Trigger.Then, "version": strconv.Itoa(n.Trigger.IntegrationVersion), "sequence": n.Trigger.Sequence,
+			"condition": n.Trigger.Condition,
+		})
+	case n.TriggerProperty != nil:
+		return _lookup(name, map[string]string{"key": n.TriggerProperty.Key, "value": n.TriggerProperty.Value, "type": n.TriggerProperty.Type})
+	case n.TriggerData != nil:
+		return _lookup(name, map[string]string{"key": n.TriggerData.Key, "value": n.TriggerData.Value, "type": n.TriggerData.Type})
+	}
+	return "", false
+}
+
+func _lookup(name string, attributes map[string]string) (string, bool) {
+	value, ok := attributes[name]
+	return value, ok
+}
@@ -0,0 +1,133 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+func _queryFrame() model.FrameDSLModel {
+	return model.FrameDSLModel{
+		Name: "welcome",
+		Variables: []model.VariableDSLModel{
+			{Key: "title", Type: "STRING", Line: 2, Column: 1},
+		},
+		Blocks: []model.BlockDSLModel{{
+			Key: "root", KeyType: "ROOT", Line: 3, Column: 1,
+			Blocks: []model.BlockDSLModel{{
+				Key: "controlsHStack", KeyType: "nativeblocks/row", Slot: "content", Line: 4, Column: 5,
+				Slots: []model.BlockSlotDSLModel{{Slot: "content", Line: 5, Column: 9}},
+				Blocks: []model.BlockDSLModel{
+					{
+						Key: "decrease", KeyType: "nativeblocks/button", Slot: "content", Line: 6, Column: 9,
+						Properties: []model.BlockPropertyDSLModel{{Key: "backgroundColor", ValueMobile: "#2563EB"}},
+						Actions: []model.ActionDSLModel{{
+							Key: "decrease", Event: "onClick", Line: 7, Column: 9,
+							Triggers: []model.ActionTriggerDSLModel{{
+								Name: "change", KeyType: "nativeblocks/change_block_property", Then: "END", Line: 8, Column: 13,
+								Properties: []model.TriggerPropertyDSLModel{{Key: "color", Value: "#FF0000"}},
+								Triggers: []model.ActionTriggerDSLModel{{
+									Name: "fallback", KeyType: "nativeblocks/change_variable", Then: "FAILURE", Line: 9, Column: 17,
+								}},
+							}},
+						}},
+					},
+					{Key: "counter", KeyType: "nativeblocks/text", Slot: "content", Line: 10, Column: 9},
+				},
+			}},
+		}},
+	}
+}
+
+func _keys(t *testing.T, selector string) string {
+	t.Helper()
+	frame := _queryFrame()
+	matches, err := Query(&frame, selector)
+	if err != nil {
+		t.Fatalf("%s: %v", selector, err)
+	}
+	keys := make([]string, len(matches))
+	for i, match := range matches {
+		keys[i] = match.Node.Key()
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestQuerySelectors(t *testing.T) {
+	tests := []struct {
+		selector string
+		expected string
+	}{
+		{`block[keyType="nativeblocks/button"]`, "decrease"},
+		{`block[key=controlsHStack] > .slot("content") block`, "decrease,counter"},
+		{`.slot(content) > block[keyType$=text]`, "counter"},
+		{`trigger[then=FAILURE]`, "fallback"},
+		{`trigger[keyType="nativeblocks/change_block_property"][prop.color]`, "change"},
+		{`trigger:has(trigger[then=FAILURE])`, "change"},
+		{`block:has(trigger)`, "root,controlsHStack,decrease"},
+		{`block[prop.backgroundColor^="#25"]`, "decrease"},
+		{`action > trigger`, "change"},
+		{`action trigger`, "change,fallback"},
+		{`variable, block[key=root]`, "title,root"},
+		{`frame > block`, "root"},
+		{`* > prop[key=color]`, "color"},
+		{`block[keyType!=ROOT][slot]`, "controlsHStack,decrease,counter"},
+		{`trigger[then*=AIL]`, "fallback"},
+	}
+
+	for _, tt := range tests {
+		if got := _keys(t, tt.selector); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.selector, tt.expected, got)
+		}
+	}
+}
+
+func TestQueryPositionsAndPaths(t *testing.T) {
+	frame := _queryFrame()
+	matches, err := Query(&frame, "trigger[then=FAILURE]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(matches))
+	}
+	match := matches[0]
+	if match.Line != 9 || match.Column != 17 {
+		t.Errorf("Expected position 9:17, got %d:%d", match.Line, match.Column)
+	}
+	if match.Path != "welcome/root/content/controlsHStack/content/decrease/onClick/change/fallback" {
+		t.Errorf("Unexpected path: %s", match.Path)
+	}
+}
+
+func TestQueryUndeclaredSlot(t *testing.T) {
+	frame := _queryFrame()
+	matches, err := Query(&frame, `block[key=root] > .slot(content) > block`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Node.Key() != "controlsHStack" {
+		t.Errorf("Expected blocks of an undeclared slot to be grouped under it, got %v", matches)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"button",
+		"block[",
+		"block[key=",
+		`block[key="open]`,
+		"block >",
+		"> block",
+		".items(content)",
+		"block:first",
+		"block:has(trigger",
+	}
+	for _, selector := range invalid {
+		if _, err := Compile(selector); err == nil {
+			t.Errorf("Expected %q to be rejected", selector)
+		}
+	}
+}
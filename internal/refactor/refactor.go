@@ -0,0 +1,451 @@
+package refactor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/compiler"
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/expr"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/validator"
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+// blockKeyProperty is the trigger property that refers to another block, as used by
+// nativeblocks/change_block_property.
+const blockKeyProperty = "blockKey"
+
+// Extracted is a block subtree removed from a frame by ExtractBlock.
+type Extracted struct {
+	Block model.BlockDSLModel
+	// Variables are the frame variables referenced inside the subtree, in declaration order.
+	Variables []model.VariableDSLModel
+}
+
+// RenameVariable renames a variable and every reference to it: computed variable expressions, visibility
+// keys, repeat items, block and trigger data bindings and {var:name} placeholders in property values, in
+// the frame's blocks and in component definitions. Components with a data parameter of the same name
+// refer to the parameter and are left unchanged.
+func RenameVariable(frame *model.FrameDSLModel, oldKey, newKey string) []*errors.Error {
+	index := slices.IndexFunc(frame.Variables, func(v model.VariableDSLModel) bool { return v.Key == oldKey })
+	if index == -1 {
+		return _fail(frame.Line, frame.Column, "Variable '%s' is not declared", oldKey)
+	}
+	if file := frame.Variables[index].File; file != "" {
+		return _fail(frame.Line, frame.Column, "Variable '%s' is imported from %s, rename it in the library", oldKey, file)
+	}
+	if !validator.IsVariableName(newKey) {
+		return _fail(frame.Variables[index].Line, frame.Variables[index].Column, "'%s' is not a valid variable name", newKey)
+	}
+	if slices.ContainsFunc(frame.Variables, func(v model.VariableDSLModel) bool { return v.Key == newKey }) {
+		return _fail(frame.Variables[index].Line, frame.Variables[index].Column, "Variable '%s' is already declared", newKey)
+	}
+
+	result := model.CloneFrame(*frame)
+	result.Variables[index].Key = newKey
+	for i, variable := range result.Variables {
+		if variable.Computed {
+			wrapped := _renameBinding(expr.Prefix+variable.Value+expr.Suffix, oldKey, newKey)
+			result.Variables[i].Value, _ = expr.Unwrap(wrapped)
+		}
+	}
+
+	walker.Walk(&result, _variableRenamer(oldKey, newKey, nil))
+	for i := range result.Components {
+		component := &result.Components[i]
+		if _hasDataParam(component, oldKey) {
+			continue
+		}
+		renamed := false
+		_walkDefinition(component, _variableRenamer(oldKey, newKey, &renamed))
+		if renamed && _hasDataParam(component, newKey) {
+			return _fail(component.Line, component.Column, "Variable '%s' would be shadowed by the data parameter '%s' of component '%s'",
+				oldKey, newKey, component.Name)
+		}
+	}
+
+	return _commit(frame, result)
+}
+
+// _variableRenamer returns a visitor that renames the references to a variable. renamed, when not nil,
+// is set once a reference was renamed.
+func _variableRenamer(oldKey, newKey string, renamed *bool) walker.Visitor {
+	oldPlaceholder, newPlaceholder := "{var:"+oldKey+"}", "{var:"+newKey+"}"
+	rename := func(value *string, renameValue func(string) string) {
+		if updated := renameValue(*value); updated != *value {
+			*value = updated
+			if renamed != nil {
+				*renamed = true
+			}
+		}
+	}
+	binding := func(value string) string { return _renameBinding(value, oldKey, newKey) }
+	placeholder := func(value string) string { return strings.ReplaceAll(value, oldPlaceholder, newPlaceholder) }
+	condition := func(value string) string {
+		updated, _ := expr.Unwrap(_renameBinding(expr.Prefix+value+expr.Suffix, oldKey, newKey))
+		return updated
+	}
+
+	return walker.Visitor{
+		Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+			switch {
+			case node.Block != nil:
+				rename(&node.Block.VisibilityKey, binding)
+				if node.Block.Repeat != nil {
+					rename(&node.Block.Repeat.Items, binding)
+				}
+			case node.Data != nil:
+				rename(&node.Data.Value, binding)
+			case node.TriggerData != nil:
+				rename(&node.TriggerData.Value, binding)
+			case node.Trigger != nil && node.Trigger.Condition != "":
+				rename(&node.Trigger.Condition, condition)
+			case node.Property != nil:
+				rename(&node.Property.ValueMobile, placeholder)
+				rename(&node.Property.ValueTablet, placeholder)
+				rename(&node.Property.ValueDesktop, placeholder)
+			case node.TriggerProperty != nil:
+				rename(&node.TriggerProperty.Value, placeholder)
+			}
+			return walker.Continue
+		},
+	}
+}
+
+// RenameBlockKey renames a block and every reference to it: the keys of its actions and the blockKey
+// property of triggers. A block of a component definition is renamed within its component.
+func RenameBlockKey(frame *model.FrameDSLModel, oldKey, newKey string) []*errors.Error {
+	index, errs := _treeOf(frame, oldKey)
+	if errs != nil {
+		return errs
+	}
+	block := _findBlock(_tree(frame, index), oldKey)
+	if strings.TrimSpace(newKey) == "" {
+		return _fail(block.Line, block.Column, "Block key must not be empty")
+	}
+	if _findBlock(_tree(frame, index), newKey) != nil {
+		return _fail(block.Line, block.Column, "Block key '%s' is already used", newKey)
+	}
+
+	result := model.CloneFrame(*frame)
+	tree := _tree(&result, index)
+	walker.Walk(tree, _blockRenamer(oldKey, newKey))
+	_store(&result, index, tree)
+	if index == -1 {
+		// Triggers of components may refer to frame blocks that the component does not declare itself.
+		for i := range result.Components {
+			component := &result.Components[i]
+			if _findBlock(_definition(component), oldKey) == nil {
+				_walkDefinition(component, _blockRenamer(oldKey, newKey))
+			}
+		}
+	}
+
+	return _commit(frame, result)
+}
+
+func _blockRenamer(oldKey, newKey string) walker.Visitor {
+	return walker.Visitor{
+		Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+			switch {
+			case node.Block != nil:
+				if node.Block.Key == oldKey {
+					node.Block.Key = newKey
+				}
+			case node.Action != nil:
+				if node.Action.Key == oldKey {
+					node.Action.Key = newKey
+				}
+			case node.TriggerProperty != nil:
+				if node.TriggerProperty.Key == blockKeyProperty && node.TriggerProperty.Value == oldKey {
+					node.TriggerProperty.Value = newKey
+				}
+			}
+			return walker.Continue
+		},
+	}
+}
+
+// MoveBlock moves a block with its subtree into the slot of newParentKey at index among the parent's
+// child blocks. An index of -1 appends the block. A block of a component definition is moved within its
+// component.
+func MoveBlock(frame *model.FrameDSLModel, key, newParentKey, slot string, index int) []*errors.Error {
+	component, errs := _treeOf(frame, key)
+	if errs != nil {
+		return errs
+	}
+	tree := _tree(frame, component)
+	block := _findBlock(tree, key)
+	if block.KeyType == "ROOT" || (component != -1 && tree.Blocks[0].Key == key) {
+		return _fail(block.Line, block.Column, "The root block cannot be moved")
+	}
+	parent := _findBlock(tree, newParentKey)
+	if parent == nil {
+		return _fail(block.Line, block.Column, "Target block '%s' does not exist", newParentKey)
+	}
+	if _findBlockIn(block.Blocks, newParentKey) != nil || newParentKey == key {
+		return _fail(block.Line, block.Column, "Block '%s' cannot be moved into itself", key)
+	}
+	if len(parent.Slots) > 0 && !slices.ContainsFunc(parent.Slots, func(s model.BlockSlotDSLModel) bool { return s.Slot == slot }) {
+		return _fail(parent.Line, parent.Column, "Block '%s' has no slot '%s'", newParentKey, slot)
+	}
+
+	result := model.CloneFrame(*frame)
+	resultTree := _tree(&result, component)
+	moved, _ := _removeBlock(&resultTree.Blocks, key)
+	moved.Slot = slot
+
+	target := _findBlock(resultTree, newParentKey)
+	if index == -1 {
+		index = len(target.Blocks)
+	}
+	if index < 0 || index > len(target.Blocks) {
+		return _fail(parent.Line, parent.Column, "Index %d is out of range for block '%s' with %d child block(s)",
+			index, newParentKey, len(target.Blocks))
+	}
+	target.Blocks = slices.Insert(target.Blocks, index, moved)
+	_store(&result, component, resultTree)
+
+	return _commit(frame, result)
+}
+
+// ExtractBlock removes a block with its subtree from the frame, or from the component definition that
+// declares it, and returns it with the frame variables it references. It refuses when the rest of the
+// frame or component still refers to a block of the subtree.
+func ExtractBlock(frame *model.FrameDSLModel, key string) (Extracted, []*errors.Error) {
+	component, errs := _treeOf(frame, key)
+	if errs != nil {
+		return Extracted{}, errs
+	}
+	tree := _tree(frame, component)
+	block := _findBlock(tree, key)
+	if block.KeyType == "ROOT" || (component != -1 && tree.Blocks[0].Key == key) {
+		return Extracted{}, _fail(block.Line, block.Column, "The root block cannot be extracted")
+	}
+
+	result := model.CloneFrame(*frame)
+	resultTree := _tree(&result, component)
+	extracted, _ := _removeBlock(&resultTree.Blocks, key)
+	_store(&result, component, resultTree)
+
+	subtree := &model.FrameDSLModel{Blocks: []model.BlockDSLModel{extracted}}
+	removed := make(map[string]bool)
+	used := make(map[string]bool)
+	walker.Walk(subtree, walker.Visitor{
+		Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+			switch {
+			case node.Block != nil:
+				removed[node.Block.Key] = true
+				_use(used, node.Block.VisibilityKey)
+				if node.Block.Repeat != nil {
+					_use(used, node.Block.Repeat.Items)
+				}
+			case node.Data != nil:
+				_use(used, node.Data.Value)
+			case node.TriggerData != nil:
+				_use(used, node.TriggerData.Value)
+			case node.Trigger != nil && node.Trigger.Condition != "":
+				_use(used, expr.Prefix+node.Trigger.Condition+expr.Suffix)
+			}
+			return walker.Continue
+		},
+	})
+
+	var dangling []*errors.Error
+	walker.Walk(resultTree, walker.Visitor{
+		Enter: func(node *walker.Node, parents []*walker.Node) walker.Result {
+			if prop := node.TriggerProperty; prop != nil && prop.Key == blockKeyProperty && removed[prop.Value] {
+				dangling = append(dangling, &errors.Error{
+					Severity: errors.SeverityError,
+					Message:  fmt.Sprintf("Block '%s' is still referenced after extracting '%s'", prop.Value, key),
+					Line:     prop.Line,
+					Column:   prop.Column,
+				})
+			}
+			return walker.Continue
+		},
+	})
+	if len(dangling) > 0 {
+		return Extracted{}, dangling
+	}
+
+	if errs := _commit(frame, result); errs != nil {
+		return Extracted{}, errs
+	}
+
+	// Computed variables need the variables they are derived from.
+	for changed := true; changed; {
+		changed = false
+		for _, variable := range frame.Variables {
+			if !variable.Computed || !used[variable.Key] {
+				continue
+			}
+			for _, name := range expr.ValueVariables(expr.Prefix + variable.Value + expr.Suffix) {
+				changed = changed || !used[name]
+				used[name] = true
+			}
+		}
+	}
+
+	output := Extracted{Block: extracted}
+	for _, variable := range frame.Variables {
+		if used[variable.Key] {
+			output.Variables = append(output.Variables, variable)
+		}
+	}
+	return output, nil
+}
+
+// _renameBinding renames the variable a visibility key or data binding refers to, by name or inside an
+// expression or template.
+func _renameBinding(value, oldKey, newKey string) string {
+	if expr.IsExpression(value) {
+		if slices.Contains(expr.ValueVariables(value), oldKey) {
+			return expr.RenameValue(value, map[string]string{oldKey: newKey})
+		}
+		return value
+	}
+	if expr.IsTemplate(value) {
+		if slices.Contains(expr.TemplateVariables(value), oldKey) {
+			return expr.RenameTemplate(value, map[string]string{oldKey: newKey})
+		}
+		return value
+	}
+	if trimmed := strings.TrimSpace(value); expr.Root(trimmed) == oldKey {
+		return expr.RenamePath(trimmed, map[string]string{oldKey: newKey})
+	}
+	return value
+}
+
+// _use marks the variables a visibility key or data binding refers to.
+func _use(used map[string]bool, value string) {
+	used[expr.Root(strings.TrimSpace(value))] = true
+	for _, name := range expr.ValueVariables(value) {
+		used[name] = true
+	}
+	for _, name := range expr.TemplateVariables(value) {
+		used[name] = true
+	}
+}
+
+// _commit validates the refactored frame, with its components expanded, and stores it unless it
+// introduces validation errors that the original frame did not have.
+func _commit(frame *model.FrameDSLModel, result model.FrameDSLModel) []*errors.Error {
+	expandedFrame, _, _ := compiler.Expand(*frame)
+	expandedResult, _, _ := compiler.Expand(result)
+	before, _ := validator.Validate(&expandedFrame)
+	after, _ := validator.Validate(&expandedResult)
+
+	existing := make(map[string]bool)
+	for _, err := range before.Errors() {
+		existing[err.Message] = true
+	}
+
+	var introduced []*errors.Error
+	for _, err := range after.Errors() {
+		if !existing[err.Message] {
+			introduced = append(introduced, err)
+		}
+	}
+	if len(introduced) > 0 {
+		return introduced
+	}
+
+	*frame = result
+	return nil
+}
+
+// _treeOf returns the index of the component whose definition declares the block key, or -1 when the
+// frame's own blocks do. The frame's blocks are searched first.
+func _treeOf(frame *model.FrameDSLModel, key string) (int, []*errors.Error) {
+	if _findBlock(frame, key) != nil {
+		return -1, nil
+	}
+	var found []string
+	index := -1
+	for i := range frame.Components {
+		if _findBlock(_definition(&frame.Components[i]), key) != nil {
+			found = append(found, frame.Components[i].Name)
+			index = i
+		}
+	}
+	switch len(found) {
+	case 0:
+		return -1, _fail(frame.Line, frame.Column, "Block '%s' does not exist", key)
+	case 1:
+		return index, nil
+	}
+	return -1, _fail(frame.Line, frame.Column, "Block '%s' is declared by the components %s", key, strings.Join(found, ", "))
+}
+
+// _tree returns the block tree at index, as returned by _treeOf: the frame itself, or a frame holding
+// the root block of the component definition. _store writes an edited definition back.
+func _tree(frame *model.FrameDSLModel, index int) *model.FrameDSLModel {
+	if index == -1 {
+		return frame
+	}
+	return _definition(&frame.Components[index])
+}
+
+func _store(frame *model.FrameDSLModel, index int, tree *model.FrameDSLModel) {
+	if index != -1 {
+		frame.Components[index].Block = tree.Blocks[0]
+	}
+}
+
+// _definition returns a frame holding the root block of a component definition, so it can be walked
+// and edited like the blocks of a frame.
+func _definition(component *model.ComponentDSLModel) *model.FrameDSLModel {
+	return &model.FrameDSLModel{Blocks: []model.BlockDSLModel{component.Block}}
+}
+
+func _walkDefinition(component *model.ComponentDSLModel, visitor walker.Visitor) {
+	definition := _definition(component)
+	walker.Walk(definition, visitor)
+	component.Block = definition.Blocks[0]
+}
+
+func _hasDataParam(component *model.ComponentDSLModel, name string) bool {
+	return slices.ContainsFunc(component.Data, func(p model.ComponentParamDSLModel) bool { return p.Key == name })
+}
+
+func _fail(line, column int, format string, args ...any) []*errors.Error {
+	return []*errors.Error{{
+		Severity: errors.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     line,
+		Column:   column,
+	}}
+}
+
+func _findBlock(frame *model.FrameDSLModel, key string) *model.BlockDSLModel {
+	return _findBlockIn(frame.Blocks, key)
+}
+
+func _findBlockIn(blocks []model.BlockDSLModel, key string) *model.BlockDSLModel {
+	for i := range blocks {
+		if blocks[i].Key == key {
+			return &blocks[i]
+		}
+		if found := _findBlockIn(blocks[i].Blocks, key); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func _removeBlock(blocks *[]model.BlockDSLModel, key string) (model.BlockDSLModel, bool) {
+	for i := range *blocks {
+		if (*blocks)[i].Key == key {
+			removed := (*blocks)[i]
+			*blocks = slices.Delete(*blocks, i, i+1)
+			return removed, true
+		}
+		if removed, ok := _removeBlock(&(*blocks)[i].Blocks, key); ok {
+			return removed, true
+		}
+	}
+	return model.BlockDSLModel{}, false
+}
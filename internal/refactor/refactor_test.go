@@ -0,0 +1,294 @@
+package refactor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/parser"
+)
+
+func _refactorFrame() model.FrameDSLModel {
+	return model.FrameDSLModel{
+		Name: "counter", Route: "/counter", Type: "FRAME",
+		Variables: []model.VariableDSLModel{
+			{Key: "visible", Type: "BOOLEAN", Value: "true"},
+			{Key: "count", Type: "INT", Value: "0"},
+			{Key: "label", Type: "STRING", Value: "Add"},
+		},
+		Blocks: []model.BlockDSLModel{{
+			Key: "root", KeyType: "ROOT", VisibilityKey: "visible",
+			Blocks: []model.BlockDSLModel{{
+				Key: "column", KeyType: "nativeblocks/column", Slot: "content", VisibilityKey: "visible",
+				Slots: []model.BlockSlotDSLModel{{Slot: "content"}},
+				Blocks: []model.BlockDSLModel{
+					{
+						Key: "text", KeyType: "nativeblocks/text", Slot: "content", VisibilityKey: "visible",
+						Data: []model.BlockDataDSLModel{{Key: "text", Value: "count"}},
+					},
+					{
+						Key: "button", KeyType: "nativeblocks/button", Slot: "content", VisibilityKey: "visible",
+						Data: []model.BlockDataDSLModel{{Key: "text", Value: "label"}},
+						Actions: []model.ActionDSLModel{{
+							Key: "button", Event: "onClick",
+							Triggers: []model.ActionTriggerDSLModel{
+								{
+									Name: "increase", KeyType: "nativeblocks/change_variable", Then: "NEXT",
+									Properties: []model.TriggerPropertyDSLModel{{Key: "variableValue", Value: "{var:count} + 1"}},
+									Data:       []model.TriggerDataDSLModel{{Key: "variableKey", Value: "count"}},
+								},
+								{
+									Name: "highlight", KeyType: "nativeblocks/change_block_property", Then: "NEXT",
+									Properties: []model.TriggerPropertyDSLModel{{Key: "blockKey", Value: "text"}},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func TestRenameVariable(t *testing.T) {
+	frame := _refactorFrame()
+	if errs := RenameVariable(&frame, "count", "total"); errs != nil {
+		t.Fatalf("Unexpected errors: %v", errs[0].Message)
+	}
+
+	if frame.Variables[1].Key != "total" {
+		t.Errorf("Expected declaration to be renamed, got %s", frame.Variables[1].Key)
+	}
+	column := frame.Blocks[0].Blocks[0]
+	if column.Blocks[0].Data[0].Value != "total" {
+		t.Error("Expected block data binding to be renamed")
+	}
+	trigger := column.Blocks[1].Actions[0].Triggers[0]
+	if trigger.Data[0].Value != "total" {
+		t.Error("Expected trigger data binding to be renamed")
+	}
+	if trigger.Properties[0].Value != "{var:total} + 1" {
+		t.Errorf("Expected placeholder to be renamed, got %s", trigger.Properties[0].Value)
+	}
+
+	frame = _refactorFrame()
+	action := &frame.Blocks[0].Blocks[0].Blocks[1].Actions[0]
+	action.Triggers[0].Condition = "count < 10"
+	action.Triggers = append(action.Triggers, model.ActionTriggerDSLModel{
+		KeyType: model.ConditionKeyType, Then: "NEXT", Condition: "count > 0 && visible",
+	})
+	if errs := RenameVariable(&frame, "count", "total"); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	action = &frame.Blocks[0].Blocks[0].Blocks[1].Actions[0]
+	if action.Triggers[0].Condition != "total < 10" || action.Triggers[2].Condition != "total > 0 && visible" {
+		t.Errorf("Expected conditions to be renamed, got %q and %q", action.Triggers[0].Condition, action.Triggers[2].Condition)
+	}
+
+	frame = _refactorFrame()
+	if errs := RenameVariable(&frame, "visible", "shown"); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	if frame.Blocks[0].Blocks[0].Blocks[1].VisibilityKey != "shown" {
+		t.Error("Expected visibility keys to be renamed")
+	}
+}
+
+func TestRenameVariableRefusals(t *testing.T) {
+	tests := []struct{ oldKey, newKey, message string }{
+		{"missing", "other", "is not declared"},
+		{"count", "label", "already declared"},
+		{"count", "1count", "not a valid variable name"},
+	}
+	for _, tt := range tests {
+		frame := _refactorFrame()
+		errs := RenameVariable(&frame, tt.oldKey, tt.newKey)
+		if len(errs) == 0 || !strings.Contains(errs[0].Message, tt.message) {
+			t.Errorf("%s -> %s: expected error containing %q, got %v", tt.oldKey, tt.newKey, tt.message, errs)
+		}
+		if frame.Variables[1].Key != "count" {
+			t.Error("Expected frame to be unchanged after a refused rename")
+		}
+	}
+
+	frame := _refactorFrame()
+	frame.Variables[1].File = "shared.nbx"
+	if errs := RenameVariable(&frame, "count", "total"); len(errs) == 0 || !strings.Contains(errs[0].Message, "imported from shared.nbx") {
+		t.Errorf("Expected an imported variable to be refused, got %v", errs)
+	}
+}
+
+func TestRenameBlockKey(t *testing.T) {
+	frame := _refactorFrame()
+	if errs := RenameBlockKey(&frame, "text", "countText"); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	column := frame.Blocks[0].Blocks[0]
+	if column.Blocks[0].Key != "countText" {
+		t.Error("Expected block key to be renamed")
+	}
+	if column.Blocks[1].Actions[0].Triggers[1].Properties[0].Value != "countText" {
+		t.Error("Expected blockKey trigger property to be renamed")
+	}
+
+	if errs := RenameBlockKey(&frame, "button", "countText"); len(errs) == 0 {
+		t.Error("Expected renaming to an existing key to be refused")
+	}
+
+	if errs := RenameBlockKey(&frame, "button", "submit"); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	if frame.Blocks[0].Blocks[0].Blocks[1].Actions[0].Key != "submit" {
+		t.Error("Expected action keys to follow the block key")
+	}
+}
+
+func TestMoveBlock(t *testing.T) {
+	frame := _refactorFrame()
+	if errs := MoveBlock(&frame, "button", "column", "content", 0); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	column := frame.Blocks[0].Blocks[0]
+	if column.Blocks[0].Key != "button" || column.Blocks[1].Key != "text" {
+		t.Errorf("Expected button to move before text, got %s, %s", column.Blocks[0].Key, column.Blocks[1].Key)
+	}
+
+	if errs := MoveBlock(&frame, "button", "root", "content", -1); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	if len(frame.Blocks[0].Blocks) != 2 || frame.Blocks[0].Blocks[1].Key != "button" {
+		t.Error("Expected button to be appended to root")
+	}
+
+	refusals := []struct {
+		key, parent, slot string
+		index             int
+	}{
+		{"column", "text", "content", 0},
+		{"column", "column", "content", 0},
+		{"root", "column", "content", 0},
+		{"text", "column", "footer", 0},
+		{"text", "column", "content", 5},
+		{"text", "missing", "content", 0},
+	}
+	for _, r := range refusals {
+		frame := _refactorFrame()
+		if errs := MoveBlock(&frame, r.key, r.parent, r.slot, r.index); len(errs) == 0 {
+			t.Errorf("Expected moving %s into %s/%s at %d to be refused", r.key, r.parent, r.slot, r.index)
+		}
+		if len(frame.Blocks[0].Blocks[0].Blocks) != 2 {
+			t.Error("Expected frame to be unchanged after a refused move")
+		}
+	}
+}
+
+func TestExtractBlock(t *testing.T) {
+	frame := _refactorFrame()
+	if _, errs := ExtractBlock(&frame, "text"); len(errs) == 0 || !strings.Contains(errs[0].Message, "still referenced") {
+		t.Errorf("Expected extracting a referenced block to be refused, got %v", errs)
+	}
+
+	extracted, errs := ExtractBlock(&frame, "button")
+	if errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	if extracted.Block.Key != "button" {
+		t.Errorf("Expected extracted block 'button', got %s", extracted.Block.Key)
+	}
+	var keys []string
+	for _, v := range extracted.Variables {
+		keys = append(keys, v.Key)
+	}
+	if strings.Join(keys, ",") != "visible,count,label" {
+		t.Errorf("Unexpected extracted variables: %v", keys)
+	}
+	if len(frame.Blocks[0].Blocks[0].Blocks) != 1 {
+		t.Error("Expected block to be removed from the frame")
+	}
+}
+
+const componentFrame = `frame(name = "profile", route = "/profile") {
+    var userName: STRING = "Ada"
+    var visible: BOOLEAN = true
+
+    component(name = "card") {
+        data label
+
+        block(keyType = "nativeblocks/column", key = "card", visibility = visible)
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "title")
+            .data(text = userName)
+            block(keyType = "nativeblocks/text", key = "caption")
+            .data(text = label)
+            block(keyType = "nativeblocks/button", key = "refresh")
+            .action(event = "onClick") {
+                trigger(keyType = "nativeblocks/change_block_property", name = "hide")
+                .prop(blockKey = "title")
+                .data(variableKey = userName)
+            }
+        }
+    }
+
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(component = "card", key = "userCard")
+        .data(label = userName)
+    }
+}`
+
+func _componentFrame(t *testing.T) model.FrameDSLModel {
+	t.Helper()
+	p := parser.NewParser(lexer.NewLexer(componentFrame), componentFrame)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse frame:\n%s", p.ErrorCollector().FormatAll())
+	}
+	return *frame
+}
+
+func TestRefactorComponents(t *testing.T) {
+	frame := _componentFrame(t)
+	if errs := RenameVariable(&frame, "userName", "displayName"); errs != nil {
+		t.Fatalf("Unexpected errors: %v", errs[0].Message)
+	}
+	card := frame.Components[0].Block
+	if card.Blocks[0].Data[0].Value != "displayName" || card.Blocks[2].Actions[0].Triggers[0].Data[0].Value != "displayName" {
+		t.Errorf("Expected the bindings inside the component to be renamed, got %+v", card.Blocks)
+	}
+	if frame.Blocks[0].Blocks[0].Data[0].Value != "displayName" {
+		t.Error("Expected the instance data to be renamed")
+	}
+
+	frame = _componentFrame(t)
+	if errs := RenameVariable(&frame, "visible", "label"); len(errs) == 0 || !strings.Contains(errs[0].Message, "shadowed") {
+		t.Errorf("Expected a rename captured by a data parameter to be refused, got %v", errs)
+	}
+
+	frame = _componentFrame(t)
+	if errs := RenameBlockKey(&frame, "title", "heading"); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	card = frame.Components[0].Block
+	if card.Blocks[0].Key != "heading" || card.Blocks[2].Actions[0].Triggers[0].Properties[0].Value != "heading" {
+		t.Errorf("Expected the definition block and its reference to be renamed, got %+v", card.Blocks)
+	}
+
+	if errs := MoveBlock(&frame, "refresh", "card", "content", 0); errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	if frame.Components[0].Block.Blocks[0].Key != "refresh" {
+		t.Errorf("Expected the block to move within the definition, got %+v", frame.Components[0].Block.Blocks)
+	}
+	if errs := MoveBlock(&frame, "card", "root", "content", 0); len(errs) == 0 {
+		t.Error("Expected moving the root of a definition to be refused")
+	}
+
+	extracted, errs := ExtractBlock(&frame, "caption")
+	if errs != nil {
+		t.Fatal(errs[0].Message)
+	}
+	if extracted.Block.Key != "caption" || len(frame.Components[0].Block.Blocks) != 2 {
+		t.Errorf("Expected the block to be extracted from the definition, got %+v", frame.Components[0].Block.Blocks)
+	}
+}
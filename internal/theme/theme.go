@@ -0,0 +1,236 @@
+package theme
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/types"
+)
+
+// namePattern matches a token name segment.
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// StringGroup is the reference group of string resources (@string.welcome), which is not a token group.
+const StringGroup = "string"
+
+// deviceKeys are the keys of a theme object holding per-device values instead of nested tokens.
+var deviceKeys = map[string]bool{"value": true, "mobile": true, "tablet": true, "desktop": true}
+
+// Token is a design token with its value for each device.
+type Token struct {
+	Name    string
+	Mobile  string
+	Tablet  string
+	Desktop string
+}
+
+// Theme holds the design tokens of a theme file, by dotted name without the leading '@'.
+type Theme struct {
+	Tokens map[string]Token
+}
+
+// Parse reads a JSON theme file. Objects group tokens and nest into dotted names, so
+// {"color": {"primary": "#2563EB"}} declares @color.primary. A token is a string, number or boolean, or
+// an object of per-device values with "value", "mobile", "tablet" and "desktop" keys; a device without
+// a value inherits the value of the next smaller device.
+func Parse(content string) (*Theme, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(content)))
+	decoder.UseNumber()
+
+	var root map[string]any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("invalid theme JSON: %w", err)
+	}
+
+	t := &Theme{Tokens: make(map[string]Token)}
+	if err := t._add("", root); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Theme) _add(prefix string, group map[string]any) error {
+	for key, value := range group {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		if !namePattern.MatchString(key) {
+			return fmt.Errorf("invalid token name '%s'", name)
+		}
+
+		object, isObject := value.(map[string]any)
+		if prefix == "" && (!isObject || _isDeviceValue(object)) {
+			return fmt.Errorf("token '%s' must be declared in a group such as \"color\"", name)
+		}
+		if prefix == "" && key == StringGroup {
+			return fmt.Errorf("group '%s' is reserved for string resources", StringGroup)
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			if !_isDeviceValue(v) {
+				if err := t._add(name, v); err != nil {
+					return err
+				}
+				continue
+			}
+			token := Token{Name: name}
+			for device, deviceValue := range v {
+				s, err := _scalar(name, deviceValue)
+				if err != nil {
+					return err
+				}
+				switch device {
+				case "value":
+					if token.Mobile == "" {
+						token.Mobile = s
+					}
+					if token.Tablet == "" {
+						token.Tablet = s
+					}
+					if token.Desktop == "" {
+						token.Desktop = s
+					}
+				case "mobile":
+					token.Mobile = s
+				case "tablet":
+					token.Tablet = s
+				case "desktop":
+					token.Desktop = s
+				}
+			}
+			if token.Tablet == "" {
+				token.Tablet = token.Mobile
+			}
+			if token.Desktop == "" {
+				token.Desktop = token.Tablet
+			}
+			t.Tokens[name] = token
+		default:
+			s, err := _scalar(name, v)
+			if err != nil {
+				return err
+			}
+			t.Tokens[name] = Token{Name: name, Mobile: s, Tablet: s, Desktop: s}
+		}
+	}
+	return nil
+}
+
+// Names returns the token references of the theme, sorted, with their leading '@'.
+func (t *Theme) Names() []string {
+	names := make([]string, 0, len(t.Tokens))
+	for name := range t.Tokens {
+		names = append(names, "@"+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Reference returns the token name of a property value that is a token reference, without the '@'.
+// Tokens are always in a group, so values such as "@handle" stay plain strings, and references to
+// string resources are not tokens.
+func Reference(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if !lexer.IsReference(value) || strings.HasPrefix(value, "@"+StringGroup+".") {
+		return "", false
+	}
+	return value[1:], true
+}
+
+// Resolve replaces the token references in the block properties of frame and its components with their
+// values for each device. With keep, references are only checked and stay in place. Unknown tokens are
+// reported with the closest token names.
+func Resolve(frame *model.FrameDSLModel, t *Theme, keep bool) []*errors.Error {
+	r := &_resolver{theme: t, keep: keep}
+	for i := range frame.Components {
+		r._resolveBlock(&frame.Components[i].Block)
+	}
+	for i := range frame.Blocks {
+		r._resolveBlock(&frame.Blocks[i])
+	}
+	return r.errs
+}
+
+type _resolver struct {
+	theme *Theme
+	keep  bool
+	errs  []*errors.Error
+}
+
+func (r *_resolver) _resolveBlock(block *model.BlockDSLModel) {
+	for i := range block.Properties {
+		prop := &block.Properties[i]
+		reported := make(map[string]bool)
+		resolved := false
+		for _, device := range []struct {
+			value *string
+			pick  func(Token) string
+		}{
+			{&prop.ValueMobile, func(token Token) string { return token.Mobile }},
+			{&prop.ValueTablet, func(token Token) string { return token.Tablet }},
+			{&prop.ValueDesktop, func(token Token) string { return token.Desktop }},
+		} {
+			name, ok := Reference(*device.value)
+			if !ok {
+				continue
+			}
+			token, exists := r.theme.Tokens[name]
+			if !exists {
+				if !reported[name] {
+					reported[name] = true
+					r.errs = append(r.errs, errors.UnknownTokenError("@"+name, prop.Line, prop.Column, r.theme.Names()))
+				}
+				continue
+			}
+			if !r.keep {
+				*device.value = device.pick(token)
+				resolved = true
+			}
+		}
+		if resolved {
+			prop.Type = types.InferType(prop.ValueMobile).Name()
+		}
+	}
+	for i := range block.Blocks {
+		r._resolveBlock(&block.Blocks[i])
+	}
+}
+
+func _isDeviceValue(object map[string]any) bool {
+	if len(object) == 0 {
+		return false
+	}
+	for key, value := range object {
+		if !deviceKeys[key] {
+			return false
+		}
+		if _, nested := value.(map[string]any); nested {
+			return false
+		}
+	}
+	return true
+}
+
+func _scalar(name string, value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	return "", fmt.Errorf("token '%s' must be a string, number, boolean or per-device object", name)
+}
@@ -0,0 +1,129 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/parser"
+)
+
+const themeJSON = `{
+  "color": {
+    "primary": "#2563EB",
+    "surface": {"mobile": "#FFFFFF", "desktop": "#F8FAFC"}
+  },
+  "spacing": {
+    "md": 16,
+    "lg": {"value": "24", "tablet": "32"}
+  },
+  "typography": {
+    "body": {"size": 14, "bold": false}
+  }
+}`
+
+func _parseFrame(t *testing.T, input string) model.FrameDSLModel {
+	t.Helper()
+	p := parser.NewParser(lexer.NewLexer(input), input)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Unexpected parse errors: %s", p.ErrorCollector().FormatAll())
+	}
+	return *frame
+}
+
+func TestParse(t *testing.T) {
+	theme, err := Parse(themeJSON)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]Token{
+		"color.primary":        {Name: "color.primary", Mobile: "#2563EB", Tablet: "#2563EB", Desktop: "#2563EB"},
+		"color.surface":        {Name: "color.surface", Mobile: "#FFFFFF", Tablet: "#FFFFFF", Desktop: "#F8FAFC"},
+		"spacing.md":           {Name: "spacing.md", Mobile: "16", Tablet: "16", Desktop: "16"},
+		"spacing.lg":           {Name: "spacing.lg", Mobile: "24", Tablet: "32", Desktop: "24"},
+		"typography.body.size": {Name: "typography.body.size", Mobile: "14", Tablet: "14", Desktop: "14"},
+		"typography.body.bold": {Name: "typography.body.bold", Mobile: "false", Tablet: "false", Desktop: "false"},
+	}
+	if len(theme.Tokens) != len(expected) {
+		t.Errorf("Expected %d tokens, got %v", len(expected), theme.Names())
+	}
+	for name, token := range expected {
+		if theme.Tokens[name] != token {
+			t.Errorf("Expected %s to be %+v, got %+v", name, token, theme.Tokens[name])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		`{"color": `:                          "invalid theme JSON",
+		`{"color": {"primary-dark": "#000"}}`: "invalid token name 'color.primary-dark'",
+		`{"color": {"primary": ["#000"]}}`:    "token 'color.primary' must be a string, number, boolean or per-device object",
+		`{"primary": "#000"}`:                 "token 'primary' must be declared in a group",
+		`{"string": {"welcome": "Hi"}}`:       "group 'string' is reserved for string resources",
+	}
+	for content, expected := range tests {
+		if _, err := Parse(content); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Parse(%s): expected error %q, got %v", content, expected, err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	theme, _ := Parse(themeJSON)
+	frame := _parseFrame(t, `frame(name = "home", route = "/home") {
+    block(keyType = "ROOT", key = "root")
+        .prop(
+            backgroundColor = @color.surface,
+            padding = (mobile = @spacing.md, desktop = @spacing.lg),
+            fontSize = "@typography.body.size",
+            handle = "@nativeblocks"
+        )
+}`)
+
+	if errs := Resolve(&frame, theme, false); len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %s", errs[0].Message)
+	}
+
+	props := frame.Blocks[0].Properties
+	expected := [][3]string{
+		{"#FFFFFF", "#FFFFFF", "#F8FAFC"},
+		{"16", "", "24"},
+		{"14", "14", "14"},
+		{"@nativeblocks", "@nativeblocks", "@nativeblocks"},
+	}
+	for i, values := range expected {
+		got := [3]string{props[i].ValueMobile, props[i].ValueTablet, props[i].ValueDesktop}
+		if got != values {
+			t.Errorf("Expected %s to be %v, got %v", props[i].Key, values, got)
+		}
+	}
+	if props[2].Type != "INT" {
+		t.Errorf("Expected the resolved type to be inferred, got %s", props[2].Type)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	theme, _ := Parse(themeJSON)
+	frame := _parseFrame(t, `frame(name = "home", route = "/home") {
+    block(keyType = "ROOT", key = "root")
+        .prop(color = @color.primry, margin = (mobile = @spacing.xl, tablet = @spacing.xl))
+}`)
+
+	errs := Resolve(&frame, theme, true)
+	if len(errs) != 2 {
+		t.Fatalf("Expected one error per unknown token and property, got %d", len(errs))
+	}
+	if errs[0].Message != "Unknown token '@color.primry'" || errs[0].Suggestion != "Did you mean '@color.primary'?" {
+		t.Errorf("Unexpected error: %s (%s)", errs[0].Message, errs[0].Suggestion)
+	}
+	if errs[0].Line != 3 || errs[0].Column != 20 {
+		t.Errorf("Expected the error at the color property, got %d:%d", errs[0].Line, errs[0].Column)
+	}
+	if frame.Blocks[0].Properties[0].ValueMobile != "@color.primry" {
+		t.Errorf("Expected references to be kept")
+	}
+}
@@ -1,8 +1,12 @@
 package types
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -34,11 +38,86 @@ func (t PrimitiveType) IsCompatible(other Type) bool {
 		return false
 	case "DOUBLE":
 		return false
+	case "COLOR", "URL", "JSON":
+		// Colors, URLs and JSON documents are strings with a checked format.
+		return other == TypeString
 	default:
 		return false
 	}
 }
 
+// ListType is LIST<T>, a JSON array whose elements are all of type Element.
+type ListType struct {
+	Element Type
+}
+
+func (t ListType) Name() string {
+	return fmt.Sprintf("LIST<%s>", t.Element.Name())
+}
+
+// IsCompatible reports whether a list can be used as other: a list whose elements are compatible, or
+// a JSON document.
+func (t ListType) IsCompatible(other Type) bool {
+	switch o := other.(type) {
+	case ListType:
+		return t.Element.IsCompatible(o.Element)
+	case PrimitiveType:
+		return o == TypeJSON
+	}
+	return false
+}
+
+// EnumType is a type declared as enum Status { IDLE, LOADING }, whose values are its member names.
+type EnumType struct {
+	name    string
+	Members []string
+}
+
+// NewEnumType creates the enum type name with members.
+func NewEnumType(name string, members []string) *EnumType {
+	return &EnumType{name: name, Members: members}
+}
+
+func (t *EnumType) Name() string {
+	return t.name
+}
+
+// IsCompatible reports whether a value of the enum can be used as other: the same enum, or a string.
+func (t *EnumType) IsCompatible(other Type) bool {
+	return other == TypeString || other.Name() == t.name
+}
+
+// Has reports whether member is a member of the enum.
+func (t *EnumType) Has(member string) bool {
+	for _, m := range t.Members {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+// MapType is MAP<STRING,T>, a JSON object whose values are all of type Value. Keys are always strings.
+type MapType struct {
+	Value Type
+}
+
+func (t MapType) Name() string {
+	return fmt.Sprintf("MAP<STRING,%s>", t.Value.Name())
+}
+
+// IsCompatible reports whether a map can be used as other: a map whose values are compatible, or a JSON
+// document.
+func (t MapType) IsCompatible(other Type) bool {
+	switch o := other.(type) {
+	case MapType:
+		return t.Value.IsCompatible(o.Value)
+	case PrimitiveType:
+		return o == TypeJSON
+	}
+	return false
+}
+
 var (
 	TypeString  = PrimitiveType{"STRING"}
 	TypeInt     = PrimitiveType{"INT"}
@@ -46,11 +125,49 @@ var (
 	TypeFloat   = PrimitiveType{"FLOAT"}
 	TypeDouble  = PrimitiveType{"DOUBLE"}
 	TypeBoolean = PrimitiveType{"BOOLEAN"}
+	TypeColor   = PrimitiveType{"COLOR"}
+	TypeURL     = PrimitiveType{"URL"}
+	TypeJSON    = PrimitiveType{"JSON"}
 	TypeUnknown = PrimitiveType{"UNKNOWN"}
 )
 
+// FromString returns the type named typeName. Parameterised types name their element type in angle
+// brackets, LIST<INT> and MAP<STRING,INT>, and nest: LIST<MAP<STRING,BOOLEAN>>.
 func FromString(typeName string) (Type, error) {
-	switch strings.ToUpper(typeName) {
+	return FromStringWith(typeName, nil)
+}
+
+// FromStringWith is FromString with the declared types in named, such as enums, by their exact name.
+// Named types can be type parameters too: LIST<Status>.
+func FromStringWith(typeName string, named map[string]Type) (Type, error) {
+	name := strings.Join(strings.Fields(typeName), "")
+	if t, exists := named[name]; exists {
+		return t, nil
+	}
+	if open := strings.IndexByte(name, '<'); open >= 0 {
+		if !strings.HasSuffix(name, ">") {
+			return TypeUnknown, fmt.Errorf("unknown type: %s", typeName)
+		}
+		arguments, err := _typeArguments(name[open+1:len(name)-1], named)
+		if err != nil {
+			return TypeUnknown, fmt.Errorf("unknown type: %s: %w", typeName, err)
+		}
+		switch strings.ToUpper(name[:open]) {
+		case "LIST":
+			if len(arguments) != 1 {
+				return TypeUnknown, fmt.Errorf("LIST takes one element type, as in LIST<STRING>: %s", typeName)
+			}
+			return ListType{Element: arguments[0]}, nil
+		case "MAP":
+			if len(arguments) != 2 || arguments[0] != TypeString {
+				return TypeUnknown, fmt.Errorf("MAP takes STRING keys and a value type, as in MAP<STRING,INT>: %s", typeName)
+			}
+			return MapType{Value: arguments[1]}, nil
+		}
+		return TypeUnknown, fmt.Errorf("unknown type: %s", typeName)
+	}
+
+	switch strings.ToUpper(name) {
 	case "STRING":
 		return TypeString, nil
 	case "INT":
@@ -63,11 +180,89 @@ func FromString(typeName string) (Type, error) {
 		return TypeDouble, nil
 	case "BOOLEAN":
 		return TypeBoolean, nil
+	case "COLOR":
+		return TypeColor, nil
+	case "URL":
+		return TypeURL, nil
+	case "JSON":
+		return TypeJSON, nil
+	case "LIST", "MAP":
+		return TypeUnknown, fmt.Errorf("%s needs a type parameter, as in LIST<STRING> or MAP<STRING,INT>", strings.ToUpper(name))
 	default:
 		return TypeUnknown, fmt.Errorf("unknown type: %s", typeName)
 	}
 }
 
+// _typeArguments splits and parses the comma-separated type arguments between angle brackets.
+func _typeArguments(arguments string, named map[string]Type) ([]Type, error) {
+	var types []Type
+	depth, start := 0, 0
+	for i := 0; i <= len(arguments); i++ {
+		if i < len(arguments) {
+			switch arguments[i] {
+			case '<':
+				depth++
+				continue
+			case '>':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		t, err := FromStringWith(arguments[start:i], named)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+		start = i + 1
+	}
+	return types, nil
+}
+
+// WireName returns the type name used in compiled JSON. Consumers only know the primitive types, so
+// COLOR, URL, JSON, LIST, MAP and enum values are compiled as STRING.
+func WireName(t Type) string {
+	switch t.(type) {
+	case ListType, MapType, *EnumType:
+		return TypeString.Name()
+	}
+	switch t {
+	case TypeColor, TypeURL, TypeJSON:
+		return TypeString.Name()
+	}
+	return t.Name()
+}
+
+// FieldType returns the type of a field of a value of type t, read as item.field: the value type of a
+// map, or JSON for a JSON document. Other types have no fields.
+func FieldType(t Type) (Type, bool) {
+	if m, ok := t.(MapType); ok {
+		return m.Value, true
+	}
+	if t == TypeJSON {
+		return TypeJSON, true
+	}
+	return nil, false
+}
+
+// IsAssignable reports whether a value of type t, such as the result of an expression, can be held by a
+// variable of type declared: a compatible type, a whole number for FLOAT or DOUBLE, or a string for an
+// enum, whose members are only checked when the value is known.
+func IsAssignable(t, declared Type) bool {
+	if t.Name() == declared.Name() || t.IsCompatible(declared) {
+		return true
+	}
+	if _, isEnum := declared.(*EnumType); isEnum {
+		return t == TypeString
+	}
+	return (t == TypeInt || t == TypeLong) && (declared == TypeFloat || declared == TypeDouble)
+}
+
 func InferType(value string) Type {
 	value = strings.TrimSpace(value)
 
@@ -148,6 +343,25 @@ func ValidateValue(value string, expectedType Type) (bool, string) {
 		}
 		return true, ""
 
+	case "COLOR":
+		if !colorRegex.MatchString(value) {
+			return false, fmt.Sprintf("'%s' is not a valid color. Expected #RGB, #ARGB, #RRGGBB or #AARRGGBB", value)
+		}
+		return true, ""
+
+	case "URL":
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || (u.Host == "" && u.Opaque == "") {
+			return false, fmt.Sprintf("'%s' is not a valid URL. Expected an absolute URL such as https://example.com", value)
+		}
+		return true, ""
+
+	case "JSON":
+		if !json.Valid([]byte(value)) {
+			return false, fmt.Sprintf("'%s' is not well-formed JSON", value)
+		}
+		return true, ""
+
 	case "STRING":
 		if _isInteger(value) {
 			return false, fmt.Sprintf("'%s' is a numeric value. Cannot assign to STRING type. Use quotes for string values", value)
@@ -160,14 +374,80 @@ func ValidateValue(value string, expectedType Type) (bool, string) {
 		}
 		return true, ""
 
-	default:
-		return false, fmt.Sprintf("unknown type: %s", expectedType.Name())
 	}
+
+	switch t := expectedType.(type) {
+	case *EnumType:
+		if !t.Has(value) {
+			return false, fmt.Sprintf("'%s' is not a member of %s. Expected one of %s", value, t.Name(), strings.Join(t.Members, ", "))
+		}
+		return true, ""
+
+	case ListType:
+		var elements []json.RawMessage
+		if err := json.Unmarshal([]byte(value), &elements); err != nil || !strings.HasPrefix(value, "[") {
+			return false, fmt.Sprintf("'%s' is not a list. Expected a literal such as [1, 2, 3]", value)
+		}
+		for i, element := range elements {
+			if valid, msg := _validateElement(element, t.Element); !valid {
+				return false, fmt.Sprintf("element %d of %s: %s", i, t.Name(), msg)
+			}
+		}
+		return true, ""
+
+	case MapType:
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(value), &entries); err != nil || !strings.HasPrefix(value, "{") {
+			return false, fmt.Sprintf("'%s' is not a map. Expected a literal such as {\"key\": 1}", value)
+		}
+		for _, key := range _sortedKeys(entries) {
+			if valid, msg := _validateElement(entries[key], t.Value); !valid {
+				return false, fmt.Sprintf("value '%s' of %s: %s", key, t.Name(), msg)
+			}
+		}
+		return true, ""
+	}
+
+	return false, fmt.Sprintf("unknown type: %s", expectedType.Name())
+}
+
+// _validateElement validates an element of a list or a value of a map. Strings must be JSON strings,
+// numbers and booleans JSON literals, and lists and maps nested arrays and objects.
+func _validateElement(element json.RawMessage, expectedType Type) (bool, string) {
+	element = bytes.TrimSpace(element)
+	_, isEnum := expectedType.(*EnumType)
+	switch {
+	case expectedType == TypeJSON:
+		return true, ""
+	case expectedType == TypeString, expectedType == TypeColor, expectedType == TypeURL, isEnum:
+		var s string
+		if err := json.Unmarshal(element, &s); err != nil {
+			return false, fmt.Sprintf("'%s' is not a string. Use quotes for string values", element)
+		}
+		if expectedType == TypeString {
+			return true, ""
+		}
+		return ValidateValue(s, expectedType)
+	}
+	if len(element) > 0 && element[0] == '"' {
+		return false, fmt.Sprintf("'%s' is a string. Expected %s", element, expectedType.Name())
+	}
+	return ValidateValue(string(element), expectedType)
+}
+
+func _sortedKeys(entries map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 var (
 	integerRegex = regexp.MustCompile(`^-?\d+$`)
 	floatRegex   = regexp.MustCompile(`^-?\d+\.\d+$`)
+	colorRegex   = regexp.MustCompile(`^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{4}|[0-9A-Fa-f]{6}|[0-9A-Fa-f]{8})$`)
 )
 
 func _isInteger(s string) bool {
@@ -46,6 +46,16 @@ func TestTypeCompatibility(t *testing.T) {
 		{"STRING to STRING", TypeString, TypeString, true},
 		{"BOOLEAN to STRING", TypeBoolean, TypeString, false},
 		{"INT to FLOAT", TypeInt, TypeFloat, false},
+		{"COLOR to STRING", TypeColor, TypeString, true},
+		{"STRING to COLOR", TypeString, TypeColor, false},
+		{"URL to STRING", TypeURL, TypeString, true},
+		{"JSON to STRING", TypeJSON, TypeString, true},
+		{"LIST<INT> to LIST<LONG>", ListType{TypeInt}, ListType{TypeLong}, true},
+		{"LIST<LONG> to LIST<INT>", ListType{TypeLong}, ListType{TypeInt}, false},
+		{"LIST<INT> to JSON", ListType{TypeInt}, TypeJSON, true},
+		{"LIST<INT> to STRING", ListType{TypeInt}, TypeString, false},
+		{"MAP<STRING,FLOAT> to MAP<STRING,DOUBLE>", MapType{TypeFloat}, MapType{TypeDouble}, true},
+		{"MAP<STRING,INT> to LIST<INT>", MapType{TypeInt}, ListType{TypeInt}, false},
 	}
 
 	for _, tt := range tests {
@@ -82,6 +92,28 @@ func TestValidateValue(t *testing.T) {
 		{"invalid double", "abc", TypeDouble, false},
 		{"valid string", "hello", TypeString, true},
 		{"empty string", "", TypeString, true},
+		{"valid color", "#2563EB", TypeColor, true},
+		{"valid short color", "#FFF", TypeColor, true},
+		{"valid color with alpha", "#802563EB", TypeColor, true},
+		{"valid short color with alpha", "#8FFF", TypeColor, true},
+		{"invalid color length", "#12345", TypeColor, false},
+		{"invalid color digits", "#GGGGGG", TypeColor, false},
+		{"valid url", "https://example.com/a?b=c", TypeURL, true},
+		{"valid opaque url", "mailto:team@example.com", TypeURL, true},
+		{"relative url", "/home", TypeURL, false},
+		{"valid json", `{"a": [1, true, null]}`, TypeJSON, true},
+		{"invalid json", `{"a": }`, TypeJSON, false},
+		{"valid list", `[1, 2, 3]`, ListType{TypeInt}, true},
+		{"empty list", `[]`, ListType{TypeString}, true},
+		{"list of wrong elements", `[1, "two"]`, ListType{TypeInt}, false},
+		{"list of numeric strings", `["1", "2"]`, ListType{TypeString}, true},
+		{"list of colors", `["#FFF", "blue"]`, ListType{TypeColor}, false},
+		{"nested list", `[[1.5], [2.5, 3.5]]`, ListType{ListType{TypeFloat}}, true},
+		{"not a list", `{"a": 1}`, ListType{TypeInt}, false},
+		{"valid map", `{"a": true, "b": false}`, MapType{TypeBoolean}, true},
+		{"map of wrong values", `{"a": 1}`, MapType{TypeBoolean}, false},
+		{"map of lists", `{"a": [1], "b": []}`, MapType{ListType{TypeInt}}, true},
+		{"not a map", `[1]`, MapType{TypeInt}, false},
 	}
 
 	for _, tt := range tests {
@@ -108,6 +140,17 @@ func TestFromString(t *testing.T) {
 		{"valid DOUBLE", "DOUBLE", false},
 		{"valid BOOLEAN", "BOOLEAN", false},
 		{"valid lowercase", "string", false},
+		{"valid COLOR", "COLOR", false},
+		{"valid URL", "URL", false},
+		{"valid JSON", "JSON", false},
+		{"valid LIST", "LIST<STRING>", false},
+		{"valid MAP", "MAP<STRING, INT>", false},
+		{"valid nested", "list<map<string,list<boolean>>>", false},
+		{"LIST without parameter", "LIST", true},
+		{"LIST with two parameters", "LIST<INT,INT>", true},
+		{"MAP with INT keys", "MAP<INT,INT>", true},
+		{"unknown parameter", "LIST<CHAR>", true},
+		{"unbalanced", "LIST<INT", true},
 		{"invalid type", "INVALID", true},
 	}
 
@@ -120,3 +163,60 @@ func TestFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeNames(t *testing.T) {
+	tests := map[string]struct {
+		name string
+		wire string
+	}{
+		"INT":                               {"INT", "INT"},
+		"color":                             {"COLOR", "STRING"},
+		"LIST< STRING >":                    {"LIST<STRING>", "STRING"},
+		"MAP<STRING, LIST<DOUBLE>>":         {"MAP<STRING,LIST<DOUBLE>>", "STRING"},
+		"LIST<MAP<STRING,MAP<STRING,URL>>>": {"LIST<MAP<STRING,MAP<STRING,URL>>>", "STRING"},
+	}
+
+	for typeName, expected := range tests {
+		typ, err := FromString(typeName)
+		if err != nil {
+			t.Fatalf("FromString(%q): unexpected error %v", typeName, err)
+		}
+		if typ.Name() != expected.name || WireName(typ) != expected.wire {
+			t.Errorf("FromString(%q) = %s (%s), want %s (%s)", typeName, typ.Name(), WireName(typ), expected.name, expected.wire)
+		}
+	}
+}
+
+func TestEnumType(t *testing.T) {
+	status := NewEnumType("Status", []string{"IDLE", "LOADING", "ERROR"})
+	named := map[string]Type{"Status": status}
+
+	typ, err := FromStringWith("Status", named)
+	if err != nil || typ != status {
+		t.Fatalf("FromStringWith(Status) = %v, %v", typ, err)
+	}
+	if _, err := FromString("Status"); err == nil {
+		t.Errorf("Expected Status to be unknown without its declaration")
+	}
+	if _, err := FromStringWith("STATUS", named); err == nil {
+		t.Errorf("Expected enum names to be case-sensitive")
+	}
+	list, err := FromStringWith("list<Status>", named)
+	if err != nil || list.Name() != "LIST<Status>" || WireName(list) != "STRING" || WireName(status) != "STRING" {
+		t.Fatalf("FromStringWith(list<Status>) = %v, %v", list, err)
+	}
+
+	if valid, _ := ValidateValue(" LOADING ", status); !valid {
+		t.Errorf("Expected LOADING to be a member")
+	}
+	if valid, msg := ValidateValue("DONE", status); valid || msg != "'DONE' is not a member of Status. Expected one of IDLE, LOADING, ERROR" {
+		t.Errorf("Expected DONE to be rejected, got %v %q", valid, msg)
+	}
+	if valid, msg := ValidateValue(`["IDLE", "done"]`, list); valid || msg != "element 1 of LIST<Status>: 'done' is not a member of Status. Expected one of IDLE, LOADING, ERROR" {
+		t.Errorf("Expected the list element to be rejected, got %v %q", valid, msg)
+	}
+
+	if !status.IsCompatible(TypeString) || !status.IsCompatible(NewEnumType("Status", nil)) || status.IsCompatible(NewEnumType("Mode", nil)) {
+		t.Errorf("Expected Status to be compatible with STRING and itself only")
+	}
+}
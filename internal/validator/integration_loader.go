@@ -3,6 +3,7 @@ package validator
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 )
 
 type BlockIntegration struct {
@@ -41,17 +42,24 @@ type SlotDefinition struct {
 	Slot string `json:"slot"`
 }
 
+// DefaultFrameEvents are the lifecycle events frame actions may handle unless a registry sets its own.
+var DefaultFrameEvents = []string{"onLoad", "onResume", "onDispose"}
+
 type IntegrationRegistry struct {
 	Blocks  map[string]BlockIntegration
 	Actions map[string]ActionIntegration
+	// FrameEvents are the lifecycle events frame actions may handle, DefaultFrameEvents when loaded. Empty
+	// allows any event.
+	FrameEvents []string
 }
 
 // LoadIntegrations creates an IntegrationRegistry from JSON strings.
 // blocksJSON and actionsJSON should contain the integration definitions in the expected format.
 func LoadIntegrations(blocksJSON, actionsJSON string) (*IntegrationRegistry, error) {
 	registry := &IntegrationRegistry{
-		Blocks:  make(map[string]BlockIntegration),
-		Actions: make(map[string]ActionIntegration),
+		Blocks:      make(map[string]BlockIntegration),
+		Actions:     make(map[string]ActionIntegration),
+		FrameEvents: slices.Clone(DefaultFrameEvents),
 	}
 
 	if err := registry._parseBlocks(blocksJSON); err != nil {
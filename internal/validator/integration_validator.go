@@ -2,6 +2,7 @@ package validator
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/nativeblocks/nbx/internal/model"
@@ -21,7 +22,19 @@ func NewIntegrationValidator(registry *IntegrationRegistry) *IntegrationValidato
 func (iv *IntegrationValidator) ValidateFrame(frame *model.FrameDSLModel) error {
 	var errors []string
 
-	blockErrors := iv._validateBlocks(frame.Blocks)
+	errors = append(errors, iv._validateFrameActions(frame)...)
+	for _, sequence := range frame.Sequences {
+		errors = append(errors, iv._validateTriggers(sequence.Triggers, fmt.Sprintf("sequence '%s'", sequence.Name))...)
+	}
+
+	styles := make(map[string]model.StyleDSLModel, len(frame.Styles))
+	for _, style := range frame.Styles {
+		if _, exists := styles[style.Name]; !exists {
+			styles[style.Name] = style
+		}
+	}
+
+	blockErrors := iv._validateBlocks(frame.Blocks, styles)
 	errors = append(errors, blockErrors...)
 
 	if len(errors) > 0 {
@@ -31,25 +44,48 @@ func (iv *IntegrationValidator) ValidateFrame(frame *model.FrameDSLModel) error
 	return nil
 }
 
-func (iv *IntegrationValidator) _validateBlocks(blocks []model.BlockDSLModel) []string {
+// _validateFrameActions checks that frame actions handle one of the registry's frame events and that
+// their triggers use known action integrations. A registry without frame events, such as one built
+// without LoadIntegrations, does not restrict them.
+func (iv *IntegrationValidator) _validateFrameActions(frame *model.FrameDSLModel) []string {
+	var errors []string
+
+	owner := fmt.Sprintf("frame '%s'", frame.Name)
+	for _, action := range frame.Actions {
+		if len(iv.registry.FrameEvents) > 0 && !slices.Contains(iv.registry.FrameEvents, action.Event) {
+			errors = append(errors, fmt.Sprintf(
+				"frame '%s' uses invalid frame event '%s'. Available events: [%s]",
+				frame.Name, action.Event, strings.Join(iv.registry.FrameEvents, ", "),
+			))
+		}
+		errors = append(errors, iv._validateTriggers(action.Triggers, owner)...)
+	}
+
+	return errors
+}
+
+func (iv *IntegrationValidator) _validateBlocks(blocks []model.BlockDSLModel, styles map[string]model.StyleDSLModel) []string {
 	var errors []string
 
 	for _, block := range blocks {
 		if block.KeyType == "ROOT" {
-			errors = append(errors, iv._validateBlocks(block.Blocks)...)
+			errors = append(errors, iv._validateBlocks(block.Blocks, styles)...)
 			continue
 		}
 
 		integration, exists := iv.registry.GetBlock(block.KeyType)
 		if !exists {
 			errors = append(errors, fmt.Sprintf("block '%s' uses unknown integration '%s'", block.Key, block.KeyType))
-			errors = append(errors, iv._validateBlocks(block.Blocks)...)
+			errors = append(errors, iv._validateBlocks(block.Blocks, styles)...)
 			continue
 		}
 
 		propErrors := iv._validateBlockProperties(block, integration)
 		errors = append(errors, propErrors...)
 
+		styleErrors := iv._validateBlockStyles(block, integration, styles)
+		errors = append(errors, styleErrors...)
+
 		dataErrors := iv._validateBlockData(block, integration)
 		errors = append(errors, dataErrors...)
 
@@ -59,7 +95,7 @@ func (iv *IntegrationValidator) _validateBlocks(blocks []model.BlockDSLModel) []
 		eventErrors := iv._validateBlockEvents(block, integration)
 		errors = append(errors, eventErrors...)
 
-		errors = append(errors, iv._validateBlocks(block.Blocks)...)
+		errors = append(errors, iv._validateBlocks(block.Blocks, styles)...)
 	}
 
 	return errors
@@ -89,6 +125,52 @@ func (iv *IntegrationValidator) _validateBlockProperties(block model.BlockDSLMod
 	return errors
 }
 
+// _validateBlockStyles checks the properties a block takes from its styles, including the styles they
+// compose, against the block's integration. Properties the block sets itself are checked by
+// _validateBlockProperties, and undefined or cyclic styles are left to the Validator.
+func (iv *IntegrationValidator) _validateBlockStyles(block model.BlockDSLModel, integration BlockIntegration, styles map[string]model.StyleDSLModel) []string {
+	var errors []string
+
+	validProps := make(map[string]bool)
+	for _, prop := range integration.Properties {
+		validProps[prop.Key] = true
+	}
+	reported := make(map[string]bool)
+	for _, prop := range block.Properties {
+		reported[prop.Key] = true
+	}
+
+	visited := make(map[string]bool)
+	var check func(names []string)
+	check = func(names []string) {
+		for _, name := range names {
+			style, exists := styles[name]
+			if !exists || visited[name] {
+				continue
+			}
+			visited[name] = true
+			check(style.Styles)
+			for _, prop := range style.Properties {
+				if validProps[prop.Key] || reported[prop.Key] {
+					continue
+				}
+				reported[prop.Key] = true
+				availableProps := make([]string, 0, len(integration.Properties))
+				for _, p := range integration.Properties {
+					availableProps = append(availableProps, p.Key)
+				}
+				errors = append(errors, fmt.Sprintf(
+					"block '%s' uses style '%s' with invalid property '%s' for integration '%s'. Available properties: [%s]",
+					block.Key, style.Name, prop.Key, block.KeyType, strings.Join(availableProps, ", "),
+				))
+			}
+		}
+	}
+	check(block.Styles)
+
+	return errors
+}
+
 func (iv *IntegrationValidator) _validateBlockData(block model.BlockDSLModel, integration BlockIntegration) []string {
 	var errors []string
 
@@ -157,40 +239,49 @@ func (iv *IntegrationValidator) _validateBlockEvents(block model.BlockDSLModel,
 			))
 		}
 
-		triggerErrors := iv._validateTriggers(action.Triggers, block.Key)
+		triggerErrors := iv._validateTriggers(action.Triggers, fmt.Sprintf("block '%s'", block.Key))
 		errors = append(errors, triggerErrors...)
 	}
 
 	return errors
 }
 
-func (iv *IntegrationValidator) _validateTriggers(triggers []model.ActionTriggerDSLModel, blockKey string) []string {
+func (iv *IntegrationValidator) _validateTriggers(triggers []model.ActionTriggerDSLModel, owner string) []string {
 	var errors []string
 
 	for _, trigger := range triggers {
+		if trigger.Sequence != "" {
+			// Calls run the triggers of the sequence, which are validated with the sequence.
+			continue
+		}
+		if trigger.KeyType == model.ConditionKeyType {
+			// If/else is built in and only runs the triggers of its branches.
+			errors = append(errors, iv._validateTriggers(trigger.Triggers, owner)...)
+			continue
+		}
 		integration, exists := iv.registry.GetAction(trigger.KeyType)
 		if !exists {
 			errors = append(errors, fmt.Sprintf(
-				"block '%s' uses unknown action integration '%s' in trigger '%s'",
-				blockKey, trigger.KeyType, trigger.Name,
+				"%s uses unknown action integration '%s' in trigger '%s'",
+				owner, trigger.KeyType, trigger.Name,
 			))
-			errors = append(errors, iv._validateTriggers(trigger.Triggers, blockKey)...)
+			errors = append(errors, iv._validateTriggers(trigger.Triggers, owner)...)
 			continue
 		}
 
-		propErrors := iv._validateTriggerProperties(trigger, integration, blockKey)
+		propErrors := iv._validateTriggerProperties(trigger, integration, owner)
 		errors = append(errors, propErrors...)
 
-		dataErrors := iv._validateTriggerData(trigger, integration, blockKey)
+		dataErrors := iv._validateTriggerData(trigger, integration, owner)
 		errors = append(errors, dataErrors...)
 
-		errors = append(errors, iv._validateTriggers(trigger.Triggers, blockKey)...)
+		errors = append(errors, iv._validateTriggers(trigger.Triggers, owner)...)
 	}
 
 	return errors
 }
 
-func (iv *IntegrationValidator) _validateTriggerProperties(trigger model.ActionTriggerDSLModel, integration ActionIntegration, blockKey string) []string {
+func (iv *IntegrationValidator) _validateTriggerProperties(trigger model.ActionTriggerDSLModel, integration ActionIntegration, owner string) []string {
 	var errors []string
 
 	validProps := make(map[string]PropertyDefinition)
@@ -205,8 +296,8 @@ func (iv *IntegrationValidator) _validateTriggerProperties(trigger model.ActionT
 				availableProps = append(availableProps, key)
 			}
 			errors = append(errors, fmt.Sprintf(
-				"block '%s' trigger '%s' uses invalid property '%s' for action integration '%s'. Available properties: [%s]",
-				blockKey, trigger.Name, prop.Key, trigger.KeyType, strings.Join(availableProps, ", "),
+				"%s trigger '%s' uses invalid property '%s' for action integration '%s'. Available properties: [%s]",
+				owner, trigger.Name, prop.Key, trigger.KeyType, strings.Join(availableProps, ", "),
 			))
 		}
 	}
@@ -214,7 +305,7 @@ func (iv *IntegrationValidator) _validateTriggerProperties(trigger model.ActionT
 	return errors
 }
 
-func (iv *IntegrationValidator) _validateTriggerData(trigger model.ActionTriggerDSLModel, integration ActionIntegration, blockKey string) []string {
+func (iv *IntegrationValidator) _validateTriggerData(trigger model.ActionTriggerDSLModel, integration ActionIntegration, owner string) []string {
 	var errors []string
 
 	validData := make(map[string]DataDefinition)
@@ -229,8 +320,8 @@ func (iv *IntegrationValidator) _validateTriggerData(trigger model.ActionTrigger
 				availableData = append(availableData, key)
 			}
 			errors = append(errors, fmt.Sprintf(
-				"block '%s' trigger '%s' uses invalid data key '%s' for action integration '%s'. Available data keys: [%s]",
-				blockKey, trigger.Name, data.Key, trigger.KeyType, strings.Join(availableData, ", "),
+				"%s trigger '%s' uses invalid data key '%s' for action integration '%s'. Available data keys: [%s]",
+				owner, trigger.Name, data.Key, trigger.KeyType, strings.Join(availableData, ", "),
 			))
 		}
 	}
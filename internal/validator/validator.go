@@ -2,9 +2,11 @@ package validator
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/expr"
 	"github.com/nativeblocks/nbx/internal/model"
 	"github.com/nativeblocks/nbx/internal/types"
 )
@@ -13,15 +15,33 @@ type Validator struct {
 	frame          *model.FrameDSLModel
 	errorCollector *errors.ErrorCollector
 	variables      map[string]variableInfo
+	enums          map[string]types.Type
 	blockKeys      map[string]int
 	actionKeys     map[string]int
 	slotNames      map[string]bool
+	// sequences maps each trigger sequence name to its declaration.
+	sequences map[string]*model.SequenceDSLModel
+	// styles maps each style name to its declaration.
+	styles map[string]*model.StyleDSLModel
+	// repeaters maps the loop variable of each repeated block to the block's key.
+	repeaters map[string]string
+	// loops holds the loop variables in scope while validating a repeated subtree, innermost last.
+	loops []loopScope
+}
+
+type loopScope struct {
+	name     string
+	itemType types.Type
 }
 
 type variableInfo struct {
 	varType types.Type
 	line    int
 	used    bool
+	// imported variables come from a library and are not reported when unused.
+	imported bool
+	// computed variables are declared with val and derived from their expression.
+	computed bool
 }
 
 func NewValidator(frame *model.FrameDSLModel, source string) *Validator {
@@ -29,18 +49,28 @@ func NewValidator(frame *model.FrameDSLModel, source string) *Validator {
 		frame:          frame,
 		errorCollector: errors.NewErrorCollector(source),
 		variables:      make(map[string]variableInfo),
+		enums:          make(map[string]types.Type),
 		blockKeys:      make(map[string]int),
 		actionKeys:     make(map[string]int),
 		slotNames:      make(map[string]bool),
+		sequences:      make(map[string]*model.SequenceDSLModel),
+		styles:         make(map[string]*model.StyleDSLModel),
+		repeaters:      make(map[string]string),
 	}
 }
 
 func (v *Validator) _validate() (*errors.ErrorCollector, error) {
+	v._collectEnums()
 	v._collectVariables()
 	v._collectBlockKeys()
 	v._collectSlots()
+	v._collectSequences()
+	v._collectStyles()
 
+	v._validateComputed()
 	v._validateFrame()
+	v._validateSequences()
+	v._validateStyles()
 	v._validateBlocks(v.frame.Blocks)
 
 	v._checkUnusedVariables()
@@ -48,37 +78,132 @@ func (v *Validator) _validate() (*errors.ErrorCollector, error) {
 	return v.errorCollector, nil
 }
 
+// EnumTypes returns the types declared by enums, by name, for types.FromStringWith.
+func EnumTypes(enums []model.EnumDSLModel) map[string]types.Type {
+	named := make(map[string]types.Type, len(enums))
+	for _, enum := range enums {
+		if _, exists := named[enum.Name]; !exists {
+			named[enum.Name] = types.NewEnumType(enum.Name, enum.Members)
+		}
+	}
+	return named
+}
+
+func (v *Validator) _collectEnums() {
+	lines := make(map[string]int)
+	for _, enum := range v.frame.Enums {
+		_fail := func(format string, args ...any) {
+			v.errorCollector.AddError(&errors.Error{
+				Severity: errors.SeverityError,
+				Message:  fmt.Sprintf(format, args...),
+				File:     enum.File,
+				Line:     enum.Line,
+				Column:   enum.Column,
+			})
+		}
+
+		if firstLine, exists := lines[enum.Name]; exists {
+			err := errors.DuplicateDeclarationError(enum.Name, enum.Line, enum.Column, firstLine)
+			err.File = enum.File
+			err.Suggestion = fmt.Sprintf("Enum '%s' is already declared", enum.Name)
+			v.errorCollector.AddError(err)
+			continue
+		}
+		lines[enum.Name] = enum.Line
+
+		if builtin, err := types.FromString(enum.Name); err == nil {
+			_fail("Enum '%s' conflicts with the built-in type %s", enum.Name, builtin.Name())
+			continue
+		}
+		if len(enum.Members) == 0 {
+			_fail("Enum '%s' has no members", enum.Name)
+		}
+		members := make(map[string]bool, len(enum.Members))
+		for _, member := range enum.Members {
+			if members[member] {
+				_fail("Duplicate member '%s' in enum '%s'", member, enum.Name)
+			}
+			members[member] = true
+		}
+		v.enums[enum.Name] = types.NewEnumType(enum.Name, enum.Members)
+	}
+}
+
 func (v *Validator) _collectVariables() {
 	for _, variable := range v.frame.Variables {
 		if existing, exists := v.variables[variable.Key]; exists {
-			v.errorCollector.AddError(errors.DuplicateDeclarationError(
-				variable.Key, variable.Line, variable.Column, existing.line,
-			))
+			err := errors.DuplicateDeclarationError(variable.Key, variable.Line, variable.Column, existing.line)
+			err.File = variable.File
+			v.errorCollector.AddError(err)
 			continue
 		}
 
-		varType, err := types.FromString(variable.Type)
+		varType, err := types.FromStringWith(variable.Type, v.enums)
 		if err != nil {
-			v.errorCollector.AddSimpleError(
-				fmt.Sprintf("Unknown type '%s' for variable '%s'", variable.Type, variable.Key),
-				variable.Line, variable.Column,
-			)
+			v.errorCollector.AddError(&errors.Error{
+				Severity: errors.SeverityError,
+				Message:  fmt.Sprintf("Unknown type '%s' for variable '%s'", variable.Type, variable.Key),
+				File:     variable.File,
+				Line:     variable.Line,
+				Column:   variable.Column,
+			})
 			varType = types.TypeUnknown
 		}
 
-		if varType != types.TypeUnknown {
+		if varType != types.TypeUnknown && !variable.Computed {
 			if valid, msg := types.ValidateValue(variable.Value, varType); !valid {
-				v.errorCollector.AddSimpleError(
-					fmt.Sprintf("Invalid initial value for variable '%s': %s", variable.Key, msg),
-					variable.Line, variable.Column,
-				)
+				v.errorCollector.AddError(&errors.Error{
+					Severity: errors.SeverityError,
+					Message:  fmt.Sprintf("Invalid initial value for variable '%s': %s", variable.Key, msg),
+					File:     variable.File,
+					Line:     variable.Line,
+					Column:   variable.Column,
+				})
 			}
 		}
 
 		v.variables[variable.Key] = variableInfo{
-			varType: varType,
-			line:    variable.Line,
-			used:    false,
+			varType:  varType,
+			line:     variable.Line,
+			used:     false,
+			imported: variable.File != "",
+			computed: variable.Computed,
+		}
+	}
+}
+
+// _validateComputed checks the expression of each computed variable against its declared type, marking
+// the variables it uses, and that no computed variable depends on itself.
+func (v *Validator) _validateComputed() {
+	for _, variable := range v.frame.Variables {
+		if !variable.Computed {
+			continue
+		}
+		t := v._validateExpression(expr.Prefix+variable.Value+expr.Suffix, variable.Line, variable.Column)
+		declared := v.variables[variable.Key].varType
+		if t != nil && declared != types.TypeUnknown && !types.IsAssignable(t, declared) {
+			v.errorCollector.AddError(&errors.Error{
+				Severity: errors.SeverityError,
+				Message:  fmt.Sprintf("Computed variable '%s' is declared %s but its expression is %s", variable.Key, declared.Name(), t.Name()),
+				File:     variable.File,
+				Line:     variable.Line,
+				Column:   variable.Column,
+			})
+		}
+	}
+
+	_, cycle := expr.ComputedOrder(v.frame.Variables)
+	for _, variable := range v.frame.Variables {
+		if cycle != nil && variable.Key == cycle[0] {
+			v.errorCollector.AddError(&errors.Error{
+				Severity:   errors.SeverityError,
+				Message:    fmt.Sprintf("Computed variable '%s' depends on itself: %s", variable.Key, strings.Join(cycle, " -> ")),
+				File:       variable.File,
+				Line:       variable.Line,
+				Column:     variable.Column,
+				Suggestion: "Break the cycle by making one of these variables a var",
+			})
+			break
 		}
 	}
 }
@@ -97,11 +222,45 @@ func (v *Validator) _collectBlockKeysRecursive(blocks []model.BlockDSLModel) {
 		} else {
 			v.blockKeys[block.Key] = block.Line
 		}
+		if block.Repeat != nil {
+			if _, exists := v.repeaters[block.Repeat.As]; !exists {
+				v.repeaters[block.Repeat.As] = block.Key
+			}
+		}
 
 		v._collectBlockKeysRecursive(block.Blocks)
 	}
 }
 
+func (v *Validator) _collectSequences() {
+	for i, sequence := range v.frame.Sequences {
+		if first, exists := v.sequences[sequence.Name]; exists {
+			v.errorCollector.AddSimpleError(
+				fmt.Sprintf("Duplicate trigger sequence '%s' (first declared at line %d)", sequence.Name, first.Line),
+				sequence.Line, sequence.Column,
+			)
+			continue
+		}
+		v.sequences[sequence.Name] = &v.frame.Sequences[i]
+	}
+}
+
+func (v *Validator) _collectStyles() {
+	for i, style := range v.frame.Styles {
+		if first, exists := v.styles[style.Name]; exists {
+			v.errorCollector.AddError(&errors.Error{
+				Severity: errors.SeverityError,
+				Message:  fmt.Sprintf("Duplicate style '%s' (first declared at line %d)", style.Name, first.Line),
+				File:     style.File,
+				Line:     style.Line,
+				Column:   style.Column,
+			})
+			continue
+		}
+		v.styles[style.Name] = &v.frame.Styles[i]
+	}
+}
+
 func (v *Validator) _collectSlots() {
 	v._collectSlotsRecursive(v.frame.Blocks)
 }
@@ -138,6 +297,19 @@ func (v *Validator) _validateFrame() {
 			"Consider using one of the standard frame types",
 		)
 	}
+
+	for _, action := range v.frame.Actions {
+		if action.Event == "" {
+			v.errorCollector.AddSimpleError(
+				"Frame action is missing required 'event' attribute",
+				action.Line, action.Column,
+			)
+		}
+
+		for _, trigger := range action.Triggers {
+			v._validateTrigger(&trigger)
+		}
+	}
 }
 
 func (v *Validator) _validateBlocks(blocks []model.BlockDSLModel) {
@@ -161,12 +333,29 @@ func (v *Validator) _validateBlock(block *model.BlockDSLModel) {
 		)
 	}
 
-	if block.VisibilityKey != "" {
+	if block.Repeat != nil {
+		loop := v._validateRepeat(block)
+		v.loops = append(v.loops, loop)
+		defer func() { v.loops = v.loops[:len(v.loops)-1] }()
+	}
+
+	if expr.IsExpression(block.VisibilityKey) {
+		if t := v._validateExpression(block.VisibilityKey, block.Line, block.Column); t != nil && t != types.TypeBoolean {
+			v.errorCollector.AddSimpleError(
+				fmt.Sprintf("Visibility of block '%s' must be BOOLEAN, got %s", block.Key, t.Name()),
+				block.Line, block.Column,
+			)
+		}
+	} else if block.VisibilityKey != "" {
 		v._validateVariableReference(block.VisibilityKey, 0, 0)
 	}
 
+	for _, name := range block.Styles {
+		v._validateStyleReference(name, "", block.Line, block.Column)
+	}
+
 	for _, data := range block.Data {
-		v._validateDataBinding(data.Value, data.Line, data.Column)
+		v._validateDataBinding(data.Value, data.Line, data.Column, data.ValueLine, data.ValueColumn)
 	}
 
 	for _, action := range block.Actions {
@@ -176,6 +365,57 @@ func (v *Validator) _validateBlock(block *model.BlockDSLModel) {
 	v._validateBlocks(block.Blocks)
 }
 
+// _validateRepeat checks that a repeated block iterates a LIST and that its loop variable does not hide
+// another variable, and returns the loop variable for the block's subtree.
+func (v *Validator) _validateRepeat(block *model.BlockDSLModel) loopScope {
+	repeat := block.Repeat
+	loop := loopScope{name: repeat.As, itemType: types.TypeUnknown}
+
+	if !_isVariableName(repeat.As) {
+		v.errorCollector.AddSimpleError(
+			fmt.Sprintf("Repeat variable '%s' of block '%s' is not a valid name", repeat.As, block.Key),
+			repeat.Line, repeat.Column,
+		)
+	} else if _, exists := v.variables[repeat.As]; exists {
+		v.errorCollector.AddSimpleError(
+			fmt.Sprintf("Repeat variable '%s' of block '%s' hides the frame variable '%s'", repeat.As, block.Key, repeat.As),
+			repeat.Line, repeat.Column,
+		)
+	} else if _, exists := v._loopType(repeat.As); exists {
+		v.errorCollector.AddSimpleError(
+			fmt.Sprintf("Repeat variable '%s' of block '%s' hides the repeat variable of an enclosing block", repeat.As, block.Key),
+			repeat.Line, repeat.Column,
+		)
+	}
+
+	itemsType := v._validateVariableReference(repeat.Items, repeat.Line, repeat.Column)
+	switch t := itemsType.(type) {
+	case nil:
+	case types.ListType:
+		loop.itemType = t.Element
+	default:
+		if t == types.TypeJSON {
+			loop.itemType = types.TypeJSON
+		} else if t != types.TypeUnknown {
+			v.errorCollector.AddSimpleError(
+				fmt.Sprintf("Repeat items '%s' of block '%s' must be a LIST, got %s", repeat.Items, block.Key, t.Name()),
+				repeat.Line, repeat.Column,
+			)
+		}
+	}
+	return loop
+}
+
+// _loopType returns the element type of the innermost loop variable named name.
+func (v *Validator) _loopType(name string) (types.Type, bool) {
+	for i := len(v.loops) - 1; i >= 0; i-- {
+		if v.loops[i].name == name {
+			return v.loops[i].itemType, true
+		}
+	}
+	return nil, false
+}
+
 func (v *Validator) _validateAction(action *model.ActionDSLModel, blockKey string) {
 	if action.Event == "" {
 		v.errorCollector.AddSimpleError(
@@ -189,8 +429,147 @@ func (v *Validator) _validateAction(action *model.ActionDSLModel, blockKey strin
 	}
 }
 
+// _validateSequences checks the triggers of every trigger sequence and reports sequences that call
+// themselves, directly or through other sequences.
+func (v *Validator) _validateSequences() {
+	for _, sequence := range v.frame.Sequences {
+		for _, trigger := range sequence.Triggers {
+			v._validateTrigger(&trigger)
+		}
+	}
+
+	done := make(map[string]bool)
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		stack = append(stack, name)
+		for _, call := range _sequenceCalls(v.sequences[name].Triggers) {
+			if _, exists := v.sequences[call]; !exists || done[call] {
+				continue
+			}
+			if index := slices.Index(stack, call); index != -1 {
+				sequence := v.sequences[call]
+				v.errorCollector.AddSimpleError(
+					fmt.Sprintf("Trigger sequence '%s' calls itself: %s -> %s", call, strings.Join(stack[index:], " -> "), call),
+					sequence.Line, sequence.Column,
+				)
+				continue
+			}
+			visit(call)
+		}
+		stack = stack[:len(stack)-1]
+		done[name] = true
+	}
+	for _, sequence := range v.frame.Sequences {
+		if !done[sequence.Name] {
+			visit(sequence.Name)
+		}
+	}
+}
+
+// _validateStyles reports styles that compose undefined styles or themselves, directly or through other
+// styles.
+func (v *Validator) _validateStyles() {
+	for _, style := range v.frame.Styles {
+		for _, name := range style.Styles {
+			v._validateStyleReference(name, style.File, style.Line, style.Column)
+		}
+	}
+
+	done := make(map[string]bool)
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		stack = append(stack, name)
+		for _, composed := range v.styles[name].Styles {
+			if _, exists := v.styles[composed]; !exists || done[composed] {
+				continue
+			}
+			if index := slices.Index(stack, composed); index != -1 {
+				style := v.styles[composed]
+				v.errorCollector.AddError(&errors.Error{
+					Severity: errors.SeverityError,
+					Message:  fmt.Sprintf("Style '%s' composes itself: %s -> %s", composed, strings.Join(stack[index:], " -> "), composed),
+					File:     style.File,
+					Line:     style.Line,
+					Column:   style.Column,
+				})
+				continue
+			}
+			visit(composed)
+		}
+		stack = stack[:len(stack)-1]
+		done[name] = true
+	}
+	for _, style := range v.frame.Styles {
+		if !done[style.Name] {
+			visit(style.Name)
+		}
+	}
+}
+
+func (v *Validator) _validateStyleReference(name, file string, line, column int) {
+	if _, exists := v.styles[name]; !exists {
+		v.errorCollector.AddError(&errors.Error{
+			Severity:   errors.SeverityError,
+			Message:    fmt.Sprintf("Undefined style '%s'", name),
+			File:       file,
+			Line:       line,
+			Column:     column,
+			Suggestion: "Declare it in the frame with style " + name + " { ... }",
+		})
+	}
+}
+
+// _sequenceCalls returns the names of the sequences called by triggers and their nested triggers.
+func _sequenceCalls(triggers []model.ActionTriggerDSLModel) []string {
+	var calls []string
+	for _, trigger := range triggers {
+		if trigger.Sequence != "" {
+			calls = append(calls, trigger.Sequence)
+		}
+		calls = append(calls, _sequenceCalls(trigger.Triggers)...)
+	}
+	return calls
+}
+
+func (v *Validator) _validateSequenceCall(trigger *model.ActionTriggerDSLModel) {
+	if _, exists := v.sequences[trigger.Sequence]; !exists {
+		v.errorCollector.AddError(&errors.Error{
+			Severity:   errors.SeverityError,
+			Message:    fmt.Sprintf("Undefined trigger sequence '%s'", trigger.Sequence),
+			Line:       trigger.Line,
+			Column:     trigger.Column,
+			Suggestion: "Declare it in the frame with sequence(name = \"" + trigger.Sequence + "\") { ... }",
+		})
+	}
+	if trigger.KeyType != "" || trigger.Name != "" || len(trigger.Properties)+len(trigger.Data)+len(trigger.Triggers) > 0 {
+		v.errorCollector.AddError(&errors.Error{
+			Severity:   errors.SeverityError,
+			Message:    fmt.Sprintf("Call of trigger sequence '%s' cannot have a keyType, name, properties, data or nested triggers", trigger.Sequence),
+			Line:       trigger.Line,
+			Column:     trigger.Column,
+			Suggestion: "Add them to the triggers of the sequence",
+		})
+	}
+}
+
 func (v *Validator) _validateTrigger(trigger *model.ActionTriggerDSLModel) {
-	if trigger.KeyType == "" {
+	if trigger.KeyType == model.ConditionKeyType {
+		v._validateIf(trigger)
+		return
+	}
+	if trigger.Condition != "" {
+		subject := fmt.Sprintf("trigger '%s'", trigger.Name)
+		if trigger.Sequence != "" {
+			subject = fmt.Sprintf("call of trigger sequence '%s'", trigger.Sequence)
+		}
+		v._validateCondition(trigger, subject)
+	}
+
+	if trigger.Sequence != "" {
+		v._validateSequenceCall(trigger)
+	} else if trigger.KeyType == "" {
 		v.errorCollector.AddSimpleError(
 			fmt.Sprintf("Trigger '%s' is missing required 'keyType' attribute", trigger.Name),
 			trigger.Line, trigger.Column,
@@ -215,39 +594,211 @@ func (v *Validator) _validateTrigger(trigger *model.ActionTriggerDSLModel) {
 	}
 
 	for _, data := range trigger.Data {
-		v._validateDataBinding(data.Value, data.Line, data.Column)
+		v._validateDataBinding(data.Value, data.Line, data.Column, data.ValueLine, data.ValueColumn)
+		if info, exists := v.variables[strings.TrimSpace(data.Value)]; data.Key == "variableKey" && exists && info.computed {
+			v.errorCollector.AddError(&errors.Error{
+				Severity:   errors.SeverityError,
+				Message:    fmt.Sprintf("Trigger '%s' cannot assign to computed variable '%s'", trigger.Name, strings.TrimSpace(data.Value)),
+				Line:       data.Line,
+				Column:     data.Column,
+				Suggestion: "Computed variables follow their expression. Assign one of the variables it uses instead",
+			})
+		}
 	}
+	v._validateEnumAssignment(trigger)
 
 	for _, nestedTrigger := range trigger.Triggers {
 		v._validateTrigger(&nestedTrigger)
 	}
 }
 
-func (v *Validator) _validateVariableReference(varName string, line int, column int) {
-	if varInfo, exists := v.variables[varName]; exists {
-		varInfo.used = true
-		v.variables[varName] = varInfo
+// _validateIf checks a trigger of model.ConditionKeyType, written as if/else: its condition and the
+// triggers of its branches, which run on SUCCESS or FAILURE.
+func (v *Validator) _validateIf(trigger *model.ActionTriggerDSLModel) {
+	if strings.TrimSpace(trigger.Condition) == "" {
+		v.errorCollector.AddSimpleError("If/else is missing its condition", trigger.Line, trigger.Column)
 	} else {
-		availableVars := make([]string, 0, len(v.variables))
-		for varName := range v.variables {
-			availableVars = append(availableVars, varName)
+		v._validateCondition(trigger, "if")
+	}
+	if trigger.Name != "" || trigger.Sequence != "" || len(trigger.Properties)+len(trigger.Data) > 0 {
+		v.errorCollector.AddSimpleError("If/else cannot have a name, sequence, properties or data", trigger.Line, trigger.Column)
+	}
+
+	for _, nestedTrigger := range trigger.Triggers {
+		if nestedTrigger.Then != "SUCCESS" && nestedTrigger.Then != "FAILURE" {
+			v.errorCollector.AddError(&errors.Error{
+				Severity:   errors.SeverityError,
+				Message:    fmt.Sprintf("Trigger '%s' in an if/else branch has unexpected 'then' value '%s'", nestedTrigger.Name, nestedTrigger.Then),
+				Line:       nestedTrigger.Line,
+				Column:     nestedTrigger.Column,
+				Suggestion: "Triggers of the if branch run on SUCCESS and triggers of the else branch on FAILURE",
+			})
+		}
+		v._validateTrigger(&nestedTrigger)
+	}
+}
+
+// _validateCondition checks that the condition of a trigger is a BOOLEAN expression over the variables.
+func (v *Validator) _validateCondition(trigger *model.ActionTriggerDSLModel, subject string) {
+	t := v._validateExpression(expr.Prefix+trigger.Condition+expr.Suffix, trigger.Line, trigger.Column)
+	if t != nil && t != types.TypeBoolean {
+		v.errorCollector.AddError(&errors.Error{
+			Severity:   errors.SeverityError,
+			Message:    fmt.Sprintf("Condition of %s must be BOOLEAN, got %s", subject, t.Name()),
+			Line:       trigger.Line,
+			Column:     trigger.Column,
+			Suggestion: "Compare the value, for example count > 0",
+		})
+	}
+}
+
+// _validateEnumAssignment checks that a trigger setting a variable of an enum type, through its
+// variableKey data and variableValue property, sets a member of the enum. Scripted and templated values
+// are only known at runtime.
+func (v *Validator) _validateEnumAssignment(trigger *model.ActionTriggerDSLModel) {
+	for _, data := range trigger.Data {
+		if data.Key != "variableKey" {
+			continue
+		}
+		info, exists := v.variables[strings.TrimSpace(data.Value)]
+		if !exists {
+			continue
+		}
+		enumType, isEnum := info.varType.(*types.EnumType)
+		if !isEnum {
+			continue
+		}
+		for _, prop := range trigger.Properties {
+			value := strings.TrimSpace(prop.Value)
+			if prop.Key != "variableValue" || value == "" || strings.Contains(value, "#SCRIPT") || strings.Contains(value, "{") {
+				continue
+			}
+			if valid, msg := types.ValidateValue(value, enumType); !valid {
+				v.errorCollector.AddSimpleError(
+					fmt.Sprintf("Invalid value for variable '%s' in trigger '%s': %s", strings.TrimSpace(data.Value), trigger.Name, msg),
+					prop.Line, prop.Column,
+				)
+			}
 		}
-		// Note: Variable references in data bindings don't have their own line/column
-		// We'd need to track where the reference occurs, not where the variable is declared
-		v.errorCollector.AddError(errors.UndefinedVariableError(varName, line, column, availableVars))
 	}
 }
 
-func (v *Validator) _validateDataBinding(value string, line int, column int) {
+// _validateVariableReference checks a reference to a variable, a loop variable in scope or a field path
+// such as item.name, marks the variable used and returns the type referred to, or nil when it is
+// undefined.
+func (v *Validator) _validateVariableReference(varName string, line int, column int) types.Type {
+	root := expr.Root(varName)
+	if itemType, exists := v._loopType(root); exists {
+		return v._validatePath(varName, itemType, line, column)
+	}
+	if varInfo, exists := v.variables[root]; exists {
+		varInfo.used = true
+		v.variables[root] = varInfo
+		return v._validatePath(varName, varInfo.varType, line, column)
+	}
+	if blockKey, exists := v.repeaters[root]; exists {
+		v.errorCollector.AddSimpleError(
+			fmt.Sprintf("'%s' is only available inside the repeated block '%s'", root, blockKey),
+			line, column,
+		)
+		return nil
+	}
+
+	availableVars := make([]string, 0, len(v.variables))
+	for varName := range v.variables {
+		availableVars = append(availableVars, varName)
+	}
+	// Note: Variable references in data bindings don't have their own line/column
+	// We'd need to track where the reference occurs, not where the variable is declared
+	v.errorCollector.AddError(errors.UndefinedVariableError(root, line, column, availableVars))
+	return nil
+}
+
+// _validatePath returns the type of a variable or field path whose root variable has type t.
+func (v *Validator) _validatePath(path string, t types.Type, line, column int) types.Type {
+	if !strings.Contains(path, ".") || t == types.TypeUnknown {
+		return t
+	}
+	pathType, err := expr.PathType(path, map[string]types.Type{expr.Root(path): t}, 0)
+	if err != nil {
+		v.errorCollector.AddSimpleError(fmt.Sprintf("Invalid reference '%s': %s", path, err), line, column)
+		return nil
+	}
+	return pathType
+}
+
+// _validateDataBinding checks the variable, expression or template a data entry binds. valueLine and
+// valueColumn locate the value itself, so template errors point inside the string; they are zero when
+// the source has no position for it.
+func (v *Validator) _validateDataBinding(value string, line, column, valueLine, valueColumn int) {
 	trimmed := strings.TrimSpace(value)
-	if _isVariableName(trimmed) {
+	if expr.IsExpression(trimmed) {
+		v._validateExpression(trimmed, line, column)
+	} else if expr.IsTemplate(value) {
+		v._validateTemplate(value, line, column, valueLine, valueColumn)
+	} else if _isVariablePath(trimmed) {
 		v._validateVariableReference(trimmed, line, column)
 	}
 }
 
+// _validateTemplate checks that every placeholder of a template names a declared variable.
+func (v *Validator) _validateTemplate(value string, line, column, valueLine, valueColumn int) {
+	position := func(offset int) (int, int) {
+		if valueLine == 0 {
+			return line, column
+		}
+		before := value[:offset]
+		if newlines := strings.Count(before, "\n"); newlines > 0 {
+			return valueLine + newlines, offset - strings.LastIndex(before, "\n")
+		}
+		return valueLine, valueColumn + offset
+	}
+
+	parts, err := expr.ParseTemplate(value)
+	if err != nil {
+		errLine, errColumn := position(err.(*expr.Error).Offset)
+		v.errorCollector.AddSimpleError(fmt.Sprintf("Invalid template \"%s\": %s", value, err), errLine, errColumn)
+		return
+	}
+	for _, part := range parts {
+		if part.Variable != "" {
+			partLine, partColumn := position(part.Offset)
+			v._validateVariableReference(part.Variable, partLine, partColumn)
+		}
+	}
+}
+
+// _validateExpression checks a wrapped expression and marks the variables it uses. It returns the type of
+// the expression, or nil when it has errors.
+func (v *Validator) _validateExpression(value string, line, column int) types.Type {
+	node, err := expr.ParseValue(value)
+	if err != nil {
+		v.errorCollector.AddSimpleError(fmt.Sprintf("Invalid expression %s: %s", value, err), line, column)
+		return nil
+	}
+
+	scope := make(map[string]types.Type)
+	defined := true
+	for _, name := range expr.Variables(node) {
+		t := v._validateVariableReference(name, line, column)
+		defined = defined && t != nil && t != types.TypeUnknown
+		scope[name] = t
+	}
+	if !defined {
+		return nil
+	}
+
+	t, err := expr.Check(node, scope)
+	if err != nil {
+		v.errorCollector.AddSimpleError(fmt.Sprintf("Invalid expression %s: %s", value, err), line, column)
+		return nil
+	}
+	return t
+}
+
 func (v *Validator) _checkUnusedVariables() {
 	for varName, info := range v.variables {
-		if !info.used {
+		if !info.used && !info.imported {
 			v.errorCollector.AddWarning(
 				fmt.Sprintf("Variable '%s' is declared but never used", varName),
 				info.line, 0,
@@ -257,6 +808,21 @@ func (v *Validator) _checkUnusedVariables() {
 	}
 }
 
+// _isVariablePath reports whether s is a variable name or a field path such as item.name.
+func _isVariablePath(s string) bool {
+	for _, segment := range strings.Split(s, ".") {
+		if !_isVariableName(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsVariableName reports whether s can be used as a variable name and is treated as a variable reference in data bindings.
+func IsVariableName(s string) bool {
+	return _isVariableName(s)
+}
+
 func _isVariableName(s string) bool {
 	if len(s) == 0 {
 		return false
@@ -1,9 +1,12 @@
 package validator
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/nativeblocks/nbx/internal/lexer"
 	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/parser"
 )
 
 func TestValidateVariables(t *testing.T) {
@@ -248,6 +251,80 @@ func TestValidateTrigger(t *testing.T) {
 	}
 }
 
+func TestValidateEnums(t *testing.T) {
+	frame := &model.FrameDSLModel{
+		Name:  "test",
+		Route: "/test",
+		Type:  "FRAME",
+		Enums: []model.EnumDSLModel{
+			{Name: "Status", Members: []string{"IDLE", "LOADING", "ERROR"}, Line: 2},
+			{Name: "Status", Members: []string{"A"}, Line: 3},
+			{Name: "Mode", Members: []string{"ON", "ON"}, Line: 4},
+			{Name: "Empty", Members: []string{}, Line: 5},
+			{Name: "string", Members: []string{"A"}, Line: 6},
+		},
+		Variables: []model.VariableDSLModel{
+			{Key: "status", Type: "Status", Value: "IDLE", Line: 7},
+			{Key: "mode", Type: "Mode", Value: "OFF", Line: 8},
+		},
+		Blocks: []model.BlockDSLModel{
+			{
+				KeyType:       "BUTTON",
+				Key:           "btn1",
+				VisibilityKey: "mode",
+				Actions: []model.ActionDSLModel{
+					{
+						Event: "onClick",
+						Triggers: []model.ActionTriggerDSLModel{
+							{
+								KeyType:    "nativeblocks/change_variable",
+								Name:       "load",
+								Data:       []model.TriggerDataDSLModel{{Key: "variableKey", Value: "status"}},
+								Properties: []model.TriggerPropertyDSLModel{{Key: "variableValue", Value: "DONE", Line: 12, Column: 24}},
+							},
+							{
+								KeyType:    "nativeblocks/change_variable",
+								Name:       "reset",
+								Data:       []model.TriggerDataDSLModel{{Key: "variableKey", Value: "status"}},
+								Properties: []model.TriggerPropertyDSLModel{{Key: "variableValue", Value: "IDLE"}},
+							},
+							{
+								KeyType:    "nativeblocks/change_variable",
+								Name:       "scripted",
+								Data:       []model.TriggerDataDSLModel{{Key: "variableKey", Value: "status"}},
+								Properties: []model.TriggerPropertyDSLModel{{Key: "variableValue", Value: "#SCRIPT {var:status} #ENDSCRIPT"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	collector, _ := Validate(frame)
+
+	expected := []string{
+		"Duplicate declaration of 'Status'",
+		"Duplicate member 'ON' in enum 'Mode'",
+		"Enum 'Empty' has no members",
+		"Enum 'string' conflicts with the built-in type STRING",
+		"Invalid initial value for variable 'mode': 'OFF' is not a member of Mode. Expected one of ON, ON",
+		"Invalid value for variable 'status' in trigger 'load': 'DONE' is not a member of Status. Expected one of IDLE, LOADING, ERROR",
+	}
+	errs := collector.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got: %s", len(expected), collector.FormatAll())
+	}
+	for i, err := range errs {
+		if err.Message != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], err.Message)
+		}
+	}
+	if errs[5].Line != 12 || errs[5].Column != 24 {
+		t.Errorf("Expected the trigger error at the variableValue prop, got %d:%d", errs[5].Line, errs[5].Column)
+	}
+}
+
 func TestIsVariableName(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -270,3 +347,396 @@ func TestIsVariableName(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateExpressions(t *testing.T) {
+	frame := &model.FrameDSLModel{
+		Name:  "test",
+		Route: "/test",
+		Type:  "FRAME",
+		Enums: []model.EnumDSLModel{{Name: "Status", Members: []string{"IDLE", "ERROR"}}},
+		Variables: []model.VariableDSLModel{
+			{Key: "status", Type: "Status", Value: "IDLE"},
+			{Key: "loading", Type: "BOOLEAN", Value: "false"},
+			{Key: "count", Type: "INT", Value: "0"},
+			{Key: "title", Type: "STRING", Value: "Cart"},
+		},
+		Blocks: []model.BlockDSLModel{
+			{KeyType: "TEXT", Key: "error", VisibilityKey: `$(status == "ERROR" && !loading)`, Line: 10},
+			{
+				KeyType:       "TEXT",
+				Key:           "total",
+				VisibilityKey: "$(count)",
+				Line:          11,
+				Data:          []model.BlockDataDSLModel{{Key: "text", Value: `$(title + ": " + count)`, Line: 12}},
+			},
+			{KeyType: "TEXT", Key: "done", VisibilityKey: `$(status == "DONE")`, Line: 13},
+			{KeyType: "TEXT", Key: "empty", VisibilityKey: "$(isEmpty(items))", Line: 14},
+			{KeyType: "TEXT", Key: "broken", VisibilityKey: "$(count >)", Line: 15},
+		},
+	}
+
+	collector, _ := Validate(frame)
+
+	expected := []string{
+		"Visibility of block 'total' must be BOOLEAN, got INT",
+		"Invalid expression $(status == \"DONE\"): 'DONE' is not a member of Status. Expected one of IDLE, ERROR",
+		"Undefined variable 'items'",
+		"Invalid expression $(count >): Unexpected end of expression",
+	}
+	errs := collector.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got: %s", len(expected), collector.FormatAll())
+	}
+	for i, err := range errs {
+		if !strings.HasPrefix(err.Message, expected[i]) {
+			t.Errorf("Expected %q, got %q", expected[i], err.Message)
+		}
+	}
+	if errs[0].Line != 11 {
+		t.Errorf("Expected the visibility error at the block, got line %d", errs[0].Line)
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	dsl := `frame(name = "greeting", route = "/greeting") {
+    var username: STRING = "Ada"
+    var count: INT = 0
+
+    block(keyType = "ROOT", key = "root")
+        .data(title = "Hello, {username}! You have {count} messages", subtitle = "Hi {usrname}")
+        .data(footer = "Broken {count")
+        .action(event = "onClick") {
+            trigger(keyType = "nativeblocks/log", name = "log")
+                .data(message = "{username} clicked {{root}}")
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	collector, _ := Validate(frame)
+
+	errs := collector.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got: %s", collector.FormatAll())
+	}
+	if !strings.HasPrefix(errs[0].Message, "Undefined variable 'usrname'") || errs[0].Line != 6 || errs[0].Column != 86 {
+		t.Errorf("Expected the undefined placeholder at 6:86, got %q at %d:%d", errs[0].Message, errs[0].Line, errs[0].Column)
+	}
+	if errs[1].Message != `Invalid template "Broken {count": Unclosed placeholder, expected '}'` || errs[1].Line != 7 || errs[1].Column != 32 {
+		t.Errorf("Expected the unclosed placeholder at 7:32, got %q at %d:%d", errs[1].Message, errs[1].Line, errs[1].Column)
+	}
+}
+
+func TestValidateRepeat(t *testing.T) {
+	dsl := `frame(name = "shop", route = "/shop") {
+    var products: LIST<MAP<STRING, STRING>> = [{"name": "Tea"}]
+    var names: LIST<STRING> = ["Tea"]
+    var title: STRING = "Shop"
+
+    block(keyType = "ROOT", key = "root")
+        .slot("content") {
+        block(keyType = "nativeblocks/column", key = "list")
+            .repeat(items = products, as = product)
+            .slot("content") {
+            block(keyType = "nativeblocks/text", key = "name", visibility = $(product.name != ""))
+                .data(text = product.name, label = "Buy {product.name}")
+        }
+        block(keyType = "nativeblocks/text", key = "outside")
+            .data(text = product.name)
+        block(keyType = "nativeblocks/column", key = "scalars")
+            .repeat(items = names, as = name)
+            .data(text = name.length)
+        block(keyType = "nativeblocks/column", key = "bad")
+            .repeat(items = title, as = title)
+            .data(text = title)
+    }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	collector, _ := Validate(frame)
+
+	expected := []string{
+		"'product' is only available inside the repeated block 'list'",
+		"'name' is a STRING and has no field 'length'",
+		"Repeat variable 'title' of block 'bad' hides the frame variable 'title'",
+		"Repeat items 'title' of block 'bad' must be a LIST, got STRING",
+	}
+	for _, message := range expected {
+		found := false
+		for _, err := range collector.Errors() {
+			if strings.Contains(err.Message, message) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected error containing %q, got: %s", message, collector.FormatAll())
+		}
+	}
+	if len(collector.Errors()) != len(expected) {
+		t.Errorf("Expected %d errors, got: %s", len(expected), collector.FormatAll())
+	}
+}
+
+func TestValidateComputed(t *testing.T) {
+	dsl := `frame(name = "cart", route = "/cart") {
+    var price: INT = 3
+    var quantity: INT = 2
+    val total: INT = price * quantity
+    val average: DOUBLE = total / quantity
+    val empty: BOOLEAN = total
+    val a: INT = b + 1
+    val b: INT = a * 2
+
+    block(keyType = "ROOT", key = "root", visibility = empty)
+        .data(text = average)
+        .action(event = "onClick") {
+            trigger(keyType = "nativeblocks/change_variable", name = "reset")
+                .data(variableKey = total)
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	collector, _ := Validate(frame)
+
+	expected := []string{
+		"Computed variable 'empty' is declared BOOLEAN but its expression is INT",
+		"Computed variable 'a' depends on itself: a -> b -> a",
+		"Trigger 'reset' cannot assign to computed variable 'total'",
+	}
+	errs := collector.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got: %s", len(expected), collector.FormatAll())
+	}
+	for i, err := range errs {
+		if err.Message != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], err.Message)
+		}
+	}
+	if errs[1].Line != 7 {
+		t.Errorf("Expected the cycle at the declaration of 'a', got line %d", errs[1].Line)
+	}
+	// price and quantity are only used by computed expressions.
+	for _, warning := range collector.Warnings() {
+		t.Errorf("Unexpected warning: %s", warning.Message)
+	}
+}
+
+func TestValidateFrameActions(t *testing.T) {
+	dsl := `frame(name = "feed", route = "/feed") {
+    var status: STRING = "idle"
+
+    action(event = "onLoad") {
+        trigger(keyType = "nativeblocks/change_variable", name = "load")
+            .data(variableKey = status)
+    }
+    action(name = "onResume") {
+        trigger(keyType = "nativeblocks/change_variable", name = "track")
+            .data(variableKey = missing)
+    }
+
+    block(keyType = "ROOT", key = "root")
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	collector, _ := Validate(frame)
+
+	expected := []string{
+		"Frame action is missing required 'event' attribute",
+		"Undefined variable 'missing'",
+	}
+	errs := collector.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got: %s", len(expected), collector.FormatAll())
+	}
+	for i, err := range errs {
+		if err.Message != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], err.Message)
+		}
+	}
+	if errs[0].Line != 8 {
+		t.Errorf("Expected the missing event at line 8, got line %d", errs[0].Line)
+	}
+	if len(collector.Warnings()) != 0 {
+		t.Errorf("Expected 'status' to count as used by the frame action, got: %s", collector.FormatAll())
+	}
+}
+
+func TestValidateFrameEvents(t *testing.T) {
+	frame := &model.FrameDSLModel{
+		Name:    "feed",
+		Actions: []model.ActionDSLModel{{Event: "onAppear"}},
+	}
+
+	loaded, err := LoadIntegrations("{}", "{}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewIntegrationValidator(loaded).ValidateFrame(frame); err == nil || !strings.Contains(err.Error(), "invalid frame event 'onAppear'") {
+		t.Errorf("Expected the default frame events to reject 'onAppear', got %v", err)
+	}
+	if err := NewIntegrationValidator(&IntegrationRegistry{}).ValidateFrame(frame); err != nil {
+		t.Errorf("Expected a registry without frame events to allow any event, got %v", err)
+	}
+}
+
+func TestValidateSequences(t *testing.T) {
+	dsl := `frame(name = "form", route = "/form") {
+    var status: STRING = "idle"
+
+    sequence(name = "validate") {
+        trigger(keyType = "nativeblocks/change_variable", name = "check")
+            .data(variableKey = status)
+        trigger(sequence = "retry")
+    }
+    sequence(name = "retry") {
+        trigger(sequence = "validate")
+    }
+    sequence(name = "validate") {
+        trigger(keyType = "nativeblocks/change_variable", name = "reset")
+            .data(variableKey = missing)
+    }
+
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(sequence = "submit")
+            trigger(sequence = "validate", name = "run")
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	collector, _ := Validate(frame)
+
+	expected := []string{
+		"Duplicate trigger sequence 'validate' (first declared at line 4)",
+		"Undefined variable 'missing'",
+		"Trigger sequence 'validate' calls itself: validate -> retry -> validate",
+		"Undefined trigger sequence 'submit'",
+		"Call of trigger sequence 'validate' cannot have a keyType, name, properties, data or nested triggers",
+	}
+	errs := collector.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got: %s", len(expected), collector.FormatAll())
+	}
+	for i, err := range errs {
+		if err.Message != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], err.Message)
+		}
+	}
+	if errs[2].Line != 4 {
+		t.Errorf("Expected the cycle at the declaration of 'validate', got line %d", errs[2].Line)
+	}
+	if len(collector.Warnings()) != 0 {
+		t.Errorf("Expected 'status' to count as used by the sequence, got: %s", collector.FormatAll())
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	dsl := `frame(name = "cart", route = "/cart") {
+    var count: INT = 0
+    var label: STRING = "Add"
+
+    block(keyType = "ROOT", key = "root")
+        .action(event = "onClick") {
+            trigger(keyType = "nativeblocks/change_variable", name = "increase")
+                .when(count < 10)
+            trigger(keyType = "nativeblocks/change_variable", name = "rename")
+                .when(label)
+            if (count + 1) {
+                trigger(keyType = "nativeblocks/change_variable", name = "first")
+            } else {
+                trigger(keyType = "nativeblocks/change_variable", name = "more")
+                    .when(total > 0)
+            }
+        }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+	frame.Blocks[0].Actions[0].Triggers[2].Triggers[0].Then = "ALWAYS"
+
+	collector, _ := Validate(frame)
+
+	expected := []string{
+		"Condition of trigger 'rename' must be BOOLEAN, got STRING",
+		"Condition of if must be BOOLEAN, got INT",
+		"Trigger 'first' in an if/else branch has unexpected 'then' value 'ALWAYS'",
+		"Undefined variable 'total'",
+	}
+	errs := collector.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got: %s", len(expected), collector.FormatAll())
+	}
+	for i, err := range errs {
+		if err.Message != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], err.Message)
+		}
+	}
+	if len(collector.Warnings()) != 0 {
+		t.Errorf("Expected the variables to count as used by the conditions, got: %s", collector.FormatAll())
+	}
+}
+
+func TestValidateStyles(t *testing.T) {
+	dsl := `frame(name = "form", route = "/form") {
+    style rounded {
+        .style(primary)
+        .prop(radiusTopStart = "12")
+    }
+    style primary {
+        .style(rounded, outlined)
+    }
+    style rounded {
+        .prop(radiusTopStart = "8")
+    }
+
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(keyType = "nativeblocks/button", key = "submit")
+        .style(primary, large)
+    }
+}`
+	p := parser.NewParser(lexer.NewLexer(dsl), dsl)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse: %s", p.ErrorCollector().FormatAll())
+	}
+
+	collector, _ := Validate(frame)
+
+	expected := []string{
+		"Duplicate style 'rounded' (first declared at line 2)",
+		"Undefined style 'outlined'",
+		"Style 'rounded' composes itself: rounded -> primary -> rounded",
+		"Undefined style 'large'",
+	}
+	errs := collector.Errors()
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got: %s", len(expected), collector.FormatAll())
+	}
+	for i, err := range errs {
+		if err.Message != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], err.Message)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package walker
+
+import (
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+type Kind string
+
+const (
+	KindFrame    Kind = "frame"
+	KindVariable Kind = "variable"
+	KindBlock    Kind = "block"
+	KindSlot     Kind = "slot"
+	KindProperty Kind = "prop"
+	KindData     Kind = "data"
+	KindAction   Kind = "action"
+	KindSequence Kind = "sequence"
+	KindTrigger  Kind = "trigger"
+)
+
+// Result tells the walker how to continue after a callback.
+type Result int
+
+const (
+	// Continue walks into the node's children.
+	Continue Result = iota
+	// SkipChildren skips the node's children; its Leave callback is still called.
+	SkipChildren
+	// Stop ends the walk immediately. No further Enter or Leave callbacks are called.
+	Stop
+)
+
+// Node is one element of the frame model. Exactly one of the pointer fields matching Kind is set;
+// properties and data of a trigger use TriggerProperty and TriggerData instead of Property and Data.
+// Pointers refer into the walked frame, so callbacks can modify it in place.
+type Node struct {
+	Kind            Kind
+	Frame           *model.FrameDSLModel
+	Variable        *model.VariableDSLModel
+	Block           *model.BlockDSLModel
+	Slot            *model.BlockSlotDSLModel
+	Property        *model.BlockPropertyDSLModel
+	Data            *model.BlockDataDSLModel
+	Action          *model.ActionDSLModel
+	Sequence        *model.SequenceDSLModel
+	Trigger         *model.ActionTriggerDSLModel
+	TriggerProperty *model.TriggerPropertyDSLModel
+	TriggerData     *model.TriggerDataDSLModel
+}
+
+// Key returns the identifying name of the node: the frame name, variable, block, property or data key,
+// slot name, action event, sequence or trigger name.
+func (n *Node) Key() string {
+	switch {
+	case n.Frame != nil:
+		return n.Frame.Name
+	case n.Variable != nil:
+		return n.Variable.Key
+	case n.Block != nil:
+		return n.Block.Key
+	case n.Slot != nil:
+		return n.Slot.Slot
+	case n.Property != nil:
+		return n.Property.Key
+	case n.Data != nil:
+		return n.Data.Key
+	case n.Action != nil:
+		return n.Action.Event
+	case n.Sequence != nil:
+		return n.Sequence.Name
+	case n.Trigger != nil:
+		return n.Trigger.Name
+	case n.TriggerProperty != nil:
+		return n.TriggerProperty.Key
+	case n.TriggerData != nil:
+		return n.TriggerData.Key
+	}
+	return ""
+}
+
+// Position returns the source position of the node.
+func (n *Node) Position() (line, column int) {
+	switch {
+	case n.Frame != nil:
+		return n.Frame.Line, n.Frame.Column
+	case n.Variable != nil:
+		return n.Variable.Line, n.Variable.Column
+	case n.Block != nil:
+		return n.Block.Line, n.Block.Column
+	case n.Slot != nil:
+		return n.Slot.Line, n.Slot.Column
+	case n.Property != nil:
+		return n.Property.Line, n.Property.Column
+	case n.Data != nil:
+		return n.Data.Line, n.Data.Column
+	case n.Action != nil:
+		return n.Action.Line, n.Action.Column
+	case n.Sequence != nil:
+		return n.Sequence.Line, n.Sequence.Column
+	case n.Trigger != nil:
+		return n.Trigger.Line, n.Trigger.Column
+	case n.TriggerProperty != nil:
+		return n.TriggerProperty.Line, n.TriggerProperty.Column
+	case n.TriggerData != nil:
+		return n.TriggerData.Line, n.TriggerData.Column
+	}
+	return 0, 0
+}
+
+// Visitor receives callbacks while walking a frame. Either callback may be nil.
+// parents holds the enclosing nodes from the frame down to the direct parent; the slice is only
+// valid during the callback and must be copied to be retained.
+type Visitor struct {
+	Enter func(node *Node, parents []*Node) Result
+	Leave func(node *Node, parents []*Node) Result
+}
+
+// Walk visits the frame depth-first in declaration order: variables, frame actions, trigger sequences,
+// then blocks. Each block visits its slots, properties, data and actions before its child blocks; each
+// trigger visits its properties and data before its nested triggers. Walk reports whether the walk ran to completion without a Stop.
+func Walk(frame *model.FrameDSLModel, visitor Visitor) bool {
+	w := &walker{visitor: visitor}
+	return w.visit(&Node{Kind: KindFrame, Frame: frame}, func() bool {
+		for i := range frame.Variables {
+			if !w.visit(&Node{Kind: KindVariable, Variable: &frame.Variables[i]}, nil) {
+				return false
+			}
+		}
+		if !w.actions(frame.Actions) {
+			return false
+		}
+		for i := range frame.Sequences {
+			sequence := &frame.Sequences[i]
+			ok := w.visit(&Node{Kind: KindSequence, Sequence: sequence}, func() bool {
+				return w.triggers(sequence.Triggers)
+			})
+			if !ok {
+				return false
+			}
+		}
+		return w.blocks(frame.Blocks)
+	})
+}
+
+type walker struct {
+	visitor Visitor
+	parents []*Node
+}
+
+// visit calls Enter, walks the children and calls Leave. It returns false once the walk was stopped.
+func (w *walker) visit(node *Node, children func() bool) bool {
+	result := Continue
+	if w.visitor.Enter != nil {
+		result = w.visitor.Enter(node, w.parents)
+	}
+	if result == Stop {
+		return false
+	}
+
+	if result != SkipChildren && children != nil {
+		w.parents = append(w.parents, node)
+		ok := children()
+		w.parents = w.parents[:len(w.parents)-1]
+		if !ok {
+			return false
+		}
+	}
+
+	if w.visitor.Leave != nil && w.visitor.Leave(node, w.parents) == Stop {
+		return false
+	}
+	return true
+}
+
+func (w *walker) blocks(blocks []model.BlockDSLModel) bool {
+	for i := range blocks {
+		block := &blocks[i]
+		ok := w.visit(&Node{Kind: KindBlock, Block: block}, func() bool {
+			for j := range block.Slots {
+				if !w.visit(&Node{Kind: KindSlot, Slot: &block.Slots[j]}, nil) {
+					return false
+				}
+			}
+			for j := range block.Properties {
+				if !w.visit(&Node{Kind: KindProperty, Property: &block.Properties[j]}, nil) {
+					return false
+				}
+			}
+			for j := range block.Data {
+				if !w.visit(&Node{Kind: KindData, Data: &block.Data[j]}, nil) {
+					return false
+				}
+			}
+			if !w.actions(block.Actions) {
+				return false
+			}
+			return w.blocks(block.Blocks)
+		})
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *walker) actions(actions []model.ActionDSLModel) bool {
+	for i := range actions {
+		action := &actions[i]
+		ok := w.visit(&Node{Kind: KindAction, Action: action}, func() bool {
+			return w.triggers(action.Triggers)
+		})
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *walker) triggers(triggers []model.ActionTriggerDSLModel) bool {
+	for i := range triggers {
+		trigger := &triggers[i]
+		ok := w.visit(&Node{Kind: KindTrigger, Trigger: trigger}, func() bool {
+			for j := range trigger.Properties {
+				if !w.visit(&Node{Kind: KindProperty, TriggerProperty: &trigger.Properties[j]}, nil) {
+					return false
+				}
+			}
+			for j := range trigger.Data {
+				if !w.visit(&Node{Kind: KindData, TriggerData: &trigger.Data[j]}, nil) {
+					return false
+				}
+			}
+			return w.triggers(trigger.Triggers)
+		})
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
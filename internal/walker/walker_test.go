@@ -0,0 +1,161 @@
+package walker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/model"
+)
+
+func _walkerFrame() model.FrameDSLModel {
+	return model.FrameDSLModel{
+		Name:      "welcome",
+		Variables: []model.VariableDSLModel{{Key: "title"}},
+		Actions:   []model.ActionDSLModel{{Event: "onLoad"}},
+		Sequences: []model.SequenceDSLModel{{
+			Name:     "reset",
+			Triggers: []model.ActionTriggerDSLModel{{Name: "clear"}},
+		}},
+		Blocks: []model.BlockDSLModel{{
+			Key:        "root",
+			Slots:      []model.BlockSlotDSLModel{{Slot: "content"}},
+			Properties: []model.BlockPropertyDSLModel{{Key: "width"}},
+			Blocks: []model.BlockDSLModel{{
+				Key:  "button",
+				Slot: "content",
+				Data: []model.BlockDataDSLModel{{Key: "text", Value: "title"}},
+				Actions: []model.ActionDSLModel{{
+					Event: "onClick",
+					Triggers: []model.ActionTriggerDSLModel{{
+						Name:       "change",
+						Properties: []model.TriggerPropertyDSLModel{{Key: "value"}},
+						Data:       []model.TriggerDataDSLModel{{Key: "variableKey"}},
+						Triggers:   []model.ActionTriggerDSLModel{{Name: "next"}},
+					}},
+				}},
+			}},
+		}},
+	}
+}
+
+func _trace(frame *model.FrameDSLModel, enter func(node *Node) Result) ([]string, bool) {
+	var events []string
+	completed := Walk(frame, Visitor{
+		Enter: func(node *Node, parents []*Node) Result {
+			events = append(events, "+"+string(node.Kind)+":"+node.Key())
+			if enter != nil {
+				return enter(node)
+			}
+			return Continue
+		},
+		Leave: func(node *Node, parents []*Node) Result {
+			events = append(events, "-"+string(node.Kind)+":"+node.Key())
+			return Continue
+		},
+	})
+	return events, completed
+}
+
+func TestWalkOrder(t *testing.T) {
+	frame := _walkerFrame()
+	events, completed := _trace(&frame, nil)
+	if !completed {
+		t.Error("Expected walk to complete")
+	}
+
+	expected := []string{
+		"+frame:welcome",
+		"+variable:title", "-variable:title",
+		"+action:onLoad", "-action:onLoad",
+		"+sequence:reset",
+		"+trigger:clear", "-trigger:clear",
+		"-sequence:reset",
+		"+block:root",
+		"+slot:content", "-slot:content",
+		"+prop:width", "-prop:width",
+		"+block:button",
+		"+data:text", "-data:text",
+		"+action:onClick",
+		"+trigger:change",
+		"+prop:value", "-prop:value",
+		"+data:variableKey", "-data:variableKey",
+		"+trigger:next", "-trigger:next",
+		"-trigger:change",
+		"-action:onClick",
+		"-block:button",
+		"-block:root",
+		"-frame:welcome",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Unexpected walk order:\n got: %v\nwant: %v", events, expected)
+	}
+}
+
+func TestWalkParents(t *testing.T) {
+	frame := _walkerFrame()
+	var chain string
+	Walk(&frame, Visitor{
+		Enter: func(node *Node, parents []*Node) Result {
+			if node.Kind == KindTrigger && node.Key() == "next" {
+				keys := make([]string, len(parents))
+				for i, parent := range parents {
+					keys[i] = parent.Key()
+				}
+				chain = strings.Join(keys, "/")
+			}
+			return Continue
+		},
+	})
+	if chain != "welcome/root/button/onClick/change" {
+		t.Errorf("Unexpected parent chain: %q", chain)
+	}
+}
+
+func TestWalkSkipChildren(t *testing.T) {
+	frame := _walkerFrame()
+	events, _ := _trace(&frame, func(node *Node) Result {
+		if node.Kind == KindBlock && node.Key() == "button" {
+			return SkipChildren
+		}
+		return Continue
+	})
+	joined := strings.Join(events, " ")
+	if strings.Contains(joined, "action:onClick") {
+		t.Errorf("Expected button children to be skipped: %v", events)
+	}
+	if !strings.Contains(joined, "+block:button -block:button") {
+		t.Errorf("Expected Leave to be called for a skipped block: %v", events)
+	}
+}
+
+func TestWalkStop(t *testing.T) {
+	frame := _walkerFrame()
+	events, completed := _trace(&frame, func(node *Node) Result {
+		if node.Kind == KindSlot {
+			return Stop
+		}
+		return Continue
+	})
+	if completed {
+		t.Error("Expected walk to report it was stopped")
+	}
+	if events[len(events)-1] != "+slot:content" {
+		t.Errorf("Expected no callbacks after Stop: %v", events)
+	}
+}
+
+func TestWalkMutation(t *testing.T) {
+	frame := _walkerFrame()
+	Walk(&frame, Visitor{
+		Enter: func(node *Node, parents []*Node) Result {
+			if node.Kind == KindData && node.Data != nil && node.Data.Value == "title" {
+				node.Data.Value = "heading"
+			}
+			return Continue
+		},
+	})
+	if frame.Blocks[0].Blocks[0].Data[0].Value != "heading" {
+		t.Error("Expected walker nodes to point into the frame")
+	}
+}
@@ -0,0 +1,509 @@
+package wireframe
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nativeblocks/nbx/internal/expr"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/preview"
+)
+
+// Box is a laid out block. X and Y are absolute positions within the frame.
+type Box struct {
+	Block    model.BlockDSLModel
+	Kind     string
+	Label    string
+	X        float64
+	Y        float64
+	Width    float64
+	Height   float64
+	Children []*Box
+}
+
+const (
+	kindColumn      = "column"
+	kindRow         = "row"
+	kindBox         = "box"
+	kindText        = "text"
+	kindImage       = "image"
+	kindButton      = "button"
+	kindSpacer      = "spacer"
+	kindInput       = "input"
+	kindPlaceholder = "placeholder"
+)
+
+const (
+	defaultFontSize   = 14.0
+	placeholderLabelH = 20.0
+)
+
+type sizeSpec struct {
+	kind  string // fill, wrap or fixed
+	value float64
+}
+
+type layouter struct {
+	device    preview.Device
+	variables map[string]string
+	values    map[string]any
+	// elements holds the element each copy of a repeated block is laid out with, by its repeat.
+	elements map[*model.RepeatDSLModel]any
+}
+
+// Layout lays out the frame's block tree for the given device class.
+func Layout(frame model.FrameDSLModel, device preview.Device) []*Box {
+	l := &layouter{
+		device:    device,
+		variables: make(map[string]string, len(frame.Variables)),
+		values:    expr.Values(frame.Variables),
+		elements:  make(map[*model.RepeatDSLModel]any),
+	}
+	for _, variable := range frame.Variables {
+		// Computed variables are only known from their evaluated values.
+		if !variable.Computed {
+			l.variables[variable.Key] = variable.Value
+		}
+	}
+
+	width, height := float64(device.Width()), float64(device.Height())
+	boxes := make([]*Box, 0, len(frame.Blocks))
+	for _, block := range l._visibleBlocks(frame.Blocks) {
+		box := l._layout(block, width, height)
+		if block.KeyType == "ROOT" {
+			box.Width, box.Height = width, height
+		}
+		_translate(box, 0, 0)
+		boxes = append(boxes, box)
+	}
+	return boxes
+}
+
+// _layout computes the size of block within the given constraints. Children are positioned relative to box.
+func (l *layouter) _layout(block model.BlockDSLModel, maxW, maxH float64) *Box {
+	defer l._bind(block)()
+
+	box := &Box{
+		Block: block,
+		Kind:  _kindOf(block.KeyType),
+		Label: block.Key,
+	}
+
+	top, end, bottom, start := l._padding(block)
+	widthSpec := l._sizeSpec(block, "width")
+	heightSpec := l._sizeSpec(block, "height")
+
+	innerW := _resolveMax(widthSpec, maxW) - start - end
+	innerH := _resolveMax(heightSpec, maxH) - top - bottom
+
+	var contentW, contentH float64
+	switch box.Kind {
+	case kindColumn, kindRow:
+		contentW, contentH = l._layoutLinear(box, math.Max(innerW, 0), math.Max(innerH, 0))
+	case kindBox:
+		contentW, contentH = l._layoutOverlay(box, math.Max(innerW, 0), math.Max(innerH, 0), 0)
+	case kindPlaceholder:
+		box.Label = block.KeyType + " (" + block.Key + ")"
+		contentW, contentH = l._layoutOverlay(box, math.Max(innerW, 0), math.Max(innerH-placeholderLabelH, 0), placeholderLabelH)
+		contentW = math.Max(contentW, float64(utf8.RuneCountInString(box.Label))*6+8)
+	default:
+		contentW, contentH = l._measureLeaf(box, math.Max(innerW, 0))
+	}
+
+	for _, child := range box.Children {
+		child.X += start
+		child.Y += top
+	}
+
+	box.Width = _resolveSize(widthSpec, maxW, contentW+start+end)
+	box.Height = _resolveSize(heightSpec, maxH, contentH+top+bottom)
+	return box
+}
+
+// _layoutLinear stacks children along the main axis of a column or row, honouring weight, spacing,
+// alignment and arrangement props.
+func (l *layouter) _layoutLinear(box *Box, innerW, innerH float64) (float64, float64) {
+	vertical := box.Kind == kindColumn
+	spacing := _parseNumber(l._prop(box.Block, "spacing"))
+
+	mainMax, crossMax := innerH, innerW
+	if !vertical {
+		mainMax, crossMax = innerW, innerH
+	}
+
+	children := l._visibleChildren(box.Block)
+	boxes := make([]*Box, len(children))
+	weights := make([]float64, len(children))
+	totalWeight, used := 0.0, 0.0
+
+	for i, child := range children {
+		weights[i] = _parseNumber(l._prop(child, "weight"))
+		if weights[i] > 0 {
+			totalWeight += weights[i]
+			continue
+		}
+		if vertical {
+			boxes[i] = l._layout(child, crossMax, math.Max(mainMax-used, 0))
+			used += boxes[i].Height
+		} else {
+			boxes[i] = l._layout(child, math.Max(mainMax-used, 0), crossMax)
+			used += boxes[i].Width
+		}
+	}
+	if len(children) > 1 {
+		used += spacing * float64(len(children)-1)
+	}
+
+	remaining := math.Max(mainMax-used, 0)
+	for i, child := range children {
+		if weights[i] <= 0 {
+			continue
+		}
+		share := remaining * weights[i] / totalWeight
+		if vertical {
+			boxes[i] = l._layout(child, crossMax, share)
+			boxes[i].Height = share
+		} else {
+			boxes[i] = l._layout(child, share, crossMax)
+			boxes[i].Width = share
+		}
+		used += share
+	}
+
+	crossSize := 0.0
+	for _, b := range boxes {
+		if vertical {
+			crossSize = math.Max(crossSize, b.Width)
+		} else {
+			crossSize = math.Max(crossSize, b.Height)
+		}
+	}
+
+	arrangementKey, alignmentKey := "verticalArrangement", "horizontalAlignment"
+	mainKey, crossKey := "height", "width"
+	if !vertical {
+		arrangementKey, alignmentKey = "horizontalArrangement", "verticalAlignment"
+		mainKey, crossKey = "width", "height"
+	}
+
+	// The container wraps its children along the main axis unless it is sized, so arrangement only has
+	// the space left within its resolved extent.
+	mainAvail := mainMax
+	if l._sizeSpec(box.Block, mainKey).kind == "wrap" {
+		mainAvail = math.Min(used, mainMax)
+	}
+	free := 0.0
+	if totalWeight == 0 {
+		free = math.Max(mainAvail-used, 0)
+	}
+	offset, gap := _arrange(l._prop(box.Block, arrangementKey), free, len(boxes))
+	alignment := l._prop(box.Block, alignmentKey)
+
+	crossAvail := crossSize
+	if l._sizeSpec(box.Block, crossKey).kind != "wrap" {
+		crossAvail = crossMax
+	}
+
+	position := offset
+	for _, b := range boxes {
+		if vertical {
+			b.X, b.Y = _align(alignment, crossAvail, b.Width), position
+			position += b.Height + spacing + gap
+		} else {
+			b.X, b.Y = position, _align(alignment, crossAvail, b.Height)
+			position += b.Width + spacing + gap
+		}
+		box.Children = append(box.Children, b)
+	}
+
+	if vertical {
+		return crossSize, used
+	}
+	return used, crossSize
+}
+
+// _layoutOverlay stacks children on top of each other, starting at offsetY.
+func (l *layouter) _layoutOverlay(box *Box, innerW, innerH, offsetY float64) (float64, float64) {
+	width, height := 0.0, 0.0
+	for _, child := range l._visibleChildren(box.Block) {
+		b := l._layout(child, innerW, innerH)
+		b.Y = offsetY
+		width = math.Max(width, b.Width)
+		height = math.Max(height, b.Height)
+		box.Children = append(box.Children, b)
+	}
+	return width, height + offsetY
+}
+
+// _measureLeaf returns the intrinsic content size of a block without children.
+func (l *layouter) _measureLeaf(box *Box, maxW float64) (float64, float64) {
+	fontSize := _parseNumber(l._prop(box.Block, "fontSize"))
+	if fontSize <= 0 {
+		fontSize = defaultFontSize
+	}
+
+	switch box.Kind {
+	case kindText:
+		box.Label = l._data(box.Block, "text")
+		return _measureText(box.Label, fontSize, maxW)
+	case kindButton:
+		box.Label = l._data(box.Block, "text")
+		w, h := _measureText(box.Label, fontSize, math.Max(maxW-32, 0))
+		return w + 32, h + 16
+	case kindInput:
+		return math.Min(200, maxW), fontSize*1.3 + 24
+	case kindImage:
+		return math.Min(48, maxW), 48
+	default:
+		return 0, 0
+	}
+}
+
+func (l *layouter) _visibleChildren(block model.BlockDSLModel) []model.BlockDSLModel {
+	return l._visibleBlocks(block.Blocks)
+}
+
+// _visibleBlocks returns the visible blocks, with a repeated block replaced by a copy per element of its
+// items.
+func (l *layouter) _visibleBlocks(blocks []model.BlockDSLModel) []model.BlockDSLModel {
+	visible := make([]model.BlockDSLModel, 0, len(blocks))
+	for _, block := range l._expand(blocks) {
+		restore := l._bind(block)
+		if l._isVisible(block) {
+			visible = append(visible, block)
+		}
+		restore()
+	}
+	return visible
+}
+
+func (l *layouter) _expand(blocks []model.BlockDSLModel) []model.BlockDSLModel {
+	expanded := make([]model.BlockDSLModel, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Repeat == nil {
+			expanded = append(expanded, block)
+			continue
+		}
+		items, _ := expr.Lookup(l.values, block.Repeat.Items)
+		elements, _ := items.([]any)
+		for _, element := range elements {
+			repeat := *block.Repeat
+			block.Repeat = &repeat
+			l.elements[&repeat] = element
+			expanded = append(expanded, block)
+		}
+	}
+	return expanded
+}
+
+// _bind binds the loop variable of a copy of a repeated block to its element and returns a function
+// restoring the previous value.
+func (l *layouter) _bind(block model.BlockDSLModel) func() {
+	element, exists := l.elements[block.Repeat]
+	if block.Repeat == nil || !exists {
+		return func() {}
+	}
+	previous, shadowed := l.values[block.Repeat.As]
+	l.values[block.Repeat.As] = element
+	return func() {
+		if shadowed {
+			l.values[block.Repeat.As] = previous
+		} else {
+			delete(l.values, block.Repeat.As)
+		}
+	}
+}
+
+func (l *layouter) _isVisible(block model.BlockDSLModel) bool {
+	if expr.IsExpression(block.VisibilityKey) {
+		value, err := expr.EvalValue(block.VisibilityKey, l.values)
+		return err != nil || expr.Truthy(value)
+	}
+	if value, exists := l.variables[block.VisibilityKey]; exists {
+		return value != "false"
+	}
+	if value, exists := expr.Lookup(l.values, block.VisibilityKey); exists {
+		return expr.Truthy(value)
+	}
+	return true
+}
+
+func (l *layouter) _prop(block model.BlockDSLModel, key string) string {
+	for _, prop := range block.Properties {
+		if prop.Key == key {
+			return preview.PropertyValue(prop, l.device)
+		}
+	}
+	return ""
+}
+
+func (l *layouter) _data(block model.BlockDSLModel, key string) string {
+	for _, data := range block.Data {
+		if data.Key == key {
+			if expr.IsExpression(data.Value) {
+				if value, err := expr.EvalValue(data.Value, l.values); err == nil {
+					return expr.Format(value)
+				}
+			}
+			if expr.IsTemplate(data.Value) {
+				if value, err := expr.EvalTemplate(data.Value, l.values); err == nil {
+					return value
+				}
+			}
+			if value, exists := l.variables[data.Value]; exists {
+				return value
+			}
+			if value, exists := expr.Lookup(l.values, data.Value); exists {
+				return expr.Format(value)
+			}
+			return data.Value
+		}
+	}
+	return ""
+}
+
+func (l *layouter) _padding(block model.BlockDSLModel) (top, end, bottom, start float64) {
+	all := _parseNumber(l._prop(block, "padding"))
+	top, end, bottom, start = all, all, all, all
+	if v := l._prop(block, "paddingTop"); v != "" {
+		top = _parseNumber(v)
+	}
+	if v := l._prop(block, "paddingBottom"); v != "" {
+		bottom = _parseNumber(v)
+	}
+	for _, key := range []string{"paddingEnd", "paddingTrailing"} {
+		if v := l._prop(block, key); v != "" {
+			end = _parseNumber(v)
+		}
+	}
+	for _, key := range []string{"paddingStart", "paddingLeading"} {
+		if v := l._prop(block, key); v != "" {
+			start = _parseNumber(v)
+		}
+	}
+	return top, end, bottom, start
+}
+
+func (l *layouter) _sizeSpec(block model.BlockDSLModel, key string) sizeSpec {
+	if block.KeyType == "ROOT" {
+		return sizeSpec{kind: "fill"}
+	}
+	value := strings.TrimSpace(l._prop(block, key))
+	switch value {
+	case "match", "fill":
+		return sizeSpec{kind: "fill"}
+	case "", "wrap":
+		return sizeSpec{kind: "wrap"}
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		return sizeSpec{kind: "fixed", value: n}
+	}
+	return sizeSpec{kind: "wrap"}
+}
+
+func _resolveMax(spec sizeSpec, max float64) float64 {
+	if spec.kind == "fixed" {
+		return spec.value
+	}
+	return max
+}
+
+func _resolveSize(spec sizeSpec, max, content float64) float64 {
+	switch spec.kind {
+	case "fill":
+		return max
+	case "fixed":
+		return spec.value
+	default:
+		return math.Min(content, max)
+	}
+}
+
+func _measureText(text string, fontSize, maxW float64) (float64, float64) {
+	width := float64(utf8.RuneCountInString(text)) * fontSize * 0.55
+	lines := 1.0
+	if maxW > 0 && width > maxW {
+		lines = math.Ceil(width / maxW)
+		width = maxW
+	}
+	return width, lines * fontSize * 1.3
+}
+
+// _arrange returns the leading offset and the extra gap between children for a main-axis arrangement.
+func _arrange(arrangement string, free float64, count int) (float64, float64) {
+	if count == 0 || free <= 0 {
+		return 0, 0
+	}
+	n := float64(count)
+	switch arrangement {
+	case "center":
+		return free / 2, 0
+	case "end", "bottom":
+		return free, 0
+	case "spaceBetween":
+		if count == 1 {
+			return 0, 0
+		}
+		return 0, free / (n - 1)
+	case "spaceAround":
+		return free / n / 2, free / n
+	case "spaceEvenly":
+		return free / (n + 1), free / (n + 1)
+	default:
+		return 0, 0
+	}
+}
+
+func _align(alignment string, available, size float64) float64 {
+	switch alignment {
+	case "centerHorizontally", "centerVertically", "center":
+		return math.Max((available-size)/2, 0)
+	case "end", "bottom":
+		return math.Max(available-size, 0)
+	default:
+		return 0
+	}
+}
+
+func _translate(box *Box, x, y float64) {
+	box.X += x
+	box.Y += y
+	for _, child := range box.Children {
+		_translate(child, box.X, box.Y)
+	}
+}
+
+func _parseNumber(value string) float64 {
+	value = strings.TrimSpace(value)
+	value = strings.TrimRight(value, "fFdD")
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func _kindOf(keyType string) string {
+	switch keyType {
+	case "ROOT", "nativeblocks/vstack", "nativeblocks/column", "nativeblocks/lazy_column":
+		return kindColumn
+	case "nativeblocks/hstack", "nativeblocks/row", "nativeblocks/lazy_row":
+		return kindRow
+	case "nativeblocks/box":
+		return kindBox
+	case "nativeblocks/text":
+		return kindText
+	case "nativeblocks/image":
+		return kindImage
+	case "nativeblocks/button":
+		return kindButton
+	case "nativeblocks/spacer":
+		return kindSpacer
+	case "nativeblocks/text_field":
+		return kindInput
+	default:
+		return kindPlaceholder
+	}
+}
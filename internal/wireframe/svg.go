@@ -0,0 +1,97 @@
+package wireframe
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/preview"
+)
+
+// Document is the SVG wireframe of a frame for one device class.
+type Document struct {
+	Device preview.Device
+	SVG    string
+}
+
+// Render lays out the frame for the device class and returns it as an SVG document.
+// The output only depends on its input, so it is suitable for golden-file tests.
+func Render(frame model.FrameDSLModel, device preview.Device) string {
+	width, height := device.Width(), device.Height()
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		width, height, width, height))
+	builder.WriteString(fmt.Sprintf("  <title>%s (%s)</title>\n", html.EscapeString(frame.Name), device))
+	builder.WriteString(fmt.Sprintf("  <rect x=\"0\" y=\"0\" width=\"%d\" height=\"%d\" fill=\"#ffffff\" stroke=\"#111827\"/>\n", width, height))
+
+	for _, box := range Layout(frame, device) {
+		_writeBox(&builder, box, 1)
+	}
+
+	builder.WriteString("</svg>\n")
+	return builder.String()
+}
+
+// RenderAll renders one SVG document per device class, in mobile, tablet, desktop order.
+func RenderAll(frame model.FrameDSLModel) []Document {
+	documents := make([]Document, 0, len(preview.Devices))
+	for _, device := range preview.Devices {
+		documents = append(documents, Document{Device: device, SVG: Render(frame, device)})
+	}
+	return documents
+}
+
+func _writeBox(builder *strings.Builder, box *Box, indent int) {
+	ind := strings.Repeat("  ", indent)
+
+	builder.WriteString(fmt.Sprintf("%s<g data-key=\"%s\" data-key-type=\"%s\">\n",
+		ind, html.EscapeString(box.Block.Key), html.EscapeString(box.Block.KeyType)))
+
+	fill, stroke, dash := _boxStyle(box.Kind)
+	builder.WriteString(fmt.Sprintf("%s  <rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\" stroke=\"%s\"",
+		ind, _num(box.X), _num(box.Y), _num(box.Width), _num(box.Height), fill, stroke))
+	if dash {
+		builder.WriteString(" stroke-dasharray=\"4 2\"")
+	}
+	builder.WriteString("/>\n")
+
+	if box.Kind == kindImage {
+		builder.WriteString(fmt.Sprintf("%s  <line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\"/>\n",
+			ind, _num(box.X), _num(box.Y), _num(box.X+box.Width), _num(box.Y+box.Height), stroke))
+		builder.WriteString(fmt.Sprintf("%s  <line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\"/>\n",
+			ind, _num(box.X+box.Width), _num(box.Y), _num(box.X), _num(box.Y+box.Height), stroke))
+	}
+
+	if box.Label != "" && box.Kind != kindSpacer {
+		builder.WriteString(fmt.Sprintf("%s  <text x=\"%s\" y=\"%s\" font-family=\"monospace\" font-size=\"10\" fill=\"#374151\">%s</text>\n",
+			ind, _num(box.X+4), _num(box.Y+12), html.EscapeString(box.Label)))
+	}
+
+	for _, child := range box.Children {
+		_writeBox(builder, child, indent+1)
+	}
+
+	builder.WriteString(fmt.Sprintf("%s</g>\n", ind))
+}
+
+func _boxStyle(kind string) (fill, stroke string, dashed bool) {
+	switch kind {
+	case kindColumn, kindRow, kindBox:
+		return "none", "#2563EB", true
+	case kindPlaceholder:
+		return "#fef3c7", "#d97706", true
+	case kindSpacer:
+		return "none", "#d1d5db", true
+	default:
+		return "#f3f4f6", "#6b7280", false
+	}
+}
+
+// _num formats coordinates with at most two decimals so output is stable across platforms.
+func _num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,183 @@
+package wireframe
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nativeblocks/nbx/internal/lexer"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/parser"
+	"github.com/nativeblocks/nbx/internal/preview"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func _parseExample(t *testing.T, path string) model.FrameDSLModel {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := string(content)
+	p := parser.NewParser(lexer.NewLexer(source), source)
+	frame := p.ParseNBX()
+	if frame == nil || p.ErrorCollector().HasErrors() {
+		t.Fatalf("Failed to parse %s: %s", path, p.ErrorCollector().FormatAll())
+	}
+	return *frame
+}
+
+func TestRenderGolden(t *testing.T) {
+	frame := _parseExample(t, "../example/welcome_android.nbx")
+
+	for _, document := range RenderAll(frame) {
+		golden := filepath.Join("testdata", "welcome_"+string(document.Device)+".svg")
+
+		if *update {
+			if err := os.WriteFile(golden, []byte(document.SVG), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+		}
+		if string(expected) != document.SVG {
+			t.Errorf("%s does not match rendered output. Run 'go test ./internal/wireframe -update' if the change is intended", golden)
+		}
+	}
+}
+
+func TestLayoutSizes(t *testing.T) {
+	frame := model.FrameDSLModel{
+		Name: "sizes",
+		Blocks: []model.BlockDSLModel{
+			{
+				KeyType: "ROOT",
+				Key:     "root",
+				Blocks: []model.BlockDSLModel{
+					{
+						KeyType: "nativeblocks/column",
+						Key:     "column",
+						Properties: []model.BlockPropertyDSLModel{
+							{Key: "width", ValueMobile: "match", ValueTablet: "match", ValueDesktop: "match"},
+							{Key: "paddingTop", ValueMobile: "10", ValueTablet: "20", ValueDesktop: "30"},
+							{Key: "spacing", ValueMobile: "8", ValueTablet: "8", ValueDesktop: "8"},
+						},
+						Blocks: []model.BlockDSLModel{
+							{
+								KeyType: "nativeblocks/image",
+								Key:     "fixed",
+								Properties: []model.BlockPropertyDSLModel{
+									{Key: "width", ValueMobile: "100", ValueTablet: "200", ValueDesktop: "300"},
+									{Key: "height", ValueMobile: "50", ValueTablet: "50", ValueDesktop: "50"},
+								},
+							},
+							{KeyType: "nativeblocks/image", Key: "second"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		device      preview.Device
+		fixedWidth  float64
+		fixedY      float64
+		secondY     float64
+		columnWidth float64
+	}{
+		{preview.DeviceMobile, 100, 10, 68, 390},
+		{preview.DeviceTablet, 200, 20, 78, 820},
+		{preview.DeviceDesktop, 300, 30, 88, 1440},
+	}
+
+	for _, tt := range tests {
+		boxes := Layout(frame, tt.device)
+		column := boxes[0].Children[0]
+		fixed, second := column.Children[0], column.Children[1]
+
+		if column.Width != tt.columnWidth {
+			t.Errorf("%s: expected column width %v, got %v", tt.device, tt.columnWidth, column.Width)
+		}
+		if fixed.Width != tt.fixedWidth {
+			t.Errorf("%s: expected fixed width %v, got %v", tt.device, tt.fixedWidth, fixed.Width)
+		}
+		if fixed.Y != tt.fixedY {
+			t.Errorf("%s: expected padding to place first child at y=%v, got %v", tt.device, tt.fixedY, fixed.Y)
+		}
+		if second.Y != tt.secondY {
+			t.Errorf("%s: expected spacing to place second child at y=%v, got %v", tt.device, tt.secondY, second.Y)
+		}
+	}
+}
+
+func TestLayoutWeight(t *testing.T) {
+	frame := model.FrameDSLModel{
+		Blocks: []model.BlockDSLModel{
+			{
+				KeyType: "ROOT",
+				Key:     "root",
+				Blocks: []model.BlockDSLModel{
+					{
+						KeyType:    "nativeblocks/spacer",
+						Key:        "top",
+						Properties: []model.BlockPropertyDSLModel{{Key: "weight", ValueMobile: "0.25f"}},
+					},
+					{
+						KeyType:    "nativeblocks/spacer",
+						Key:        "bottom",
+						Properties: []model.BlockPropertyDSLModel{{Key: "weight", ValueMobile: "0.75f"}},
+					},
+				},
+			},
+		},
+	}
+
+	root := Layout(frame, preview.DeviceMobile)[0]
+	top, bottom := root.Children[0], root.Children[1]
+
+	if top.Height != 211 || bottom.Height != 633 {
+		t.Errorf("Expected weighted heights 211 and 633, got %v and %v", top.Height, bottom.Height)
+	}
+	if bottom.Y != 211 {
+		t.Errorf("Expected bottom spacer at y=211, got %v", bottom.Y)
+	}
+}
+
+func TestLayoutArrangementWrap(t *testing.T) {
+	_prop := func(key, value string) model.BlockPropertyDSLModel {
+		return model.BlockPropertyDSLModel{Key: key, ValueMobile: value}
+	}
+	_row := func(key, width string) model.BlockDSLModel {
+		return model.BlockDSLModel{
+			KeyType:    "nativeblocks/row",
+			Key:        key,
+			Properties: []model.BlockPropertyDSLModel{_prop("width", width), _prop("horizontalArrangement", "spaceBetween")},
+			Blocks: []model.BlockDSLModel{
+				{KeyType: "nativeblocks/spacer", Key: key + "A", Properties: []model.BlockPropertyDSLModel{_prop("width", "40")}},
+				{KeyType: "nativeblocks/spacer", Key: key + "B", Properties: []model.BlockPropertyDSLModel{_prop("width", "40")}},
+			},
+		}
+	}
+	frame := model.FrameDSLModel{
+		Blocks: []model.BlockDSLModel{
+			{KeyType: "ROOT", Key: "root", Blocks: []model.BlockDSLModel{_row("wrapped", "wrap"), _row("sized", "200")}},
+		},
+	}
+
+	root := Layout(frame, preview.DeviceMobile)[0]
+	wrapped, sized := root.Children[0], root.Children[1]
+
+	if wrapped.Width != 80 || wrapped.Children[1].X != wrapped.X+40 {
+		t.Errorf("Expected the children of a wrapped row to stay inside its 80 wide box, got width %v and x=%v",
+			wrapped.Width, wrapped.Children[1].X)
+	}
+	if sized.Width != 200 || sized.Children[1].X != sized.X+160 {
+		t.Errorf("Expected spaceBetween to push the last child to the end of a sized row, got x=%v", sized.Children[1].X)
+	}
+}
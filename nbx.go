@@ -1,6 +1,8 @@
 package nbx
 
 import (
+	"fmt"
+
 	"github.com/nativeblocks/nbx/internal/compiler"
 	"github.com/nativeblocks/nbx/internal/detector"
 	"github.com/nativeblocks/nbx/internal/errors"
@@ -14,17 +16,26 @@ import (
 type Error = errors.Error
 type Errors []Error
 
+const (
+	SeverityError   = errors.SeverityError
+	SeverityWarning = errors.SeverityWarning
+)
+
 type FrameJson = model.FrameJson
 type FrameDSLModel = model.FrameDSLModel
+type ToJSONOptions = compiler.Options
 
 // DSL model types
 type VariableDSLModel = model.VariableDSLModel
+type EnumDSLModel = model.EnumDSLModel
 type BlockDSLModel = model.BlockDSLModel
 type BlockPropertyDSLModel = model.BlockPropertyDSLModel
 type BlockDataDSLModel = model.BlockDataDSLModel
 type BlockSlotDSLModel = model.BlockSlotDSLModel
 type ActionDSLModel = model.ActionDSLModel
 type ActionTriggerDSLModel = model.ActionTriggerDSLModel
+type SequenceDSLModel = model.SequenceDSLModel
+type StyleDSLModel = model.StyleDSLModel
 type TriggerPropertyDSLModel = model.TriggerPropertyDSLModel
 type TriggerDataDSLModel = model.TriggerDataDSLModel
 
@@ -40,6 +51,21 @@ type ActionTriggerJson = model.ActionTriggerJson
 type TriggerPropertyJson = model.TriggerPropertyJson
 type TriggerDataJson = model.TriggerDataJson
 
+// FrameActionKey is the ActionJson key of actions declared on the frame rather than on a block.
+const FrameActionKey = model.FrameActionKey
+
+// ConditionKeyType is the keyType of the built-in trigger that if/else compiles to.
+const ConditionKeyType = model.ConditionKeyType
+
+// Integration registry types
+type IntegrationRegistry = validator.IntegrationRegistry
+type BlockIntegration = validator.BlockIntegration
+type ActionIntegration = validator.ActionIntegration
+type PropertyDefinition = validator.PropertyDefinition
+type DataDefinition = validator.DataDefinition
+type EventDefinition = validator.EventDefinition
+type SlotDefinition = validator.SlotDefinition
+
 // Parse parses NBX content with automatic format detection (DSL or XML).
 // It detects the format and delegates to ParseDSL or ParseXML accordingly.
 func Parse(content string) (FrameDSLModel, Errors) {
@@ -72,17 +98,21 @@ func ParseDSL(stringifyDsl string) (FrameDSLModel, Errors) {
 		return FrameDSLModel{}, _errorValueOf(errorCollector.Errors())
 	}
 
-	collector, _ := validator.ValidateWithSource(frame, stringifyDsl)
+	if len(frame.Imports) > 0 {
+		return FrameDSLModel{}, _unresolvedImports(frame.Imports)
+	}
+
+	issues, ok := _compile(frame, stringifyDsl)
+	if !ok {
+		return FrameDSLModel{}, issues
+	}
 
 	var all Errors
 	if errorCollector != nil {
 		all = append(all, _errorValueOf(errorCollector.Errors())...)
 		all = append(all, _errorValueOf(errorCollector.Warnings())...)
 	}
-	if collector != nil {
-		all = append(all, _errorValueOf(collector.Errors())...)
-		all = append(all, _errorValueOf(collector.Warnings())...)
-	}
+	all = append(all, issues...)
 
 	return *frame, all
 }
@@ -94,16 +124,20 @@ func ParseXML(xmlString string) (FrameDSLModel, Errors) {
 		return frame, _errorValueOf(errs)
 	}
 
-	// Run validation on the parsed frame
-	collector, _ := validator.ValidateWithSource(&frame, xmlString)
-
-	var all Errors
-	if collector != nil {
-		all = append(all, _errorValueOf(collector.Errors())...)
-		all = append(all, _errorValueOf(collector.Warnings())...)
+	if len(frame.Imports) > 0 {
+		return frame, _unresolvedImports(frame.Imports)
 	}
 
-	return frame, all
+	issues, _ := _compile(&frame, xmlString)
+	return frame, issues
+}
+
+// Expand returns a copy of frame with its constants resolved and its component instances expanded into
+// ordinary blocks, as ToJSON compiles it. Parse keeps the constants and components of the frame, so that
+// it formats back to its source.
+func Expand(frameDSL FrameDSLModel) (FrameDSLModel, Errors) {
+	expanded, _, errs := compiler.Expand(frameDSL)
+	return expanded, _errorValueOf(errs)
 }
 
 // DetectFormat detects whether the input is DSL, XML, or unknown format.
@@ -131,6 +165,26 @@ func ToJSON(frameDSL FrameDSLModel, blocksJSON, actionsJSON, frameID string) (Fr
 	return result, nil
 }
 
+// ToJSONWithOptions is ToJSON with options: with options.Theme, token references such as @color.primary
+// are resolved for each device, or only checked when options.KeepTokens is set. With options.Strings,
+// string resources such as @string.welcome.title are translated; without it they stay symbolic keys.
+func ToJSONWithOptions(frameDSL FrameDSLModel, blocksJSON, actionsJSON, frameID string, options ToJSONOptions) (FrameJson, Errors) {
+	result, err := compiler.ToJsonWithOptions(frameDSL, blocksJSON, actionsJSON, frameID, options)
+	if err != nil {
+		return FrameJson{}, _errorsOf(err)
+	}
+	return result, nil
+}
+
+// LoadIntegrations creates an IntegrationRegistry from the blocks.json and actions.json integration definitions.
+func LoadIntegrations(blocksJSON, actionsJSON string) (*IntegrationRegistry, Errors) {
+	registry, err := validator.LoadIntegrations(blocksJSON, actionsJSON)
+	if err != nil {
+		return nil, _errorsOf(err)
+	}
+	return registry, nil
+}
+
 // ToString converts a FrameDSLModel back to DSL string format.
 func ToString(frameDSL FrameDSLModel) string {
 	return compiler.ToString(frameDSL)
@@ -216,11 +270,66 @@ func (errs Errors) FormatAll() string {
 	return collector.FormatAll()
 }
 
+// HasErrors reports whether errs contains at least one issue with error severity.
+func (errs Errors) HasErrors() bool {
+	for _, e := range errs {
+		if e.Severity == errors.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
 // Format is an alias for FormatAll for convenience.
 func (errs Errors) Format() string {
 	return errs.FormatAll()
 }
 
+// _compile validates a parsed frame, with its constants resolved and its components expanded in a copy
+// so the frame still formats back to its source. It reports false when the frame cannot be expanded.
+func _compile(frame *model.FrameDSLModel, source string) (Errors, bool) {
+	expanded, expansions, errs := compiler.Expand(*frame)
+	if len(errs) > 0 {
+		collector := errors.NewErrorCollector(source)
+		for _, err := range errs {
+			collector.AddError(err)
+		}
+		return _errorValueOf(collector.Errors()), false
+	}
+
+	collector, _ := validator.ValidateWithSource(&expanded, source)
+	if collector == nil {
+		return nil, true
+	}
+	compiler.AnnotateExpansions(collector.AllIssues(), expansions)
+
+	var all Errors
+	all = append(all, _errorValueOf(collector.Errors())...)
+	all = append(all, _errorValueOf(collector.Warnings())...)
+	return all, true
+}
+
+func _unresolvedImports(imports []model.ImportDSLModel) Errors {
+	var errs Errors
+	for _, imp := range imports {
+		errs = append(errs, Error{
+			Severity:   errors.SeverityError,
+			Message:    fmt.Sprintf("Cannot resolve import '%s' without a file system", imp.Path),
+			Line:       imp.Line,
+			Column:     imp.Column,
+			Suggestion: "Use ParseFS to parse frames with imports",
+		})
+	}
+	return errs
+}
+
+func _errorsOf(err error) Errors {
+	return Errors{{
+		Severity: errors.SeverityError,
+		Message:  err.Error(),
+	}}
+}
+
 func _errorValueOf(items []*Error) Errors {
 	out := make(Errors, 0, len(items))
 	for _, e := range items {
@@ -0,0 +1,136 @@
+package nbx
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const componentFrame = `frame(
+    name = "profile",
+    route = "/profile"
+) {
+    var userName: STRING = "Ada"
+
+    component(name = "card") {
+        prop title: STRING = "Untitled"
+        data label
+
+        block(keyType = "nativeblocks/column", key = "card")
+        .slot("content") {
+            block(keyType = "nativeblocks/text", key = "title")
+            .prop(
+                text = "{prop:title}"
+            )
+            .data(text = label)
+        }
+    }
+
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(component = "card", key = "userCard")
+        .prop(
+            title = "Profile"
+        )
+        .data(label = userName)
+    }
+}`
+
+func TestParseKeepsComponents(t *testing.T) {
+	frame, errs := ParseDSL(componentFrame)
+	if errs.HasErrors() {
+		t.Fatalf("Failed to parse: %s", errs.FormatAll())
+	}
+	if len(frame.Components) != 1 || frame.Blocks[0].Blocks[0].Component != "card" {
+		t.Fatalf("Expected the component and its instance to be kept, got %+v", frame)
+	}
+	if formatted := FormatFrameDSL(frame); formatted != componentFrame {
+		t.Errorf("Expected the parsed frame to format back to its source, got:\n%s", formatted)
+	}
+
+	expanded, errs := Expand(frame)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected expansion errors: %s", errs.FormatAll())
+	}
+	if len(expanded.Components) != 0 || expanded.Blocks[0].Blocks[0].KeyType != "nativeblocks/column" {
+		t.Errorf("Expected the instance to expand into the component's blocks, got %+v", expanded.Blocks[0].Blocks[0])
+	}
+	if len(frame.Components) != 1 {
+		t.Errorf("Expected Expand to leave the frame unchanged")
+	}
+
+	html, errs := PreviewHTML(frame, "mobile")
+	if len(errs) > 0 || !strings.Contains(html, `data-key="userCard_title"`) {
+		t.Errorf("Expected the preview to render the expanded instance, got errors %v", errs)
+	}
+
+	_, errs = ParseDSL(strings.Replace(componentFrame, ".data(text = label)", ".data(text = missing)", 1))
+	if !errs.HasErrors() || !strings.Contains(errs.FormatAll(), "In component 'card' instantiated as 'userCard'") {
+		t.Errorf("Expected errors inside the component to name the instance, got %s", errs.FormatAll())
+	}
+}
+
+const importingFrame = `frame(
+    name = "home",
+    route = "/home"
+) {
+    import "shared.nbx"
+
+    const greeting: STRING = "Welcome"
+
+    var title: STRING = "Home"
+
+    block(keyType = "ROOT", key = "root")
+    .slot("content") {
+        block(keyType = "nativeblocks/text", key = "header")
+        .prop(
+            text = "{const:greeting}",
+            color = "{const:primary}"
+        )
+        .data(text = theme)
+    }
+}`
+
+func TestParseFSKeepsImports(t *testing.T) {
+	fsys := fstest.MapFS{
+		"home.nbx": {Data: []byte(importingFrame)},
+		"shared.nbx": {Data: []byte(`library {
+    const primary: STRING = "#0A84FF"
+    var theme: STRING = "light"
+}`)},
+	}
+
+	frame, errs := ParseFS(fsys, "home.nbx")
+	if errs.HasErrors() {
+		t.Fatalf("Failed to parse: %s", errs.FormatAll())
+	}
+	if len(frame.Imports) != 1 || len(frame.Constants) != 2 {
+		t.Fatalf("Expected the imports and constants to be kept, got %+v and %+v", frame.Imports, frame.Constants)
+	}
+	if formatted := FormatFrameDSL(frame); formatted != importingFrame {
+		t.Errorf("Expected the frame to format back to its source without the library declarations, got:\n%s", formatted)
+	}
+
+	expanded, errs := Expand(frame)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected expansion errors: %s", errs.FormatAll())
+	}
+	if header := expanded.Blocks[0].Blocks[0]; header.Properties[0].ValueMobile != "Welcome" || header.Properties[1].ValueMobile != "#0A84FF" {
+		t.Errorf("Expected Expand to resolve the constants, got %+v", header.Properties)
+	}
+}
+
+func TestWireframeSVGsReportsExpansionErrors(t *testing.T) {
+	frame, errs := ParseDSL(componentFrame)
+	if errs.HasErrors() {
+		t.Fatalf("Failed to parse: %s", errs.FormatAll())
+	}
+	if svgs, errs := WireframeSVGs(frame); len(errs) > 0 || len(svgs) != 3 {
+		t.Fatalf("Expected a wireframe per device, got %d with errors %v", len(svgs), errs)
+	}
+
+	frame.Blocks[0].Blocks[0].Component = "missing"
+	if svgs, errs := WireframeSVGs(frame); !errs.HasErrors() || svgs != nil {
+		t.Errorf("Expected the expansion error to be returned instead of wireframes, got %d with errors %v", len(svgs), errs)
+	}
+}
@@ -0,0 +1,32 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/patch"
+)
+
+type FramePatch = patch.Patch
+type PatchOp = patch.Op
+type MergeConflict = patch.Conflict
+
+// MakePatch returns the structural patch that turns frame a into frame b. Patches marshal to JSON.
+func MakePatch(a, b FrameDSLModel) FramePatch {
+	return patch.Make(a, b)
+}
+
+// PatchFromDiff builds a patch from the semantic diff of two frames, where b is the newer frame.
+func PatchFromDiff(changes FrameChanges, b FrameDSLModel) FramePatch {
+	return patch.FromDiff(changes, b)
+}
+
+// ApplyPatch applies a patch to the frame. The frame is only changed when every op applies; an op fails
+// when its target is missing or a changed field no longer holds the value the patch expects.
+func ApplyPatch(frame *FrameDSLModel, p FramePatch) Errors {
+	return _errorValueOf(patch.Apply(frame, p))
+}
+
+// Merge performs a three-way merge of ours and theirs against their common base. Non-overlapping changes
+// to blocks, props, data and triggers are combined. True conflicts keep our side and are returned with
+// their paths.
+func Merge(base, ours, theirs FrameDSLModel) (FrameDSLModel, []MergeConflict) {
+	return patch.Merge(base, ours, theirs)
+}
@@ -0,0 +1,36 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/preview"
+)
+
+// Preview types
+type PreviewRenderer = preview.Renderer
+type PreviewBlockContext = preview.BlockContext
+type PreviewBlockRenderer = preview.BlockRenderer
+
+// NewPreviewRenderer creates an HTML preview renderer for the given device class ("mobile", "tablet" or "desktop").
+// Use Register on the returned renderer to plug in custom block-to-HTML mappings.
+func NewPreviewRenderer(device string) (*PreviewRenderer, Errors) {
+	d, err := preview.DeviceFromString(device)
+	if err != nil {
+		return nil, _errorsOf(err)
+	}
+	return preview.NewRenderer(d), nil
+}
+
+// PreviewHTML renders a FrameDSLModel into a standalone HTML page for the given device class, with its
+// components expanded. Common nativeblocks blocks are mapped to flexbox elements; unknown blocks render as
+// labelled placeholders. A renderer from NewPreviewRenderer renders the frame as given, so expand it first
+// with Expand.
+func PreviewHTML(frameDSL FrameDSLModel, device string) (string, Errors) {
+	renderer, errs := NewPreviewRenderer(device)
+	if errs != nil {
+		return "", errs
+	}
+	expanded, errs := Expand(frameDSL)
+	if len(errs) > 0 {
+		return "", errs
+	}
+	return renderer.Render(expanded), nil
+}
@@ -0,0 +1,40 @@
+package nbx
+
+import (
+	"io/fs"
+
+	"github.com/nativeblocks/nbx/internal/errors"
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/project"
+)
+
+type Project = project.Project
+type ProjectFrame = project.Frame
+type NavigationEdge = project.Edge
+
+// LoadProject parses every frame in fsys with ParseFS and checks them as one app: frame names and routes
+// must be unique and must not conflict (/user/{id} and /user/{name}), exactly one frame must be marked
+// starter = true, and navigation triggers (NAVIGATE, nativeblocks/navigate, ...) must lead to a frame
+// through their route property. Frames that cannot be reached from the starter are reported as warnings.
+// When fsys has blocks.json or actions.json at its root, every frame is validated against them; with a
+// theme.json, token references are checked, and with locale bundles in an i18n directory, every string
+// resource must be translated in every locale.
+//
+// The project is returned with the frames that parsed, together with all errors found.
+func LoadProject(fsys fs.FS) (*Project, Errors) {
+	p, errs := project.Load(fsys, func(fsys fs.FS, name string) (model.FrameDSLModel, []*errors.Error) {
+		frame, errs := ParseFS(fsys, name)
+		issues := make([]*errors.Error, len(errs))
+		for i := range errs {
+			issues[i] = &errs[i]
+		}
+		return frame, issues
+	})
+	return p, _errorValueOf(errs)
+}
+
+// NewProject creates a project from frames that are already parsed and builds its navigation graph.
+// registry may be nil. Use Project.Check to validate it.
+func NewProject(frames []ProjectFrame, registry *IntegrationRegistry) *Project {
+	return project.New(frames, registry)
+}
@@ -0,0 +1,31 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/query"
+)
+
+type QueryMatch = query.Match
+type Selector = query.Selector
+
+// Query returns the nodes of the frame matching a CSS-like selector, in document order, with their
+// source positions. For example:
+//
+//	block[keyType="nativeblocks/text"] > .slot("content") block
+//	trigger[then=FAILURE]
+//	trigger[keyType="nativeblocks/change_block_property"][prop.color]
+func Query(frame *FrameDSLModel, selector string) ([]QueryMatch, Errors) {
+	matches, err := query.Query(frame, selector)
+	if err != nil {
+		return nil, _errorsOf(err)
+	}
+	return matches, nil
+}
+
+// CompileSelector parses a selector once so it can be matched against many frames with Selector.Match.
+func CompileSelector(selector string) (*Selector, Errors) {
+	compiled, err := query.Compile(selector)
+	if err != nil {
+		return nil, _errorsOf(err)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,35 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/refactor"
+)
+
+type ExtractedBlock = refactor.Extracted
+
+// RenameVariable renames a variable and updates every reference to it: visibility keys, block and trigger
+// data bindings and {var:name} placeholders. The frame is only changed when the result validates.
+func RenameVariable(frame *FrameDSLModel, oldKey, newKey string) Errors {
+	return _errorValueOf(refactor.RenameVariable(frame, oldKey, newKey))
+}
+
+// RenameBlockKey renames a block and updates its actions and the blockKey property of triggers referring
+// to it. The frame is only changed when the result validates.
+func RenameBlockKey(frame *FrameDSLModel, oldKey, newKey string) Errors {
+	return _errorValueOf(refactor.RenameBlockKey(frame, oldKey, newKey))
+}
+
+// MoveBlock moves a block and its subtree into the given slot of newParentKey at index among the parent's
+// child blocks; -1 appends. The frame is only changed when the result validates.
+func MoveBlock(frame *FrameDSLModel, key, newParentKey, slot string, index int) Errors {
+	return _errorValueOf(refactor.MoveBlock(frame, key, newParentKey, slot, index))
+}
+
+// ExtractBlock removes a block and its subtree from the frame and returns it with the variables it uses.
+// It refuses when another block still refers to the extracted blocks.
+func ExtractBlock(frame *FrameDSLModel, key string) (ExtractedBlock, Errors) {
+	extracted, errs := refactor.ExtractBlock(frame, key)
+	if len(errs) > 0 {
+		return ExtractedBlock{}, _errorValueOf(errs)
+	}
+	return extracted, nil
+}
@@ -0,0 +1,33 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/model"
+	"github.com/nativeblocks/nbx/internal/theme"
+)
+
+type Theme = theme.Theme
+type ThemeToken = theme.Token
+
+// LoadTheme reads a JSON theme file of design tokens grouped by kind, for example
+// {"color": {"primary": "#2563EB"}, "spacing": {"md": {"mobile": "12", "desktop": "16"}}}. Block
+// properties reference tokens as @color.primary, alone or in a mobile/tablet/desktop triple.
+func LoadTheme(themeJSON string) (*Theme, Errors) {
+	t, err := theme.Parse(themeJSON)
+	if err != nil {
+		return nil, _errorsOf(err)
+	}
+	return t, nil
+}
+
+// ValidateTokens reports the token references in the block properties of frame that t does not declare,
+// with the closest token names as suggestions. The frame is not modified.
+func ValidateTokens(frame FrameDSLModel, t *Theme) Errors {
+	clone := model.CloneFrame(frame)
+	return _errorValueOf(theme.Resolve(&clone, t, true))
+}
+
+// ResolveTokens replaces the token references in the block properties of frame with their values for
+// each device. Unknown tokens are reported and left in place.
+func ResolveTokens(frame *FrameDSLModel, t *Theme) Errors {
+	return _errorValueOf(theme.Resolve(frame, t, false))
+}
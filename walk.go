@@ -0,0 +1,36 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/walker"
+)
+
+type Node = walker.Node
+type NodeKind = walker.Kind
+type Visitor = walker.Visitor
+type WalkResult = walker.Result
+
+const (
+	NodeFrame    = walker.KindFrame
+	NodeVariable = walker.KindVariable
+	NodeBlock    = walker.KindBlock
+	NodeSlot     = walker.KindSlot
+	NodeProperty = walker.KindProperty
+	NodeData     = walker.KindData
+	NodeAction   = walker.KindAction
+	NodeSequence = walker.KindSequence
+	NodeTrigger  = walker.KindTrigger
+)
+
+const (
+	WalkContinue     = walker.Continue
+	WalkSkipChildren = walker.SkipChildren
+	WalkStop         = walker.Stop
+)
+
+// Walk visits every node of the frame depth-first, calling visitor.Enter before and visitor.Leave after
+// a node's children. Callbacks receive the parent chain and return WalkContinue, WalkSkipChildren or
+// WalkStop. Nodes point into frame, so callbacks can modify it in place. Walk reports whether the walk
+// ran to completion.
+func Walk(frame *FrameDSLModel, visitor Visitor) bool {
+	return walker.Walk(frame, visitor)
+}
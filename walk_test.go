@@ -0,0 +1,38 @@
+package nbx
+
+import "testing"
+
+func TestWalkSequences(t *testing.T) {
+	frame, errs := ParseDSL(`frame(name = "form", route = "/form") {
+    var status: STRING = "idle"
+    sequence(name = "reset") {
+        trigger(keyType = "nativeblocks/change_variable", name = "clear")
+            .data(variableKey = status)
+    }
+    block(keyType = "ROOT", key = "root")
+}`)
+	if errs.HasErrors() {
+		t.Fatalf("Failed to parse: %s", errs.FormatAll())
+	}
+
+	var sequences []string
+	var triggerParent NodeKind
+	Walk(&frame, Visitor{
+		Enter: func(node *Node, parents []*Node) WalkResult {
+			switch node.Kind {
+			case NodeSequence:
+				sequences = append(sequences, node.Sequence.Name)
+			case NodeTrigger:
+				triggerParent = parents[len(parents)-1].Kind
+			}
+			return WalkContinue
+		},
+	})
+
+	if len(sequences) != 1 || sequences[0] != "reset" {
+		t.Errorf("Expected the sequence 'reset' to be visited, got %v", sequences)
+	}
+	if triggerParent != NodeSequence {
+		t.Errorf("Expected the trigger to be visited under its sequence, got parent %q", triggerParent)
+	}
+}
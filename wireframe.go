@@ -0,0 +1,35 @@
+package nbx
+
+import (
+	"github.com/nativeblocks/nbx/internal/preview"
+	"github.com/nativeblocks/nbx/internal/wireframe"
+)
+
+// WireframeSVG lays out the frame's block tree, with its components expanded, for the given device class
+// ("mobile", "tablet" or "desktop") and returns it as a deterministic SVG document.
+func WireframeSVG(frameDSL FrameDSLModel, device string) (string, Errors) {
+	d, err := preview.DeviceFromString(device)
+	if err != nil {
+		return "", _errorsOf(err)
+	}
+	expanded, errs := Expand(frameDSL)
+	if len(errs) > 0 {
+		return "", errs
+	}
+	return wireframe.Render(expanded, d), nil
+}
+
+// WireframeSVGs returns one SVG wireframe per device class, keyed by "mobile", "tablet" and "desktop".
+// Nothing is rendered when the frame's components cannot be expanded.
+func WireframeSVGs(frameDSL FrameDSLModel) (map[string]string, Errors) {
+	expanded, errs := Expand(frameDSL)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	documents := wireframe.RenderAll(expanded)
+	result := make(map[string]string, len(documents))
+	for _, document := range documents {
+		result[string(document.Device)] = document.SVG
+	}
+	return result, nil
+}